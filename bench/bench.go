@@ -0,0 +1,152 @@
+// Package bench measures the throughput of the transforms over a fixed ledger range so that
+// performance changes can be compared between builds.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// Result holds the measurements of a single benchmark
+type Result struct {
+	Name          string        `json:"name"`
+	Items         int           `json:"items"`
+	Duration      time.Duration `json:"duration"`
+	ItemsPerSec   float64       `json:"items_per_sec"`
+	AllocsPerItem float64       `json:"allocs_per_item"`
+	EncodedBytes  int           `json:"encoded_bytes"`
+	EncodedMBPerS float64       `json:"encoded_mb_per_sec"`
+}
+
+// Measure runs fn once and records its duration and allocations. fn processes items entries and returns the
+// number of bytes it encoded.
+func Measure(name string, items int, fn func() (int, error)) (Result, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	encodedBytes, err := fn()
+	if err != nil {
+		return Result{}, fmt.Errorf("benchmark %s failed: %v", name, err)
+	}
+
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result := Result{
+		Name:         name,
+		Items:        items,
+		Duration:     duration,
+		EncodedBytes: encodedBytes,
+	}
+
+	if seconds := duration.Seconds(); seconds > 0 {
+		result.ItemsPerSec = float64(items) / seconds
+		result.EncodedMBPerS = float64(encodedBytes) / (1 << 20) / seconds
+	}
+
+	if items > 0 {
+		result.AllocsPerItem = float64(after.Mallocs-before.Mallocs) / float64(items)
+	}
+
+	return result, nil
+}
+
+// Ledgers measures TransformLedger and the JSON encoding of its output
+func Ledgers(ledgers []utils.HistoryArchiveLedgerAndLCM) (Result, error) {
+	return Measure("ledgers", len(ledgers), func() (int, error) {
+		encodedBytes := 0
+		for _, ledger := range ledgers {
+			transformed, err := transform.TransformLedger(ledger.Ledger, ledger.LCM)
+			if err != nil {
+				return 0, err
+			}
+			n, err := encodedSize(transformed)
+			if err != nil {
+				return 0, err
+			}
+			encodedBytes += n
+		}
+		return encodedBytes, nil
+	})
+}
+
+// Transactions measures TransformTransaction and the JSON encoding of its output
+func Transactions(transactions []input.LedgerTransformInput) (Result, error) {
+	return Measure("transactions", len(transactions), func() (int, error) {
+		encodedBytes := 0
+		for _, transformInput := range transactions {
+			transformed, err := transform.TransformTransaction(transformInput.Transaction, transformInput.LedgerHistory)
+			if err != nil {
+				return 0, err
+			}
+			n, err := encodedSize(transformed)
+			if err != nil {
+				return 0, err
+			}
+			encodedBytes += n
+		}
+		return encodedBytes, nil
+	})
+}
+
+// Operations measures TransformOperation and the JSON encoding of its output
+func Operations(operations []input.OperationTransformInput, networkPassphrase string) (Result, error) {
+	return Measure("operations", len(operations), func() (int, error) {
+		encodedBytes := 0
+		for _, transformInput := range operations {
+			transformed, err := transform.TransformOperation(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, networkPassphrase)
+			if err != nil {
+				return 0, err
+			}
+			n, err := encodedSize(transformed)
+			if err != nil {
+				return 0, err
+			}
+			encodedBytes += n
+		}
+		return encodedBytes, nil
+	})
+}
+
+func encodedSize(entry interface{}) (int, error) {
+	marshalled, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	return len(marshalled), nil
+}
+
+// Regressions compares the current results with a baseline and describes every benchmark whose throughput
+// dropped, or whose allocations per item grew, by more than tolerance (0.1 allows a 10% change)
+func Regressions(baseline, current []Result, tolerance float64) []string {
+	baselineByName := map[string]Result{}
+	for _, result := range baseline {
+		baselineByName[result.Name] = result
+	}
+
+	regressions := []string{}
+	for _, result := range current {
+		previous, ok := baselineByName[result.Name]
+		if !ok {
+			continue
+		}
+
+		if previous.ItemsPerSec > 0 && result.ItemsPerSec < previous.ItemsPerSec*(1-tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s: throughput dropped from %.1f to %.1f items/sec", result.Name, previous.ItemsPerSec, result.ItemsPerSec))
+		}
+
+		if previous.AllocsPerItem > 0 && result.AllocsPerItem > previous.AllocsPerItem*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s: allocations grew from %.1f to %.1f per item", result.Name, previous.AllocsPerItem, result.AllocsPerItem))
+		}
+	}
+
+	return regressions
+}
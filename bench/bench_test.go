@@ -0,0 +1,104 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasure(t *testing.T) {
+	result, err := Measure("test", 10, func() (int, error) {
+		return 2048, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", result.Name)
+	assert.Equal(t, 10, result.Items)
+	assert.Equal(t, 2048, result.EncodedBytes)
+
+	_, err = Measure("failing", 1, func() (int, error) {
+		return 0, fmt.Errorf("transform error")
+	})
+	assert.Equal(t, fmt.Errorf("benchmark failing failed: transform error"), err)
+}
+
+func TestRegressions(t *testing.T) {
+	baseline := []Result{
+		{Name: "transactions", ItemsPerSec: 1000, AllocsPerItem: 100},
+		{Name: "operations", ItemsPerSec: 1000, AllocsPerItem: 100},
+	}
+	current := []Result{
+		{Name: "transactions", ItemsPerSec: 950, AllocsPerItem: 105},
+		{Name: "operations", ItemsPerSec: 800, AllocsPerItem: 150},
+		{Name: "ledgers", ItemsPerSec: 10, AllocsPerItem: 10},
+	}
+
+	assert.Equal(t, []string{
+		"operations: throughput dropped from 1000.0 to 800.0 items/sec",
+		"operations: allocations grew from 100.0 to 150.0 per item",
+	}, Regressions(baseline, current, 0.1))
+}
+
+func TestNewFixture(t *testing.T) {
+	fixture, err := NewFixture(3, 4, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Len(t, fixture.Ledgers, 3)
+	assert.Len(t, fixture.Transactions, 12)
+	assert.Len(t, fixture.Operations, 12)
+	assert.Equal(t, int32(FixtureStartLedger+2), fixture.Operations[11].LedgerSeqNum)
+
+	for _, run := range []func() (Result, error){
+		func() (Result, error) { return Ledgers(fixture.Ledgers) },
+		func() (Result, error) { return Transactions(fixture.Transactions) },
+		func() (Result, error) { return Operations(fixture.Operations, network.TestNetworkPassphrase) },
+	} {
+		result, err := run()
+		assert.NoError(t, err)
+		assert.Greater(t, result.EncodedBytes, 0)
+	}
+
+	ledger, err := transform.TransformLedger(fixture.Ledgers[0].Ledger, fixture.Ledgers[0].LCM)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), ledger.SuccessfulTransactionCount)
+	assert.Equal(t, int32(4), ledger.OperationCount)
+}
+
+func mustFixture(b *testing.B) Fixture {
+	fixture, err := NewFixture(8, 50, network.TestNetworkPassphrase)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return fixture
+}
+
+// The benchmarks below run the transforms over the fixture range, e.g. with go test ./bench -run '^$' -bench .
+// Every iteration transforms and encodes the whole range, and the bytes reported are the encoded JSON.
+
+func BenchmarkTransformLedger(b *testing.B) {
+	fixture := mustFixture(b)
+	benchmarkRange(b, func() (Result, error) { return Ledgers(fixture.Ledgers) })
+}
+
+func BenchmarkTransformTransaction(b *testing.B) {
+	fixture := mustFixture(b)
+	benchmarkRange(b, func() (Result, error) { return Transactions(fixture.Transactions) })
+}
+
+func BenchmarkTransformOperation(b *testing.B) {
+	fixture := mustFixture(b)
+	benchmarkRange(b, func() (Result, error) { return Operations(fixture.Operations, network.TestNetworkPassphrase) })
+}
+
+func benchmarkRange(b *testing.B, run func() (Result, error)) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := run()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(result.EncodedBytes))
+	}
+}
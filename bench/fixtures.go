@@ -0,0 +1,160 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// FixtureStartLedger is the sequence of the first ledger of the fixture range
+const FixtureStartLedger = 30000000
+
+// Fixture holds the transform inputs of a fixture ledger range
+type Fixture struct {
+	Ledgers      []utils.HistoryArchiveLedgerAndLCM
+	Transactions []input.LedgerTransformInput
+	Operations   []input.OperationTransformInput
+}
+
+// NewFixture builds a range of ledgers, each with transactionsPerLedger successful payments, and the transform
+// inputs read from them. The ledgers only depend on the arguments, so every build benchmarks the same data.
+func NewFixture(ledgerCount, transactionsPerLedger int, networkPassphrase string) (Fixture, error) {
+	fixture := Fixture{}
+	for i := 0; i < ledgerCount; i++ {
+		lcm, err := fixtureLedger(uint32(FixtureStartLedger+i), transactionsPerLedger, networkPassphrase)
+		if err != nil {
+			return Fixture{}, err
+		}
+
+		fixture.Ledgers = append(fixture.Ledgers, utils.HistoryArchiveLedgerAndLCM{
+			Ledger: input.HistoryArchiveLedger(lcm),
+			LCM:    lcm,
+		})
+
+		txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, lcm)
+		if err != nil {
+			return Fixture{}, err
+		}
+
+		for {
+			tx, err := txReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return Fixture{}, err
+			}
+
+			fixture.Transactions = append(fixture.Transactions, input.LedgerTransformInput{
+				Transaction:     tx,
+				LedgerHistory:   txReader.GetHeader(),
+				LedgerCloseMeta: lcm,
+			})
+
+			for index, op := range tx.Envelope.Operations() {
+				fixture.Operations = append(fixture.Operations, input.OperationTransformInput{
+					Operation:       op,
+					OperationIndex:  int32(index),
+					Transaction:     tx,
+					LedgerSeqNum:    int32(lcm.LedgerSequence()),
+					LedgerCloseMeta: lcm,
+				})
+			}
+		}
+		txReader.Close()
+	}
+
+	return fixture, nil
+}
+
+func fixtureAccount(name string) xdr.MuxedAccount {
+	return xdr.MustMuxedAddress(keypair.Master(name).Address())
+}
+
+func fixtureLedger(sequence uint32, transactionCount int, networkPassphrase string) (xdr.LedgerCloseMeta, error) {
+	envelopes := []xdr.TransactionEnvelope{}
+	processing := []xdr.TransactionResultMeta{}
+	for i := 0; i < transactionCount; i++ {
+		source := fixtureAccount(fmt.Sprintf("bench source %d", i))
+		envelope := xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source,
+					Fee:           100,
+					SeqNum:        xdr.SequenceNumber(int64(sequence)<<32 | int64(i)),
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypePayment,
+								PaymentOp: &xdr.PaymentOp{
+									Destination: fixtureAccount(fmt.Sprintf("bench destination %d", i)),
+									Asset:       xdr.MustNewNativeAsset(),
+									Amount:      xdr.Int64(10000000 + i),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		hash, err := network.HashTransactionInEnvelope(envelope, networkPassphrase)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, err
+		}
+
+		results := []xdr.OperationResult{
+			{
+				Code: xdr.OperationResultCodeOpInner,
+				Tr: &xdr.OperationResultTr{
+					Type:          xdr.OperationTypePayment,
+					PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+				},
+			},
+		}
+
+		envelopes = append(envelopes, envelope)
+		processing = append(processing, xdr.TransactionResultMeta{
+			Result: xdr.TransactionResultPair{
+				TransactionHash: hash,
+				Result: xdr.TransactionResult{
+					FeeCharged: 100,
+					Result: xdr.TransactionResultResult{
+						Code:    xdr.TransactionResultCodeTxSuccess,
+						Results: &results,
+					},
+				},
+			},
+			TxApplyProcessing: xdr.TransactionMeta{
+				V:  1,
+				V1: &xdr.TransactionMetaV1{Operations: []xdr.OperationMeta{{}}},
+			},
+		})
+	}
+
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					LedgerVersion: 20,
+					LedgerSeq:     xdr.Uint32(sequence),
+					ScpValue:      xdr.StellarValue{CloseTime: xdr.TimePoint(1700000000 + 5*int64(sequence-FixtureStartLedger))},
+					TotalCoins:    1000000000000000000,
+					BaseFee:       100,
+					BaseReserve:   5000000,
+				},
+			},
+			TxSet:        xdr.TransactionSet{Txs: envelopes},
+			TxProcessing: processing,
+		},
+	}, nil
+}
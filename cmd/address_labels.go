@@ -0,0 +1,30 @@
+package cmd
+
+import "github.com/stellar/stellar-etl/internal/transform"
+
+// addAddressLabels adds a <column>_label column next to every address column of a decoded entry, including the ones
+// nested in operation and effect details, holding the label of the address in the address labels file. Address columns
+// are the ones transform.IsAddressColumn reports. The label is null for addresses the file does not list.
+func addAddressLabels(i map[string]interface{}, labels map[string]string) {
+	for k, v := range i {
+		if nested, ok := v.(map[string]interface{}); ok {
+			addAddressLabels(nested, labels)
+			continue
+		}
+
+		if !transform.IsAddressColumn(k) {
+			continue
+		}
+
+		address, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		if label, ok := labels[address]; ok {
+			i[k+"_label"] = label
+		} else {
+			i[k+"_label"] = nil
+		}
+	}
+}
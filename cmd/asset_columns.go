@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/stellar/go/xdr"
+)
+
+// forEachAssetColumns calls fn with every map of a decoded entry holding a <prefix>asset_type column, and the prefix of
+// the column. The maps nested in operation and effect details and in lists, such as the path of path payments, are
+// walked too. fn can add columns to the map it is called with.
+func forEachAssetColumns(i map[string]interface{}, fn func(columns map[string]interface{}, prefix string)) {
+	prefixes := []string{}
+	for k, v := range i {
+		switch nested := v.(type) {
+		case map[string]interface{}:
+			forEachAssetColumns(nested, fn)
+		case []interface{}:
+			for _, element := range nested {
+				if nestedElement, ok := element.(map[string]interface{}); ok {
+					forEachAssetColumns(nestedElement, fn)
+				}
+			}
+		default:
+			if strings.HasSuffix(k, "asset_type") {
+				prefixes = append(prefixes, strings.TrimSuffix(k, "asset_type"))
+			}
+		}
+	}
+
+	for _, prefix := range prefixes {
+		fn(i, prefix)
+	}
+}
+
+// isNativeAssetType reports whether an asset_type column holds the type of lumens, whichever way the table writes it
+func isNativeAssetType(assetType interface{}) bool {
+	switch value := assetType.(type) {
+	case string:
+		return value == "native" || value == xdr.AssetTypeAssetTypeNative.String()
+	case json.Number:
+		return value.String() == "0"
+	}
+	return false
+}
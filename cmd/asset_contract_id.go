@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// addAssetContractIDs adds a <prefix>asset_contract_id column next to every <prefix>asset_type column of a decoded entry,
+// including the ones nested in operation and effect details and in lists such as the path of path payments. It holds
+// the id of the Stellar Asset Contract of the asset on the network of the passphrase, so that classic and Soroban rows
+// can be joined on it. Pool shares have no contract.
+func addAssetContractIDs(i map[string]interface{}, passphrase string) {
+	forEachAssetColumns(i, func(columns map[string]interface{}, prefix string) {
+		assetType, _ := columns[prefix+"asset_type"].(string)
+		code, _ := columns[prefix+"asset_code"].(string)
+		issuer, _ := columns[prefix+"asset_issuer"].(string)
+		if contractID, ok := assetContractID(assetType, code, issuer, passphrase); ok {
+			columns[prefix+"asset_contract_id"] = contractID
+		}
+	})
+}
+
+// assetContractID returns the strkey encoded id of the Stellar Asset Contract of a classic asset
+func assetContractID(assetType, code, issuer, passphrase string) (string, bool) {
+	asset, err := xdr.BuildAsset(assetType, issuer, code)
+	if err != nil {
+		return "", false
+	}
+
+	contractID, err := asset.ContractID(passphrase)
+	if err != nil {
+		return "", false
+	}
+
+	encoded, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+	if err != nil {
+		return "", false
+	}
+	return encoded, true
+}
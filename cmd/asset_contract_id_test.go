@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+)
+
+// xlmPubnetContractID is the id of the Stellar Asset Contract of native XLM on pubnet
+const xlmPubnetContractID = "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA"
+
+func TestAddAssetContractIDs(t *testing.T) {
+	row := map[string]interface{}{
+		"asset_type": "native",
+		"details": map[string]interface{}{
+			"source_asset_type": "native",
+			"path": []interface{}{
+				map[string]interface{}{"asset_type": "native"},
+				map[string]interface{}{"asset_type": "liquidity_pool_shares"},
+			},
+		},
+	}
+
+	addAssetContractIDs(row, network.PublicNetworkPassphrase)
+	assert.Equal(t, map[string]interface{}{
+		"asset_type":        "native",
+		"asset_contract_id": xlmPubnetContractID,
+		"details": map[string]interface{}{
+			"source_asset_type":        "native",
+			"source_asset_contract_id": xlmPubnetContractID,
+			"path": []interface{}{
+				map[string]interface{}{"asset_type": "native", "asset_contract_id": xlmPubnetContractID},
+				// pool shares have no contract
+				map[string]interface{}{"asset_type": "liquidity_pool_shares"},
+			},
+		},
+	}, row)
+}
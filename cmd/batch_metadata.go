@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// addBatchMetadata adds the batch_id, batch_run_date, batch_insert_ts and etl_version columns to a decoded entry, following
+// the conventions of the Hubble tables. batch_insert_ts is the time the row was written. The timestamps are written in the
+// chosen timestamp format.
+func addBatchMetadata(i map[string]interface{}, commonArgs utils.CommonFlagValues) {
+	i["batch_id"] = commonArgs.BatchID
+	i["batch_run_date"] = formatTimestamp(commonArgs.BatchRunDate, commonArgs.TimestampFormat)
+	i["batch_insert_ts"] = formatTimestamp(time.Now(), commonArgs.TimestampFormat)
+	i["etl_version"] = utils.ToolVersion()
+}
+
+// formatTimestamp encodes a timestamp the way applyTimestampFormat rewrites the timestamp columns of an entry
+func formatTimestamp(t time.Time, timestampFormat string) interface{} {
+	switch timestampFormat {
+	case utils.TimestampFormatEpochSeconds:
+		return t.Unix()
+	case utils.TimestampFormatEpochMicros:
+		return t.UnixMicro()
+	default:
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+}
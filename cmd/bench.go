@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/network"
+	"github.com/stellar/stellar-etl/bench"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmarks the transforms over a fixture ledger range.",
+	Long: `Builds a fixture range of ledgers of successful payments in memory and measures the ledger, transaction and operation
+transforms, including the JSON encoding of their output. For each transform it reports the items per second, the allocations
+per item and the MB per second encoded. The fixture only depends on the range size, and nothing is read from the network,
+so results of the same size are comparable between builds.
+
+If a baseline file written by a previous run is provided, the command fails when throughput drops, or allocations grow,
+by more than the tolerance. The same transforms are benchmarked by go test ./bench -bench .`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		ledgerCount, err := cmd.Flags().GetInt("ledgers")
+		if err != nil {
+			cmdLogger.Fatal("could not get ledger count: ", err)
+		}
+
+		transactionsPerLedger, err := cmd.Flags().GetInt("transactions-per-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get transactions per ledger: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output path: ", err)
+		}
+
+		baselinePath, err := cmd.Flags().GetString("baseline")
+		if err != nil {
+			cmdLogger.Fatal("could not get baseline path: ", err)
+		}
+
+		tolerance, err := cmd.Flags().GetFloat64("tolerance")
+		if err != nil {
+			cmdLogger.Fatal("could not get tolerance: ", err)
+		}
+
+		if ledgerCount <= 0 || transactionsPerLedger <= 0 {
+			cmdLogger.Fatal("--ledgers and --transactions-per-ledger have to be positive")
+		}
+
+		fixture, err := bench.NewFixture(ledgerCount, transactionsPerLedger, network.PublicNetworkPassphrase)
+		if err != nil {
+			cmdLogger.Fatal("could not build the fixture ledgers: ", err)
+		}
+
+		results := []bench.Result{}
+		for _, run := range []func() (bench.Result, error){
+			func() (bench.Result, error) { return bench.Ledgers(fixture.Ledgers) },
+			func() (bench.Result, error) { return bench.Transactions(fixture.Transactions) },
+			func() (bench.Result, error) {
+				return bench.Operations(fixture.Operations, network.PublicNetworkPassphrase)
+			},
+		} {
+			result, err := run()
+			if err != nil {
+				cmdLogger.Fatal(err)
+			}
+			cmdLogger.Infof("%s: %d items in %s, %.1f items/sec, %.1f allocs/item, %.2f MB/sec encoded",
+				result.Name, result.Items, result.Duration, result.ItemsPerSec, result.AllocsPerItem, result.EncodedMBPerS)
+			results = append(results, result)
+		}
+
+		if path != "" {
+			encoded, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				cmdLogger.Fatal("could not marshal benchmark results: ", err)
+			}
+			outFile := mustOutFile(path)
+			outFile.Write(encoded)
+			outFile.Close()
+			mustCommitOutputFile(outFile)
+		}
+
+		if baselinePath == "" {
+			return
+		}
+
+		contents, err := os.ReadFile(baselinePath)
+		if err != nil {
+			cmdLogger.Fatal("could not read baseline: ", utils.InputError{Err: err})
+		}
+
+		var baseline []bench.Result
+		if err := json.Unmarshal(contents, &baseline); err != nil {
+			cmdLogger.Fatal("could not parse baseline: ", err)
+		}
+
+		regressions := bench.Regressions(baseline, results, tolerance)
+		for _, regression := range regressions {
+			cmdLogger.Error(regression)
+		}
+
+		if len(regressions) > 0 {
+			cmdLogger.Fatal(fmt.Sprintf("%d performance regressions compared to %s", len(regressions), baselinePath))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().Int("ledgers", 64, "Number of ledgers in the fixture range")
+	benchCmd.Flags().Int("transactions-per-ledger", 100, "Number of transactions in every fixture ledger")
+	benchCmd.Flags().StringP("output", "o", "", "Filename of the JSON benchmark results, which can be used as a baseline for later runs")
+	benchCmd.Flags().String("baseline", "", "Filename of the results of a previous run to compare against")
+	benchCmd.Flags().Float64("tolerance", 0.1, "Relative change from the baseline that is reported as a regression, e.g. 0.1 for 10%")
+}
@@ -2,14 +2,22 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
 )
 
 type CloudStorage interface {
-	UploadTo(credentialsPath, bucket, path string) error
+	UploadTo(ctx context.Context, credentialsPath, bucket, path string) error
 }
 
 func createOutputFile(filepath string) error {
@@ -24,31 +32,102 @@ func createOutputFile(filepath string) error {
 	return nil
 }
 
-func mustOutFile(path string) *os.File {
+// outputFile is an export file along with the metadata gathered while writing entries to it
+type outputFile struct {
+	*os.File
+	path          string
+	firstClosedAt time.Time
+	// sink and location are the sink that the rows of a sink output are appended to and where, and table the table
+	// they belong to
+	sink     *rowSink
+	location string
+	table    string
+}
+
+// closedAtKeys are the json keys that hold the ledger close time of an exported entry
+var closedAtKeys = []string{"closed_at", "ledger_closed_at"}
+
+// mustOutFile creates the staged file that will be committed to path once it is complete. The returned file holds the
+// staged path.
+func mustOutFile(path string) *outputFile {
+	outFile, err := openOutFile(path)
+	if err != nil {
+		cmdLogger.Fatal(err)
+	}
+	return outFile
+}
+
+// openOutFile creates the staged file of path like mustOutFile, but returns an error instead of exiting
+func openOutFile(path string) (*outputFile, error) {
+	if sink, location, ok := outputRowSink(path); ok {
+		return openRowSinkOutFile(sink, location)
+	}
+
+	path = stagedPath(path)
 	absolutePath, err := filepath.Abs(path)
 	if err != nil {
-		cmdLogger.Fatal("could not get absolute filepath: ", err)
+		return nil, fmt.Errorf("could not get absolute filepath: %v", err)
 	}
 
 	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
 	if err != nil {
-		cmdLogger.Fatalf("could not create directory %s: %s", path, err)
+		return nil, utils.SinkError{Err: fmt.Errorf("could not create directory %s: %v", path, err)}
 	}
 
 	err = createOutputFile(absolutePath)
 	if err != nil {
-		cmdLogger.Fatal("could not create output file: ", err)
+		return nil, utils.SinkError{Err: fmt.Errorf("could not create output file: %v", err)}
 	}
 
 	outFile, err := os.OpenFile(absolutePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
-		cmdLogger.Fatal("error in opening output file: ", err)
+		return nil, utils.SinkError{Err: fmt.Errorf("error in opening output file: %v", err)}
 	}
 
-	return outFile
+	return &outputFile{File: outFile, path: path}, nil
+}
+
+// extendedEntry pairs a transformed entry with columns added to it when it is written, such as the base64 XDR it was
+// transformed from
+type extendedEntry struct {
+	entry   interface{}
+	columns map[string]interface{}
 }
 
-func exportEntry(entry interface{}, outFile *os.File, extra map[string]string) (int, error) {
+// withColumns returns the entry with the columns added, keeping the columns added to it before
+func withColumns(entry interface{}, columns map[string]interface{}) interface{} {
+	extended, ok := entry.(extendedEntry)
+	if !ok {
+		extended = extendedEntry{entry: entry, columns: map[string]interface{}{}}
+	}
+	for column, value := range columns {
+		extended.columns[column] = value
+	}
+	return extended
+}
+
+// withRawXDR attaches the base64 encoding of raw to the entry, to be written in column, if --include-xdr is set
+func withRawXDR(entry interface{}, column string, raw interface{}, commonArgs utils.CommonFlagValues) interface{} {
+	if !commonArgs.IncludeXDR {
+		return entry
+	}
+
+	encoded, err := xdr.MarshalBase64(raw)
+	if err != nil {
+		cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not encode %s: %v", column, err)})
+		return entry
+	}
+
+	return withColumns(entry, map[string]interface{}{column: encoded})
+}
+
+func exportEntry(entry interface{}, outFile *outputFile, commonArgs utils.CommonFlagValues) (int, error) {
+	var columns map[string]interface{}
+	if extended, ok := entry.(extendedEntry); ok {
+		columns = extended.columns
+		entry = extended.entry
+	}
+
 	// This extra marshalling/unmarshalling is silly, but it's required to properly handle the null.[String|Int*] types, and add the extra fields.
 	m, err := json.Marshal(entry)
 	if err != nil {
@@ -62,13 +141,34 @@ func exportEntry(entry interface{}, outFile *os.File, extra map[string]string) (
 	if err != nil {
 		cmdLogger.Errorf("Error unmarshalling %+v: %v ", i, err)
 	}
-	for k, v := range extra {
+	outFile.trackClosedAt(i)
+	outFile.trackTable(entry)
+	applyNullSemantics(i, entry, commonArgs.NullSemantics)
+	if commonArgs.AssetContractIDs {
+		addAssetContractIDs(i, commonArgs.Passphrase)
+	}
+	if commonArgs.WellKnownAssets != nil {
+		addWellKnownAssets(i, commonArgs.WellKnownAssets)
+	}
+	if commonArgs.AddressLabels != nil {
+		addAddressLabels(i, commonArgs.AddressLabels)
+	}
+	if commonArgs.BatchMetadata {
+		addBatchMetadata(i, commonArgs)
+	}
+	for k, v := range commonArgs.Extra {
 		i[k] = v
 	}
+	for column, value := range columns {
+		i[column] = value
+	}
+	applyRedaction(i, entry, commonArgs.Redact)
+	applyTimestampFormat(i, entry, commonArgs.TimestampFormat)
+	applyWarehouseFormat(i, entry, commonArgs.Warehouse)
 
 	marshalled, err := json.Marshal(i)
 	if err != nil {
-		return 0, fmt.Errorf("could not json encode %+v: %s", entry, err)
+		return 0, utils.SinkError{Err: fmt.Errorf("could not json encode %+v: %s", entry, err)}
 	}
 	cmdLogger.Debugf("Writing entry to %s", outFile.Name())
 	numBytes, err := outFile.Write(marshalled)
@@ -82,6 +182,111 @@ func exportEntry(entry interface{}, outFile *os.File, extra map[string]string) (
 	return numBytes + newLineNumBytes, nil
 }
 
+// trackClosedAt records the close time of the first entry written to the file
+func (o *outputFile) trackClosedAt(i map[string]interface{}) {
+	if !o.firstClosedAt.IsZero() {
+		return
+	}
+
+	for _, key := range closedAtKeys {
+		if closedAt, ok := i[key].(string); ok {
+			parsed, err := time.Parse(time.RFC3339Nano, closedAt)
+			if err == nil {
+				o.firstClosedAt = parsed.UTC()
+				return
+			}
+		}
+	}
+}
+
+// applyNullSemantics rewrites absent values in a decoded entry according to the chosen null semantics.
+// With utils.NullSemanticsZero, nulls become the zero value of the field's Go type; timestamps stay null
+// since a zero timestamp would be indistinguishable from a real one. With utils.NullSemanticsNull, empty
+// strings and empty arrays become null. utils.NullSemanticsPreserve leaves the entry untouched.
+func applyNullSemantics(i map[string]interface{}, entry interface{}, nullSemantics string) {
+	switch nullSemantics {
+	case utils.NullSemanticsZero:
+		fieldTypes := jsonFieldTypes(reflect.TypeOf(entry))
+		for k, v := range i {
+			if v != nil {
+				continue
+			}
+			if fieldType, ok := fieldTypes[k]; ok {
+				if zeroValue := zeroJSONValue(fieldType); zeroValue != nil {
+					i[k] = zeroValue
+				}
+			}
+		}
+	case utils.NullSemanticsNull:
+		for k, v := range i {
+			switch value := v.(type) {
+			case string:
+				if value == "" {
+					i[k] = nil
+				}
+			case []interface{}:
+				if len(value) == 0 {
+					i[k] = nil
+				}
+			}
+		}
+	}
+}
+
+// jsonFieldTypes maps the json key of every field in a struct, including embedded structs, to its type
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fieldTypes := map[string]reflect.Type{}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fieldTypes
+	}
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		if field.Anonymous {
+			for k, v := range jsonFieldTypes(field.Type) {
+				fieldTypes[k] = v
+			}
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldTypes[name] = field.Type
+	}
+
+	return fieldTypes
+}
+
+// zeroJSONValue returns the value that represents the zero value of the type once encoded as json.
+// A nil return means the field should stay null.
+func zeroJSONValue(t reflect.Type) interface{} {
+	switch t {
+	case reflect.TypeOf(null.Int{}), reflect.TypeOf(null.Float{}):
+		return 0
+	case reflect.TypeOf(null.String{}):
+		return ""
+	case reflect.TypeOf(null.Bool{}):
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return []interface{}{}
+	case reflect.Map:
+		return map[string]interface{}{}
+	}
+
+	return nil
+}
+
 // Prints the number of attempted, failed, and successful transformations as a JSON object
 func printTransformStats(attempts, failures int) {
 	resultsMap := map[string]int{
@@ -111,7 +316,7 @@ func deleteLocalFiles(path string) {
 	cmdLogger.Infof("Successfully deleted %s", path)
 }
 
-func maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path string) {
+func maybeUpload(ctx context.Context, cloudCredentials, cloudStorageBucket, cloudProvider, path string) {
 	if cloudProvider == "" {
 		cmdLogger.Info("No cloud provider specified for upload. Skipping upload.")
 		return
@@ -126,7 +331,7 @@ func maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path strin
 	switch cloudProvider {
 	case "gcp":
 		cloudStorage = newGCS(cloudCredentials, cloudStorageBucket)
-		err := cloudStorage.UploadTo(cloudCredentials, cloudStorageBucket, path)
+		err := cloudStorage.UploadTo(ctx, cloudCredentials, cloudStorageBucket, path)
 		if err != nil {
 			cmdLogger.Errorf("Unable to upload output to GCS: %s", err)
 			return
@@ -135,3 +340,16 @@ func maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path strin
 		cmdLogger.Error("Unknown cloud provider")
 	}
 }
+
+// waitForRetry waits for the backoff before a failed request is sent again. It returns the error of ctx instead when ctx
+// is done first, so that retries stop once the command is cancelled or timed out.
+func waitForRetry(ctx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/guregu/null/zero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+type nullSemanticsEntry struct {
+	NullString null.String            `json:"null_string"`
+	NullInt    null.Int               `json:"null_int"`
+	NullFloat  null.Float             `json:"null_float"`
+	NullBool   null.Bool              `json:"null_bool"`
+	NullTime   null.Time              `json:"null_time"`
+	ZeroString zero.String            `json:"zero_string"`
+	ZeroInt    zero.Int               `json:"zero_int"`
+	String     string                 `json:"string"`
+	Strings    []string               `json:"strings"`
+	Tags       []string               `json:"tags"`
+	Details    map[string]interface{} `json:"details"`
+}
+
+// nullSemanticsRow is nullSemanticsEntry{Strings: []string{}} as decoded by exportEntry
+func nullSemanticsRow() map[string]interface{} {
+	return map[string]interface{}{
+		"null_string": nil,
+		"null_int":    nil,
+		"null_float":  nil,
+		"null_bool":   nil,
+		"null_time":   nil,
+		"zero_string": "",
+		"zero_int":    json.Number("0"),
+		"string":      "",
+		"strings":     []interface{}{},
+		"tags":        nil,
+		"details":     nil,
+	}
+}
+
+func TestApplyNullSemantics(t *testing.T) {
+	type nullSemanticsTest struct {
+		nullSemantics string
+		want          map[string]interface{}
+	}
+
+	tests := []nullSemanticsTest{
+		{
+			nullSemantics: utils.NullSemanticsPreserve,
+			want:          nullSemanticsRow(),
+		},
+		{
+			// timestamps stay null, and values that are not null are kept
+			nullSemantics: utils.NullSemanticsZero,
+			want: map[string]interface{}{
+				"null_string": "",
+				"null_int":    0,
+				"null_float":  0,
+				"null_bool":   false,
+				"null_time":   nil,
+				"zero_string": "",
+				"zero_int":    json.Number("0"),
+				"string":      "",
+				"strings":     []interface{}{},
+				"tags":        []interface{}{},
+				"details":     map[string]interface{}{},
+			},
+		},
+		{
+			// empty strings and arrays become null, while zero numbers are kept
+			nullSemantics: utils.NullSemanticsNull,
+			want: map[string]interface{}{
+				"null_string": nil,
+				"null_int":    nil,
+				"null_float":  nil,
+				"null_bool":   nil,
+				"null_time":   nil,
+				"zero_string": nil,
+				"zero_int":    json.Number("0"),
+				"string":      nil,
+				"strings":     nil,
+				"tags":        nil,
+				"details":     nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		row := nullSemanticsRow()
+		applyNullSemantics(row, nullSemanticsEntry{Strings: []string{}}, test.nullSemantics)
+		assert.Equal(t, test.want, row, test.nullSemantics)
+	}
+}
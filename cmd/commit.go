@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// stagingDir is the hidden folder, next to the final location of a file, that the file is written to until its batch is
+// committed. Loaders listing the output folder never see a file that is still being written.
+const stagingDir = ".staging"
+
+// stagedPath returns where the file that will be committed to path is written
+func stagedPath(path string) string {
+	return filepath.Join(filepath.Dir(path), stagingDir, filepath.Base(path))
+}
+
+// committedPath returns the final location of a staged file, dropping the staging folder from its path. Partition
+// folders created inside the staging folder are kept.
+func committedPath(path string) string {
+	dir, rest := filepath.Dir(path), filepath.Base(path)
+	for dir != filepath.Dir(dir) {
+		if filepath.Base(dir) == stagingDir {
+			return filepath.Join(filepath.Dir(dir), rest)
+		}
+		dir, rest = filepath.Dir(dir), filepath.Join(filepath.Base(dir), rest)
+	}
+
+	return path
+}
+
+// commitFiles moves the staged files of a batch to their final location and returns the committed paths. Each file is
+// moved with a rename, so a committed file is always complete.
+func commitFiles(paths []string) ([]string, error) {
+	committed := make([]string, 0, len(paths))
+	for _, path := range paths {
+		finalPath := committedPath(path)
+		if finalPath == path {
+			committed = append(committed, path)
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(finalPath), os.ModePerm)
+		if err == nil {
+			err = os.Rename(path, finalPath)
+		}
+		if err != nil {
+			return committed, fmt.Errorf("could not commit %s to %s: %v", path, finalPath, err)
+		}
+		removeEmptyStagingDirs(path)
+		committed = append(committed, finalPath)
+	}
+
+	return committed, nil
+}
+
+// abortFiles deletes the staged files of a batch that failed, so that they are never committed
+func abortFiles(paths []string) {
+	for _, path := range paths {
+		deleteLocalFiles(path)
+		removeEmptyStagingDirs(path)
+	}
+}
+
+// removeEmptyStagingDirs removes the folders of a staged file that are left empty, up to and including the staging folder
+func removeEmptyStagingDirs(path string) {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil || filepath.Base(dir) == stagingDir {
+			return
+		}
+	}
+}
+
+// mustCommitOutputFile commits a closed output file that is not finalized into a partition layout
+func mustCommitOutputFile(outFile *outputFile) string {
+	committed, err := commitFiles([]string{outFile.path})
+	if err != nil {
+		cmdLogger.Fatal("could not commit output file: ", utils.SinkError{Err: err})
+	}
+
+	return committed[0]
+}
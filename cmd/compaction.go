@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// pendingBatches are the batch files of one resource that have not been compacted and uploaded yet
+type pendingBatches struct {
+	start, end    uint32
+	files         []*outputFile
+	size          int64
+	firstClosedAt time.Time
+}
+
+// batchCompactor merges the small files written for consecutive batches into files of about targetBytes before
+// they are finalized and uploaded. Sparse ranges otherwise produce many tiny files that are slow to query.
+type batchCompactor struct {
+	targetBytes                                         int64
+	folderPath                                          string
+	cloudCredentials, cloudStorageBucket, cloudProvider string
+	commonArgs                                          utils.CommonFlagValues
+	pending                                             map[string]*pendingBatches
+}
+
+func newBatchCompactor(targetBytes int64, folderPath, cloudCredentials, cloudStorageBucket, cloudProvider string, commonArgs utils.CommonFlagValues) *batchCompactor {
+	return &batchCompactor{
+		targetBytes:        targetBytes,
+		folderPath:         folderPath,
+		cloudCredentials:   cloudCredentials,
+		cloudStorageBucket: cloudStorageBucket,
+		cloudProvider:      cloudProvider,
+		commonArgs:         commonArgs,
+		pending:            map[string]*pendingBatches{},
+	}
+}
+
+// add queues the closed file of a batch and compacts the resource once its pending files reach the target size
+func (c *batchCompactor) add(ctx context.Context, resource string, outFile *outputFile, start, end uint32) error {
+	info, err := os.Stat(outFile.path)
+	if err != nil {
+		return fmt.Errorf("could not stat %s: %v", outFile.path, err)
+	}
+
+	pending, ok := c.pending[resource]
+	if !ok {
+		pending = &pendingBatches{start: start}
+		c.pending[resource] = pending
+	}
+	pending.end = end
+	pending.files = append(pending.files, outFile)
+	pending.size += info.Size()
+	if pending.firstClosedAt.IsZero() {
+		pending.firstClosedAt = outFile.firstClosedAt
+	}
+
+	if pending.size >= c.targetBytes {
+		return c.compact(ctx, resource)
+	}
+	return nil
+}
+
+// flush compacts and uploads every pending resource, regardless of its size
+func (c *batchCompactor) flush(ctx context.Context) error {
+	for resource := range c.pending {
+		if err := c.compact(ctx, resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abort deletes the staged files of every pending resource when the export stops before they are compacted
+func (c *batchCompactor) abort() {
+	for resource, pending := range c.pending {
+		for _, file := range pending.files {
+			abortFiles([]string{file.path})
+		}
+		delete(c.pending, resource)
+	}
+}
+
+// compact merges the pending files of a resource and commits the result. The pending files are only deleted once the
+// compacted file is committed, so that a failure leaves them pending to be compacted again or aborted.
+func (c *batchCompactor) compact(ctx context.Context, resource string) error {
+	pending, ok := c.pending[resource]
+	if !ok {
+		return nil
+	}
+
+	compacted := pending.files[0]
+	if len(pending.files) > 1 {
+		path := filepath.Join(c.folderPath, exportFilename(pending.start, pending.end+1, resource))
+		var err error
+		compacted, err = openOutFile(path)
+		if err != nil {
+			return err
+		}
+		for _, part := range pending.files {
+			if err := appendFile(compacted, part.path); err != nil {
+				compacted.Close()
+				abortFiles([]string{compacted.path})
+				return err
+			}
+		}
+		compacted.firstClosedAt = pending.firstClosedAt
+		compacted.table = pending.files[0].table
+		compacted.Close()
+	}
+
+	staged := stageOutputFile(ctx, compacted, pending.start, pending.end, c.commonArgs)
+	committed, err := commitFiles(staged)
+	if err != nil {
+		abortFiles(staged[len(committed):])
+		if len(pending.files) == 1 {
+			// the only pending file was moved by staging, so there is nothing left to compact again
+			delete(c.pending, resource)
+		}
+		return utils.SinkError{Err: err}
+	}
+
+	delete(c.pending, resource)
+	if len(pending.files) > 1 {
+		for _, part := range pending.files {
+			abortFiles([]string{part.path})
+		}
+	}
+	for _, outputPath := range committed {
+		maybeUpload(ctx, c.cloudCredentials, c.cloudStorageBucket, c.cloudProvider, outputPath)
+	}
+	return nil
+}
+
+func appendFile(dst io.Writer, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open %s for compaction: %v", path, err)
+	}
+	defer src.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("could not compact %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeBatchFile(t *testing.T, folder string, start, end uint32, contents string) *outputFile {
+	outFile, err := openOutFile(filepath.Join(folder, exportFilename(start, end, "accounts")))
+	assert.NoError(t, err)
+	_, err = outFile.WriteString(contents)
+	assert.NoError(t, err)
+	assert.NoError(t, outFile.Close())
+	return outFile
+}
+
+func TestBatchCompactorMergesBatches(t *testing.T) {
+	folder := t.TempDir()
+	compactor := newBatchCompactor(1<<20, folder, "", "", "", utils.CommonFlagValues{})
+	first := writeBatchFile(t, folder, 10, 20, "{\"a\":1}\n")
+	second := writeBatchFile(t, folder, 20, 30, "{\"a\":2}\n")
+
+	assert.NoError(t, compactor.add(context.Background(), "accounts", first, 10, 19))
+	assert.NoError(t, compactor.add(context.Background(), "accounts", second, 20, 29))
+	assert.Len(t, compactor.pending["accounts"].files, 2)
+
+	assert.NoError(t, compactor.flush(context.Background()))
+	assert.Empty(t, compactor.pending)
+
+	contents, err := os.ReadFile(filepath.Join(folder, "10-29-accounts.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(contents))
+	for _, part := range []*outputFile{first, second} {
+		_, err := os.Stat(part.path)
+		assert.True(t, os.IsNotExist(err), part.path)
+	}
+}
+
+func TestBatchCompactorReturnsErrorWhenOutputCannotBeCreated(t *testing.T) {
+	folder := t.TempDir()
+	first := writeBatchFile(t, folder, 10, 20, "{\"a\":1}\n")
+	second := writeBatchFile(t, folder, 20, 30, "{\"a\":2}\n")
+
+	// the compacted file cannot be staged inside a regular file
+	blocked := filepath.Join(folder, "blocked")
+	assert.NoError(t, os.WriteFile(blocked, nil, 0644))
+	compactor := newBatchCompactor(1<<20, blocked, "", "", "", utils.CommonFlagValues{})
+	assert.NoError(t, compactor.add(context.Background(), "accounts", first, 10, 19))
+	assert.NoError(t, compactor.add(context.Background(), "accounts", second, 20, 29))
+
+	err := compactor.flush(context.Background())
+	assert.ErrorAs(t, err, &utils.SinkError{})
+
+	// the batches stay pending, so that they can be compacted again or aborted
+	assert.Len(t, compactor.pending["accounts"].files, 2)
+	for _, part := range []*outputFile{first, second} {
+		_, err := os.Stat(part.path)
+		assert.NoError(t, err)
+	}
+
+	compactor.abort()
+	assert.Empty(t, compactor.pending)
+	for _, part := range []*outputFile{first, second} {
+		_, err := os.Stat(part.path)
+		assert.True(t, os.IsNotExist(err), part.path)
+	}
+}
+
+func TestOpenOutFileReturnsError(t *testing.T) {
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	assert.NoError(t, os.WriteFile(blocked, nil, 0644))
+
+	_, err := openOutFile(filepath.Join(blocked, "ledgers.txt"))
+	assert.ErrorAs(t, err, &utils.SinkError{})
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// controlUnixPrefix marks a control-addr that is the path of a unix socket rather than a TCP address
+const controlUnixPrefix = "unix:"
+
+// Values of the state field of controlStatus
+const (
+	controlStateRunning = "running"
+	controlStatePausing = "pausing"
+	controlStatePaused  = "paused"
+)
+
+// controlStatus is the body returned by the control endpoints
+type controlStatus struct {
+	State              string       `json:"state"`
+	BatchInFlight      *ledgerRange `json:"batch_in_flight"`
+	LastExportedLedger uint32       `json:"last_exported_ledger"`
+}
+
+// exportControl lets an operator pause, resume and flush a continuous export. A pause takes effect once the batch in
+// flight is exported, so that the export can be drained before planned maintenance. Flushes run between batches.
+type exportControl struct {
+	mu           sync.Mutex
+	paused       bool
+	resumed      chan struct{}
+	inFlight     *ledgerRange
+	lastExported uint32
+	pauses       chan struct{}
+	flushes      chan chan error
+}
+
+func newExportControl() *exportControl {
+	return &exportControl{pauses: make(chan struct{}, 1), flushes: make(chan chan error)}
+}
+
+// pauseRequests returns the channel waking up an export waiting for its next batch when it is paused, which is nil when
+// the export is not controlled
+func (c *exportControl) pauseRequests() chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.pauses
+}
+
+// flushRequests returns the channel of the flushes requested with /flush, which is nil when the export is not controlled
+func (c *exportControl) flushRequests() chan chan error {
+	if c == nil {
+		return nil
+	}
+	return c.flushes
+}
+
+// batchStarted records that the batch of ledgers [start, end] is being exported
+func (c *exportControl) batchStarted(start, end uint32) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight = &ledgerRange{Start: int64(start), End: int64(end)}
+}
+
+// batchExported records that every ledger up to and including ledger was exported
+func (c *exportControl) batchExported(ledger uint32) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastExported = ledger
+}
+
+// waitWhilePaused marks the batch in flight as done, and blocks while the export is paused, running the requested flushes
+// with flush, until it is resumed or ctx is done
+func (c *exportControl) waitWhilePaused(ctx context.Context, flush func() error) {
+	if c == nil {
+		return
+	}
+
+	for {
+		c.mu.Lock()
+		c.inFlight = nil
+		paused, resumed := c.paused, c.resumed
+		c.mu.Unlock()
+		if !paused {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-resumed:
+		case done := <-c.flushes:
+			done <- flush()
+		}
+	}
+}
+
+// running returns whether the export is neither paused nor pausing, which an export that is not controlled always is
+func (c *exportControl) running() bool {
+	if c == nil {
+		return true
+	}
+	return c.status().State == controlStateRunning
+}
+
+func (c *exportControl) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.resumed = make(chan struct{})
+		select {
+		case c.pauses <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *exportControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.resumed)
+	}
+}
+
+func (c *exportControl) status() controlStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := controlStatus{State: controlStateRunning, BatchInFlight: c.inFlight, LastExportedLedger: c.lastExported}
+	if c.paused {
+		status.State = controlStatePaused
+		if c.inFlight != nil {
+			status.State = controlStatePausing
+		}
+	}
+	return status
+}
+
+// handle registers POST /pause, /resume and /flush and GET /status below prefix on mux. They all respond with the
+// current controlStatus; /flush responds once the buffered compacted files are uploaded.
+func (c *exportControl) handle(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(prefix+"/pause", c.post(func(w http.ResponseWriter, r *http.Request) {
+		c.pause()
+		writeControlStatus(w, c.status())
+	}))
+	mux.HandleFunc(prefix+"/resume", c.post(func(w http.ResponseWriter, r *http.Request) {
+		c.resume()
+		writeControlStatus(w, c.status())
+	}))
+	mux.HandleFunc(prefix+"/flush", c.post(func(w http.ResponseWriter, r *http.Request) {
+		done := make(chan error, 1)
+		select {
+		case c.flushes <- done:
+		case <-r.Context().Done():
+			return
+		}
+		if err := <-done; err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeControlStatus(w, c.status())
+	}))
+	mux.HandleFunc(prefix+"/status", func(w http.ResponseWriter, r *http.Request) {
+		writeControlStatus(w, c.status())
+	})
+}
+
+// post rejects the requests to a control endpoint that changes the export which are not POST requests
+func (c *exportControl) post(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// serveControl serves the control endpoints registered on mux on addr, a TCP address or unix: followed by a socket path.
+// The address is bound before returning, so that an address already in use fails the command before anything is exported.
+func serveControl(addr string, mux *http.ServeMux) error {
+	network, address := "tcp", addr
+	if strings.HasPrefix(addr, controlUnixPrefix) {
+		network, address = "unix", strings.TrimPrefix(addr, controlUnixPrefix)
+		// a socket left behind by a previous run would make the listen fail, but anything else at the path is kept
+		if info, err := os.Lstat(address); err == nil {
+			if info.Mode()&os.ModeSocket == 0 {
+				return fmt.Errorf("%s already exists and is not a socket", address)
+			}
+			if err := os.Remove(address); err != nil {
+				return err
+			}
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			cmdLogger.Errorf("stopped serving control endpoints on %s: %v", addr, err)
+		}
+	}()
+	return nil
+}
+
+func writeControlStatus(w http.ResponseWriter, status controlStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
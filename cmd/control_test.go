@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func postControl(t *testing.T, server *httptest.Server, endpoint string) controlStatus {
+	resp, err := http.Post(server.URL+endpoint, "application/json", nil)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, endpoint)
+
+	var status controlStatus
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	return status
+}
+
+func TestExportControl(t *testing.T) {
+	control := newExportControl()
+	mux := http.NewServeMux()
+	control.handle(mux, "")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// batches are exported by a loop shaped like the one of export_ledger_entry_changes
+	batches := make(chan uint32)
+	var flushes int32
+	flush := func() error {
+		atomic.AddInt32(&flushes, 1)
+		return nil
+	}
+	go func() {
+		for {
+			control.waitWhilePaused(ctx, flush)
+			select {
+			case <-ctx.Done():
+				return
+			case <-control.pauseRequests():
+				continue
+			case done := <-control.flushRequests():
+				done <- flush()
+			case ledger := <-batches:
+				control.batchStarted(ledger, ledger)
+				control.batchExported(ledger)
+			}
+		}
+	}()
+
+	batches <- 10
+	status := postControl(t, server, "/flush")
+	assert.Equal(t, controlStatus{State: controlStateRunning, LastExportedLedger: 10}, status)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&flushes))
+
+	status = postControl(t, server, "/pause")
+	assert.Equal(t, controlStatus{State: controlStatePaused, LastExportedLedger: 10}, status)
+
+	// flushes still run while the export is paused, and the loop waits for the resume once it is done with them
+	status = postControl(t, server, "/flush")
+	assert.Equal(t, controlStatePaused, status.State)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&flushes))
+	select {
+	case batches <- 20:
+		t.Fatal("a batch was exported while the export is paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	status = postControl(t, server, "/resume")
+	assert.Equal(t, controlStateRunning, status.State)
+	batches <- 20
+	status = postControl(t, server, "/flush")
+	assert.Equal(t, controlStatus{State: controlStateRunning, LastExportedLedger: 20}, status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&flushes))
+
+	resp, err := http.Get(server.URL + "/pause")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestLagCheckSuspendedWhilePaused(t *testing.T) {
+	control := newExportControl()
+	health := &exportHealth{maxLag: 10, networkTip: 100, lastLedger: 95, control: control}
+	_, exceeded := health.lagExceeded()
+	assert.False(t, exceeded)
+
+	control.pause()
+	health.networkTip = 200
+	_, exceeded = health.lagExceeded()
+	assert.False(t, exceeded)
+
+	// the export has to catch up with the tip again after it is resumed
+	control.resume()
+	_, exceeded = health.lagExceeded()
+	assert.False(t, exceeded)
+
+	health.lastLedger = 195
+	_, exceeded = health.lagExceeded()
+	assert.False(t, exceeded)
+
+	health.networkTip = 300
+	lag, exceeded := health.lagExceeded()
+	assert.True(t, exceeded)
+	assert.Equal(t, uint32(105), lag)
+}
+
+func TestServeControlKeepsFilesThatAreNotSockets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control")
+	assert.NoError(t, os.WriteFile(path, []byte("kept"), 0644))
+
+	err := serveControl(controlUnixPrefix+path, http.NewServeMux())
+	assert.Error(t, err)
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "kept", string(contents))
+}
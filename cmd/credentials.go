@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"google.golang.org/api/option"
+)
+
+// Prefixes of the cloud-credentials flag that select where credentials are read from
+const (
+	credentialsEnvPrefix     = "env:"
+	credentialsFilePrefix    = "file:"
+	credentialsAWSRolePrefix = "aws-role:"
+)
+
+// resolveCredentials reads the credentials described by the cloud-credentials flag. Credentials are read every time
+// they are needed so that secrets rotated by Vault or a secret manager are picked up without restarting the export.
+// The flag accepts:
+//   - "" to use the ambient credentials of the environment, e.g. GCP workload identity or an AWS instance role
+//   - "env:NAME" to read the credentials from the NAME environment variable
+//   - "file:PATH" or a plain path to read the credentials from a file, such as a mounted secret
+//
+// A nil result means the ambient credentials should be used.
+func resolveCredentials(source string) ([]byte, error) {
+	switch {
+	case source == "":
+		return nil, nil
+	case strings.HasPrefix(source, credentialsEnvPrefix):
+		name := strings.TrimPrefix(source, credentialsEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("credentials environment variable %s is not set", name)
+		}
+		return []byte(value), nil
+	case strings.HasPrefix(source, credentialsAWSRolePrefix):
+		return nil, fmt.Errorf("%s credentials can only be used with AWS sinks", credentialsAWSRolePrefix)
+	default:
+		path := strings.TrimPrefix(source, credentialsFilePrefix)
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read credentials file %s: %v", path, err)
+		}
+		return contents, nil
+	}
+}
+
+// gcpClientOptions returns the client options that authenticate GCP clients with the provided credentials source.
+// Without explicit credentials, the clients fall back to Application Default Credentials, which covers workload identity.
+func gcpClientOptions(source string) ([]option.ClientOption, error) {
+	credentialsJSON, err := resolveCredentials(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentialsJSON == nil {
+		cmdLogger.Info("No cloud credentials provided. Using application default credentials.")
+		return nil, nil
+	}
+
+	return []option.ClientOption{option.WithCredentialsJSON(credentialsJSON)}, nil
+}
+
+// awsSession creates an AWS session from the provided credentials source. "aws-role:ARN" assumes the IAM role
+// with the ambient credentials; the temporary credentials are refreshed automatically before they expire.
+// Without a role, the default credential chain is used, which covers instance roles and web identity tokens.
+func awsSession(source string) (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %v", err)
+	}
+
+	if !strings.HasPrefix(source, credentialsAWSRolePrefix) {
+		if source != "" {
+			return nil, fmt.Errorf("AWS sinks only accept ambient or %s credentials", credentialsAWSRolePrefix)
+		}
+		return sess, nil
+	}
+
+	roleARN := strings.TrimPrefix(source, credentialsAWSRolePrefix)
+	return sess.Copy(&aws.Config{Credentials: stscreds.NewCredentials(sess, roleARN)}), nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// currentStateKey identifies the ledger entry an exported row describes, and whether the row removes it
+type currentStateKey struct {
+	key     string
+	deleted bool
+}
+
+// rowCurrentStateKey returns the key of the ledger entry the row was transformed from. Signers are keyed by
+// account and signer, since one account entry produces a row for each of its signers.
+func rowCurrentStateKey(output interface{}, change ingest.Change) currentStateKey {
+	entry, _, deleted, err := utils.ExtractEntryFromChange(change)
+	if err != nil {
+		return currentStateKey{}
+	}
+
+	key := utils.LedgerEntryToLedgerKeyHash(entry)
+	if signer, ok := output.(transform.AccountSignerOutput); ok {
+		key += "/" + signer.Signer
+		deleted = signer.Deleted
+	}
+
+	return currentStateKey{key: key, deleted: deleted}
+}
+
+// currentState keeps the latest row of every live ledger entry seen since the start of the export, so that a
+// snapshot of the current state can be written next to the append-only history of changes
+type currentState struct {
+	folderPath string
+	rows       map[string]map[string]interface{}
+}
+
+func newCurrentState(folderPath string) *currentState {
+	return &currentState{
+		folderPath: filepath.Join(folderPath, "current"),
+		rows:       map[string]map[string]interface{}{},
+	}
+}
+
+// apply replaces the state of the entries in rows, in order, so that the latest change of an entry wins
+func (c *currentState) apply(resource string, rows []interface{}, keys []currentStateKey) {
+	if len(rows) == 0 {
+		return
+	}
+
+	state, ok := c.rows[resource]
+	if !ok {
+		state = map[string]interface{}{}
+		c.rows[resource] = state
+	}
+
+	for i, row := range rows {
+		if keys[i].key == "" {
+			continue
+		}
+		if keys[i].deleted {
+			delete(state, keys[i].key)
+		} else {
+			state[keys[i].key] = row
+		}
+	}
+}
+
+// write rewrites the snapshot file of every resource, ordered by key so that unchanged entries keep their position
+func (c *currentState) write(ctx context.Context, resources []string, cloudCredentials, cloudStorageBucket, cloudProvider string, commonArgs utils.CommonFlagValues) error {
+	for _, resource := range resources {
+		state, ok := c.rows[resource]
+		if !ok {
+			continue
+		}
+
+		keys := make([]string, 0, len(state))
+		for key := range state {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		path := filepath.Join(c.folderPath, resource+".txt")
+		outFile := mustOutFile(path)
+		for _, key := range keys {
+			if _, err := exportEntry(state[key], outFile, commonArgs); err != nil {
+				outFile.Close()
+				abortFiles([]string{outFile.path})
+				return err
+			}
+		}
+		outFile.Close()
+
+		maybeUpload(ctx, cloudCredentials, cloudStorageBucket, cloudProvider, mustCommitOutputFile(outFile))
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentStateApply(t *testing.T) {
+	current := newCurrentState(t.TempDir())
+
+	current.apply("accounts", []interface{}{
+		map[string]interface{}{"account_id": "b", "balance": 1},
+		map[string]interface{}{"account_id": "a", "balance": 1},
+		map[string]interface{}{"account_id": "b", "balance": 2},
+		map[string]interface{}{"account_id": "unknown"},
+	}, []currentStateKey{{key: "b"}, {key: "a"}, {key: "b"}, {}})
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"account_id": "a", "balance": 1},
+		"b": map[string]interface{}{"account_id": "b", "balance": 2},
+	}, current.rows["accounts"])
+
+	// a removal only drops the entry once it comes after its last update
+	current.apply("accounts", []interface{}{
+		map[string]interface{}{"account_id": "a", "deleted": true},
+		map[string]interface{}{"account_id": "b", "deleted": true},
+		map[string]interface{}{"account_id": "b", "balance": 3},
+	}, []currentStateKey{{key: "a", deleted: true}, {key: "b", deleted: true}, {key: "b"}})
+	assert.Equal(t, map[string]interface{}{
+		"b": map[string]interface{}{"account_id": "b", "balance": 3},
+	}, current.rows["accounts"])
+
+	current.apply("offers", nil, nil)
+	_, ok := current.rows["offers"]
+	assert.False(t, ok)
+}
+
+func TestCurrentStateWrite(t *testing.T) {
+	folder := t.TempDir()
+	current := newCurrentState(folder)
+	current.apply("accounts", []interface{}{
+		map[string]interface{}{"account_id": "b"},
+		map[string]interface{}{"account_id": "a"},
+	}, []currentStateKey{{key: "b"}, {key: "a"}})
+
+	// resources without any tracked change are not written
+	err := current.write(context.Background(), []string{"accounts", "offers"}, "", "", "", utils.CommonFlagValues{})
+	assert.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(folder, "current", "accounts.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"account_id\":\"a\"}\n{\"account_id\":\"b\"}\n", string(contents))
+	_, err = os.Stat(filepath.Join(folder, "current", "offers.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// tableEstimate is the extrapolated output of a single table over the requested range
+type tableEstimate struct {
+	Table            string  `json:"table"`
+	RowsPerLedger    float64 `json:"rows_per_ledger"`
+	BytesPerLedger   float64 `json:"bytes_per_ledger"`
+	EstimatedRows    int64   `json:"estimated_rows"`
+	EstimatedBytes   int64   `json:"estimated_bytes"`
+	EstimatedRuntime string  `json:"estimated_runtime"`
+}
+
+// tableSamplers read and transform the ledgers in [start, end], returning the number of rows and encoded bytes produced
+var tableSamplers = map[string]func(ctx context.Context, start, end uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (int, int, error){
+	"ledgers": func(ctx context.Context, start, end uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (int, int, error) {
+		ledgers, err := input.GetLedgers(ctx, start, end, -1, env, useCaptiveCore)
+		if err != nil {
+			return 0, 0, utils.InputError{Err: err}
+		}
+		rows, numBytes := 0, 0
+		for _, ledger := range ledgers {
+			transformed, err := transform.TransformLedger(ledger.Ledger, ledger.LCM)
+			if err != nil {
+				return 0, 0, utils.TransformError{Err: err}
+			}
+			rows, numBytes = rows+1, numBytes+encodedLength(transformed)
+		}
+		return rows, numBytes, nil
+	},
+	"transactions": func(ctx context.Context, start, end uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (int, int, error) {
+		transactions, err := input.GetTransactions(ctx, start, end, -1, env, useCaptiveCore)
+		if err != nil {
+			return 0, 0, utils.InputError{Err: err}
+		}
+		rows, numBytes := 0, 0
+		for _, transformInput := range transactions {
+			transformed, err := transform.TransformTransaction(transformInput.Transaction, transformInput.LedgerHistory)
+			if err != nil {
+				return 0, 0, utils.TransformError{Err: err}
+			}
+			rows, numBytes = rows+1, numBytes+encodedLength(transformed)
+		}
+		return rows, numBytes, nil
+	},
+	"operations": func(ctx context.Context, start, end uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (int, int, error) {
+		operations, err := input.GetOperations(ctx, start, end, -1, env, useCaptiveCore)
+		if err != nil {
+			return 0, 0, utils.InputError{Err: err}
+		}
+		rows, numBytes := 0, 0
+		for _, transformInput := range operations {
+			transformed, err := transform.TransformOperation(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+			if err != nil {
+				return 0, 0, utils.TransformError{Err: err}
+			}
+			rows, numBytes = rows+1, numBytes+encodedLength(transformed)
+		}
+		return rows, numBytes, nil
+	},
+	"effects": func(ctx context.Context, start, end uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (int, int, error) {
+		transactions, err := input.GetTransactions(ctx, start, end, -1, env, useCaptiveCore)
+		if err != nil {
+			return 0, 0, utils.InputError{Err: err}
+		}
+		rows, numBytes := 0, 0
+		for _, transformInput := range transactions {
+			effects, err := transform.TransformEffect(transformInput.Transaction, uint32(transformInput.LedgerHistory.Header.LedgerSeq), transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+			if err != nil {
+				return 0, 0, utils.TransformError{Err: err}
+			}
+			for _, effect := range effects {
+				rows, numBytes = rows+1, numBytes+encodedLength(effect)
+			}
+		}
+		return rows, numBytes, nil
+	},
+}
+
+// encodedLength is the size of the newline delimited JSON line of an entry
+func encodedLength(entry interface{}) int {
+	marshalled, err := json.Marshal(entry)
+	if err != nil {
+		return 0
+	}
+	return len(marshalled) + 1
+}
+
+// sampleRanges spreads samples of sampleSize ledgers, aligned to checkpoint boundaries, evenly across [start, end]
+func sampleRanges(start, end, samples, sampleSize uint32) [][2]uint32 {
+	ranges := [][2]uint32{}
+	if end-start+1 <= samples*sampleSize {
+		return append(ranges, [2]uint32{start, end})
+	}
+
+	step := (end - start + 1) / samples
+	for i := uint32(0); i < samples; i++ {
+		sampleStart := utils.GetMostRecentCheckpoint(start+i*step) + 1
+		if sampleStart < start {
+			sampleStart = start
+		}
+		sampleEnd := sampleStart + sampleSize - 1
+		if sampleEnd > end {
+			sampleEnd = end
+		}
+		ranges = append(ranges, [2]uint32{sampleStart, sampleEnd})
+	}
+
+	return ranges
+}
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimates the size and duration of an export.",
+	Long: `Samples a few ranges of ledgers spread across the requested range, measures the rows and bytes that each
+table produces per ledger and how long reading and transforming them takes, then extrapolates the total output size
+and runtime of exporting the whole range. Use it for capacity planning before large backfills.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		startNum, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start sequence number: ", err)
+		}
+
+		tables, err := cmd.Flags().GetStringSlice("tables")
+		if err != nil {
+			cmdLogger.Fatal("could not get tables: ", err)
+		}
+
+		samples, err := cmd.Flags().GetUint32("samples")
+		if err != nil {
+			cmdLogger.Fatal("could not get samples: ", err)
+		}
+
+		sampleSize, err := cmd.Flags().GetUint32("sample-size")
+		if err != nil {
+			cmdLogger.Fatal("could not get sample size: ", err)
+		}
+
+		if commonArgs.EndNum < startNum {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("end-ledger (%d) must not be before start-ledger (%d)", commonArgs.EndNum, startNum))
+		}
+		if samples == 0 || sampleSize == 0 {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "samples and sample-size must be greater than 0")
+		}
+
+		for _, table := range tables {
+			if _, ok := tableSamplers[table]; !ok {
+				supported := []string{}
+				for name := range tableSamplers {
+					supported = append(supported, name)
+				}
+				sort.Strings(supported)
+				cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("cannot estimate table %s; must be one of %s", table, strings.Join(supported, ", ")))
+			}
+		}
+
+		totalLedgers := float64(commonArgs.EndNum - startNum + 1)
+		ranges := sampleRanges(startNum, commonArgs.EndNum, samples, sampleSize)
+		estimates := []tableEstimate{}
+		for _, table := range tables {
+			sampledLedgers, rows, numBytes := 0, 0, 0
+			var elapsed time.Duration
+			for _, r := range ranges {
+				sampleStart := time.Now()
+				sampleRows, sampleBytes, err := tableSamplers[table](cmd.Context(), r[0], r[1], env, commonArgs.UseCaptiveCore)
+				if err != nil {
+					cmdLogger.Fatalf("could not sample %s in [%d, %d]: %v", table, r[0], r[1], err)
+				}
+				elapsed += time.Since(sampleStart)
+				sampledLedgers += int(r[1] - r[0] + 1)
+				rows += sampleRows
+				numBytes += sampleBytes
+			}
+
+			rowsPerLedger := float64(rows) / float64(sampledLedgers)
+			bytesPerLedger := float64(numBytes) / float64(sampledLedgers)
+			estimatedRuntime := time.Duration(float64(elapsed) / float64(sampledLedgers) * totalLedgers)
+			estimates = append(estimates, tableEstimate{
+				Table:            table,
+				RowsPerLedger:    rowsPerLedger,
+				BytesPerLedger:   bytesPerLedger,
+				EstimatedRows:    int64(rowsPerLedger * totalLedgers),
+				EstimatedBytes:   int64(bytesPerLedger * totalLedgers),
+				EstimatedRuntime: estimatedRuntime.Round(time.Second).String(),
+			})
+		}
+
+		output, err := json.MarshalIndent(estimates, "", "  ")
+		if err != nil {
+			cmdLogger.Fatal("could not marshal estimates: ", err)
+		}
+		fmt.Println(string(output))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+	utils.AddCommonFlags(estimateCmd.Flags())
+	estimateCmd.Flags().Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the estimated range. Defaults to genesis ledger")
+	estimateCmd.Flags().StringSlice("tables", []string{"ledgers", "transactions", "operations", "effects"}, "Tables to estimate: ledgers, transactions, operations or effects")
+	estimateCmd.Flags().Uint32("samples", 5, "Number of sampled ranges spread across the requested range")
+	estimateCmd.Flags().Uint32("sample-size", 64, "Number of ledgers in each sampled range")
+	estimateCmd.MarkFlagRequired("end-ledger")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+var exportAccountDataCmd = &cobra.Command{
+	Use:   "export_account_data",
+	Short: "This command exports the changes in account data entries.",
+	Long: `This command exports the data entries that manage data operations attach to accounts, including the
+account, name, base64 encoded value, sponsor and whether the entry was deleted.
+
+It behaves like export_ledger_entry_changes with only the --export-account-data flag set. The information is exported
+in batches determined by the batch-size flag, and if the end-ledger is omitted the command continues exporting
+new ledgers as they are confirmed by the Stellar network.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exportLedgerEntryChanges(cmd, map[string]bool{"export-account-data": true}, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportAccountDataCmd)
+	utils.AddCommonFlags(exportAccountDataCmd.Flags())
+	utils.AllowSeveralNetworks(exportAccountDataCmd.Flags())
+	utils.AddCoreFlags(exportAccountDataCmd.Flags(), "account_data_output/")
+	utils.AddCloudStorageFlags(exportAccountDataCmd.Flags())
+	exportAccountDataCmd.Flags().Int64("compact-target-bytes", 0, "If set, the files of consecutive batches are merged until they reach this size, e.g. 268435456 for 256MB, before being uploaded")
+	exportAccountDataCmd.Flags().Bool("current-state", false, "If set, a snapshot holding the latest row of every live data entry is rewritten to the current/ folder after each batch, next to the history of changes")
+	addContinuousFlags(exportAccountDataCmd.Flags())
+
+	exportAccountDataCmd.MarkFlagRequired("start-ledger")
+}
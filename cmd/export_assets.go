@@ -28,12 +28,12 @@ var assetsCmd = &cobra.Command{
 		var err error
 
 		if commonArgs.UseCaptiveCore {
-			paymentOps, err = input.GetPaymentOperationsHistoryArchive(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			paymentOps, err = input.GetPaymentOperationsHistoryArchive(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		} else {
-			paymentOps, err = input.GetPaymentOperations(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			paymentOps, err = input.GetPaymentOperations(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		}
 		if err != nil {
-			cmdLogger.Fatal("could not read asset: ", err)
+			cmdLogger.Fatal("could not read asset: ", utils.InputError{Err: err})
 		}
 
 		// With seenIDs, the code doesn't export duplicate assets within a single export. Note that across exports, assets may be duplicated
@@ -44,7 +44,7 @@ var assetsCmd = &cobra.Command{
 			transformed, err := transform.TransformAsset(transformInput.Operation, transformInput.OperationIndex, transformInput.TransactionIndex, transformInput.LedgerSeqNum)
 			if err != nil {
 				txIndex := transformInput.TransactionIndex
-				cmdLogger.LogError(fmt.Errorf("could not extract asset from operation %d in transaction %d in ledger %d: ", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not extract asset from operation %d in transaction %d in ledger %d: ", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum)})
 				numFailures += 1
 				continue
 			}
@@ -55,7 +55,7 @@ var assetsCmd = &cobra.Command{
 			}
 
 			seenIDs[transformed.AssetID] = true
-			numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+			numBytes, err := exportEntry(transformed, outFile, commonArgs)
 			if err != nil {
 				cmdLogger.Error(err)
 				numFailures += 1
@@ -69,7 +69,9 @@ var assetsCmd = &cobra.Command{
 
 		printTransformStats(len(paymentOps), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
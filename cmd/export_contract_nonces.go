@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+var exportContractNoncesCmd = &cobra.Command{
+	Use:   "export_contract_nonces",
+	Short: "This command exports the changes in contract nonce entries.",
+	Long: `This command exports the nonces that addresses consume to protect their Soroban authorizations against replay,
+including the address, the nonce value and the ledger the nonce entry lives until.
+
+It behaves like export_ledger_entry_changes with only the --export-contract-nonces and --join-ttl flags set. The
+information is exported in batches determined by the batch-size flag, and if the end-ledger is omitted the command
+continues exporting new ledgers as they are confirmed by the Stellar network.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exportLedgerEntryChanges(cmd, map[string]bool{"export-contract-nonces": true}, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportContractNoncesCmd)
+	utils.AddCommonFlags(exportContractNoncesCmd.Flags())
+	utils.AllowSeveralNetworks(exportContractNoncesCmd.Flags())
+	utils.AddCoreFlags(exportContractNoncesCmd.Flags(), "contract_nonces_output/")
+	utils.AddCloudStorageFlags(exportContractNoncesCmd.Flags())
+	exportContractNoncesCmd.Flags().Int64("compact-target-bytes", 0, "If set, the files of consecutive batches are merged until they reach this size, e.g. 268435456 for 256MB, before being uploaded")
+	exportContractNoncesCmd.Flags().Bool("current-state", false, "If set, a snapshot holding the latest row of every live nonce entry is rewritten to the current/ folder after each batch, next to the history of changes")
+	addContinuousFlags(exportContractNoncesCmd.Flags())
+
+	exportContractNoncesCmd.MarkFlagRequired("start-ledger")
+}
@@ -22,18 +22,18 @@ var diagnosticEventsCmd = &cobra.Command{
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
 
-		transactions, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		if err != nil {
-			cmdLogger.Fatal("could not read transactions: ", err)
+			cmdLogger.Fatal("could not read transactions: ", utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
 		numFailures := 0
 		for _, transformInput := range transactions {
-			transformed, err, ok := transform.TransformDiagnosticEvent(transformInput.Transaction, transformInput.LedgerHistory)
+			transformed, err, ok := transform.TransformDiagnosticEvent(transformInput.Transaction, transformInput.LedgerHistory, env.NetworkPassphrase)
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform diagnostic events in transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform diagnostic events in transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq)})
 				numFailures += 1
 				continue
 			}
@@ -42,9 +42,9 @@ var diagnosticEventsCmd = &cobra.Command{
 				continue
 			}
 			for _, diagnosticEvent := range transformed {
-				_, err := exportEntry(diagnosticEvent, outFile, commonArgs.Extra)
+				_, err := exportEntry(diagnosticEvent, outFile, commonArgs)
 				if err != nil {
-					cmdLogger.LogError(fmt.Errorf("could not export diagnostic event: %v", err))
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export diagnostic event: %v", err)})
 					numFailures += 1
 					continue
 				}
@@ -55,7 +55,9 @@ var diagnosticEventsCmd = &cobra.Command{
 
 		printTransformStats(len(transactions), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
@@ -20,9 +20,9 @@ var effectsCmd = &cobra.Command{
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
 
-		transactions, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		if err != nil {
-			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
+			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
@@ -39,7 +39,7 @@ var effectsCmd = &cobra.Command{
 			}
 
 			for _, transformed := range effects {
-				numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+				numBytes, err := exportEntry(transformed, outFile, commonArgs)
 				if err != nil {
 					cmdLogger.LogError(err)
 					numFailures += 1
@@ -54,7 +54,9 @@ var effectsCmd = &cobra.Command{
 
 		printTransformStats(len(transactions), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
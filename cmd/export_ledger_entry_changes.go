@@ -2,12 +2,17 @@ package cmd
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/ingest/ledgerbackend"
 	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/internal/input"
@@ -17,34 +22,22 @@ import (
 
 var exportLedgerEntryChangesCmd = &cobra.Command{
 	Use:   "export_ledger_entry_changes",
-	Short: "This command exports the changes in accounts, offers, trustlines and liquidity pools.",
-	Long: `This command instantiates a stellar-core instance and uses it to export about accounts, offers, trustlines and liquidity pools.
+	Short: "This command exports the changes in accounts, account data, offers, trustlines and liquidity pools.",
+	Long: `This command instantiates a stellar-core instance and uses it to export about accounts, account data, offers, trustlines and liquidity pools.
 The information is exported in batches determined by the batch-size flag. Each exported file will include the changes to the 
 relevant data type that occurred during that batch.
 
 If the end-ledger is omitted, then the stellar-core node will continue running and exporting information as new ledgers are 
 confirmed by the Stellar network. 
 
-If no data type flags are set, then by default all of them are exported. If any are set, it is assumed that the others should not
-be exported.`,
+If no data type flags are set, then by default all of them are exported, except contract nonces which are only exported
+when --export-contract-nonces is set. If any are set, it is assumed that the others should not be exported.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
-		cmdLogger.StrictExport = commonArgs.StrictExport
-		env := utils.GetEnvironmentDetails(commonArgs)
-
-		_, configPath, startNum, batchSize, outputFolder := utils.MustCoreFlags(cmd.Flags(), cmdLogger)
 		exports := utils.MustExportTypeFlags(cmd.Flags(), cmdLogger)
-		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
-
-		cmd.Flags()
 
-		err := os.MkdirAll(outputFolder, os.ModePerm)
+		joinTtl, err := cmd.Flags().GetBool("join-ttl")
 		if err != nil {
-			cmdLogger.Fatalf("unable to mkdir %s: %v", outputFolder, err)
-		}
-
-		if batchSize <= 0 {
-			cmdLogger.Fatalf("batch-size (%d) must be greater than 0", batchSize)
+			cmdLogger.Fatal("could not get join-ttl flag: ", err)
 		}
 
 		// If none of the export flags are set, then we assume that everything should be exported
@@ -58,239 +51,615 @@ be exported.`,
 
 		if allFalse {
 			for export_name := range exports {
-				exports[export_name] = true
+				// Nonces are only used for replay protection analysis, so they are opt-in
+				exports[export_name] = export_name != "export-contract-nonces"
 			}
 		}
 
-		if configPath == "" && commonArgs.EndNum == 0 {
-			cmdLogger.Fatal("stellar-core needs a config file path when exporting ledgers continuously (endNum = 0)")
+		exportLedgerEntryChanges(cmd, exports, joinTtl)
+	},
+}
+
+// exportLedgerEntryChanges streams the ledger entry changes of the requested range and exports the types enabled in exports.
+// When several networks are selected, they are exported concurrently, each into a subfolder of the output folder named
+// after it, with its health endpoints served below /<network>.
+func exportLedgerEntryChanges(cmd *cobra.Command, exports map[string]bool, joinTtl bool) {
+	commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+	cmdLogger.StrictExport = commonArgs.StrictExport
+
+	_, _, startNum, _, outputFolder := utils.MustCoreFlags(cmd.Flags(), cmdLogger)
+
+	healthAddr, err := cmd.Flags().GetString("health-addr")
+	if err != nil {
+		cmdLogger.Fatal("could not get health-addr: ", err)
+	}
+
+	var healthMux *http.ServeMux
+	if healthAddr != "" {
+		healthMux = http.NewServeMux()
+		if err := serveHealth(healthAddr, healthMux); err != nil {
+			cmdLogger.Fatalf("could not serve health endpoints on %s: %v", healthAddr, err)
+		}
+	}
+
+	controlAddr, err := cmd.Flags().GetString("control-addr")
+	if err != nil {
+		cmdLogger.Fatal("could not get control-addr: ", err)
+	}
+
+	var controlMux *http.ServeMux
+	if controlAddr != "" {
+		controlMux = http.NewServeMux()
+		if err := serveControl(controlAddr, controlMux); err != nil {
+			cmdLogger.Fatalf("could not serve control endpoints on %s: %v", controlAddr, err)
+		}
+	}
+
+	// Failed checks, like the lag check, stop every network of the export by cancelling the command context with their error
+	ctx, stop := context.WithCancelCause(cmd.Context())
+	defer stop(nil)
+	cmd.SetContext(ctx)
+
+	if len(commonArgs.Networks) <= 1 {
+		if len(commonArgs.Networks) == 1 && commonArgs.Networks[0].Start != 0 {
+			startNum, commonArgs.EndNum = alignedNetworkRange(commonArgs.Networks[0], commonArgs.AlignCheckpoints)
 		}
+		exportNetworkLedgerEntryChanges(cmd, stop, exports, joinTtl, commonArgs, startNum, outputFolder, healthMux, controlMux, "")
+		return
+	}
 
-		ctx := context.Background()
-		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
-		if err != nil {
-			cmdLogger.Fatal("error creating a cloud storage backend: ", err)
+	var wg sync.WaitGroup
+	for _, network := range commonArgs.Networks {
+		networkArgs := commonArgs.ForNetwork(network.Network)
+		networkStart := startNum
+		if network.Start != 0 {
+			networkStart, networkArgs.EndNum = alignedNetworkRange(network, commonArgs.AlignCheckpoints)
 		}
 
-		err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(startNum, commonArgs.EndNum))
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			exportNetworkLedgerEntryChanges(cmd, stop, exports, joinTtl, networkArgs, networkStart, filepath.Join(outputFolder, name), healthMux, controlMux, "/"+name)
+		}(network.Network)
+	}
+	wg.Wait()
+}
+
+// exportNetworkLedgerEntryChanges exports the ledger entry changes of the network selected by commonArgs, starting at
+// startNum, into outputFolder. Its health and control endpoints are registered below endpointPrefix on healthMux and
+// controlMux, when they are set. Failed checks stop the export with stop.
+func exportNetworkLedgerEntryChanges(cmd *cobra.Command, stop context.CancelCauseFunc, exports map[string]bool, joinTtl bool, commonArgs utils.CommonFlagValues, startNum uint32, outputFolder string, healthMux, controlMux *http.ServeMux, endpointPrefix string) {
+	env := utils.GetEnvironmentDetails(commonArgs)
+
+	_, configPath, _, batchSize, _ := utils.MustCoreFlags(cmd.Flags(), cmdLogger)
+	cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+
+	// the rows of a sink output are appended to the sink instead of written to the folder
+	if _, _, ok := outputRowSink(outputFolder); !ok {
+		err := os.MkdirAll(outputFolder, os.ModePerm)
 		if err != nil {
-			cmdLogger.Fatal("error preparing ledger range for cloud storage backend: ", err)
+			cmdLogger.Fatalf("unable to mkdir %s: %v", outputFolder, utils.SinkError{Err: err})
 		}
+	}
+
+	if configPath == "" && commonArgs.EndNum == 0 {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, "stellar-core needs a config file path when exporting ledgers continuously (endNum = 0)")
+	}
+
+	ctx := cmd.Context()
+	backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+	if err != nil {
+		cmdLogger.Fatal("error creating a cloud storage backend: ", utils.InputError{Err: err})
+	}
+
+	err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(startNum, commonArgs.EndNum))
+	if err != nil {
+		cmdLogger.Fatal("error preparing ledger range for cloud storage backend: ", utils.InputError{Err: err})
+	}
+
+	if commonArgs.EndNum == 0 {
+		commonArgs.EndNum = math.MaxInt32
+	}
+
+	changeChan := make(chan input.ChangeBatch)
+	closeChan := make(chan int)
+
+	compactTargetBytes, err := cmd.Flags().GetInt64("compact-target-bytes")
+	if err != nil {
+		cmdLogger.Fatal("could not get compact-target-bytes: ", err)
+	}
+
+	var compactor *batchCompactor
+	if compactTargetBytes > 0 {
+		compactor = newBatchCompactor(compactTargetBytes, outputFolder, cloudCredentials, cloudStorageBucket, cloudProvider, commonArgs)
+	}
+
+	trackCurrentState, err := cmd.Flags().GetBool("current-state")
+	if err != nil {
+		cmdLogger.Fatal("could not get current-state flag: ", err)
+	}
+
+	splitTemporary, err := cmd.Flags().GetBool("split-temporary-contract-data")
+	if err != nil {
+		cmdLogger.Fatal("could not get split-temporary-contract-data flag: ", err)
+	}
 
-		if commonArgs.EndNum == 0 {
-			commonArgs.EndNum = math.MaxInt32
+	var current *currentState
+	if trackCurrentState {
+		current = newCurrentState(outputFolder)
+	}
+
+	maxLagLedgers, err := cmd.Flags().GetUint32("max-lag-ledgers")
+	if err != nil {
+		cmdLogger.Fatal("could not get max-lag-ledgers: ", err)
+	}
+
+	var control *exportControl
+	if controlMux != nil {
+		control = newExportControl()
+		control.handle(controlMux, endpointPrefix)
+	}
+
+	var health *exportHealth
+	if healthMux != nil || maxLagLedgers > 0 {
+		health = &exportHealth{maxLag: maxLagLedgers, stop: stop, control: control}
+		go health.watch(ctx, backend, env.ArchiveURLs)
+	}
+	if healthMux != nil {
+		health.handle(healthMux, endpointPrefix)
+	}
+	flushCompactor := func() error {
+		if compactor == nil {
+			return nil
 		}
+		return compactor.flush(ctx)
+	}
 
-		changeChan := make(chan input.ChangeBatch)
-		closeChan := make(chan int)
-		go input.StreamChanges(&backend, startNum, commonArgs.EndNum, batchSize, changeChan, closeChan, env, cmdLogger)
-
-		for {
-			select {
-			case <-closeChan:
-				return
-			case batch, ok := <-changeChan:
-				if !ok {
-					continue
-				}
-				transformedOutputs := map[string][]interface{}{
-					"accounts":           {},
-					"signers":            {},
-					"claimable_balances": {},
-					"offers":             {},
-					"trustlines":         {},
-					"liquidity_pools":    {},
-					"contract_data":      {},
-					"contract_code":      {},
-					"config_settings":    {},
-					"ttl":                {},
+	var batchTuner *utils.BatchTuner
+	if commonArgs.AutoTune {
+		batchTuner = utils.NewBatchTuner(batchSize, cmdLogger)
+		go input.StreamChangesWithBatchSizer(ctx, &backend, startNum, commonArgs.EndNum, batchTuner.BatchSize, changeChan, closeChan, env, cmdLogger)
+	} else {
+		go input.StreamChanges(ctx, &backend, startNum, commonArgs.EndNum, batchSize, changeChan, closeChan, env, cmdLogger)
+	}
+
+	// Live until ledgers of the live ttl entries seen so far, keyed by the ledger key hash of the entry they extend
+	liveUntilByKeyHash := map[string]uint32{}
+
+	for {
+		control.waitWhilePaused(ctx, flushCompactor)
+		select {
+		case <-closeChan:
+			if err := flushCompactor(); err != nil {
+				cmdLogger.LogError(utils.SinkError{Err: err})
+				compactor.abort()
+			}
+			return
+		case <-ctx.Done():
+			// the command exits with the cause of the cancellation; batches that were not committed are exported again
+			// by the next run
+			if compactor != nil {
+				compactor.abort()
+			}
+			return
+		case <-control.pauseRequests():
+			continue
+		case done := <-control.flushRequests():
+			done <- flushCompactor()
+		case batch, ok := <-changeChan:
+			if !ok {
+				continue
+			}
+			control.batchStarted(batch.BatchStart, batch.BatchEnd)
+			// Keys of the ledger entries the transformed outputs describe, kept in the same order when tracking the current state
+			transformedKeys := map[string][]currentStateKey{}
+			trackEntry := func(resource string, output interface{}, change ingest.Change, cause transform.LedgerEntryChangeCause) interface{} {
+				if current != nil {
+					transformedKeys[resource] = append(transformedKeys[resource], rowCurrentStateKey(output, change))
 				}
+				return withChangeCause(withLedgerEntryXDR(output, change, commonArgs), cause)
+			}
+
+			transformedOutputs := map[string][]interface{}{
+				"accounts":           {},
+				"account_data":       {},
+				"signers":            {},
+				"claimable_balances": {},
+				"offers":             {},
+				"trustlines":         {},
+				"liquidity_pools":    {},
+				"contract_data":      {},
+				"contract_nonces":    {},
+				"contract_code":      {},
+				"config_settings":    {},
+				"ttl":                {},
+				"evicted_entries":    {},
+			}
+			if splitTemporary {
+				transformedOutputs[temporaryContractDataResource] = []interface{}{}
+			}
 
-				for entryType, changes := range batch.Changes {
-					switch entryType {
-					case xdr.LedgerEntryTypeAccount:
-						if !exports["export-accounts"] {
+			for entryType, changes := range batch.Changes {
+				switch entryType {
+				case xdr.LedgerEntryTypeAccount:
+					if !exports["export-accounts"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						if changed, err := change.AccountChangedExceptSigners(); err != nil {
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("unable to identify changed accounts: %v", err)})
 							continue
-						}
-						for i, change := range changes.Changes {
-							if changed, err := change.AccountChangedExceptSigners(); err != nil {
-								cmdLogger.LogError(fmt.Errorf("unable to identify changed accounts: %v", err))
+						} else if changed {
+
+							acc, err := transform.TransformAccount(change, changes.LedgerHeaders[i])
+							if err != nil {
+								entry, _, _, _ := utils.ExtractEntryFromChange(change)
+								cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming account entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 								continue
-							} else if changed {
-
-								acc, err := transform.TransformAccount(change, changes.LedgerHeaders[i])
-								if err != nil {
-									entry, _, _, _ := utils.ExtractEntryFromChange(change)
-									cmdLogger.LogError(fmt.Errorf("error transforming account entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-									continue
-								}
-								transformedOutputs["accounts"] = append(transformedOutputs["accounts"], acc)
-							}
-							if utils.AccountSignersChanged(change) {
-								signers, err := transform.TransformSigners(change, changes.LedgerHeaders[i])
-								if err != nil {
-									entry, _, _, _ := utils.ExtractEntryFromChange(change)
-									cmdLogger.LogError(fmt.Errorf("error transforming account signers from %d :%s", entry.LastModifiedLedgerSeq, err))
-									continue
-								}
-								for _, s := range signers {
-									transformedOutputs["signers"] = append(transformedOutputs["signers"], s)
-								}
 							}
+							transformedOutputs["accounts"] = append(transformedOutputs["accounts"], trackEntry("accounts", acc, change, changes.Causes[i]))
 						}
-					case xdr.LedgerEntryTypeClaimableBalance:
-						if !exports["export-balances"] {
-							continue
-						}
-						for i, change := range changes.Changes {
-							balance, err := transform.TransformClaimableBalance(change, changes.LedgerHeaders[i])
+						if utils.AccountSignersChanged(change) {
+							signers, err := transform.TransformSigners(change, changes.LedgerHeaders[i])
 							if err != nil {
 								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming balance entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
+								cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming account signers from %d :%s", entry.LastModifiedLedgerSeq, err)})
 								continue
 							}
-							transformedOutputs["claimable_balances"] = append(transformedOutputs["claimable_balances"], balance)
+							for _, s := range signers {
+								transformedOutputs["signers"] = append(transformedOutputs["signers"], trackEntry("signers", s, change, changes.Causes[i]))
+							}
 						}
-					case xdr.LedgerEntryTypeOffer:
-						if !exports["export-offers"] {
+					}
+				case xdr.LedgerEntryTypeData:
+					if !exports["export-account-data"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						data, err := transform.TransformAccountData(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming account data entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 							continue
 						}
-						for i, change := range changes.Changes {
-							offer, err := transform.TransformOffer(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming offer entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["offers"] = append(transformedOutputs["offers"], offer)
+						transformedOutputs["account_data"] = append(transformedOutputs["account_data"], trackEntry("account_data", data, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeClaimableBalance:
+					if !exports["export-balances"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						balance, err := transform.TransformClaimableBalance(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming balance entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
+							continue
 						}
-					case xdr.LedgerEntryTypeTrustline:
-						if !exports["export-trustlines"] {
+						transformedOutputs["claimable_balances"] = append(transformedOutputs["claimable_balances"], trackEntry("claimable_balances", balance, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeOffer:
+					if !exports["export-offers"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						offer, err := transform.TransformOffer(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming offer entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 							continue
 						}
-						for i, change := range changes.Changes {
-							trust, err := transform.TransformTrustline(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming trustline entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["trustlines"] = append(transformedOutputs["trustlines"], trust)
+						transformedOutputs["offers"] = append(transformedOutputs["offers"], trackEntry("offers", offer, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeTrustline:
+					if !exports["export-trustlines"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						trust, err := transform.TransformTrustline(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming trustline entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
+							continue
 						}
-					case xdr.LedgerEntryTypeLiquidityPool:
-						if !exports["export-pools"] {
+						transformedOutputs["trustlines"] = append(transformedOutputs["trustlines"], trackEntry("trustlines", trust, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeLiquidityPool:
+					if !exports["export-pools"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						pool, err := transform.TransformPool(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming liquidity pool entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 							continue
 						}
-						for i, change := range changes.Changes {
-							pool, err := transform.TransformPool(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming liquidity pool entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
+						transformedOutputs["liquidity_pools"] = append(transformedOutputs["liquidity_pools"], trackEntry("liquidity_pools", pool, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeContractData:
+					if !exports["export-contract-data"] && !exports["export-contract-nonces"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						contractNonce, err, isNonce := transform.TransformContractNonce(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming contract nonce entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
+							continue
+						}
+
+						if isNonce {
+							if exports["export-contract-nonces"] {
+								transformedOutputs["contract_nonces"] = append(transformedOutputs["contract_nonces"], trackEntry("contract_nonces", contractNonce, change, changes.Causes[i]))
 							}
-							transformedOutputs["liquidity_pools"] = append(transformedOutputs["liquidity_pools"], pool)
+							continue
 						}
-					case xdr.LedgerEntryTypeContractData:
+
 						if !exports["export-contract-data"] {
 							continue
 						}
-						for i, change := range changes.Changes {
-							TransformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
-							contractData, err, _ := TransformContractData.TransformContractData(change, env.NetworkPassphrase, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming contract data entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-
-							// Empty contract data that has no error is a nonce. Does not need to be recorded
-							if contractData == (transform.ContractDataOutput{}) {
-								continue
-							}
 
-							transformedOutputs["contract_data"] = append(transformedOutputs["contract_data"], contractData)
+						TransformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
+						contractData, err, ok := TransformContractData.TransformContractData(change, env.NetworkPassphrase, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming contract data entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
+							continue
 						}
-					case xdr.LedgerEntryTypeContractCode:
-						if !exports["export-contract-code"] {
+						if !ok {
 							continue
 						}
-						for i, change := range changes.Changes {
-							contractCode, err := transform.TransformContractCode(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming contract code entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["contract_code"] = append(transformedOutputs["contract_code"], contractCode)
+
+						resource := "contract_data"
+						if splitTemporary && contractData.ContractDurability == xdr.ContractDataDurabilityTemporary.String() {
+							resource = temporaryContractDataResource
 						}
-					case xdr.LedgerEntryTypeConfigSetting:
-						if !exports["export-config-settings"] {
+						transformedOutputs[resource] = append(transformedOutputs[resource], trackEntry(resource, contractData, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeContractCode:
+					if !exports["export-contract-code"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						contractCode, err := transform.TransformContractCode(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming contract code entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 							continue
 						}
-						for i, change := range changes.Changes {
-							configSettings, err := transform.TransformConfigSetting(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming config settings entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["config_settings"] = append(transformedOutputs["config_settings"], configSettings)
+						transformedOutputs["contract_code"] = append(transformedOutputs["contract_code"], trackEntry("contract_code", contractCode, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeConfigSetting:
+					if !exports["export-config-settings"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						configSettings, err := transform.TransformConfigSetting(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming config settings entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
+							continue
 						}
-					case xdr.LedgerEntryTypeTtl:
-						if !exports["export-ttl"] {
+						transformedOutputs["config_settings"] = append(transformedOutputs["config_settings"], trackEntry("config_settings", configSettings, change, changes.Causes[i]))
+					}
+				case xdr.LedgerEntryTypeTtl:
+					if !exports["export-ttl"] && !joinTtl {
+						continue
+					}
+					for i, change := range changes.Changes {
+						ttl, err := transform.TransformTtl(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming ttl entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err)})
 							continue
 						}
-						for i, change := range changes.Changes {
-							ttl, err := transform.TransformTtl(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming ttl entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["ttl"] = append(transformedOutputs["ttl"], ttl)
+
+						if ttl.Deleted {
+							delete(liveUntilByKeyHash, ttl.KeyHash)
+						} else {
+							liveUntilByKeyHash[ttl.KeyHash] = ttl.LiveUntilLedgerSeq
+						}
+
+						if exports["export-ttl"] {
+							transformedOutputs["ttl"] = append(transformedOutputs["ttl"], trackEntry("ttl", ttl, change, changes.Causes[i]))
 						}
 					}
 				}
+			}
 
-				err := exportTransformedData(
-					batch.BatchStart,
-					batch.BatchEnd,
-					outputFolder,
-					transformedOutputs,
-					cloudCredentials,
-					cloudStorageBucket,
-					cloudProvider,
-					commonArgs.Extra,
-				)
-				if err != nil {
-					cmdLogger.LogError(err)
-					continue
+			if exports["export-evicted-entries"] {
+				for _, eviction := range batch.Evictions {
+					for _, key := range eviction.Keys {
+						evicted, err := transform.TransformEvictedEntry(key, eviction.LedgerHeader)
+						if err != nil {
+							cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("error transforming entry evicted in ledger %d: %s", eviction.LedgerHeader.Header.LedgerSeq, err)})
+							continue
+						}
+						transformedOutputs["evicted_entries"] = append(transformedOutputs["evicted_entries"], evicted)
+					}
+				}
+			}
+
+			if joinTtl {
+				joinContractDataTtl := func(output interface{}) interface{} {
+					contractData := output.(transform.ContractDataOutput)
+					if liveUntil, ok := liveUntilByKeyHash[contractData.LedgerKeyHash]; ok {
+						return transform.JoinContractDataTtl(contractData, liveUntil)
+					}
+					return contractData
+				}
+				joinLiveUntil(transformedOutputs["contract_data"], joinContractDataTtl)
+				joinLiveUntil(transformedOutputs[temporaryContractDataResource], joinContractDataTtl)
+				joinLiveUntil(transformedOutputs["contract_nonces"], func(output interface{}) interface{} {
+					contractNonce := output.(transform.ContractNonceOutput)
+					if liveUntil, ok := liveUntilByKeyHash[contractNonce.LedgerKeyHash]; ok {
+						return transform.JoinContractNonceTtl(contractNonce, liveUntil)
+					}
+					return contractNonce
+				})
+				pruneLiveUntil(liveUntilByKeyHash, batch.Evictions, batch.BatchEnd)
+			}
+
+			exportStart := time.Now()
+			err := exportTransformedData(
+				ctx,
+				batch.BatchStart,
+				batch.BatchEnd,
+				outputFolder,
+				transformedOutputs,
+				cloudCredentials,
+				cloudStorageBucket,
+				cloudProvider,
+				commonArgs,
+				compactor,
+			)
+			if batchTuner != nil {
+				batchTuner.ObserveSinkLatency(time.Since(exportStart))
+			}
+			if err != nil {
+				cmdLogger.LogError(utils.SinkError{Err: err})
+				continue
+			}
+
+			control.batchExported(batch.BatchEnd)
+			if health != nil {
+				health.processed(batch.BatchEnd)
+				health.stopIfLagging()
+			}
+
+			if current != nil {
+				// the state only takes the changes of committed batches, so that it never holds rows missing from
+				// the history. Only the tracked ledger entries have a current state; evictions are events.
+				changedResources := []string{}
+				for resource, keys := range transformedKeys {
+					current.apply(resource, transformedOutputs[resource], keys)
+					changedResources = append(changedResources, resource)
+				}
+				if err := current.write(ctx, changedResources, cloudCredentials, cloudStorageBucket, cloudProvider, commonArgs); err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: err})
 				}
 			}
 		}
-	},
+	}
+}
+
+// temporaryContractDataResource is the resource temporary contract data is written to with --split-temporary-contract-data.
+// Temporary entries are created and evicted at a much higher rate than persistent ones.
+const temporaryContractDataResource = "contract_data_temporary"
+
+// pruneLiveUntil drops the live until ledgers of the entries evicted in the batch, and of the entries that expired
+// before the next batch. Expired entries only change again once they are restored, which changes their ttl entry.
+func pruneLiveUntil(liveUntilByKeyHash map[string]uint32, evictions []input.LedgerEvictions, nextLedger uint32) {
+	for _, eviction := range evictions {
+		for _, key := range eviction.Keys {
+			if ttl, ok := key.GetTtl(); ok {
+				delete(liveUntilByKeyHash, hex.EncodeToString(ttl.KeyHash[:]))
+			} else {
+				delete(liveUntilByKeyHash, utils.LedgerKeyToLedgerKeyHash(key))
+			}
+		}
+	}
+
+	for keyHash, liveUntil := range liveUntilByKeyHash {
+		if liveUntil < nextLedger {
+			delete(liveUntilByKeyHash, keyHash)
+		}
+	}
+}
+
+// joinLiveUntil replaces each output with the result of join, keeping the columns added to it
+func joinLiveUntil(outputs []interface{}, join func(output interface{}) interface{}) {
+	for i, output := range outputs {
+		extended, isExtended := output.(extendedEntry)
+		if !isExtended {
+			outputs[i] = join(output)
+			continue
+		}
+
+		extended.entry = join(extended.entry)
+		outputs[i] = extended
+	}
+}
+
+// withLedgerEntryXDR attaches the ledger entry of the change to the transformed output if --include-xdr is set
+func withLedgerEntryXDR(output interface{}, change ingest.Change, commonArgs utils.CommonFlagValues) interface{} {
+	entry, _, _, err := utils.ExtractEntryFromChange(change)
+	if err != nil {
+		return output
+	}
+	return withRawXDR(output, "ledger_entry_xdr", &entry, commonArgs)
+}
+
+// withChangeCause adds the columns identifying the transaction and operation, upgrade or eviction that caused the change
+func withChangeCause(output interface{}, cause transform.LedgerEntryChangeCause) interface{} {
+	var transactionHash, operationIndex interface{}
+	if cause.TransactionHash != "" {
+		transactionHash = cause.TransactionHash
+	}
+	if cause.OperationIndex.Valid {
+		operationIndex = cause.OperationIndex.Int64
+	}
+	return withColumns(output, map[string]interface{}{
+		"transaction_hash": transactionHash,
+		"operation_index":  operationIndex,
+		"operation_type":   cause.OperationType,
+	})
 }
 
 func exportTransformedData(
+	ctx context.Context,
 	start, end uint32,
 	folderPath string,
 	transformedOutput map[string][]interface{},
 	cloudCredentials, cloudStorageBucket, cloudProvider string,
-	extra map[string]string) error {
+	commonArgs utils.CommonFlagValues,
+	compactor *batchCompactor) error {
 
+	// The files of every resource are staged first and only committed once the whole batch is written, so that loaders
+	// never pick up a batch with some of its files missing or truncated
+	staged := []string{}
 	for resource, output := range transformedOutput {
 		// Filenames are typically exclusive of end point. This processor
 		// is different and we have to increment by 1 since the end batch number
 		// is included in this filename.
 		path := filepath.Join(folderPath, exportFilename(start, end+1, resource))
+		_, _, isRowSink := outputRowSink(folderPath)
+		if isRowSink {
+			// every resource is appended to its own table of the sink
+			path = folderPath
+		}
 		outFile := mustOutFile(path)
+		if len(output) > 0 {
+			// resources sharing an output struct, like the temporary contract data, are told apart by name
+			outFile.table = resource
+		}
 		for _, o := range output {
-			_, err := exportEntry(o, outFile, extra)
+			_, err := exportEntry(o, outFile, commonArgs)
 			if err != nil {
+				outFile.Close()
+				abortFiles(append(staged, outFile.path))
 				return err
 			}
 		}
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		outFile.Close()
+		if compactor != nil {
+			if err := compactor.add(ctx, resource, outFile, start, end); err != nil {
+				// the batches the compactor holds are dropped along with this one, so that no stale file stays staged
+				compactor.abort()
+				abortFiles(staged)
+				return err
+			}
+			continue
+		}
+		staged = append(staged, stageOutputFile(ctx, outFile, start, end, commonArgs)...)
+	}
+
+	committed, err := commitFiles(staged)
+	if err != nil {
+		return utils.SinkError{Err: err}
+	}
+	for _, outputPath := range committed {
+		maybeUpload(ctx, cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
 	}
 
 	return nil
@@ -299,9 +668,18 @@ func exportTransformedData(
 func init() {
 	rootCmd.AddCommand(exportLedgerEntryChangesCmd)
 	utils.AddCommonFlags(exportLedgerEntryChangesCmd.Flags())
+	utils.AllowSeveralNetworks(exportLedgerEntryChangesCmd.Flags())
 	utils.AddCoreFlags(exportLedgerEntryChangesCmd.Flags(), "changes_output/")
 	utils.AddExportTypeFlags(exportLedgerEntryChangesCmd.Flags())
 	utils.AddCloudStorageFlags(exportLedgerEntryChangesCmd.Flags())
+	exportLedgerEntryChangesCmd.Flags().Int64("compact-target-bytes", 0, "If set, the files of consecutive batches are merged until they reach this size, e.g. 268435456 for 256MB, before being uploaded")
+	exportLedgerEntryChangesCmd.Flags().Bool("current-state", false, "If set, a snapshot holding the latest row of every live ledger entry changed since the start ledger is rewritten to the current/ folder after each batch, next to the history of changes. "+
+		"The snapshot is not seeded from a checkpoint, and it is kept in memory")
+	addContinuousFlags(exportLedgerEntryChangesCmd.Flags())
+	exportLedgerEntryChangesCmd.Flags().Bool("split-temporary-contract-data", false, "If set, contract data entries with temporary durability are written "+
+		"to their own contract_data_temporary files instead of the contract_data files, which then only hold persistent entries")
+	exportLedgerEntryChangesCmd.Flags().Bool("join-ttl", false, "If set, contract data and contract nonce entries are joined with their ttl entries to add the live_until_ledger_seq column, and the expired column of contract data. "+
+		"Only the ttl entries changed since the start ledger are joined")
 
 	exportLedgerEntryChangesCmd.MarkFlagRequired("start-ledger")
 	/*
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"fmt"
 	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stretchr/testify/assert"
 )
 
 const coreExecutablePath = "../stellar-core/src/stellar-core"
@@ -41,3 +47,28 @@ func TestExportChanges(t *testing.T) {
 		runCLITest(t, test, "testdata/changes/")
 	}
 }
+
+func TestPruneLiveUntil(t *testing.T) {
+	dataKey := xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractCode,
+		ContractCode: &xdr.LedgerKeyContractCode{Hash: xdr.Hash{1}},
+	}
+	dataKeyHash := utils.LedgerKeyToLedgerKeyHash(dataKey)
+	var ttlKeyHash xdr.Hash
+	ttlKeyHash[0] = 2
+	ttlKey := xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeTtl,
+		Ttl:  &xdr.LedgerKeyTtl{KeyHash: ttlKeyHash},
+	}
+
+	liveUntilByKeyHash := map[string]uint32{
+		dataKeyHash:                       200,
+		hex.EncodeToString(ttlKeyHash[:]): 200,
+		"expired":                         99,
+		"expiring":                        100,
+		"live":                            200,
+	}
+	evictions := []input.LedgerEvictions{{Keys: []xdr.LedgerKey{dataKey, ttlKey}}}
+	pruneLiveUntil(liveUntilByKeyHash, evictions, 100)
+	assert.Equal(t, map[string]uint32{"expiring": 100, "live": 200}, liveUntilByKeyHash)
+}
@@ -22,9 +22,9 @@ var ledgerTransactionCmd = &cobra.Command{
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
 
-		ledgerTransaction, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		ledgerTransaction, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		if err != nil {
-			cmdLogger.Fatal("could not read ledger_transaction: ", err)
+			cmdLogger.Fatal("could not read ledger_transaction: ", utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
@@ -34,14 +34,14 @@ var ledgerTransactionCmd = &cobra.Command{
 			transformed, err := transform.TransformLedgerTransaction(transformInput.Transaction, transformInput.LedgerHistory)
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform ledger_transaction transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform ledger_transaction transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq)})
 				numFailures += 1
 				continue
 			}
 
-			numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+			numBytes, err := exportEntry(transformed, outFile, commonArgs)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export transaction: %v", err))
+				cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export transaction: %v", err)})
 				numFailures += 1
 				continue
 			}
@@ -53,7 +53,9 @@ var ledgerTransactionCmd = &cobra.Command{
 
 		printTransformStats(len(ledgerTransaction), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
@@ -26,41 +26,131 @@ var ledgersCmd = &cobra.Command{
 		var err error
 
 		if commonArgs.UseCaptiveCore {
-			ledgers, err = input.GetLedgersHistoryArchive(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			ledgers, err = input.GetLedgersHistoryArchive(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		} else {
-			ledgers, err = input.GetLedgers(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			ledgers, err = input.GetLedgers(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		}
 		if err != nil {
-			cmdLogger.Fatal("could not read ledgers: ", err)
+			cmdLogger.Fatal("could not read ledgers: ", utils.InputError{Err: err})
+		}
+
+		statsPath, err := cmd.Flags().GetString("stats-output")
+		if err != nil {
+			cmdLogger.Fatal("could not get stats-output: ", err)
+		}
+
+		bucketListPath, err := cmd.Flags().GetString("bucket-list-output")
+		if err != nil {
+			cmdLogger.Fatal("could not get bucket-list-output: ", err)
+		}
+
+		writeFeeStatePath, err := cmd.Flags().GetString("soroban-write-fee-state")
+		if err != nil {
+			cmdLogger.Fatal("could not get soroban-write-fee-state: ", err)
+		}
+
+		// The write fee settings active at the ledger being exported, which are nil until they are known
+		var writeFeeConfig *transform.SorobanWriteFeeConfig
+		if writeFeeStatePath != "" {
+			writeFeeConfig, err = loadSorobanWriteFeeState(writeFeeStatePath, startNum)
+			if err != nil {
+				cmdLogger.Fatal("could not read the Soroban write fee state: ", utils.InputError{Err: err})
+			}
+		}
+		if writeFeeConfig == nil {
+			cmdLogger.Warnf("the Soroban write fee settings active at ledger %d are unknown; the write fee columns are null until the first config upgrade of the range", startNum)
 		}
 
 		outFile := mustOutFile(path)
+		var statsFile *outputFile
+		if statsPath != "" {
+			statsFile = mustOutFile(statsPath)
+		}
+		var bucketListFile *outputFile
+		if bucketListPath != "" {
+			bucketListFile = mustOutFile(bucketListPath)
+		}
 
 		numFailures := 0
 		totalNumBytes := 0
 		for i, ledger := range ledgers {
+			// upgrades take effect once the ledger is closed, so the row gets the settings active before them
+			activeWriteFeeConfig := writeFeeConfig
+			if upgraded, ok := transform.SorobanWriteFeeConfigUpgrade(ledger.LCM); ok {
+				writeFeeConfig = &upgraded
+			}
+
 			transformed, err := transform.TransformLedger(ledger.Ledger, ledger.LCM)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not json transform ledger %d: %s", startNum+uint32(i), err))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not json transform ledger %d: %s", startNum+uint32(i), err)})
 				numFailures += 1
 				continue
 			}
+			transformed = transform.JoinSorobanWriteFeeConfig(transformed, activeWriteFeeConfig)
 
-			numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+			numBytes, err := exportEntry(transformed, outFile, commonArgs)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export ledger %d: %s", startNum+uint32(i), err))
+				cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export ledger %d: %s", startNum+uint32(i), err)})
 				numFailures += 1
 				continue
 			}
 			totalNumBytes += numBytes
+
+			if statsFile != nil {
+				stats, err := transform.TransformLedgerStats(ledger.LCM, env.NetworkPassphrase)
+				if err != nil {
+					cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not compute stats of ledger %d: %s", startNum+uint32(i), err)})
+					numFailures += 1
+				} else if _, err := exportEntry(stats, statsFile, commonArgs); err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export stats of ledger %d: %s", startNum+uint32(i), err)})
+					numFailures += 1
+				}
+			}
+
+			if bucketListFile != nil {
+				metrics, err := transform.TransformBucketListMetrics(ledger.LCM)
+				if err != nil {
+					cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not read bucket list metrics of ledger %d: %s", startNum+uint32(i), err)})
+					numFailures += 1
+					continue
+				}
+
+				if _, err := exportEntry(metrics, bucketListFile, commonArgs); err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export bucket list metrics of ledger %d: %s", startNum+uint32(i), err)})
+					numFailures += 1
+				}
+			}
 		}
 
 		outFile.Close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
+		if writeFeeStatePath != "" && writeFeeConfig != nil && len(ledgers) > 0 {
+			lastLedger := ledgers[len(ledgers)-1].LCM.LedgerSequence()
+			if err := saveSorobanWriteFeeState(writeFeeStatePath, lastLedger, *writeFeeConfig); err != nil {
+				cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not write the Soroban write fee state: %v", err)})
+			}
+		}
+
 		printTransformStats(len(ledgers), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
+
+		if statsFile != nil {
+			statsFile.Close()
+			for _, outputPath := range finalizeOutputFile(cmd.Context(), statsFile, startNum, commonArgs.EndNum, commonArgs) {
+				maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+			}
+		}
+
+		if bucketListFile != nil {
+			bucketListFile.Close()
+			for _, outputPath := range finalizeOutputFile(cmd.Context(), bucketListFile, startNum, commonArgs.EndNum, commonArgs) {
+				maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+			}
+		}
 	},
 }
 
@@ -69,6 +159,11 @@ func init() {
 	utils.AddCommonFlags(ledgersCmd.Flags())
 	utils.AddArchiveFlags("ledgers", ledgersCmd.Flags())
 	utils.AddCloudStorageFlags(ledgersCmd.Flags())
+	ledgersCmd.Flags().String("stats-output", "", "If set, per ledger network statistics are computed in the same pass and written to this file")
+	ledgersCmd.Flags().String("bucket-list-output", "", "If set, the bucket list size and evictions of every ledger are written to this file")
+	ledgersCmd.Flags().String("soroban-write-fee-state", "", "If set, the Soroban write fee settings active before the start ledger are read from this file, "+
+		"and the settings active after the end ledger are written to it, so that consecutive exports fill the write fee columns of every ledger. "+
+		"The export fails if the file was not written by the export of the ledgers right before the start ledger")
 	ledgersCmd.MarkFlagRequired("end-ledger")
 	/*
 		Current flags:
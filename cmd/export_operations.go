@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/stellar-etl/internal/input"
 	"github.com/stellar/stellar-etl/internal/transform"
 	"github.com/stellar/stellar-etl/internal/utils"
@@ -21,10 +23,14 @@ var operationsCmd = &cobra.Command{
 		startNum, path, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		denormalize, err := cmd.Flags().GetBool("denormalize")
+		if err != nil {
+			cmdLogger.Fatal("could not get denormalize boolean: ", err)
+		}
 
-		operations, err := input.GetOperations(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		operations, err := input.GetOperations(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		if err != nil {
-			cmdLogger.Fatal("could not read operations: ", err)
+			cmdLogger.Fatal("could not read operations: ", utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
@@ -34,14 +40,18 @@ var operationsCmd = &cobra.Command{
 			transformed, err := transform.TransformOperation(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
 			if err != nil {
 				txIndex := transformInput.Transaction.Index
-				cmdLogger.LogError(fmt.Errorf("could not transform operation %d in transaction %d in ledger %d: %v", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum, err))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform operation %d in transaction %d in ledger %d: %v", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum, err)})
 				numFailures += 1
 				continue
 			}
 
-			numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+			var entry interface{} = transformed
+			if denormalize {
+				entry = withTransactionColumns(entry, transformInput.Transaction)
+			}
+			numBytes, err := exportEntry(withRawXDR(entry, "operation_body_xdr", &transformInput.Operation.Body, commonArgs), outFile, commonArgs)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export operation: %v", err))
+				cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export operation: %v", err)})
 				numFailures += 1
 				continue
 			}
@@ -53,15 +63,36 @@ var operationsCmd = &cobra.Command{
 
 		printTransformStats(len(operations), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
+// withTransactionColumns adds the most frequently joined columns of the transaction of an operation to it. The fee
+// account is the account that paid the fee, which is the fee bump account of fee bump transactions.
+func withTransactionColumns(entry interface{}, transaction ingest.LedgerTransaction) interface{} {
+	feeAccount := transaction.Envelope.SourceAccount().ToAccountId()
+	if transaction.Envelope.IsFeeBump() {
+		feeAccount = transaction.Envelope.FeeBumpAccount().ToAccountId()
+	}
+
+	return withColumns(entry, map[string]interface{}{
+		"transaction_hash":       hex.EncodeToString(transaction.Result.TransactionHash[:]),
+		"transaction_successful": transaction.Result.Successful(),
+		"transaction_account":    transaction.Envelope.SourceAccount().ToAccountId().Address(),
+		"account_sequence":       transaction.Envelope.SeqNum(),
+		"fee_account":            feeAccount.Address(),
+	})
+}
+
 func init() {
 	rootCmd.AddCommand(operationsCmd)
 	utils.AddCommonFlags(operationsCmd.Flags())
 	utils.AddArchiveFlags("operations", operationsCmd.Flags())
 	utils.AddCloudStorageFlags(operationsCmd.Flags())
+	operationsCmd.Flags().Bool("denormalize", false, "If set, every operation gets the transaction_hash, transaction_successful, "+
+		"transaction_account, account_sequence and fee_account columns of its transaction, so that most queries do not need to join the transactions")
 	operationsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+var participantsCmd = &cobra.Command{
+	Use:   "export_participants",
+	Short: "Exports the accounts taking part in each transaction over a specified range.",
+	Long: `Exports a row for every account taking part in a transaction over a specified range to an output file.
+Participants are the source and fee accounts, the participants of each operation and the accounts changed by the transaction.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		startNum, path, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", utils.InputError{Err: err})
+		}
+
+		outFile := mustOutFile(path)
+		numFailures := 0
+		totalNumBytes := 0
+		for _, transformInput := range transactions {
+			participants, err := transform.TransformParticipants(transformInput.Transaction, transformInput.LedgerHistory)
+			if err != nil {
+				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform participants of transaction %d in ledger %d: %v", transformInput.Transaction.Index, ledgerSeq, err)})
+				numFailures += 1
+				continue
+			}
+
+			for _, transformed := range participants {
+				numBytes, err := exportEntry(transformed, outFile, commonArgs)
+				if err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export participant: %v", err)})
+					numFailures += 1
+					continue
+				}
+				totalNumBytes += numBytes
+			}
+		}
+
+		outFile.Close()
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+
+		printTransformStats(len(transactions), numFailures)
+
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(participantsCmd)
+	utils.AddCommonFlags(participantsCmd.Flags())
+	utils.AddArchiveFlags("transactions", participantsCmd.Flags())
+	utils.AddCloudStorageFlags(participantsCmd.Flags())
+	participantsCmd.MarkFlagRequired("end-ledger")
+}
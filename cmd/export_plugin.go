@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stellar/stellar-etl/processor"
+)
+
+var exportPluginCmd = &cobra.Command{
+	Use:   "export_plugin",
+	Short: "Exports the tables produced by custom transform plugins.",
+	Long: `Exports the tables produced by custom transform plugins over a specified range.
+Each plugin is a Go plugin exporting a NewProcessor function of type func(passphrase string) processor.Processor.
+Every table the plugins produce is written to its own file in the output folder.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		startNum, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start sequence number: ", err)
+		}
+
+		outputFolder, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output folder: ", err)
+		}
+
+		pluginPaths, err := cmd.Flags().GetStringSlice("plugin")
+		if err != nil {
+			cmdLogger.Fatal("could not get plugin paths: ", err)
+		}
+
+		processors := []processor.Processor{}
+		for _, pluginPath := range pluginPaths {
+			p, err := processor.LoadPlugin(pluginPath, env.NetworkPassphrase)
+			if err != nil {
+				cmdLogger.Fatal("could not load plugin: ", err)
+			}
+			processors = append(processors, p)
+		}
+		pluginProcessor := processor.Combine(processors...)
+
+		ledgers, err := input.GetLedgers(cmd.Context(), startNum, commonArgs.EndNum, -1, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", utils.InputError{Err: err})
+		}
+
+		outFiles := map[string]*outputFile{}
+		numFailures := 0
+		totalNumBytes := 0
+		for _, ledger := range ledgers {
+			records, err := pluginProcessor.ProcessLedger(ledger.LCM)
+			if err != nil {
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not process ledger %d: %v", ledger.LCM.LedgerSequence(), err)})
+				numFailures += 1
+				continue
+			}
+
+			for _, record := range records {
+				outFile, ok := outFiles[record.Table]
+				if !ok {
+					outFile = mustOutFile(filepath.Join(outputFolder, exportFilename(startNum, commonArgs.EndNum+1, record.Table)))
+					outFiles[record.Table] = outFile
+				}
+
+				numBytes, err := exportEntry(record.Data, outFile, commonArgs)
+				if err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export %s row of ledger %d: %v", record.Table, ledger.LCM.LedgerSequence(), err)})
+					numFailures += 1
+					continue
+				}
+				totalNumBytes += numBytes
+			}
+		}
+
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+		printTransformStats(len(ledgers), numFailures)
+
+		for _, outFile := range outFiles {
+			outFile.Close()
+			for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+				maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportPluginCmd)
+	utils.AddCommonFlags(exportPluginCmd.Flags())
+	utils.AddCloudStorageFlags(exportPluginCmd.Flags())
+	exportPluginCmd.Flags().Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the export period. Defaults to genesis ledger")
+	exportPluginCmd.Flags().StringP("output", "o", "exported_plugin_data", "Folder that will contain the output files")
+	exportPluginCmd.Flags().StringSlice("plugin", []string{}, "Path of a Go plugin providing a custom processor. Can be repeated to run several plugins")
+	exportPluginCmd.MarkFlagRequired("end-ledger")
+	exportPluginCmd.MarkFlagRequired("plugin")
+}
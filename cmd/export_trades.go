@@ -25,9 +25,9 @@ var tradesCmd = &cobra.Command{
 		env := utils.GetEnvironmentDetails(commonArgs)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 
-		trades, err := input.GetTrades(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		trades, err := input.GetTrades(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
 		if err != nil {
-			cmdLogger.Fatal("could not read trades ", err)
+			cmdLogger.Fatal("could not read trades ", utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
@@ -37,13 +37,13 @@ var tradesCmd = &cobra.Command{
 			trades, err := transform.TransformTrade(tradeInput.OperationIndex, tradeInput.OperationHistoryID, tradeInput.Transaction, tradeInput.CloseTime)
 			if err != nil {
 				parsedID := toid.Parse(tradeInput.OperationHistoryID)
-				cmdLogger.LogError(fmt.Errorf("from ledger %d, transaction %d, operation %d: %v", parsedID.LedgerSequence, parsedID.TransactionOrder, parsedID.OperationOrder, err))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("from ledger %d, transaction %d, operation %d: %v", parsedID.LedgerSequence, parsedID.TransactionOrder, parsedID.OperationOrder, err)})
 				numFailures += 1
 				continue
 			}
 
 			for _, transformed := range trades {
-				numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+				numBytes, err := exportEntry(transformed, outFile, commonArgs)
 				if err != nil {
 					cmdLogger.LogError(err)
 					numFailures += 1
@@ -58,7 +58,9 @@ var tradesCmd = &cobra.Command{
 
 		printTransformStats(len(trades), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
@@ -22,26 +22,57 @@ var transactionsCmd = &cobra.Command{
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
 
-		transactions, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		txIndex, err := cmd.Flags().GetBool("tx-index")
 		if err != nil {
-			cmdLogger.Fatal("could not read transactions: ", err)
+			cmdLogger.Fatal("could not get tx-index boolean: ", err)
+		}
+		if _, _, ok := outputRowSink(path); ok && txIndex {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "tx-index cannot be used with a sink output")
+		}
+		if tableFormat("transactions") == formatParquet && txIndex {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "tx-index cannot be used with parquet transactions, whose rows have no byte offsets")
+		}
+
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", utils.InputError{Err: err})
 		}
 
 		outFile := mustOutFile(path)
 		numFailures := 0
 		totalNumBytes := 0
+		// Positions in the transaction set of the ledger the previous transaction belongs to
+		var txSetPositions map[string]transform.TxSetPosition
+		var txSetLedger uint32
 		for _, transformInput := range transactions {
 			transformed, err := transform.TransformTransaction(transformInput.Transaction, transformInput.LedgerHistory)
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq)})
 				numFailures += 1
 				continue
 			}
 
-			numBytes, err := exportEntry(transformed, outFile, commonArgs.Extra)
+			if ledgerSeq := transformInput.LedgerCloseMeta.LedgerSequence(); txSetPositions == nil || ledgerSeq != txSetLedger {
+				txSetPositions, err = transform.TxSetPositions(transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+				if err != nil {
+					cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not read the transaction set of ledger %d: %v", ledgerSeq, err)})
+					txSetPositions = map[string]transform.TxSetPosition{}
+				}
+				txSetLedger = ledgerSeq
+			}
+			transformed = transform.JoinTxSetPosition(transformed, txSetPositions)
+
+			transformed, err = transform.VerifyTransactionHash(transformed, transformInput.Transaction, env.NetworkPassphrase)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export transaction: %v", err))
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not hash transaction %s: %v", transformed.TransactionHash, err)})
+			} else if transformed.HashMismatch.Bool {
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("the envelope of transaction %s in ledger %d does not match its hash", transformed.TransactionHash, transformed.LedgerSequence)})
+			}
+
+			numBytes, err := exportEntry(transformed, outFile, commonArgs)
+			if err != nil {
+				cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export transaction: %v", err)})
 				numFailures += 1
 				continue
 			}
@@ -53,7 +84,20 @@ var transactionsCmd = &cobra.Command{
 
 		printTransformStats(len(transactions), numFailures)
 
-		maybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		outputPaths := finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs)
+		// the index is built from the local files, which are deleted once they are uploaded
+		if txIndex {
+			indexPath, err := writeTxIndex(outputPaths, path+txIndexExtension)
+			if err != nil {
+				cmdLogger.LogError(utils.SinkError{Err: err})
+			} else {
+				outputPaths = append(outputPaths, indexPath)
+			}
+		}
+
+		for _, outputPath := range outputPaths {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
 	},
 }
 
@@ -62,6 +106,7 @@ func init() {
 	utils.AddCommonFlags(transactionsCmd.Flags())
 	utils.AddArchiveFlags("transactions", transactionsCmd.Flags())
 	utils.AddCloudStorageFlags(transactionsCmd.Flags())
+	transactionsCmd.Flags().Bool("tx-index", false, "If set, a CSV index locating the row of every transaction hash (ledger, file, byte offset and length) is written next to the output, with the extension "+txIndexExtension)
 	transactionsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+var txFootprintsCmd = &cobra.Command{
+	Use:   "export_tx_footprints",
+	Short: "Exports the footprints of the Soroban transactions over a specified range.",
+	Long: `Exports one row per ledger key declared in the footprint of each Soroban transaction over a specified range,
+with whether the transaction could only read the key or also write it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		startNum, path, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", utils.InputError{Err: err})
+		}
+
+		outFile := mustOutFile(path)
+		numFailures := 0
+		for _, transformInput := range transactions {
+			transformed, err := transform.TransformTransactionFootprint(transformInput.Transaction, transformInput.LedgerHistory)
+			if err != nil {
+				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+				cmdLogger.LogError(utils.TransformError{Err: fmt.Errorf("could not transform the footprint of transaction %d in ledger %d: %v", transformInput.Transaction.Index, ledgerSeq, err)})
+				numFailures += 1
+				continue
+			}
+
+			for _, footprintKey := range transformed {
+				_, err := exportEntry(footprintKey, outFile, commonArgs)
+				if err != nil {
+					cmdLogger.LogError(utils.SinkError{Err: fmt.Errorf("could not export footprint key: %v", err)})
+					numFailures += 1
+					continue
+				}
+			}
+		}
+
+		outFile.Close()
+
+		printTransformStats(len(transactions), numFailures)
+
+		for _, outputPath := range finalizeOutputFile(cmd.Context(), outFile, startNum, commonArgs.EndNum, commonArgs) {
+			maybeUpload(cmd.Context(), cloudCredentials, cloudStorageBucket, cloudProvider, outputPath)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(txFootprintsCmd)
+	utils.AddCommonFlags(txFootprintsCmd.Flags())
+	utils.AddArchiveFlags("tx_footprints", txFootprintsCmd.Flags())
+	utils.AddCloudStorageFlags(txFootprintsCmd.Flags())
+	txFootprintsCmd.MarkFlagRequired("end-ledger")
+}
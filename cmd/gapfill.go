@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// exportFileRange matches the ledger range and table of the files written by the export commands, e.g.
+// 1-64-ledgers.txt, and their rotated and gzipped variants such as 1-64-ledgers.0.txt.gz
+var exportFileRange = regexp.MustCompile(`^(?P<start>\d+)-(?P<end>\d+)-(?P<table>[a-z_]+)[.]`)
+
+// The placeholders of the flags of the export command, which are replaced with the first and last ledger of each range
+const (
+	startPlaceholder = "{start}"
+	endPlaceholder   = "{end}"
+)
+
+// rangeNumber matches the numbers of the lines of a ranges file
+var rangeNumber = regexp.MustCompile(`\d+`)
+
+var gapfillCmd = &cobra.Command{
+	Use:   "gapfill [-- flags of the export command]",
+	Short: "Exports the ledger ranges missing from a manifest or a list of loaded ranges",
+	Long: `Reads the ledger ranges already loaded, either from a manifest of uploaded objects (see --manifest) or from a file
+with one range per line such as the output of a warehouse query, computes the ranges missing between --start-ledger and
+--end-ledger, and runs the export command on each of them. The flags after -- are passed to the export command as they
+are, after {start} and {end} are replaced with the first and last ledger of the range, e.g.
+--output transactions_{start}-{end}.txt. With --dry-run, the missing ranges are only printed, one JSON object per line.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		startNum, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start sequence number: ", err)
+		}
+
+		endNum, err := cmd.Flags().GetUint32("end-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get end sequence number: ", err)
+		}
+
+		command, err := cmd.Flags().GetString("command")
+		if err != nil {
+			cmdLogger.Fatal("could not get command: ", err)
+		}
+
+		manifestFile, err := cmd.Flags().GetString("manifest-file")
+		if err != nil {
+			cmdLogger.Fatal("could not get manifest file: ", err)
+		}
+
+		rangesFile, err := cmd.Flags().GetString("ranges-file")
+		if err != nil {
+			cmdLogger.Fatal("could not get ranges file: ", err)
+		}
+
+		table, err := cmd.Flags().GetString("table")
+		if err != nil {
+			cmdLogger.Fatal("could not get table: ", err)
+		}
+
+		maxRangeLedgers, err := cmd.Flags().GetUint32("max-range-ledgers")
+		if err != nil {
+			cmdLogger.Fatal("could not get max range ledgers: ", err)
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			cmdLogger.Fatal("could not get dry-run boolean: ", err)
+		}
+
+		if endNum == 0 || endNum < startNum {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("gapfill needs an --end-ledger after --start-ledger %d, e.g. --end latest", startNum))
+		}
+		if (manifestFile == "") == (rangesFile == "") {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "exactly one of --manifest-file and --ranges-file must be set")
+		}
+		if command == "" && !dryRun {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "--command is needed to export the missing ranges, or --dry-run to only print them")
+		}
+
+		templated := false
+		for _, arg := range args {
+			templated = templated || strings.Contains(arg, startPlaceholder)
+		}
+
+		var loaded []ledgerRange
+		if manifestFile != "" {
+			// the objects written to a templated output are named after it rather than after the export commands
+			pattern := exportFileRange
+			if output := exportOutputArg(args); strings.Contains(output, startPlaceholder) && strings.Contains(output, endPlaceholder) {
+				pattern = outputRangePattern(output)
+			}
+			loaded, err = readManifestRanges(manifestFile, pattern, table)
+		} else {
+			loaded, err = readLoadedRanges(rangesFile)
+		}
+		if err != nil {
+			cmdLogger.Fatal("could not read the loaded ranges: ", utils.InputError{Err: err})
+		}
+
+		gaps := splitRanges(missingRanges(startNum, endNum, loaded), maxRangeLedgers)
+		cmdLogger.Infof("%d ranges are missing from [%d, %d]", len(gaps), startNum, endNum)
+		if len(gaps) > 1 && !templated && !dryRun {
+			cmdLogger.Warnf("the flags of %s hold no %s placeholder, so every range is exported to the same output", command, startPlaceholder)
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			cmdLogger.Fatal("could not find the stellar-etl executable: ", err)
+		}
+
+		for _, gap := range gaps {
+			marshalled, err := json.Marshal(gap)
+			if err != nil {
+				cmdLogger.Fatal("could not json encode ledger range: ", err)
+			}
+			fmt.Println(string(marshalled))
+
+			if dryRun {
+				continue
+			}
+
+			exportArgs := append([]string{command,
+				"--start-ledger", strconv.FormatInt(gap.Start, 10),
+				"--end-ledger", strconv.FormatInt(gap.End, 10),
+			}, rangeArgs(args, gap)...)
+			export := exec.CommandContext(cmd.Context(), executable, exportArgs...)
+			export.Stdout = os.Stdout
+			export.Stderr = os.Stderr
+			if err = export.Run(); err != nil {
+				cmdLogger.Fatalf("could not export ledgers [%d, %d] with %s: %v", gap.Start, gap.End, command, err)
+			}
+		}
+	},
+}
+
+// rangeArgs returns the flags of the export command of the range, with the placeholders replaced with its ledgers
+func rangeArgs(args []string, r ledgerRange) []string {
+	replacer := strings.NewReplacer(startPlaceholder, strconv.FormatInt(r.Start, 10), endPlaceholder, strconv.FormatInt(r.End, 10))
+	replaced := make([]string, len(args))
+	for i, arg := range args {
+		replaced[i] = replacer.Replace(arg)
+	}
+
+	return replaced
+}
+
+// exportOutputArg returns the value of the --output flag among the flags of the export command, or "" if it is not set
+func exportOutputArg(args []string) string {
+	output := ""
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--output="); ok {
+			output = value
+		} else if value, ok := strings.CutPrefix(arg, "-o="); ok {
+			output = value
+		} else if (arg == "--output" || arg == "-o") && i+1 < len(args) {
+			output = args[i+1]
+		}
+	}
+
+	return output
+}
+
+// outputRangePattern returns the pattern matching the ledger range of the files written to an output templated with
+// the placeholders, e.g. transactions_{start}-{end}.txt matches transactions_1-64.txt and transactions_1-64.0.txt.gz
+func outputRangePattern(output string) *regexp.Regexp {
+	stem, _, _ := strings.Cut(path.Base(output), ".")
+	pattern := regexp.QuoteMeta(stem)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta(startPlaceholder), `(?P<start>\d+)`, 1)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta(endPlaceholder), `(?P<end>\d+)`, 1)
+	return regexp.MustCompile("^" + pattern + "(?:[.]|$)")
+}
+
+// readManifestRanges returns the ledger ranges of the objects recorded in a manifest whose file name matches pattern,
+// keeping only the files of table when it is set and the pattern holds a table
+func readManifestRanges(manifestFile string, pattern *regexp.Regexp, table string) ([]ledgerRange, error) {
+	file, err := os.Open(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ranges := []ledgerRange{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry manifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid manifest line %q: %v", scanner.Text(), err)
+		}
+
+		match := pattern.FindStringSubmatch(path.Base(entry.Object))
+		if match == nil {
+			continue
+		}
+		if tableIndex := pattern.SubexpIndex("table"); table != "" && tableIndex >= 0 && match[tableIndex] != table {
+			continue
+		}
+		start, _ := strconv.ParseInt(match[pattern.SubexpIndex("start")], 10, 64)
+		end, _ := strconv.ParseInt(match[pattern.SubexpIndex("end")], 10, 64)
+		ranges = append(ranges, ledgerRange{Start: start, End: end})
+	}
+
+	return ranges, scanner.Err()
+}
+
+// readLoadedRanges returns the ledger ranges of a file holding the first and last ledger of a loaded range on each
+// line, e.g. 1-64, 1,64 or {"start": 1, "end": 64}. Lines with fewer than two numbers, such as headers, are skipped.
+func readLoadedRanges(rangesFile string) ([]ledgerRange, error) {
+	file, err := os.Open(rangesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ranges := []ledgerRange{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		numbers := rangeNumber.FindAllString(scanner.Text(), 2)
+		if len(numbers) < 2 {
+			continue
+		}
+		start, _ := strconv.ParseInt(numbers[0], 10, 64)
+		end, _ := strconv.ParseInt(numbers[1], 10, 64)
+		if end < start {
+			return nil, fmt.Errorf("invalid range %q: %d is before %d", scanner.Text(), end, start)
+		}
+		ranges = append(ranges, ledgerRange{Start: start, End: end})
+	}
+
+	return ranges, scanner.Err()
+}
+
+// missingRanges returns the ranges of the ledgers in [start, end] that are not in any of the loaded ranges
+func missingRanges(start, end uint32, loaded []ledgerRange) []ledgerRange {
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Start < loaded[j].Start })
+
+	missing := []ledgerRange{}
+	next := int64(start)
+	for _, r := range loaded {
+		if r.Start > int64(end) {
+			break
+		}
+		if r.Start > next {
+			missing = append(missing, ledgerRange{Start: next, End: r.Start - 1})
+		}
+		if r.End+1 > next {
+			next = r.End + 1
+		}
+	}
+	if next <= int64(end) {
+		missing = append(missing, ledgerRange{Start: next, End: int64(end)})
+	}
+
+	return missing
+}
+
+// splitRanges splits the ranges longer than maxLedgers into consecutive ranges of at most maxLedgers ledgers
+func splitRanges(ranges []ledgerRange, maxLedgers uint32) []ledgerRange {
+	if maxLedgers == 0 {
+		return ranges
+	}
+
+	split := []ledgerRange{}
+	for _, r := range ranges {
+		for start := r.Start; start <= r.End; start += int64(maxLedgers) {
+			split = append(split, ledgerRange{Start: start, End: min(start+int64(maxLedgers)-1, r.End)})
+		}
+	}
+
+	return split
+}
+
+func init() {
+	rootCmd.AddCommand(gapfillCmd)
+
+	gapfillCmd.Flags().Uint32P("start-ledger", "s", 2, "The first ledger of the range that should be loaded. Defaults to genesis ledger")
+	gapfillCmd.Flags().Uint32P("end-ledger", "e", 0, "The last ledger of the range that should be loaded")
+	gapfillCmd.Flags().Bool("testnet", false, "If set, --start and --end datetimes and 'latest' are resolved on testnet instead of mainnet")
+	gapfillCmd.Flags().Bool("futurenet", false, "If set, --start and --end datetimes and 'latest' are resolved on futurenet instead of mainnet")
+	gapfillCmd.Flags().String("command", "", "Export command run on each missing range, e.g. export_transactions")
+	gapfillCmd.Flags().String("manifest-file", "", "Manifest of the uploaded objects, as written with --manifest. Their file names give the loaded ranges, "+
+		"either the names of the export commands such as 1-64-transactions.txt, or the --output passed after -- when it is templated with {start} and {end}")
+	gapfillCmd.Flags().String("ranges-file", "", "File with the first and last ledger of a loaded range on each line, e.g. the CSV output of "+
+		"a warehouse query such as SELECT MIN(ledger_sequence), MAX(ledger_sequence) ... GROUP BY batch_id")
+	gapfillCmd.Flags().String("table", "", "If set, only the manifest objects of this table are counted as loaded, e.g. transactions")
+	gapfillCmd.Flags().Uint32("max-range-ledgers", 0, "If set, missing ranges longer than this many ledgers are exported in several runs")
+	gapfillCmd.Flags().Bool("dry-run", false, "If set, the missing ranges are printed without being exported")
+}
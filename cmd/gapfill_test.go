@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		loaded []ledgerRange
+		want   []ledgerRange
+	}{
+		{"nothing loaded", nil, []ledgerRange{{Start: 10, End: 100}}},
+		{"everything loaded", []ledgerRange{{Start: 1, End: 200}}, []ledgerRange{}},
+		{
+			"gaps between unsorted and overlapping ranges",
+			[]ledgerRange{{Start: 50, End: 60}, {Start: 10, End: 20}, {Start: 15, End: 30}},
+			[]ledgerRange{{Start: 31, End: 49}, {Start: 61, End: 100}},
+		},
+		{"adjacent ranges", []ledgerRange{{Start: 10, End: 20}, {Start: 21, End: 100}}, []ledgerRange{}},
+		{"ranges outside of the bounds", []ledgerRange{{Start: 1, End: 5}, {Start: 101, End: 200}}, []ledgerRange{{Start: 10, End: 100}}},
+		{"range inside another one", []ledgerRange{{Start: 10, End: 90}, {Start: 20, End: 30}}, []ledgerRange{{Start: 91, End: 100}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, missingRanges(10, 100, test.loaded))
+		})
+	}
+}
+
+func TestSplitRanges(t *testing.T) {
+	ranges := []ledgerRange{{Start: 1, End: 10}, {Start: 20, End: 22}}
+	assert.Equal(t, ranges, splitRanges(ranges, 0))
+	assert.Equal(t, []ledgerRange{
+		{Start: 1, End: 4},
+		{Start: 5, End: 8},
+		{Start: 9, End: 10},
+		{Start: 20, End: 22},
+	}, splitRanges(ranges, 4))
+	assert.Equal(t, []ledgerRange{{Start: 1, End: 1}, {Start: 2, End: 2}}, splitRanges([]ledgerRange{{Start: 1, End: 2}}, 1))
+}
+
+func TestRangeArgs(t *testing.T) {
+	args := []string{"--output", "transactions_{start}-{end}.txt", "--cloud-provider", "gcp"}
+	assert.Equal(t, []string{"--output", "transactions_1-64.txt", "--cloud-provider", "gcp"}, rangeArgs(args, ledgerRange{Start: 1, End: 64}))
+	assert.Equal(t, "transactions_{start}-{end}.txt", exportOutputArg(args))
+	assert.Equal(t, "out_{start}-{end}.txt", exportOutputArg([]string{"-o=out_{start}-{end}.txt"}))
+	assert.Equal(t, "", exportOutputArg([]string{"--cloud-provider", "gcp"}))
+}
+
+func TestReadManifestRanges(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.jsonl")
+	content := `{"object": "bucket/1-64-transactions.txt"}
+{"object": "bucket/1-64-ledgers.txt"}
+{"object": "bucket/65-128-transactions.0.txt.gz"}
+
+{"object": "bucket/transactions_129-192.txt"}
+{"object": "bucket/transactions_193-256.1.txt.gz"}
+{"object": "bucket/exported_transactions.txt"}
+`
+	assert.NoError(t, os.WriteFile(manifest, []byte(content), 0644))
+
+	ranges, err := readManifestRanges(manifest, exportFileRange, "transactions")
+	assert.NoError(t, err)
+	assert.Equal(t, []ledgerRange{{Start: 1, End: 64}, {Start: 65, End: 128}}, ranges)
+
+	ranges, err = readManifestRanges(manifest, outputRangePattern("out/transactions_{start}-{end}.txt"), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []ledgerRange{{Start: 129, End: 192}, {Start: 193, End: 256}}, ranges)
+}
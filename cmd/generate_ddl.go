@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// snowflakeFileFormatDDL creates the file format that COPY INTO needs in order to load files written with --warehouse snowflake
+const snowflakeFileFormatDDL = `CREATE FILE FORMAT IF NOT EXISTS stellar_etl_ndjson
+    TYPE = JSON
+    COMPRESSION = GZIP
+    TIMESTAMP_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FF6 TZH:TZM';
+`
+
+var generateDDLCmd = &cobra.Command{
+	Use:   "generate_ddl",
+	Short: "Generates the CREATE TABLE statements for a warehouse.",
+	Long: `Generates the CREATE TABLE statements matching the files exported with the provided warehouse preset.
+If no table is provided, statements for every exported table are generated.
+
+Athena statements create Glue external tables partitioned by dt and ledger_range, matching files exported
+with --partition-layout hive. Each table reads the files under <location>/<table>/.
+
+BigQuery statements create the tables in the dataset given as location, partitioned by the day their ledgers closed
+and clustered by the columns most queries filter on.
+
+Timestamp columns are declared as integers when the files are exported with an epoch --timestamp-format. The flags
+of the exports that add columns, like --asset-contract-ids, add the same columns to the statements.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		warehouse, err := cmd.Flags().GetString("warehouse")
+		if err != nil {
+			cmdLogger.Fatal("could not get warehouse: ", err)
+		}
+
+		table, err := cmd.Flags().GetString("table")
+		if err != nil {
+			cmdLogger.Fatal("could not get table: ", err)
+		}
+
+		location, err := cmd.Flags().GetString("location")
+		if err != nil {
+			cmdLogger.Fatal("could not get location: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output path: ", err)
+		}
+
+		timestampFormat, err := cmd.Flags().GetString("timestamp-format")
+		if err != nil {
+			cmdLogger.Fatal("could not get timestamp format: ", err)
+		}
+		assetContractIDs, err := cmd.Flags().GetBool("asset-contract-ids")
+		if err != nil {
+			cmdLogger.Fatal("could not get asset-contract-ids boolean: ", err)
+		}
+
+		includeXDR, err := cmd.Flags().GetBool("include-xdr")
+		if err != nil {
+			cmdLogger.Fatal("could not get include-xdr boolean: ", err)
+		}
+
+		wellKnownAssetsPath, err := cmd.Flags().GetString("well-known-assets")
+		if err != nil {
+			cmdLogger.Fatal("could not get well-known-assets string: ", err)
+		}
+
+		addressLabelsPath, err := cmd.Flags().GetString("address-labels")
+		if err != nil {
+			cmdLogger.Fatal("could not get address-labels string: ", err)
+		}
+
+		batchMetadata, err := cmd.Flags().GetBool("batch-metadata")
+		if err != nil {
+			cmdLogger.Fatal("could not get batch-metadata boolean: ", err)
+		}
+
+		options := transform.DDLOptions{
+			TimestampFormat:  timestampFormat,
+			AssetContractIDs: assetContractIDs,
+			IncludeXDR:       includeXDR,
+			WellKnownAssets:  wellKnownAssetsPath != "",
+			AddressLabels:    addressLabelsPath != "",
+			BatchMetadata:    batchMetadata,
+		}
+
+		tables := transform.TableNames()
+		if table != "" {
+			tables = []string{table}
+		}
+
+		statements := []string{}
+		if warehouse == utils.WarehouseSnowflake {
+			statements = append(statements, snowflakeFileFormatDDL)
+		}
+
+		for _, t := range tables {
+			tableLocation := location
+			if location != "" && warehouse == utils.WarehouseAthena {
+				tableLocation = strings.TrimSuffix(location, "/") + "/" + t
+			}
+
+			ddl, err := transform.GenerateDDL(warehouse, t, tableLocation, options)
+			if err != nil {
+				cmdLogger.Fatal("could not generate DDL: ", err)
+			}
+			statements = append(statements, ddl)
+		}
+
+		output := strings.Join(statements, "\n")
+		if path != "" {
+			outFile := mustOutFile(path)
+			outFile.WriteString(output)
+			outFile.Close()
+			mustCommitOutputFile(outFile)
+		} else {
+			fmt.Print(output)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateDDLCmd)
+
+	generateDDLCmd.Flags().String("warehouse", utils.WarehouseSnowflake, "Warehouse dialect of the generated statements: bigquery, snowflake, redshift or athena")
+	generateDDLCmd.Flags().StringP("table", "t", "", "Table to generate the statement for. Defaults to every exported table")
+	generateDDLCmd.Flags().String("location", "", "Storage prefix that holds one folder of exported files per table, e.g. s3://bucket/prefix. Required for Athena. "+
+		"For BigQuery, the dataset the tables are created in, e.g. my-project.stellar")
+	generateDDLCmd.Flags().StringP("output", "o", "", "Filename of the output file. Defaults to stdout")
+	generateDDLCmd.Flags().String("timestamp-format", utils.TimestampFormatRFC3339, "The --timestamp-format of the exported files. "+
+		"With 'epoch_seconds' and 'epoch_micros', timestamp columns are declared as integers")
+	generateDDLCmd.Flags().Bool("asset-contract-ids", false, "If set, the tables get the asset_contract_id columns written by --asset-contract-ids")
+	generateDDLCmd.Flags().Bool("include-xdr", false, "If set, the operations and ledger entry change tables get the raw XDR columns written by --include-xdr")
+	generateDDLCmd.Flags().String("well-known-assets", "", "The --well-known-assets file of the exported files. If set, the tables get the asset metadata columns "+
+		"it adds; the file itself is not read")
+	generateDDLCmd.Flags().String("address-labels", "", "The --address-labels file of the exported files. If set, the tables get the address label columns "+
+		"it adds; the file itself is not read")
+	generateDDLCmd.Flags().Bool("batch-metadata", false, "If set, the tables get the batch metadata columns written by --batch-metadata")
+}
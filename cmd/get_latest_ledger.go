@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// latestLedgerOutput is the output of get_latest_ledger
+type latestLedgerOutput struct {
+	Network            string     `json:"network"`
+	CheckpointLedger   uint32     `json:"checkpoint_ledger"`
+	CheckpointClosedAt time.Time  `json:"checkpoint_closed_at"`
+	NetworkTip         uint32     `json:"network_tip,omitempty"`
+	NetworkTipClosedAt *time.Time `json:"network_tip_closed_at,omitempty"`
+}
+
+var getLatestLedgerCmd = &cobra.Command{
+	Use:   "get_latest_ledger",
+	Short: "Prints the latest checkpoint ledger of a network",
+	Long: `Prints the latest checkpoint ledger published to the history archives of a network, along with its close time, as JSON.
+Schedulers can use it to compute the range of the next incremental export. With --network-tip, the latest ledger closed
+by the network is read from the ledger backend as well, e.g. from captive core with --captive-core.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output path: ", err)
+		}
+
+		readTip, err := cmd.Flags().GetBool("network-tip")
+		if err != nil {
+			cmdLogger.Fatal("could not get network-tip boolean: ", err)
+		}
+
+		checkpoint, checkpointClosedAt, err := input.GetLatestCheckpoint(cmd.Context(), env.ArchiveURLs)
+		if err != nil {
+			cmdLogger.Fatal("could not get the latest checkpoint: ", utils.InputError{Err: err})
+		}
+
+		latest := latestLedgerOutput{
+			Network:            env.Network,
+			CheckpointLedger:   checkpoint,
+			CheckpointClosedAt: checkpointClosedAt,
+		}
+
+		if readTip {
+			tip, tipClosedAt, err := input.GetNetworkTip(cmd.Context(), checkpoint, env, commonArgs.UseCaptiveCore)
+			if err != nil {
+				cmdLogger.Fatal("could not get the network tip: ", utils.InputError{Err: err})
+			}
+			latest.NetworkTip = tip
+			latest.NetworkTipClosedAt = &tipClosedAt
+		}
+
+		marshalled, err := json.Marshal(latest)
+		if err != nil {
+			cmdLogger.Fatal("could not json encode latest ledger", err)
+		}
+
+		if path != "" {
+			outFile := mustOutFile(path)
+			outFile.Write(marshalled)
+			outFile.WriteString("\n")
+			outFile.Close()
+			mustCommitOutputFile(outFile)
+		} else {
+			fmt.Println(string(marshalled))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getLatestLedgerCmd)
+	utils.AddCommonFlags(getLatestLedgerCmd.Flags())
+
+	getLatestLedgerCmd.Flags().StringP("output", "o", "", "Filename of the output file. Defaults to stdout")
+	getLatestLedgerCmd.Flags().Bool("network-tip", false, "If set, the latest ledger closed by the network is read from the ledger backend as well")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+type ledgerKeyHash struct {
+	LedgerKeyHash string `json:"ledger_key_hash"`
+}
+
+var getLedgerKeyHashCmd = &cobra.Command{
+	Use:   "get_ledger_key_hash",
+	Short: "Computes the ledger key hash of a ledger entry",
+	Long: `Computes the hex encoded SHA-256 hash of a ledger key. For contract data and contract code entries, this is the
+	key_hash of their TTL entries, so contract state can be joined with ttl without deriving the hash in SQL.
+
+	The key can be given as a base64 encoded XDR LedgerKey with --ledger-key, as a contract data key with
+	--contract-id, --key and --durability, or as a contract code key with --wasm-hash.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ledgerKeyString, err := cmd.Flags().GetString("ledger-key")
+		if err != nil {
+			cmdLogger.Fatal("could not get ledger key: ", err)
+		}
+
+		contractID, err := cmd.Flags().GetString("contract-id")
+		if err != nil {
+			cmdLogger.Fatal("could not get contract id: ", err)
+		}
+
+		keyString, err := cmd.Flags().GetString("key")
+		if err != nil {
+			cmdLogger.Fatal("could not get contract data key: ", err)
+		}
+
+		durabilityString, err := cmd.Flags().GetString("durability")
+		if err != nil {
+			cmdLogger.Fatal("could not get contract data durability: ", err)
+		}
+
+		wasmHashString, err := cmd.Flags().GetString("wasm-hash")
+		if err != nil {
+			cmdLogger.Fatal("could not get wasm hash: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output path: ", err)
+		}
+
+		var keyHash string
+		switch {
+		case ledgerKeyString != "":
+			var ledgerKey xdr.LedgerKey
+			if err := xdr.SafeUnmarshalBase64(ledgerKeyString, &ledgerKey); err != nil {
+				cmdLogger.Fatal("could not decode ledger key: ", err)
+			}
+			keyHash = utils.LedgerKeyToLedgerKeyHash(ledgerKey)
+		case contractID != "":
+			keyHash, err = contractDataKeyHash(contractID, keyString, durabilityString)
+			if err != nil {
+				cmdLogger.Fatal("could not compute contract data key hash: ", err)
+			}
+		case wasmHashString != "":
+			keyHash, err = contractCodeKeyHash(wasmHashString)
+			if err != nil {
+				cmdLogger.Fatal("could not compute contract code key hash: ", err)
+			}
+		default:
+			cmdLogger.Fatal("one of --ledger-key, --contract-id or --wasm-hash must be set")
+		}
+
+		marshalled, err := json.Marshal(ledgerKeyHash{LedgerKeyHash: keyHash})
+		if err != nil {
+			cmdLogger.Fatal("could not json encode ledger key hash", err)
+		}
+
+		if path != "" {
+			outFile := mustOutFile(path)
+			outFile.Write(marshalled)
+			outFile.WriteString("\n")
+			outFile.Close()
+		} else {
+			fmt.Println(string(marshalled))
+		}
+	},
+}
+
+// contractDataKeyHash returns the ledger key hash of the contract data entry of a strkey encoded contract id,
+// a base64 encoded XDR ScVal key and a persistent or temporary durability
+func contractDataKeyHash(contractID, keyString, durabilityString string) (string, error) {
+	rawContractID, err := strkey.Decode(strkey.VersionByteContract, contractID)
+	if err != nil {
+		return "", fmt.Errorf("could not decode contract id: %v", err)
+	}
+
+	var hash xdr.Hash
+	copy(hash[:], rawContractID)
+	contract := xdr.ScAddress{
+		Type:       xdr.ScAddressTypeScAddressTypeContract,
+		ContractId: &hash,
+	}
+
+	var key xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(keyString, &key); err != nil {
+		return "", fmt.Errorf("could not decode contract data key: %v", err)
+	}
+
+	var durability xdr.ContractDataDurability
+	switch durabilityString {
+	case "persistent":
+		durability = xdr.ContractDataDurabilityPersistent
+	case "temporary":
+		durability = xdr.ContractDataDurabilityTemporary
+	default:
+		return "", fmt.Errorf("durability must be persistent or temporary, got %s", durabilityString)
+	}
+
+	return utils.ContractDataLedgerKeyHash(contract, key, durability)
+}
+
+// contractCodeKeyHash returns the ledger key hash of the contract code entry of a hex encoded wasm hash
+func contractCodeKeyHash(wasmHashString string) (string, error) {
+	rawWasmHash, err := hex.DecodeString(wasmHashString)
+	if err != nil || len(rawWasmHash) != len(xdr.Hash{}) {
+		return "", fmt.Errorf("wasm hash must be a hex encoded 32 byte hash")
+	}
+
+	var wasmHash xdr.Hash
+	copy(wasmHash[:], rawWasmHash)
+	return utils.ContractCodeLedgerKeyHash(wasmHash)
+}
+
+func init() {
+	rootCmd.AddCommand(getLedgerKeyHashCmd)
+
+	getLedgerKeyHashCmd.Flags().String("ledger-key", "", "Base64 encoded XDR LedgerKey to hash")
+	getLedgerKeyHashCmd.Flags().String("contract-id", "", "Strkey encoded id of the contract storing the contract data entry")
+	getLedgerKeyHashCmd.Flags().String("key", "", "Base64 encoded XDR ScVal key of the contract data entry")
+	getLedgerKeyHashCmd.Flags().String("durability", "persistent", "Durability of the contract data entry: persistent or temporary")
+	getLedgerKeyHashCmd.Flags().String("wasm-hash", "", "Hex encoded wasm hash of the contract code entry")
+	getLedgerKeyHashCmd.Flags().StringP("output", "o", "", "Filename of the output file; printed to stdout if not set")
+}
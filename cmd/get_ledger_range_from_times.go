@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -39,30 +40,22 @@ var getLedgerRangeFromTimesCmd = &cobra.Command{
 			cmdLogger.Fatal("could not get output path: ", err)
 		}
 
-		isTest, err := cmd.Flags().GetBool("testnet")
-		if err != nil {
-			cmdLogger.Fatal("could not get testnet boolean: ", err)
-		}
-
-        isFuture, err := cmd.Flags().GetBool("futurenet")
-		if err != nil {
-			cmdLogger.Fatal("could not get futurenet boolean: ", err)
-		}
+		isTest, isFuture := utils.MustNetworkFlags(cmd.Flags(), cmdLogger)
 
 		formatString := "2006-01-02T15:04:05-07:00"
 		startTime, err := time.Parse(formatString, startString)
 		if err != nil {
-			cmdLogger.Fatal("could not parse start time: ", err)
+			cmdLogger.Fatal("could not parse start time: ", utils.ValidationError{Err: err})
 		}
 
 		endTime, err := time.Parse(formatString, endString)
 		if err != nil {
-			cmdLogger.Fatal("could not parse end time: ", err)
+			cmdLogger.Fatal("could not parse end time: ", utils.ValidationError{Err: err})
 		}
 
 		startLedger, endLedger, err := input.GetLedgerRange(startTime, endTime, isTest, isFuture)
 		if err != nil {
-			cmdLogger.Fatal("could not calculate ledger range: ", err)
+			cmdLogger.Fatal("could not calculate ledger range: ", utils.InputError{Err: err})
 		}
 
 		toExport := ledgerRange{Start: startLedger, End: endLedger}
@@ -76,6 +69,7 @@ var getLedgerRangeFromTimesCmd = &cobra.Command{
 			outFile.Write(marshalled)
 			outFile.WriteString("\n")
 			outFile.Close()
+			mustCommitOutputFile(outFile)
 		} else {
 			fmt.Println(string(marshalled))
 		}
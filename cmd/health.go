@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// healthCheckInterval is how often the backend and the network tip are polled
+const healthCheckInterval = 30 * time.Second
+
+// addContinuousFlags adds the flags used when exporting ledger entry changes continuously
+func addContinuousFlags(flags *pflag.FlagSet) {
+	flags.String("health-addr", "", "If set, /healthz and /readyz endpoints reporting the backend connectivity, the last processed ledger "+
+		"and the lag behind the network tip are served on this address, e.g. :8080")
+	flags.Uint32("max-lag-ledgers", 0, "If set, the export exits with code 3 when it falls more than this many ledgers behind the network tip "+
+		"after having caught up with it")
+	flags.String("control-addr", "", "If set, POST /pause, /resume and /flush and GET /status endpoints controlling the export are served on this "+
+		"address, e.g. 127.0.0.1:8081, or on a unix socket, e.g. unix:/run/stellar-etl.sock")
+}
+
+// healthStatus is the body returned by the health endpoints
+type healthStatus struct {
+	BackendConnected bool      `json:"backend_connected"`
+	BackendError     string    `json:"backend_error,omitempty"`
+	LastLedger       uint32    `json:"last_processed_ledger"`
+	LastProcessedAt  time.Time `json:"last_processed_at"`
+	NetworkTip       uint32    `json:"network_tip"`
+	LagLedgers       uint32    `json:"lag_ledgers"`
+}
+
+// exportHealth tracks the progress of a continuous export so that an orchestrator can manage its lifecycle
+type exportHealth struct {
+	mu              sync.Mutex
+	backendErr      error
+	lastLedger      uint32
+	lastProcessedAt time.Time
+	networkTip      uint32
+	maxLag          uint32
+	caughtUp        bool
+	// stop cancels the export with the error of a failed check
+	stop context.CancelCauseFunc
+	// control suspends the lag check while the export is paused, when it is set
+	control *exportControl
+}
+
+// processed records that every ledger up to and including ledger was exported
+func (h *exportHealth) processed(ledger uint32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastLedger = ledger
+	h.lastProcessedAt = time.Now()
+}
+
+// check asks the backend for its latest ledger to verify it is reachable, and reads the network tip from the history archives.
+// The archives are only updated at every checkpoint, so the tip can be up to 64 ledgers behind the network.
+func (h *exportHealth) check(ctx context.Context, backend ledgerbackend.LedgerBackend, archiveURLs []string) {
+	_, backendErr := backend.GetLatestLedgerSequence(ctx)
+	networkTip, tipErr := utils.GetLatestLedgerSequence(archiveURLs)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.backendErr = backendErr
+	if tipErr != nil {
+		cmdLogger.Warnf("could not get the network tip from the history archives: %v", tipErr)
+		return
+	}
+	h.networkTip = networkTip
+}
+
+// watch checks the health every healthCheckInterval until ctx is done
+func (h *exportHealth) watch(ctx context.Context, backend ledgerbackend.LedgerBackend, archiveURLs []string) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		h.check(ctx, backend, archiveURLs)
+		h.stopIfLagging()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lagExceeded returns the lag behind the network tip, and whether it is above maxLag. The lag only counts once the export
+// has caught up with the tip, so that exports starting from an old ledger, or resumed after a pause, are not aborted
+// while they catch up.
+func (h *exportHealth) lagExceeded() (uint32, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.maxLag == 0 || h.networkTip == 0 {
+		return 0, false
+	}
+	if !h.control.running() {
+		h.caughtUp = false
+		return 0, false
+	}
+
+	var lag uint32
+	if h.networkTip > h.lastLedger {
+		lag = h.networkTip - h.lastLedger
+	}
+	if lag <= h.maxLag {
+		h.caughtUp = true
+		return lag, false
+	}
+	return lag, h.caughtUp
+}
+
+// stopIfLagging stops the export with a utils.LagError when it fell more than maxLag ledgers behind the network tip. The
+// export returns once its context is done and the command exits with the exit code of utils.ErrorCategoryLag.
+func (h *exportHealth) stopIfLagging() {
+	if lag, exceeded := h.lagExceeded(); exceeded {
+		h.stop(utils.LagError{Err: fmt.Errorf("export is %d ledgers behind the network tip, more than the %d allowed by max-lag-ledgers", lag, h.maxLag)})
+	}
+}
+
+func (h *exportHealth) status() healthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := healthStatus{
+		BackendConnected: h.backendErr == nil,
+		LastLedger:       h.lastLedger,
+		LastProcessedAt:  h.lastProcessedAt,
+		NetworkTip:       h.networkTip,
+	}
+	if h.backendErr != nil {
+		status.BackendError = h.backendErr.Error()
+	}
+	if h.networkTip > h.lastLedger {
+		status.LagLedgers = h.networkTip - h.lastLedger
+	}
+	return status
+}
+
+// handle registers /healthz, which fails while the backend is unreachable, and /readyz, which also fails until the first
+// batch is exported, below prefix on mux. Both respond with the current healthStatus.
+func (h *exportHealth) handle(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(prefix+"/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := h.status()
+		writeHealthStatus(w, status, status.BackendConnected)
+	})
+	mux.HandleFunc(prefix+"/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := h.status()
+		writeHealthStatus(w, status, status.BackendConnected && status.LastLedger != 0)
+	})
+}
+
+// serveHealth serves the health endpoints registered on mux on addr. The address is bound before returning, so that a
+// port already in use fails the command before anything is exported.
+func serveHealth(addr string, mux *http.ServeMux) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			cmdLogger.Errorf("stopped serving health endpoints on %s: %v", addr, err)
+		}
+	}()
+	return nil
+}
+
+func writeHealthStatus(w http.ResponseWriter, status healthStatus, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
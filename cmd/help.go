@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// rangeExportExample is the example of the commands exporting a ledger range to a single file
+const rangeExportExample = `  # Export the {{.Object}} of a ledger range
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 --output exported_{{.Object}}.txt
+
+  # Export from testnet and upload the file to GCS
+  stellar-etl {{.Command}} --testnet --start-ledger 1000 --end-ledger 500000 \
+    --cloud-provider gcp --cloud-storage-bucket my-bucket
+
+  # Write gzipped NDJSON for Snowflake, with one file per UTC day
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 \
+    --warehouse snowflake --partition-by day`
+
+// changesExportExample is the example of the commands exporting ledger entry changes to a folder of batch files
+const changesExportExample = `  # Export the {{.Object}} changes of a ledger range in batches of 64 ledgers
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 --output exported_{{.Object}}/
+
+  # Export continuously from captive core, serving health checks and exiting when more than 1000 ledgers behind
+  stellar-etl {{.Command}} --start-ledger 1000 --captive-core --core-config /etl/docker/stellar-core.cfg \
+    --health-addr :8080 --max-lag-ledgers 1000
+
+  # Merge the batch files into files of about 256MB before uploading them to GCS
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 --compact-target-bytes 268435456 \
+    --cloud-provider gcp --cloud-storage-bucket my-bucket`
+
+// commandExample is the template of the examples of a command, rendered with the command name and the exported object
+type commandExample struct {
+	Template string
+	Object   string
+}
+
+// commandExamples are the examples shown in the help of each command, keyed by command name
+var commandExamples = map[string]commandExample{
+	"export_ledgers":            {Template: rangeExportExample, Object: "ledgers"},
+	"export_transactions":       {Template: rangeExportExample, Object: "transactions"},
+	"export_participants":       {Template: rangeExportExample, Object: "participants"},
+	"export_operations":         {Template: rangeExportExample, Object: "operations"},
+	"export_effects":            {Template: rangeExportExample, Object: "effects"},
+	"export_assets":             {Template: rangeExportExample, Object: "assets"},
+	"export_trades":             {Template: rangeExportExample, Object: "trades"},
+	"export_diagnostic_events":  {Template: rangeExportExample, Object: "diagnostic_events"},
+	"export_tx_footprints":      {Template: rangeExportExample, Object: "tx_footprints"},
+	"export_ledger_transaction": {Template: rangeExportExample, Object: "ledger_transaction"},
+	"export_ledger_entry_changes": {Template: changesExportExample + `
+
+  # Only export the account and trustline changes
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 --export-accounts --export-trustlines`, Object: "changes"},
+	"export_account_data":    {Template: changesExportExample, Object: "account_data"},
+	"export_contract_nonces": {Template: changesExportExample, Object: "contract_nonces"},
+	"export_plugin": {Template: `  # Run the processors of a Go plugin over a ledger range
+  stellar-etl {{.Command}} --start-ledger 1000 --end-ledger 500000 --plugin ./my_tables.so --output exported_plugin_data/`},
+	"get_ledger_range_from_times": {Template: `  # Find the ledgers closed between two times
+  stellar-etl {{.Command}} --start-time 2019-09-13T23:00:00+00:00 --end-time 2019-09-14T13:35:10+00:00 \
+    --output exported_range.txt`},
+	"get_ledger_key_hash": {Template: `  # Compute the key_hash of the ttl entry of a contract instance
+  stellar-etl {{.Command}} --contract-id CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA --key AAAAFA==
+
+  # Compute the key_hash of the ttl entry of a contract code entry
+  stellar-etl {{.Command}} --wasm-hash 5d0c6c6b1f8b5a3c2b7e5e4c6e1d6a8f0f3a5b9c1d2e4f60718293a4b5c6d7e8`},
+	"get_latest_ledger": {Template: `  # Print the latest checkpoint ledger of pubnet
+  stellar-etl {{.Command}} --network pubnet
+
+  # Also read the network tip from captive core
+  stellar-etl {{.Command}} --network testnet --network-tip --captive-core`},
+	"generate_ddl": {Template: `  # Print the Snowflake statements of every table
+  stellar-etl {{.Command}}
+
+  # Write the Athena statement of the transactions table, reading from an S3 prefix
+  stellar-etl {{.Command}} --warehouse athena --table transactions --location s3://my-bucket/stellar \
+    --output transactions.sql`},
+	"bench": {Template: `  # Benchmark the transforms and compare them with a previous run
+  stellar-etl {{.Command}} --ledgers 64 --transactions-per-ledger 100 --output bench.json \
+    --baseline previous_bench.json --tolerance 0.1`},
+	"validate_horizon": {Template: `  # Compare the transactions and operations of a ledger range with Horizon
+  stellar-etl {{.Command}} --start-ledger 30000000 --end-ledger 30000010 --output discrepancies.txt`},
+	"estimate": {Template: `  # Estimate the size and runtime of a full history export of two tables
+  stellar-etl {{.Command}} --start-ledger 2 --end-ledger 50000000 --tables transactions,operations`},
+	"stats_changes": {Template: `  # Summarize the changes of a day of ledgers and list the 20 contracts writing the most data
+  stellar-etl {{.Command}} --start-ledger 50000000 --end-ledger 50017280 --top 20`},
+	"gapfill": {Template: `  # Print the ranges of the transactions missing from the uploads of a manifest
+  stellar-etl {{.Command}} --start-ledger 2 --end latest --manifest-file manifest.jsonl --table transactions --dry-run
+
+  # Export the ranges missing from the output of a warehouse query, in runs of at most 100000 ledgers
+  stellar-etl {{.Command}} --start-ledger 2 --end-ledger 50000000 --ranges-file loaded_ranges.csv \
+    --command export_transactions --max-range-ledgers 100000 -- --output exported_transactions.txt`},
+}
+
+// flagValues are the accepted values of the flags taking one of a fixed set of values, offered by shell completion
+var flagValues = map[string][]string{
+	"null-semantics":       {utils.NullSemanticsPreserve, utils.NullSemanticsZero, utils.NullSemanticsNull},
+	"timestamp-format":     {utils.TimestampFormatRFC3339, utils.TimestampFormatEpochSeconds, utils.TimestampFormatEpochMicros},
+	"warehouse":            {utils.WarehouseBigQueryAlias, utils.WarehouseSnowflake, utils.WarehouseRedshift, utils.WarehouseAthena},
+	"partition-layout":     {utils.PartitionLayoutHive},
+	"partition-by":         {utils.PartitionByDay},
+	"redact":               {utils.RedactHash, utils.RedactDrop},
+	"network":              {utils.NetworkPubnet, utils.NetworkTestnet, utils.NetworkFuturenet},
+	"cloud-provider":       {"gcp"},
+	"stream-partition-key": {utils.StreamPartitionKeyLedger, utils.StreamPartitionKeyAccount, utils.StreamPartitionKeyContract},
+}
+
+// addCommandHelp sets the examples and the flag value completions of every command below root
+func addCommandHelp(root *cobra.Command) {
+	for _, cmd := range root.Commands() {
+		if example, ok := commandExamples[cmd.Name()]; ok && cmd.Example == "" {
+			cmd.Example = renderExample(cmd.Name(), example)
+		}
+
+		for name, values := range flagValues {
+			if cmd.Flags().Lookup(name) != nil {
+				cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+			}
+		}
+
+		addCommandHelp(cmd)
+	}
+}
+
+// addTableCompletions completes the table names taken by generate_ddl and estimate
+func addTableCompletions() {
+	generateDDLCmd.RegisterFlagCompletionFunc("table", cobra.FixedCompletions(transform.TableNames(), cobra.ShellCompDirectiveNoFileComp))
+
+	tables := make([]string, 0, len(tableSamplers))
+	for table := range tableSamplers {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	estimateCmd.RegisterFlagCompletionFunc("tables", cobra.FixedCompletions(tables, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func renderExample(command string, example commandExample) string {
+	var rendered bytes.Buffer
+	err := template.Must(template.New(command).Parse(example.Template)).Execute(&rendered, struct {
+		Command string
+		Object  string
+	}{command, example.Object})
+	if err != nil {
+		cmdLogger.Fatalf("could not render the examples of %s: %v", command, err)
+	}
+	return strings.TrimRight(rendered.String(), "\n")
+}
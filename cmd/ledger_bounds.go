@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// ledgerBoundFlags maps the --start and --end flags to the ledger flags they are resolved into
+var ledgerBoundFlags = []struct {
+	name, ledgerFlag string
+}{
+	{"start", "start-ledger"},
+	{"end", "end-ledger"},
+}
+
+// latestLedgerKeyword resolves a ledger bound to the latest ledger published to the history archives
+const latestLedgerKeyword = "latest"
+
+// addLedgerBoundFlags adds --start and --end to every command taking a --start-ledger or --end-ledger, so that the
+// ledger range of any command can be given as ledgers, datetimes or 'latest'
+func addLedgerBoundFlags(root *cobra.Command) {
+	for _, cmd := range root.Commands() {
+		for _, bound := range ledgerBoundFlags {
+			if cmd.Flags().Lookup(bound.ledgerFlag) != nil && cmd.Flags().Lookup(bound.name) == nil {
+				cmd.Flags().String(bound.name, "", fmt.Sprintf("The %s of the export range as a ledger sequence number, an RFC3339 datetime "+
+					"such as 2024-01-01T00:00:00Z, or 'latest' for the latest ledger in the history archives. Resolved into --%s", bound.name, bound.ledgerFlag))
+			}
+		}
+
+		addLedgerBoundFlags(cmd)
+	}
+}
+
+// mustResolveLedgerBounds resolves --start and --end into the ledger sequence numbers of --start-ledger and --end-ledger,
+// which the commands read. It aborts when a bound cannot be resolved or is set along with its ledger flag.
+func mustResolveLedgerBounds(cmd *cobra.Command) {
+	for _, bound := range ledgerBoundFlags {
+		value := stringFlag(cmd, bound.name)
+		if value == "" {
+			continue
+		}
+		if cmd.Flags().Changed(bound.ledgerFlag) {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, invalidFlagsMessage(cmd, []string{fmt.Sprintf("--%s and --%s cannot be set together", bound.name, bound.ledgerFlag)}))
+		}
+
+		sequence, err := resolveLedgerBound(cmd, value)
+		if err != nil {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, invalidFlagsMessage(cmd, []string{fmt.Sprintf("could not resolve --%s %s: %v", bound.name, value, err)}))
+		}
+		if err = cmd.Flags().Set(bound.ledgerFlag, strconv.FormatUint(uint64(sequence), 10)); err != nil {
+			cmdLogger.Fatalf("could not set --%s: %v", bound.ledgerFlag, err)
+		}
+		cmdLogger.Infof("--%s %s resolved to ledger %d", bound.name, value, sequence)
+	}
+}
+
+// resolveLedgerBound returns the ledger sequence number of a ledger bound: a sequence number, an RFC3339 datetime or
+// 'latest', on the network selected by the flags of the command
+func resolveLedgerBound(cmd *cobra.Command, value string) (uint32, error) {
+	if sequence, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return uint32(sequence), nil
+	}
+
+	isTest, isFuture, err := boundNetwork(cmd)
+	if err != nil {
+		return 0, err
+	}
+
+	if strings.EqualFold(value, latestLedgerKeyword) {
+		env := utils.GetEnvironmentDetails(utils.CommonFlagValues{IsTest: isTest, IsFuture: isFuture})
+		return utils.GetLatestLedgerSequence(env.ArchiveURLs)
+	}
+
+	closeTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a ledger sequence number, an RFC3339 datetime or '%s'", latestLedgerKeyword)
+	}
+	return input.GetLedgerForTime(closeTime, isTest, isFuture)
+}
+
+// boundNetwork returns the network datetimes and 'latest' are resolved on. With several --network flags, the bounds
+// would differ by network, so each network should set its range in its :start-end suffix instead.
+func boundNetwork(cmd *cobra.Command) (isTest, isFuture bool, err error) {
+	isTest, _ = cmd.Flags().GetBool("testnet")
+	isFuture, _ = cmd.Flags().GetBool("futurenet")
+
+	networks := stringArrayFlag(cmd, "network")
+	if len(networks) > 1 {
+		return false, false, fmt.Errorf("datetimes and '%s' cannot be resolved for several networks, set the range of each network in its --network suffix", latestLedgerKeyword)
+	}
+	if len(networks) == 1 {
+		network, err := utils.ParseNetworkRange(networks[0])
+		if err != nil {
+			return false, false, err
+		}
+		isTest = network.Network == utils.NetworkTestnet
+		isFuture = network.Network == utils.NetworkFuturenet
+	}
+
+	return isTest, isFuture, nil
+}
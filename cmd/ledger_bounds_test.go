@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLedgerBoundsTestCommand(networks ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "export_test"}
+	cmd.Flags().Uint32("start-ledger", 2, "")
+	cmd.Flags().Uint32("end-ledger", 0, "")
+	cmd.Flags().Bool("testnet", false, "")
+	cmd.Flags().Bool("futurenet", false, "")
+	cmd.Flags().StringArray("network", nil, "")
+	for _, network := range networks {
+		cmd.Flags().Set("network", network)
+	}
+	return cmd
+}
+
+func TestAddLedgerBoundFlags(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	exportCmd := newLedgerBoundsTestCommand()
+	otherCmd := &cobra.Command{Use: "other"}
+	root.AddCommand(exportCmd, otherCmd)
+
+	addLedgerBoundFlags(root)
+	assert.NotNil(t, exportCmd.Flags().Lookup("start"))
+	assert.NotNil(t, exportCmd.Flags().Lookup("end"))
+	assert.Nil(t, otherCmd.Flags().Lookup("start"))
+	assert.Nil(t, otherCmd.Flags().Lookup("end"))
+
+	// adding the flags again must not redefine them, which would panic
+	assert.NotPanics(t, func() { addLedgerBoundFlags(root) })
+}
+
+func TestResolveLedgerBound(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []string
+		value    string
+		want     uint32
+		wantErr  error
+	}{
+		{
+			name:  "sequence number",
+			value: "1000",
+			want:  1000,
+		},
+		{
+			name:     "sequence number with several networks",
+			networks: []string{"pubnet", "testnet"},
+			value:    "1000",
+			want:     1000,
+		},
+		{
+			name:    "invalid value",
+			value:   "yesterday",
+			wantErr: fmt.Errorf("expected a ledger sequence number, an RFC3339 datetime or '%s'", latestLedgerKeyword),
+		},
+		{
+			name:     "latest with several networks",
+			networks: []string{"pubnet", "testnet"},
+			value:    latestLedgerKeyword,
+			wantErr:  fmt.Errorf("datetimes and 'latest' cannot be resolved for several networks, set the range of each network in its --network suffix"),
+		},
+		{
+			name:     "datetime with an invalid network",
+			networks: []string{"mainnet"},
+			value:    "2024-01-01T00:00:00Z",
+			wantErr:  fmt.Errorf("invalid network \"mainnet\": must be one of pubnet, testnet or futurenet"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := resolveLedgerBound(newLedgerBoundsTestCommand(test.networks...), test.value)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestBoundNetwork(t *testing.T) {
+	isTest, isFuture, err := boundNetwork(newLedgerBoundsTestCommand())
+	assert.NoError(t, err)
+	assert.False(t, isTest)
+	assert.False(t, isFuture)
+
+	isTest, isFuture, err = boundNetwork(newLedgerBoundsTestCommand("testnet:100-200"))
+	assert.NoError(t, err)
+	assert.True(t, isTest)
+	assert.False(t, isFuture)
+
+	isTest, isFuture, err = boundNetwork(newLedgerBoundsTestCommand("futurenet"))
+	assert.NoError(t, err)
+	assert.False(t, isTest)
+	assert.True(t, isFuture)
+
+	cmd := newLedgerBoundsTestCommand()
+	cmd.Flags().Set("testnet", "true")
+	isTest, _, err = boundNetwork(cmd)
+	assert.NoError(t, err)
+	assert.True(t, isTest)
+}
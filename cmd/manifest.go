@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// manifestPath is the file that uploaded objects are recorded in. Uploads are not recorded when it is empty.
+var manifestPath string
+
+var manifestMutex sync.Mutex
+
+// crc32cTable is the Castagnoli table GCS uses for object checksums
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// manifestEntry records an uploaded object so that downstream jobs can validate what they load
+type manifestEntry struct {
+	LocalPath  string    `json:"local_path"`
+	Object     string    `json:"object"`
+	Bytes      int64     `json:"bytes"`
+	CRC32C     string    `json:"crc32c"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// recordUpload appends the entry as a line of the run manifest
+func recordUpload(entry manifestEntry) error {
+	if manifestPath == "" {
+		return nil
+	}
+
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := os.OpenFile(manifestPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open manifest %s: %v", manifestPath, err)
+	}
+	defer manifest.Close()
+
+	_, err = manifest.Write(append(line, '\n'))
+	return err
+}
+
+// fileCRC32C returns the CRC32C checksum and size of the file
+func fileCRC32C(path string) (uint32, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	checksum := crc32.New(crc32cTable)
+	size, err := io.Copy(checksum, file)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return checksum.Sum32(), size, nil
+}
+
+// encodeCRC32C encodes a checksum the way GCS reports it: base64 of the big-endian bytes
+func encodeCRC32C(checksum uint32) string {
+	encoded := make([]byte, 4)
+	binary.BigEndian.PutUint32(encoded, checksum)
+	return base64.StdEncoding.EncodeToString(encoded)
+}
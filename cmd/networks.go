@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// alignedNetworkRange returns the ledger range selected for a network, moved to checkpoint boundaries when align is set
+func alignedNetworkRange(network utils.NetworkRange, align bool) (start, end uint32) {
+	start, end = network.Start, network.End
+	if !align {
+		return start, end
+	}
+
+	start = utils.CheckpointAlignedStart(start)
+	if end != 0 {
+		end = utils.CheckpointAlignedEnd(end)
+	}
+	if start != network.Start || end != network.End {
+		cmdLogger.Infof("align-checkpoints: range %d-%d of %s moved to %d-%d", network.Start, network.End, network.Network, start, end)
+	}
+	return start, end
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// hiveDefaultPartition is the partition value Hive and Athena use when the partition key is unknown
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// unknownDatePartition holds the rows without a close time when partitioning by day without the hive layout
+const unknownDatePartition = "unknown_date"
+
+// hivePartitionPath places a file under the dt=YYYY-MM-DD/ledger_range=start-end partition directories of its folder
+func hivePartitionPath(path string, closedAt time.Time, start, end uint32) string {
+	dt := hiveDefaultPartition
+	if !closedAt.IsZero() {
+		dt = closedAt.UTC().Format("2006-01-02")
+	}
+
+	return filepath.Join(filepath.Dir(path), "dt="+dt, fmt.Sprintf("ledger_range=%d-%d", start, end), filepath.Base(path))
+}
+
+// partitionPath returns where rows closed at the provided time should be written according to the partition flags
+func partitionPath(path string, closedAt time.Time, start, end uint32, commonArgs utils.CommonFlagValues) string {
+	if commonArgs.PartitionLayout == utils.PartitionLayoutHive {
+		return hivePartitionPath(path, closedAt, start, end)
+	}
+
+	if commonArgs.PartitionBy == utils.PartitionByDay {
+		day := unknownDatePartition
+		if !closedAt.IsZero() {
+			day = closedAt.UTC().Format("2006-01-02")
+		}
+		return filepath.Join(filepath.Dir(path), day, filepath.Base(path))
+	}
+
+	return path
+}
+
+// partitionOutputFile moves or splits a closed output file into its partition layout and returns the resulting paths
+func partitionOutputFile(outFile *outputFile, start, end uint32, commonArgs utils.CommonFlagValues) []string {
+	destination := func(closedAt time.Time) string {
+		return partitionPath(outFile.path, closedAt, start, end, commonArgs)
+	}
+
+	if commonArgs.PartitionBy == utils.PartitionByDay {
+		paths, err := splitByDay(outFile.path, commonArgs.TimestampFormat, destination)
+		if err != nil {
+			cmdLogger.Errorf("could not partition %s by day: %v", outFile.path, err)
+			return []string{outFile.path}
+		}
+
+		deleteLocalFiles(outFile.path)
+		return paths
+	}
+
+	path := destination(outFile.firstClosedAt)
+	if path == outFile.path {
+		return []string{path}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		err = os.Rename(outFile.path, path)
+	}
+	if err != nil {
+		cmdLogger.Errorf("could not move %s to %s: %v", outFile.path, path, err)
+		return []string{outFile.path}
+	}
+
+	return []string{path}
+}
+
+// splitByDay routes every row of a newline delimited json file into the file returned by destination for the UTC date the row's ledger closed
+func splitByDay(path, timestampFormat string, destination func(closedAt time.Time) string) ([]string, error) {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer inFile.Close()
+
+	dayFiles := map[string]*os.File{}
+	paths := []string{}
+	defer func() {
+		for _, f := range dayFiles {
+			f.Close()
+		}
+	}()
+
+	reader := bufio.NewReader(inFile)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+
+		if len(line) > 0 {
+			day := rowCloseDay(line, timestampFormat)
+			dayPath := destination(day)
+			dayFile, ok := dayFiles[dayPath]
+			if !ok {
+				if err := os.MkdirAll(filepath.Dir(dayPath), os.ModePerm); err != nil {
+					return nil, err
+				}
+				dayFile, err = os.OpenFile(dayPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+				if err != nil {
+					return nil, err
+				}
+				dayFiles[dayPath] = dayFile
+				paths = append(paths, dayPath)
+			}
+
+			if _, err := dayFile.Write(line); err != nil {
+				return nil, err
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	return paths, nil
+}
+
+// rowCloseDay returns the UTC date the ledger of an exported row closed, or the zero time if the row has no close time
+func rowCloseDay(line []byte, timestampFormat string) time.Time {
+	closedAt := rowClosedAt(line, timestampFormat)
+	if closedAt.IsZero() {
+		return closedAt
+	}
+
+	year, month, day := closedAt.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// rowClosedAt returns the UTC time the ledger of an exported row closed, or the zero time if the row has no close time
+func rowClosedAt(line []byte, timestampFormat string) time.Time {
+	row := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); err != nil {
+		return time.Time{}
+	}
+
+	for _, key := range closedAtKeys {
+		if parsed, ok := parseTimestamp(row[key], timestampFormat); ok {
+			return parsed.UTC()
+		}
+	}
+
+	return time.Time{}
+}
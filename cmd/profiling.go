@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"path/filepath"
+	runtimepprof "runtime/pprof"
+	"syscall"
+	"time"
+)
+
+// pprofAddr is the address the net/http/pprof endpoints are served on. They are not served when it is empty.
+var pprofAddr string
+
+// profileDir is the folder profiles are written to when the process receives SIGUSR1. Profiles are not captured when
+// it is empty.
+var profileDir string
+
+// cpuProfileDuration is how long the CPU is profiled for after a SIGUSR1
+const cpuProfileDuration = 30 * time.Second
+
+// startProfiling serves the pprof endpoints and listens for profile requests, as set by the pprof-addr and profile-dir
+// flags
+func startProfiling() {
+	if pprofAddr != "" {
+		servePprof(pprofAddr)
+	}
+	if profileDir != "" {
+		if err := os.MkdirAll(profileDir, os.ModePerm); err != nil {
+			cmdLogger.Fatalf("could not create profile directory %s: %v", profileDir, err)
+		}
+		go dumpProfilesOnSignal(profileDir)
+	}
+}
+
+// servePprof serves the endpoints of net/http/pprof below /debug/pprof/ on addr
+func servePprof(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		cmdLogger.Fatalf("could not serve pprof endpoints on %s: %v", addr, err)
+	}
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			cmdLogger.Errorf("could not serve pprof endpoints on %s: %v", addr, err)
+		}
+	}()
+}
+
+// dumpProfilesOnSignal writes a heap profile and a CPU profile of the next cpuProfileDuration to dir every time the
+// process receives SIGUSR1. Signals received while the CPU is being profiled only dump the heap.
+func dumpProfilesOnSignal(dir string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	cpuDone := make(chan struct{}, 1)
+	cpuDone <- struct{}{}
+	for range signals {
+		timestamp := time.Now().UTC().Format("20060102T150405Z")
+
+		heapPath := filepath.Join(dir, fmt.Sprintf("heap-%s.pprof", timestamp))
+		if err := writeHeapProfile(heapPath); err != nil {
+			cmdLogger.Errorf("could not write heap profile: %v", err)
+		} else {
+			cmdLogger.Infof("Wrote heap profile to %s", heapPath)
+		}
+
+		select {
+		case <-cpuDone:
+		default:
+			cmdLogger.Info("The CPU is already being profiled, skipping the CPU profile")
+			continue
+		}
+		cpuPath := filepath.Join(dir, fmt.Sprintf("cpu-%s.pprof", timestamp))
+		go func() {
+			defer func() { cpuDone <- struct{}{} }()
+			if err := writeCPUProfile(cpuPath, cpuProfileDuration); err != nil {
+				cmdLogger.Errorf("could not write CPU profile: %v", err)
+				return
+			}
+			cmdLogger.Infof("Wrote CPU profile to %s", cpuPath)
+		}()
+	}
+}
+
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := runtimepprof.Lookup("heap").WriteTo(file, 0); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeCPUProfile(path string, duration time.Duration) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// fails when the CPU is already profiled, e.g. through the pprof endpoints
+	if err := runtimepprof.StartCPUProfile(file); err != nil {
+		return err
+	}
+	time.Sleep(duration)
+	runtimepprof.StopCPUProfile()
+	return file.Close()
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// applyRedaction hashes or drops the free-text values of a decoded entry: text memos, manage data values and contract
+// event bodies, topics and data. The raw XDR columns embedding the same values are redacted too, since they could otherwise be decoded.
+func applyRedaction(i map[string]interface{}, entry interface{}, redact string) {
+	if redact == utils.RedactNone {
+		return
+	}
+
+	switch entry.(type) {
+	case transform.TransactionOutput:
+		if i["memo_type"] == xdr.MemoTypeMemoText.String() {
+			redactValue(i, "memo", redact)
+		}
+		redactValue(i, "tx_envelope", redact)
+		redactValue(i, "tx_meta", redact)
+	case transform.LedgerTransactionOutput:
+		redactValue(i, "tx_envelope", redact)
+		redactValue(i, "tx_meta", redact)
+	case transform.OperationOutput:
+		if i["type_string"] == "manage_data" {
+			if details, ok := i["details"].(map[string]interface{}); ok {
+				redactValue(details, "value", redact)
+			}
+			redactValue(i, "operation_body_xdr", redact)
+		}
+	case transform.EffectOutput:
+		if typeString := i["type_string"]; typeString == "data_created" || typeString == "data_updated" {
+			if details, ok := i["details"].(map[string]interface{}); ok {
+				redactValue(details, "value", redact)
+			}
+		}
+	case transform.AccountDataOutput:
+		redactValue(i, "data_value", redact)
+		redactValue(i, "ledger_entry_xdr", redact)
+	case transform.DiagnosticEventOutput:
+		redactValue(i, "body", redact)
+		redactStructuredValue(i, "topics", redact)
+		// the decoded body holds the same values as the body XDR
+		redactStructuredValue(i, "data", redact)
+		for _, topic := range []string{"topic_1", "topic_2", "topic_3", "topic_4"} {
+			redactValue(i, topic, redact)
+		}
+	}
+}
+
+// redactValue replaces a non empty string value by its hex encoded SHA-256 hash, or by null
+func redactValue(i map[string]interface{}, key string, redact string) {
+	value, ok := i[key].(string)
+	if !ok || value == "" {
+		return
+	}
+
+	switch redact {
+	case utils.RedactHash:
+		hash := sha256.Sum256([]byte(value))
+		i[key] = hex.EncodeToString(hash[:])
+	case utils.RedactDrop:
+		i[key] = nil
+	}
+}
+
+// redactStructuredValue redacts a non null value that may not be a plain string, like the rendered topics of a contract
+// event, by hashing its JSON encoding or dropping it
+func redactStructuredValue(i map[string]interface{}, key string, redact string) {
+	value := i[key]
+	if value == nil {
+		return
+	}
+	if _, ok := value.(string); ok {
+		redactValue(i, key, redact)
+		return
+	}
+
+	switch redact {
+	case utils.RedactHash:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			cmdLogger.Errorf("could not encode %s to redact it: %v", key, err)
+			i[key] = nil
+			return
+		}
+		hash := sha256.Sum256(encoded)
+		i[key] = hex.EncodeToString(hash[:])
+	case utils.RedactDrop:
+		i[key] = nil
+	}
+}
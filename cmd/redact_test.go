@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func sha256Hex(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func diagnosticEventRow() map[string]interface{} {
+	return map[string]interface{}{
+		"contract_id": "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA",
+		"body":        "AAAAAQ==",
+		"topics":      []interface{}{map[string]interface{}{"symbol": "transfer"}},
+		"topic_1":     "transfer",
+		"topic_2":     "",
+		"topic_3":     "",
+		"topic_4":     "",
+		"data":        map[string]interface{}{"string": "hello"},
+	}
+}
+
+func TestApplyRedaction(t *testing.T) {
+	type redactionTest struct {
+		name   string
+		redact string
+		want   map[string]interface{}
+	}
+
+	tests := []redactionTest{
+		{
+			name:   "none",
+			redact: utils.RedactNone,
+			want:   diagnosticEventRow(),
+		},
+		{
+			name:   "hash",
+			redact: utils.RedactHash,
+			want: map[string]interface{}{
+				"contract_id": "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA",
+				"body":        sha256Hex("AAAAAQ=="),
+				"topics":      sha256Hex(`[{"symbol":"transfer"}]`),
+				"topic_1":     sha256Hex("transfer"),
+				"topic_2":     "",
+				"topic_3":     "",
+				"topic_4":     "",
+				"data":        sha256Hex(`{"string":"hello"}`),
+			},
+		},
+		{
+			name:   "drop",
+			redact: utils.RedactDrop,
+			want: map[string]interface{}{
+				"contract_id": "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA",
+				"body":        nil,
+				"topics":      nil,
+				"topic_1":     nil,
+				"topic_2":     "",
+				"topic_3":     "",
+				"topic_4":     "",
+				"data":        nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		row := diagnosticEventRow()
+		applyRedaction(row, transform.DiagnosticEventOutput{}, test.redact)
+		assert.Equal(t, test.want, row, test.name)
+	}
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -13,6 +18,12 @@ import (
 
 var cfgFile string
 
+// commandTimeout bounds the runtime of a command, so that hung archive, datastore or sink connections abort it
+var commandTimeout time.Duration
+
+// stopTimeout releases the timer of --timeout once the command returned
+var stopTimeout context.CancelFunc = func() {}
+
 var cmdLogger = utils.NewEtlLogger()
 
 // rootCmd represents the base command when called without any subcommands
@@ -23,15 +34,51 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startRunReport(cmd)
+		startProfiling()
+		mustResolveLedgerBounds(cmd)
+		mustValidateFlags(cmd)
+		if commandTimeout > 0 {
+			// reads and writes given the context fail once it expires, and the command returns
+			ctx, cancel := context.WithTimeoutCause(cmd.Context(), commandTimeout, fmt.Errorf("command did not finish within the %s timeout", commandTimeout))
+			stopTimeout = cancel
+			cmd.SetContext(ctx)
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// Commands read and write through cmd.Context(), which is cancelled on SIGINT and SIGTERM. The default behaviour is
+	// restored after the first signal, so that a second one kills a command that does not stop.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	addLedgerBoundFlags(rootCmd)
+	addCommandHelp(rootCmd)
+	addTableCompletions()
+
+	// Errors returned by cobra are caused by unknown commands or invalid flags
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	stopTimeout()
+	if err != nil {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, invalidFlagsMessage(cmd, []string{err.Error()}))
+	}
+
+	// A command returns early once its context is done. When it was stopped by --timeout or by a failed check, like the
+	// lag check of continuous exports, rather than by a signal, it exits with the exit code of the cause.
+	if cmd != nil && cmd.Context() != nil {
+		if cause := context.Cause(cmd.Context()); cause != nil && !errors.Is(cause, context.Canceled) {
+			cmdLogger.Fatal(cause)
+		}
 	}
+	finishRunReport(0, "", "")
 }
 
 func init() {
@@ -42,6 +89,13 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.stellar-etl.yaml)")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "If set, e.g. to 2h, the command is aborted with a non-zero exit code once "+
+		"it runs for longer than this. Defaults to no timeout")
+	rootCmd.PersistentFlags().StringVar(&runReportPath, "run-report", "", "If set, a JSON report holding the exit code, the category of the error "+
+		"that stopped the command, if any, and the number of errors logged by category is written to this file when the command exits")
+	rootCmd.PersistentFlags().StringVar(&manifestPath, "manifest", "", "If set, every uploaded object is appended to this newline delimited JSON file along with its size and CRC32C checksum")
+	rootCmd.PersistentFlags().StringVar(&pprofAddr, "pprof-addr", "", "If set, e.g. to localhost:6060, the net/http/pprof endpoints are served on this address under /debug/pprof/")
+	rootCmd.PersistentFlags().StringVar(&profileDir, "profile-dir", "", "If set, a heap profile and a 30 second CPU profile are written to this folder every time the process receives SIGUSR1")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -72,4 +126,22 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
+
+	// The networks section of the config file overrides the settings of the built-in network presets
+	var networkOverrides map[string]utils.NetworkPreset
+	if err := viper.UnmarshalKey("networks", &networkOverrides); err != nil {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("invalid networks section in the config file: %v", err))
+	}
+	if err := utils.OverrideNetworkPresets(networkOverrides); err != nil {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, err.Error())
+	}
+
+	// The tables section of the config file sets the output settings of each table
+	var outputs map[string]tableOutput
+	if err := viper.UnmarshalKey("tables", &outputs); err != nil {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("invalid tables section in the config file: %v", err))
+	}
+	if err := setTableOutputs(outputs); err != nil {
+		cmdLogger.Abort(utils.ErrorCategoryValidation, err.Error())
+	}
 }
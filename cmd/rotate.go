@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// rotationPolicy limits how much a single output file holds. Zero values do not limit anything.
+type rotationPolicy struct {
+	maxRows  int64
+	maxBytes int64
+	interval time.Duration
+}
+
+func newRotationPolicy(commonArgs utils.CommonFlagValues) rotationPolicy {
+	return rotationPolicy{
+		maxRows:  commonArgs.MaxFileRows,
+		maxBytes: commonArgs.MaxFileBytes,
+		interval: commonArgs.RotateInterval,
+	}
+}
+
+func (p rotationPolicy) enabled() bool {
+	return p.maxRows > 0 || p.maxBytes > 0 || p.interval > 0
+}
+
+// rotatedPath returns the path of the nth file a file is rotated into, numbered before its extension so that loaders
+// matching the extension still pick it up
+func rotatedPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// rotateOutputFile rotates a closed newline delimited file into sequence numbered files that follow the policy. A file
+// that already follows it keeps its path.
+func rotateOutputFile(path, timestampFormat string, policy rotationPolicy) ([]string, error) {
+	if !policy.enabled() {
+		return []string{path}, nil
+	}
+
+	inFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer inFile.Close()
+
+	reader := bufio.NewReader(inFile)
+	paths := []string{}
+	var outFile *os.File
+	var rows, written int64
+	var window time.Time
+
+	// a failed rotation removes its partial files and leaves the original file in place
+	abort := func(err error) ([]string, error) {
+		if outFile != nil {
+			outFile.Close()
+		}
+		for _, partPath := range paths {
+			os.Remove(partPath)
+		}
+		return nil, err
+	}
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return abort(readErr)
+		}
+
+		if len(line) > 0 {
+			rotate := outFile == nil
+			if policy.maxRows > 0 && rows >= policy.maxRows {
+				rotate = true
+			}
+			if policy.maxBytes > 0 && written > 0 && written+int64(len(line)) > policy.maxBytes {
+				rotate = true
+			}
+			if policy.interval > 0 {
+				// rows are grouped by the interval their ledger closed in; rows without a close time stay with the previous rows
+				if closedAt := rowClosedAt(line, timestampFormat); !closedAt.IsZero() {
+					closedWindow := closedAt.Truncate(policy.interval)
+					rotate = rotate || !window.IsZero() && !closedWindow.Equal(window)
+					window = closedWindow
+				}
+			}
+
+			if rotate {
+				if outFile != nil {
+					if err := outFile.Close(); err != nil {
+						outFile = nil
+						return abort(err)
+					}
+				}
+				partPath := rotatedPath(path, len(paths))
+				outFile, err = os.Create(partPath)
+				if err != nil {
+					return abort(err)
+				}
+				paths = append(paths, partPath)
+				rows, written = 0, 0
+			}
+
+			n, err := outFile.Write(line)
+			if err != nil {
+				return abort(err)
+			}
+			rows++
+			written += int64(n)
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			outFile = nil
+			return abort(err)
+		}
+	}
+
+	if len(paths) == 0 {
+		return []string{path}, nil
+	}
+
+	deleteLocalFiles(path)
+	if len(paths) == 1 {
+		if err := os.Rename(paths[0], path); err == nil {
+			paths[0] = path
+		}
+	}
+
+	return paths, nil
+}
+
+// rotateOutputFiles rotates each of the closed files according to the rotation flags and returns the resulting paths
+func rotateOutputFiles(paths []string, commonArgs utils.CommonFlagValues) []string {
+	policy := newRotationPolicy(commonArgs)
+	rotated := []string{}
+	for _, path := range paths {
+		rotatedPaths, err := rotateOutputFile(path, commonArgs.TimestampFormat, policy)
+		if err != nil {
+			cmdLogger.Errorf("could not rotate %s: %v", path, err)
+			rotated = append(rotated, path)
+			continue
+		}
+		rotated = append(rotated, rotatedPaths...)
+	}
+
+	return rotated
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// sqliteBatchRows is the number of rows appended to a SQLite database by each insert statement
+const sqliteBatchRows = 1000
+
+// sinkHTTPClient sends the requests of the sinks reached over HTTP, so that the requests of an export reuse their
+// connections
+var sinkHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// rowSink appends the exported rows to the tables of a database, or sends them to a service such as a search cluster,
+// a webhook or a stream, instead of writing them to files. It is selected by an --output starting with its scheme
+// followed by its location, e.g. the path of a local database file, the URL of a service without its protocol or the
+// name of a stream.
+type rowSink struct {
+	scheme string
+	// tables are the only tables the sink accepts, or nil when it accepts every table
+	tables []string
+	// stagingPrefix names the staged files of sinks whose location is not a local file. They are staged in the working
+	// directory.
+	stagingPrefix string
+	// cli is the command line tool of the database that the rows are appended with, which has to be on the PATH
+	cli string
+	// append appends the rows of the newline delimited file at path to the table at location, creating the table
+	// when it does not exist yet
+	append func(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error
+}
+
+// rowSinks are the sinks that exports can append their rows to
+var rowSinks = []*rowSink{
+	{
+		scheme: "duckdb://",
+		cli:    "duckdb",
+		append: func(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error {
+			statements, err := transform.DuckDBAppend(table, path, ddlOptions(commonArgs))
+			if err != nil {
+				return err
+			}
+			return runDatabaseCLI(exec.CommandContext(ctx, "duckdb", "-bail", location), strings.NewReader(statements))
+		},
+	},
+	{
+		scheme: "sqlite://",
+		cli:    "sqlite3",
+		append: func(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error {
+			statements, err := sqliteStatements(table, path, ddlOptions(commonArgs))
+			if err != nil {
+				return err
+			}
+			return runDatabaseCLI(exec.CommandContext(ctx, "sqlite3", "-bail", location), statements)
+		},
+	},
+	newOpenSearchSink("opensearch+https://", "https"),
+	newOpenSearchSink("opensearch+http://", "http"),
+	newWebhookSink("webhook+https://", "https"),
+	newWebhookSink("webhook+http://", "http"),
+	{scheme: "kinesis://", stagingPrefix: "kinesis", append: putToKinesis},
+	{scheme: "eventhubs://", stagingPrefix: "eventhubs", append: sendToEventHub},
+}
+
+// runDatabaseCLI runs the CLI of a database with the statements on its stdin
+func runDatabaseCLI(cli *exec.Cmd, statements io.Reader) error {
+	cli.Stdin = statements
+	if output, err := cli.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, output)
+	}
+	return nil
+}
+
+// acceptsTable reports whether rows of the table can be appended to the sink
+func (s *rowSink) acceptsTable(table string) bool {
+	if s.tables == nil {
+		return true
+	}
+	for _, accepted := range s.tables {
+		if accepted == table {
+			return true
+		}
+	}
+	return false
+}
+
+// outputRowSink returns the sink and location of an output that appends its rows to a sink, and false for
+// outputs written to files
+func outputRowSink(output string) (*rowSink, string, bool) {
+	for _, sink := range rowSinks {
+		if strings.HasPrefix(output, sink.scheme) {
+			return sink, strings.TrimPrefix(output, sink.scheme), true
+		}
+	}
+	return nil, "", false
+}
+
+// forEachRow calls fn with every row of the newline delimited file at path, and stops at the first error
+func forEachRow(path string, fn func(row []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// redactedLocation hides the password of a sink location or URL holding credentials, so that it is not logged
+func redactedLocation(location string) string {
+	if strings.Contains(location, "://") {
+		if parsed, err := url.Parse(location); err == nil && parsed.User != nil {
+			return parsed.Redacted()
+		}
+		return location
+	}
+	if parsed, err := url.Parse("//" + location); err == nil && parsed.User != nil {
+		return parsed.Redacted()[2:]
+	}
+	return location
+}
+
+// sqliteStatements streams the statements appending the rows of a newline delimited file to a SQLite table, so that
+// large files are not held in memory
+func sqliteStatements(table, path string, options transform.DDLOptions) (io.Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		defer file.Close()
+		writer.CloseWithError(transform.SQLiteAppend(writer, table, file, sqliteBatchRows, options))
+	}()
+	return reader, nil
+}
+
+// openRowSinkOutFile creates the staged newline delimited file holding the rows that are appended to the sink once
+// the file is complete. Every file gets a unique name, so that the files of several tables can be staged at once.
+func openRowSinkOutFile(sink *rowSink, location string) (*outputFile, error) {
+	stagingFolder, prefix := filepath.Join(filepath.Dir(location), stagingDir), filepath.Base(location)
+	if sink.stagingPrefix != "" {
+		stagingFolder, prefix = stagingDir, sink.stagingPrefix
+	}
+	err := os.MkdirAll(stagingFolder, os.ModePerm)
+	if err != nil {
+		return nil, utils.SinkError{Err: fmt.Errorf("could not create directory %s: %v", stagingFolder, err)}
+	}
+
+	file, err := os.CreateTemp(stagingFolder, prefix+".*.ndjson")
+	if err != nil {
+		return nil, utils.SinkError{Err: fmt.Errorf("could not create output file: %v", err)}
+	}
+
+	return &outputFile{File: file, path: file.Name(), sink: sink, location: location}, nil
+}
+
+// trackTable records the table of the entries written to an output, which picks its compression and, for sink
+// outputs, the table they are appended to
+func (o *outputFile) trackTable(entry interface{}) {
+	if o.table != "" {
+		return
+	}
+
+	table, ok := transform.OutputTable(entry)
+	if !ok {
+		if o.sink != nil {
+			cmdLogger.Errorf("%T rows have no table and cannot be appended to a sink", entry)
+		}
+		return
+	}
+	o.table = table
+}
+
+// appendToRowSink appends the rows of a closed sink output file to their table at the location of the sink and deletes the file.
+// The file is kept when the rows could not be appended.
+func appendToRowSink(ctx context.Context, outFile *outputFile, commonArgs utils.CommonFlagValues) error {
+	if outFile.table == "" {
+		deleteLocalFiles(outFile.path)
+		removeEmptyStagingDirs(outFile.path)
+		return nil
+	}
+
+	if !outFile.sink.acceptsTable(outFile.table) {
+		return fmt.Errorf("%s rows cannot be appended to a %s output, which accepts the %s tables, the rows were kept in %s", outFile.table, outFile.sink.scheme, strings.Join(outFile.sink.tables, " and "), outFile.path)
+	}
+
+	if err := outFile.sink.append(ctx, outFile.location, outFile.table, outFile.path, commonArgs); err != nil {
+		return fmt.Errorf("could not append %s to the %s table of %s, the rows were kept: %v", outFile.path, outFile.table, redactedLocation(outFile.location), err)
+	}
+
+	deleteLocalFiles(outFile.path)
+	removeEmptyStagingDirs(outFile.path)
+	return nil
+}
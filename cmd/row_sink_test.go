@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRowSinkOutputTestCommand(output string) *cobra.Command {
+	cmd := &cobra.Command{Use: "export_ledgers"}
+	cmd.Flags().String("timestamp-format", "", "")
+	cmd.Flags().String("output", output, "")
+	return cmd
+}
+
+func TestValidateRowSinkOutputCLI(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	problems := validateRowSinkOutput(newRowSinkOutputTestCommand("duckdb://stellar.db"))
+	assert.Equal(t, []string{"the rows of a duckdb:// output are appended with the duckdb CLI, which was not found on the PATH, install it first"}, problems)
+
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "duckdb"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", bin)
+	assert.Empty(t, validateRowSinkOutput(newRowSinkOutputTestCommand("duckdb://stellar.db")))
+
+	// file outputs do not need any CLI
+	assert.Empty(t, validateRowSinkOutput(newRowSinkOutputTestCommand("exported_ledgers.txt")))
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// runReportPath is the file the report of the run is written to when the command exits. No report is written when it is empty.
+var runReportPath string
+
+// runReport summarizes how a run ended, so that the wrappers retrying exports do not have to parse the logs
+type runReport struct {
+	Command       string                      `json:"command"`
+	StartedAt     time.Time                   `json:"started_at"`
+	FinishedAt    time.Time                   `json:"finished_at"`
+	ExitCode      int                         `json:"exit_code"`
+	ErrorCategory utils.ErrorCategory         `json:"error_category,omitempty"`
+	Error         string                      `json:"error,omitempty"`
+	LoggedErrors  map[utils.ErrorCategory]int `json:"logged_errors"`
+}
+
+// currentRun is the report of the running command, completed when it exits
+var currentRun *runReport
+
+// startRunReport starts the report of the command and writes it when a fatal error stops the command
+func startRunReport(cmd *cobra.Command) {
+	if runReportPath == "" {
+		return
+	}
+
+	currentRun = &runReport{Command: cmd.CommandPath(), StartedAt: time.Now().UTC()}
+	cmdLogger.OnExit = func(code int, category utils.ErrorCategory, message string) {
+		finishRunReport(code, category, message)
+	}
+}
+
+// finishRunReport writes the report of the run with the exit code of the command and the error that stopped it, if any
+func finishRunReport(code int, category utils.ErrorCategory, message string) {
+	if currentRun == nil {
+		return
+	}
+
+	currentRun.FinishedAt = time.Now().UTC()
+	currentRun.ExitCode = code
+	if code != 0 {
+		currentRun.ErrorCategory = category
+		currentRun.Error = message
+	}
+	currentRun.LoggedErrors = cmdLogger.LoggedErrors()
+
+	report, err := json.MarshalIndent(currentRun, "", "  ")
+	if err == nil {
+		err = os.WriteFile(runReportPath, append(report, '\n'), 0644)
+	}
+	if err != nil {
+		cmdLogger.Errorf("could not write the run report to %s: %v", runReportPath, err)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// releasedSchemasURL is where the schema snapshot of a release is published, formatted with the release tag
+const releasedSchemasURL = "https://github.com/stellar/stellar-etl/releases/download/%s/schemas.json"
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Inspects the schemas of the exported tables.",
+	Long:  `Inspects the schemas of the exported tables, so that changes to them can be checked before they reach downstream tables.`,
+}
+
+var schemaDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Writes the schema snapshot of this version.",
+	Long: `Writes the columns and column types of every exported table as JSON. The snapshot is published with every release,
+so that later versions can be compared against it with schema diff.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output path: ", err)
+		}
+
+		marshalled, err := json.MarshalIndent(transform.SchemaSnapshot(), "", "  ")
+		if err != nil {
+			cmdLogger.Fatal("could not json encode the schemas: ", err)
+		}
+
+		if path != "" {
+			outFile := mustOutFile(path)
+			outFile.Write(marshalled)
+			outFile.WriteString("\n")
+			outFile.Close()
+			mustCommitOutputFile(outFile)
+		} else {
+			fmt.Println(string(marshalled))
+		}
+	},
+}
+
+var schemaDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compares the schemas of this version against a released version.",
+	Long: `Compares the schemas of the exported tables against the snapshot of a released version, and fails when a change
+would break the tables created for it: a removed or renamed column or table, or a column whose type changed. Added
+tables and columns are listed without failing.
+
+--against takes a release tag, whose snapshot is downloaded from the release assets, or the path of a snapshot written
+by schema dump. Releases published before schema dump existed have no snapshot to download.`,
+	Example: `  stellar-etl schema diff --against v1.0.0
+  stellar-etl schema diff --against released_schemas.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		against, err := cmd.Flags().GetString("against")
+		if err != nil {
+			cmdLogger.Fatal("could not get against: ", err)
+		}
+
+		released, err := readSchemaSnapshot(cmd, against)
+		if err != nil {
+			cmdLogger.Fatal("could not read the released schemas: ", utils.InputError{Err: err})
+		}
+
+		breaking := 0
+		for _, change := range transform.DiffSchemas(released, transform.SchemaSnapshot()) {
+			if change.Breaking {
+				breaking++
+				fmt.Println("BREAKING", change)
+			} else {
+				fmt.Println("added   ", change)
+			}
+		}
+
+		if breaking > 0 {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("%d breaking schema changes against %s", breaking, against))
+		}
+	},
+}
+
+// readSchemaSnapshot reads the snapshot of a file if against is one, or downloads the snapshot of the release tagged against
+func readSchemaSnapshot(cmd *cobra.Command, against string) (map[string][]transform.SchemaColumn, error) {
+	var reader io.Reader
+	if file, err := os.Open(against); err == nil {
+		defer file.Close()
+		reader = file
+	} else {
+		url := fmt.Sprintf(releasedSchemasURL, against)
+		req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("could not download %s: %s", url, resp.Status)
+		}
+		reader = resp.Body
+	}
+
+	snapshot := map[string][]transform.SchemaColumn{}
+	if err := json.NewDecoder(reader).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid schema snapshot of %s: %v", against, err)
+	}
+	return snapshot, nil
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaDumpCmd)
+	schemaCmd.AddCommand(schemaDiffCmd)
+
+	schemaDumpCmd.Flags().StringP("output", "o", "", "Filename of the output file. Defaults to stdout")
+	schemaDiffCmd.Flags().String("against", "", "Release tag, e.g. v1.0.0, or path of the schema snapshot to compare against")
+	schemaDiffCmd.MarkFlagRequired("against")
+}
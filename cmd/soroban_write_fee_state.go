@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+)
+
+// sorobanWriteFeeState records the Soroban write fee settings active after the last ledger of an export, so that the
+// next export of the following ledgers knows the settings before it reads their upgrades
+type sorobanWriteFeeState struct {
+	Ledger uint32                          `json:"ledger"`
+	Config transform.SorobanWriteFeeConfig `json:"config"`
+}
+
+// loadSorobanWriteFeeState returns the write fee settings recorded in the state file at path that are active at the
+// start ledger. It returns nil when the file does not exist. A state that was not recorded right before the start
+// ledger is an error, since the upgrades of the ledgers in between would be missed.
+func loadSorobanWriteFeeState(path string, start uint32) (*transform.SorobanWriteFeeConfig, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state sorobanWriteFeeState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %v", path, err)
+	}
+	if state.Ledger+1 != start {
+		return nil, fmt.Errorf("the Soroban write fee settings of %s were recorded after ledger %d, but the export starts at ledger %d", path, state.Ledger, start)
+	}
+
+	return &state.Config, nil
+}
+
+// saveSorobanWriteFeeState writes the write fee settings active after ledger to the state file at path
+func saveSorobanWriteFeeState(path string, ledger uint32, config transform.SorobanWriteFeeConfig) error {
+	content, err := json.Marshal(sorobanWriteFeeState{Ledger: ledger, Config: config})
+	if err != nil {
+		return err
+	}
+
+	// the state is replaced with a rename, so that an interrupted export does not leave a truncated file
+	stagingPath := path + ".tmp"
+	if err := os.WriteFile(stagingPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(stagingPath, path)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSorobanWriteFeeState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "write_fee_state.json")
+	config := transform.SorobanWriteFeeConfig{
+		BucketListTargetSizeBytes:      1000,
+		WriteFee1KbBucketListLow:       10,
+		WriteFee1KbBucketListHigh:      100,
+		BucketListWriteFeeGrowthFactor: 2,
+	}
+
+	loaded, err := loadSorobanWriteFeeState(path, 11)
+	assert.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	assert.NoError(t, saveSorobanWriteFeeState(path, 10, config))
+	loaded, err = loadSorobanWriteFeeState(path, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, &config, loaded)
+
+	// the upgrades of the skipped ledgers would be missed
+	_, err = loadSorobanWriteFeeState(path, 12)
+	assert.Error(t, err)
+
+	// the settings recorded after later ledgers may have been upgraded since the start ledger
+	_, err = loadSorobanWriteFeeState(path, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// entryTypeChangeStats counts the changes of one ledger entry type by kind
+type entryTypeChangeStats struct {
+	EntryType string `json:"entry_type"`
+	Changes   int64  `json:"changes"`
+	Created   int64  `json:"created"`
+	Updated   int64  `json:"updated"`
+	Deleted   int64  `json:"deleted"`
+}
+
+// contractChangeStats counts the contract data changes of one contract
+type contractChangeStats struct {
+	ContractID string `json:"contract_id"`
+	Changes    int64  `json:"changes"`
+}
+
+// changeStats summarizes the ledger entry changes of a range
+type changeStats struct {
+	StartLedger  uint32                 `json:"start_ledger"`
+	EndLedger    uint32                 `json:"end_ledger"`
+	Changes      int64                  `json:"changes"`
+	Evictions    int64                  `json:"evictions"`
+	EntryTypes   []entryTypeChangeStats `json:"entry_types"`
+	TopContracts []contractChangeStats  `json:"top_contracts"`
+}
+
+var statsChangesCmd = &cobra.Command{
+	Use:   "stats_changes",
+	Short: "Summarizes the ledger entry changes of a range without exporting them.",
+	Long: `Reads the ledger entry changes of a range and prints a summary instead of writing the changes: the number of changes of
+every entry type, split into created, updated and deleted entries, the number of evicted entries, and the contracts whose
+data changed the most. It is meant for sizing exports and triaging anomalies, such as a contract suddenly writing far more
+entries than usual.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		startNum, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start sequence number: ", err)
+		}
+
+		batchSize, err := cmd.Flags().GetUint32("batch-size")
+		if err != nil {
+			cmdLogger.Fatal("could not get batch size: ", err)
+		}
+
+		top, err := cmd.Flags().GetInt("top")
+		if err != nil {
+			cmdLogger.Fatal("could not get top: ", err)
+		}
+
+		if commonArgs.EndNum < startNum {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("end-ledger (%d) must not be before start-ledger (%d)", commonArgs.EndNum, startNum))
+		}
+		if batchSize == 0 {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "batch-size must be greater than 0")
+		}
+		if top < 0 {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, "top must not be negative")
+		}
+
+		ctx := cmd.Context()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("error creating a cloud storage backend: ", utils.InputError{Err: err})
+		}
+
+		err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(startNum, commonArgs.EndNum))
+		if err != nil {
+			cmdLogger.Fatal("error preparing ledger range for cloud storage backend: ", utils.InputError{Err: err})
+		}
+
+		changeChan := make(chan input.ChangeBatch)
+		closeChan := make(chan int)
+		go input.StreamChanges(ctx, &backend, startNum, commonArgs.EndNum, batchSize, changeChan, closeChan, env, cmdLogger)
+
+		stats := changeStats{StartLedger: startNum, EndLedger: commonArgs.EndNum}
+		byEntryType := map[xdr.LedgerEntryType]*entryTypeChangeStats{}
+		byContract := map[string]int64{}
+		for batch := range changeChan {
+			for entryType, changes := range batch.Changes {
+				for _, change := range changes.Changes {
+					counts, ok := byEntryType[entryType]
+					if !ok {
+						counts = &entryTypeChangeStats{EntryType: ledgerEntryTypeName(entryType)}
+						byEntryType[entryType] = counts
+					}
+					countChange(counts, change)
+					stats.Changes++
+
+					if contractID, ok := changedContractID(change); ok {
+						byContract[contractID]++
+					}
+				}
+			}
+			for _, evictions := range batch.Evictions {
+				stats.Evictions += int64(len(evictions.Keys))
+			}
+		}
+		<-closeChan
+
+		stats.EntryTypes = []entryTypeChangeStats{}
+		for _, counts := range byEntryType {
+			stats.EntryTypes = append(stats.EntryTypes, *counts)
+		}
+		sort.Slice(stats.EntryTypes, func(i, j int) bool { return stats.EntryTypes[i].EntryType < stats.EntryTypes[j].EntryType })
+		stats.TopContracts = topContracts(byContract, top)
+
+		output, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			cmdLogger.Fatal("could not marshal change stats: ", err)
+		}
+		fmt.Println(string(output))
+	},
+}
+
+// ledgerEntryTypeName returns the snake_case name of a ledger entry type, such as claimable_balance
+func ledgerEntryTypeName(entryType xdr.LedgerEntryType) string {
+	return utils.ToLowerSnakeCase(strings.TrimPrefix(entryType.String(), "LedgerEntryType"))
+}
+
+// countChange adds a change to the counts of its entry type
+func countChange(counts *entryTypeChangeStats, change ingest.Change) {
+	counts.Changes++
+	switch change.LedgerEntryChangeType() {
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		counts.Created++
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		counts.Updated++
+	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+		counts.Deleted++
+	}
+}
+
+// changedContractID returns the strkey of the contract owning the contract data entry of a change
+func changedContractID(change ingest.Change) (string, bool) {
+	if change.Type != xdr.LedgerEntryTypeContractData {
+		return "", false
+	}
+
+	entry, _, _, err := utils.ExtractEntryFromChange(change)
+	if err != nil {
+		return "", false
+	}
+
+	contractData, ok := entry.Data.GetContractData()
+	if !ok || contractData.Contract.ContractId == nil {
+		return "", false
+	}
+
+	contractID, err := strkey.Encode(strkey.VersionByteContract, contractData.Contract.ContractId[:])
+	if err != nil {
+		return "", false
+	}
+	return contractID, true
+}
+
+// topContracts returns the n contracts with the most changes, breaking ties by contract id so that the output is stable
+func topContracts(byContract map[string]int64, n int) []contractChangeStats {
+	contracts := make([]contractChangeStats, 0, len(byContract))
+	for contractID, changes := range byContract {
+		contracts = append(contracts, contractChangeStats{ContractID: contractID, Changes: changes})
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].Changes != contracts[j].Changes {
+			return contracts[i].Changes > contracts[j].Changes
+		}
+		return contracts[i].ContractID < contracts[j].ContractID
+	})
+	return contracts[:min(n, len(contracts))]
+}
+
+func init() {
+	rootCmd.AddCommand(statsChangesCmd)
+	utils.AddCommonFlags(statsChangesCmd.Flags())
+	statsChangesCmd.Flags().Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the summarized range. Defaults to genesis ledger")
+	statsChangesCmd.Flags().Uint32P("batch-size", "b", 64, "number of ledgers read at a time")
+	statsChangesCmd.Flags().Int("top", 10, "Number of contracts with the most contract data changes to list")
+	statsChangesCmd.MarkFlagRequired("end-ledger")
+}
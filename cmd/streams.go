@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// kinesisMaxRecords and kinesisMaxBytes are the limits of a PutRecords request
+const (
+	kinesisMaxRecords = 500
+	kinesisMaxBytes   = 5 << 20
+)
+
+// eventHubMaxBytes is the size of the batches sent to Event Hubs, below the 1MB limit of the standard tier
+const eventHubMaxBytes = 900 << 10
+
+// eventHubConnectionStringEnv is the environment variable holding the connection string of an Event Hubs namespace
+const eventHubConnectionStringEnv = "EVENTHUBS_CONNECTION_STRING"
+
+// partitionKeyColumns are the columns holding the value of each --stream-partition-key, in order of preference. Rows
+// of tables without any of the columns are partitioned by their ledger.
+var partitionKeyColumns = map[string][]string{
+	utils.StreamPartitionKeyLedger:   {"ledger_sequence", "sequence", "last_modified_ledger"},
+	utils.StreamPartitionKeyAccount:  {"account", "account_id", "source_account", "address", "seller_id"},
+	utils.StreamPartitionKeyContract: {"contract_id"},
+}
+
+// streamPartitionKey returns the partition key of a row, so that the rows of the same ledger, account or contract go
+// to the same shard or partition and keep their order. Rows without any key column are spread by their hash.
+func streamPartitionKey(row []byte, partitionBy string) string {
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(row))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err == nil {
+		for _, by := range []string{partitionBy, utils.StreamPartitionKeyLedger} {
+			for _, column := range partitionKeyColumns[by] {
+				if value := fmt.Sprint(values[column]); values[column] != nil && value != "" {
+					return value
+				}
+			}
+		}
+	}
+
+	hash := sha256.Sum256(row)
+	return hex.EncodeToString(hash[:])
+}
+
+// putToKinesis puts the rows of the newline delimited file at path into the Kinesis stream named by location. The
+// stream name can be followed by ?region=<region> and ?role=<role ARN>, to assume an IAM role with the ambient
+// credentials.
+func putToKinesis(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error {
+	parsed, err := url.Parse("//" + location)
+	if err != nil {
+		return err
+	}
+	stream := parsed.Host + parsed.Path
+
+	credentials := ""
+	if role := parsed.Query().Get("role"); role != "" {
+		credentials = credentialsAWSRolePrefix + role
+	}
+	sess, err := awsSession(credentials)
+	if err != nil {
+		return err
+	}
+	config := aws.NewConfig()
+	if region := parsed.Query().Get("region"); region != "" {
+		config = config.WithRegion(region)
+	}
+	client := kinesis.New(sess, config)
+
+	records := []*kinesis.PutRecordsRequestEntry{}
+	size := 0
+	flush := func() error {
+		if len(records) == 0 {
+			return nil
+		}
+		err := putKinesisRecords(ctx, client, stream, records)
+		records, size = nil, 0
+		return err
+	}
+
+	err = forEachRow(path, func(row []byte) error {
+		key := streamPartitionKey(row, commonArgs.StreamPartition)
+		if len(records) == kinesisMaxRecords || size+len(row)+len(key) > kinesisMaxBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		records = append(records, &kinesis.PutRecordsRequestEntry{Data: row, PartitionKey: aws.String(key)})
+		size += len(row) + len(key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// putKinesisRecords puts a batch of records, putting the records that failed again with an exponential backoff, e.g.
+// when their shard was throttled. Retries stop when ctx is done.
+func putKinesisRecords(ctx context.Context, client *kinesis.Kinesis, stream string, records []*kinesis.PutRecordsRequestEntry) error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		output, err := client.PutRecordsWithContext(ctx, &kinesis.PutRecordsInput{StreamName: aws.String(stream), Records: records})
+		if err == nil && aws.Int64Value(output.FailedRecordCount) == 0 {
+			return nil
+		}
+
+		if err == nil {
+			failed := []*kinesis.PutRecordsRequestEntry{}
+			for i, result := range output.Records {
+				if result.ErrorCode != nil {
+					failed = append(failed, records[i])
+					err = fmt.Errorf("%s: %s", aws.StringValue(result.ErrorCode), aws.StringValue(result.ErrorMessage))
+				}
+			}
+			records = failed
+		}
+		if attempt == postAttempts {
+			return fmt.Errorf("could not put %d records into %s after %d attempts: %v", len(records), stream, attempt, err)
+		}
+		cmdLogger.Warnf("putting %d records into %s failed, retrying in %s: %v", len(records), stream, backoff, err)
+		if err := waitForRetry(ctx, backoff); err != nil {
+			return fmt.Errorf("putting %d records into %s stopped after %d attempts: %v", len(records), stream, attempt, err)
+		}
+		backoff = min(2*backoff, postMaxBackoff)
+	}
+}
+
+// eventHubMessage is a message of the batches sent to the REST API of Event Hubs
+type eventHubMessage struct {
+	Body             string            `json:"Body"`
+	BrokerProperties map[string]string `json:"BrokerProperties"`
+}
+
+// sendToEventHub sends the rows of the newline delimited file at path to the event hub at location, formatted as
+// <namespace>.servicebus.windows.net/<event hub>. It authenticates with the shared access key of the connection string
+// in the EVENTHUBS_CONNECTION_STRING environment variable.
+func sendToEventHub(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error {
+	keyName, key, err := eventHubSharedAccessKey(os.Getenv(eventHubConnectionStringEnv))
+	if err != nil {
+		return err
+	}
+	resource := "https://" + strings.TrimSuffix(location, "/")
+	endpoint := resource + "/messages?api-version=2014-01"
+
+	messages := []eventHubMessage{}
+	size := 0
+	flush := func() error {
+		if len(messages) == 0 {
+			return nil
+		}
+		payload, err := json.Marshal(messages)
+		if err != nil {
+			return err
+		}
+		messages, size = nil, 0
+
+		headers := http.Header{}
+		headers.Set("Content-Type", "application/vnd.microsoft.servicebus.json")
+		headers.Set("Authorization", eventHubSASToken(resource, keyName, key, time.Now().Add(time.Hour)))
+		return postWithRetries(ctx, endpoint, headers, payload)
+	}
+
+	err = forEachRow(path, func(row []byte) error {
+		if size+len(row) > eventHubMaxBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		messages = append(messages, eventHubMessage{
+			Body:             string(row),
+			BrokerProperties: map[string]string{"PartitionKey": streamPartitionKey(row, commonArgs.StreamPartition)},
+		})
+		size += len(row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// eventHubSharedAccessKey returns the shared access key name and key of an Event Hubs connection string
+func eventHubSharedAccessKey(connectionString string) (string, string, error) {
+	values := map[string]string{}
+	for _, part := range strings.Split(connectionString, ";") {
+		if name, value, ok := strings.Cut(part, "="); ok {
+			values[name] = value
+		}
+	}
+	if values["SharedAccessKeyName"] == "" || values["SharedAccessKey"] == "" {
+		return "", "", fmt.Errorf("%s must hold the connection string of the Event Hubs namespace, with its SharedAccessKeyName and SharedAccessKey", eventHubConnectionStringEnv)
+	}
+	return values["SharedAccessKeyName"], values["SharedAccessKey"], nil
+}
+
+// eventHubSASToken returns the shared access signature authorizing requests to the resource until expiry
+func eventHubSASToken(resource, keyName, key string, expiry time.Time) string {
+	encodedResource := url.QueryEscape(resource)
+	expires := fmt.Sprint(expiry.Unix())
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(encodedResource + "\n" + expires))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s", encodedResource, url.QueryEscape(signature), expires, keyName)
+}
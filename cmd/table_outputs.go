@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// Accepted values of the compression of a table in the tables section of the config file
+const (
+	compressionNone = "none"
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+// Accepted values of the format of a table in the tables section of the config file. Parquet files are converted from
+// the newline delimited JSON files of the export with the duckdb CLI.
+const (
+	formatNDJSON  = "ndjson"
+	formatParquet = "parquet"
+)
+
+// parquetExtension replaces the extension of the files converted to parquet
+const parquetExtension = ".parquet"
+
+// compressionExtensions are the extensions appended to the files compressed with each codec
+var compressionExtensions = map[string]string{
+	compressionGzip: ".gz",
+	compressionZstd: ".zst",
+}
+
+// tableOutput holds the output settings of a table in the tables section of the config file
+type tableOutput struct {
+	Compression string `mapstructure:"compression"`
+	Format      string `mapstructure:"format"`
+}
+
+// tableOutputs are the output settings of the tables listed in the config file, keyed by table name
+var tableOutputs map[string]tableOutput
+
+// setTableOutputs validates the output settings read from the tables section of the config file and makes them the
+// settings of the exports
+func setTableOutputs(outputs map[string]tableOutput) error {
+	for table, output := range outputs {
+		if _, ok := transform.OutputSchemas[table]; !ok {
+			return fmt.Errorf("unknown table %s in the tables section of the config file; must be one of %s", table, strings.Join(transform.TableNames(), ", "))
+		}
+		switch output.Compression {
+		case "", compressionNone, compressionGzip, compressionZstd:
+		default:
+			return fmt.Errorf("invalid compression %q of table %s: must be one of %s, %s or %s", output.Compression, table, compressionNone, compressionGzip, compressionZstd)
+		}
+		switch output.Format {
+		case "", formatNDJSON:
+		case formatParquet:
+			if _, err := exec.LookPath("duckdb"); err != nil {
+				return fmt.Errorf("table %s is converted to parquet with the duckdb CLI, which was not found on the PATH, install it first", table)
+			}
+		default:
+			return fmt.Errorf("invalid format %q of table %s: must be one of %s or %s", output.Format, table, formatNDJSON, formatParquet)
+		}
+	}
+	tableOutputs = outputs
+	return nil
+}
+
+// tableCompression returns the compression set for a table in the config file, if any
+func tableCompression(table string) (string, bool) {
+	output, ok := tableOutputs[table]
+	if !ok || output.Compression == "" {
+		return "", false
+	}
+	return output.Compression, true
+}
+
+// tableFormat returns the format set for a table in the config file, which defaults to NDJSON
+func tableFormat(table string) string {
+	if output, ok := tableOutputs[table]; ok && output.Format != "" {
+		return output.Format
+	}
+	return formatNDJSON
+}
+
+// ddlOptions returns the flags of an export that change the columns of the files it writes
+func ddlOptions(commonArgs utils.CommonFlagValues) transform.DDLOptions {
+	return transform.DDLOptions{
+		TimestampFormat:  commonArgs.TimestampFormat,
+		AssetContractIDs: commonArgs.AssetContractIDs,
+		IncludeXDR:       commonArgs.IncludeXDR,
+		WellKnownAssets:  commonArgs.WellKnownAssets != nil,
+		AddressLabels:    commonArgs.AddressLabels != nil,
+		BatchMetadata:    commonArgs.BatchMetadata,
+	}
+}
+
+// convertFilesToParquet converts every newline delimited file of the table to a parquet file whose pages are
+// compressed with the codec, and deletes the converted files. The parquet files are written next to them with the
+// parquet extension. Files that could not be converted are kept as they are.
+func convertFilesToParquet(ctx context.Context, paths []string, table, compression string, commonArgs utils.CommonFlagValues) []string {
+	options := ddlOptions(commonArgs)
+	parquetPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		parquetPath := strings.TrimSuffix(path, filepath.Ext(path)) + parquetExtension
+		statement, err := transform.DuckDBParquet(table, path, parquetPath, compression, options)
+		if err == nil {
+			err = runDatabaseCLI(exec.CommandContext(ctx, "duckdb", "-bail"), strings.NewReader(statement))
+		}
+		if err != nil {
+			cmdLogger.Errorf("could not convert %s to parquet: %v", path, err)
+			parquetPaths = append(parquetPaths, path)
+			continue
+		}
+
+		deleteLocalFiles(path)
+		parquetPaths = append(parquetPaths, parquetPath)
+	}
+	return parquetPaths
+}
+
+// compressFiles compresses every file with the codec and deletes the uncompressed files. The compressed files are written
+// next to them with the extension of the codec. Files that could not be compressed are kept uncompressed.
+func compressFiles(paths []string, compression string) []string {
+	extension, ok := compressionExtensions[compression]
+	if !ok {
+		return paths
+	}
+
+	compressedPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if err := compressFile(path, path+extension, compression); err != nil {
+			cmdLogger.Errorf("could not compress %s: %v", path, err)
+			compressedPaths = append(compressedPaths, path)
+			continue
+		}
+
+		deleteLocalFiles(path)
+		compressedPaths = append(compressedPaths, path+extension)
+	}
+	return compressedPaths
+}
+
+// compressFile writes the content of path compressed with the codec to compressedPath
+func compressFile(path, compressedPath, compression string) error {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+
+	outFile, err := os.Create(compressedPath)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	var writer io.WriteCloser
+	switch compression {
+	case compressionGzip:
+		writer = gzip.NewWriter(outFile)
+	case compressionZstd:
+		writer, err = zstd.NewWriter(outFile)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown compression %s", compression)
+	}
+
+	if _, err := io.Copy(writer, inFile); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return outFile.Close()
+}
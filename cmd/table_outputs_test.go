@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDuckDB puts a duckdb CLI on the PATH that saves the statements it reads to statementsPath and creates the
+// parquet file at parquetPath, or fails when fail is set
+func fakeDuckDB(t *testing.T, statementsPath, parquetPath string, fail bool) {
+	// the PATH only holds the fake CLI, so the script sticks to shell builtins
+	script := "#!/bin/sh\nwhile IFS= read -r line; do printf '%s\\n' \"$line\"; done > '" + statementsPath + "'\n: > '" + parquetPath + "'\n"
+	if fail {
+		script = "#!/bin/sh\necho 'Binder Error' >&2\nexit 1\n"
+	}
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "duckdb"), []byte(script), 0755))
+	t.Setenv("PATH", bin)
+}
+
+func TestSetTableOutputs(t *testing.T) {
+	defer setTableOutputs(nil)
+
+	t.Setenv("PATH", t.TempDir())
+	assert.NoError(t, setTableOutputs(map[string]tableOutput{"operations": {Format: formatNDJSON, Compression: compressionZstd}}))
+	assert.Equal(t, formatNDJSON, tableFormat("operations"))
+	assert.Equal(t, formatNDJSON, tableFormat("trades"))
+
+	assert.EqualError(t, setTableOutputs(map[string]tableOutput{"operations": {Format: "avro"}}),
+		`invalid format "avro" of table operations: must be one of ndjson or parquet`)
+	assert.EqualError(t, setTableOutputs(map[string]tableOutput{"operations": {Format: formatParquet}}),
+		"table operations is converted to parquet with the duckdb CLI, which was not found on the PATH, install it first")
+
+	folder := t.TempDir()
+	fakeDuckDB(t, filepath.Join(folder, "statements.sql"), filepath.Join(folder, "out.parquet"), false)
+	assert.NoError(t, setTableOutputs(map[string]tableOutput{"operations": {Format: formatParquet}}))
+	assert.Equal(t, formatParquet, tableFormat("operations"))
+}
+
+func TestConvertFilesToParquet(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "1-64-operations.txt")
+	parquetPath := filepath.Join(folder, "1-64-operations.parquet")
+	statementsPath := filepath.Join(folder, "statements.sql")
+	assert.NoError(t, os.WriteFile(path, []byte("{\"id\": 1}\n"), 0644))
+
+	fakeDuckDB(t, statementsPath, parquetPath, false)
+	paths := convertFilesToParquet(context.Background(), []string{path}, "operations", compressionZstd, utils.CommonFlagValues{})
+	assert.Equal(t, []string{parquetPath}, paths)
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	statements, err := os.ReadFile(statementsPath)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(statements), "COPY (SELECT * FROM read_json('"+path+"'"))
+	assert.True(t, strings.HasSuffix(string(statements), "TO '"+parquetPath+"' (FORMAT PARQUET, COMPRESSION ZSTD);\n"))
+
+	// files that could not be converted are kept as NDJSON
+	assert.NoError(t, os.WriteFile(path, []byte("{\"id\": 1}\n"), 0644))
+	fakeDuckDB(t, statementsPath, parquetPath, true)
+	paths = convertFilesToParquet(context.Background(), []string{path}, "operations", compressionZstd, utils.CommonFlagValues{})
+	assert.Equal(t, []string{path}, paths)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// isTimestampType reports whether a field of this type is encoded as a json timestamp
+func isTimestampType(t reflect.Type) bool {
+	return t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(null.Time{})
+}
+
+// applyTimestampFormat rewrites the timestamp columns of a decoded entry in the chosen format. RFC3339 timestamps
+// are normalized to UTC, so the output does not depend on the time zone of the transformed values.
+func applyTimestampFormat(i map[string]interface{}, entry interface{}, timestampFormat string) {
+	fieldTypes := jsonFieldTypes(reflect.TypeOf(entry))
+	for k, v := range i {
+		fieldType, ok := fieldTypes[k]
+		if !ok || !isTimestampType(fieldType) {
+			continue
+		}
+
+		timestamp, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			cmdLogger.Errorf("could not parse timestamp %s of %s: %v", timestamp, k, err)
+			continue
+		}
+
+		switch timestampFormat {
+		case utils.TimestampFormatEpochSeconds:
+			i[k] = parsed.Unix()
+		case utils.TimestampFormatEpochMicros:
+			i[k] = parsed.UnixMicro()
+		default:
+			i[k] = parsed.UTC().Format(time.RFC3339Nano)
+		}
+	}
+}
+
+// parseTimestamp parses a decoded timestamp column written in the chosen format
+func parseTimestamp(v interface{}, timestampFormat string) (time.Time, bool) {
+	switch timestamp := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, snowflakeTimestampFormat} {
+			if parsed, err := time.Parse(layout, timestamp); err == nil {
+				return parsed, true
+			}
+		}
+	case json.Number:
+		epoch, err := timestamp.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		if timestampFormat == utils.TimestampFormatEpochMicros {
+			return time.UnixMicro(epoch), true
+		}
+		return time.Unix(epoch, 0), true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/stellar-etl/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type timestampTestOutput struct {
+	ClosedAt  time.Time `json:"closed_at"`
+	MinTime   null.Time `json:"min_time"`
+	MaxTime   null.Time `json:"max_time"`
+	CreatedAt string    `json:"created_at"`
+}
+
+func TestApplyTimestampFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   map[string]interface{}
+	}{
+		{utils.TimestampFormatRFC3339, map[string]interface{}{"closed_at": "2024-01-02T03:04:05.5Z", "min_time": "2024-01-02T02:04:05Z"}},
+		{utils.TimestampFormatEpochSeconds, map[string]interface{}{"closed_at": int64(1704164645), "min_time": int64(1704161045)}},
+		{utils.TimestampFormatEpochMicros, map[string]interface{}{"closed_at": int64(1704164645500000), "min_time": int64(1704161045000000)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			i := map[string]interface{}{
+				"closed_at": "2024-01-02T03:04:05.5Z",
+				// timestamps are normalized to UTC
+				"min_time": "2024-01-02T03:04:05+01:00",
+				// null timestamps and columns that are not timestamps are left as they are
+				"max_time":   nil,
+				"created_at": "2024-01-02T03:04:05Z",
+				"ledger":     json.Number("5"),
+			}
+			applyTimestampFormat(i, timestampTestOutput{}, test.format)
+
+			test.want["max_time"] = nil
+			test.want["created_at"] = "2024-01-02T03:04:05Z"
+			test.want["ledger"] = json.Number("5")
+			assert.Equal(t, test.want, i)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// txIndexExtension is appended to the output path of a transaction export to name its index
+const txIndexExtension = ".index.csv"
+
+// txIndexHeader names the columns of a transaction index
+var txIndexHeader = []string{"transaction_hash", "ledger_sequence", "file", "offset", "length"}
+
+// txIndexEntry locates the row of a transaction in the exported files. Offset and length are in bytes of the
+// uncompressed file, so that rows of compressed files are found by decompressing them up to the offset.
+type txIndexEntry struct {
+	hash   string
+	ledger uint32
+	file   string
+	offset int64
+	length int64
+}
+
+// txIndexRow holds the columns of an exported transaction that are indexed
+type txIndexRow struct {
+	TransactionHash string `json:"transaction_hash"`
+	LedgerSequence  uint32 `json:"ledger_sequence"`
+}
+
+// writeTxIndex indexes the transactions of the committed export files and writes the index to indexPath, as a CSV file
+// sorted by transaction hash so that it can be binary searched. Files are recorded relative to the folder of the index.
+// It returns the committed path of the index.
+func writeTxIndex(paths []string, indexPath string) (string, error) {
+	entries := []txIndexEntry{}
+	for _, path := range paths {
+		file, err := filepath.Rel(filepath.Dir(indexPath), path)
+		if err != nil {
+			file = path
+		}
+
+		fileEntries, err := indexTransactions(path, file)
+		if err != nil {
+			return "", fmt.Errorf("could not index %s: %v", path, err)
+		}
+		entries = append(entries, fileEntries...)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].hash < entries[j].hash })
+
+	outFile := mustOutFile(indexPath)
+	writer := csv.NewWriter(outFile)
+	writer.Write(txIndexHeader)
+	for _, entry := range entries {
+		writer.Write([]string{
+			entry.hash,
+			strconv.FormatUint(uint64(entry.ledger), 10),
+			entry.file,
+			strconv.FormatInt(entry.offset, 10),
+			strconv.FormatInt(entry.length, 10),
+		})
+	}
+	writer.Flush()
+	outFile.Close()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("could not write %s: %v", indexPath, err)
+	}
+
+	return mustCommitOutputFile(outFile), nil
+}
+
+// indexTransactions returns the index entries of the rows of the newline delimited JSON file at path, recorded under
+// the name file. Files compressed by the export are decompressed while they are read.
+func indexTransactions(path, file string) ([]txIndexEntry, error) {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer inFile.Close()
+
+	var reader io.Reader = inFile
+	switch {
+	case strings.HasSuffix(path, compressionExtensions[compressionGzip]):
+		gzipReader, err := gzip.NewReader(inFile)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case strings.HasSuffix(path, compressionExtensions[compressionZstd]):
+		zstdReader, err := zstd.NewReader(inFile)
+		if err != nil {
+			return nil, err
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+
+	entries := []txIndexEntry{}
+	lines := bufio.NewReader(reader)
+	var offset int64
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(line) > 0 {
+			var row txIndexRow
+			if jsonErr := json.Unmarshal(line, &row); jsonErr != nil {
+				return nil, fmt.Errorf("could not decode the row at offset %d: %v", offset, jsonErr)
+			}
+			if row.TransactionHash == "" {
+				return nil, fmt.Errorf("the row at offset %d has no transaction_hash", offset)
+			}
+			entries = append(entries, txIndexEntry{
+				hash:   row.TransactionHash,
+				ledger: row.LedgerSequence,
+				file:   file,
+				offset: offset,
+				length: int64(len(line)),
+			})
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
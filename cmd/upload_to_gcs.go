@@ -8,8 +8,12 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/stellar/stellar-etl/internal/utils"
 )
 
+// gcsUploadAttempts is the number of times an upload is tried before giving up, e.g. when the checksums do not match
+const gcsUploadAttempts = 3
+
 type GCS struct {
 	gcsCredentialsPath string
 	gcsBucket          string
@@ -22,45 +26,86 @@ func newGCS(gcsCredentialsPath, gcsBucket string) CloudStorage {
 	}
 }
 
-func (g *GCS) UploadTo(credentialsPath, bucket, path string) error {
-	// Use credentials file in dev/local runs. Otherwise, derive credentials from the service account.
-	if len(credentialsPath) > 0 {
-		os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentialsPath)
-		cmdLogger.Infof("Using credentials found at: %s", credentialsPath)
+func (g *GCS) UploadTo(ctx context.Context, credentialsPath, bucket, path string) error {
+	// Credentials are resolved on every upload so rotated secrets are used. Without explicit credentials, the
+	// client derives them from the environment, e.g. the service account or workload identity.
+	clientOptions, err := gcpClientOptions(credentialsPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve credentials: %v", err)
 	}
 
-	reader, err := os.Open(path)
+	checksum, size, err := fileCRC32C(path)
 	if err != nil {
-		return fmt.Errorf("failed to open file %s: %v", path, err)
+		return fmt.Errorf("failed to compute the checksum of %s: %v", path, err)
 	}
 
-	ctx := context.Background()
-	client, err := storage.NewClient(ctx)
+	client, err := storage.NewClient(ctx, clientOptions...)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %v", err)
 	}
 	defer client.Close()
 
+	uploadLocation := fmt.Sprintf("gs://%s/%s", bucket, path)
+	for attempt := 1; ; attempt++ {
+		cmdLogger.Infof("Uploading %s to %s", path, uploadLocation)
+		err = uploadVerified(ctx, client.Bucket(bucket).Object(path), path, checksum)
+		if err == nil {
+			break
+		}
+		if attempt == gcsUploadAttempts {
+			return fmt.Errorf("upload of %s failed after %d attempts: %v", path, attempt, err)
+		}
+		cmdLogger.Warnf("upload of %s failed, retrying: %v", path, err)
+		if err := waitForRetry(ctx, time.Duration(attempt)*time.Second); err != nil {
+			return fmt.Errorf("upload of %s stopped after %d attempts: %v", path, attempt, err)
+		}
+	}
+
+	cmdLogger.Infof("Successfully uploaded %d bytes to %s", size, uploadLocation)
+
+	err = recordUpload(manifestEntry{
+		LocalPath:  path,
+		Object:     uploadLocation,
+		Bytes:      size,
+		CRC32C:     encodeCRC32C(checksum),
+		UploadedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		cmdLogger.Errorf("could not record %s in the manifest: %v", uploadLocation, err)
+	}
+
+	deleteLocalFiles(path)
+
+	return nil
+}
+
+// uploadVerified uploads the file and checks that the CRC32C of the stored object matches the local checksum.
+// GCS also rejects the upload itself when the checksum of the received bytes differs.
+func uploadVerified(ctx context.Context, object *storage.ObjectHandle, path string, checksum uint32) error {
+	reader, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer reader.Close()
+
 	ctx, cancel := context.WithTimeout(ctx, time.Hour)
 	defer cancel()
 
-	wc := client.Bucket(bucket).Object(path).NewWriter(ctx)
+	wc := object.NewWriter(ctx)
+	wc.CRC32C = checksum
+	wc.SendCRC32C = true
 
-	uploadLocation := fmt.Sprintf("gs://%s/%s", bucket, path)
-	cmdLogger.Infof("Uploading %s to %s", path, uploadLocation)
-
-	var written int64
-	if written, err = io.Copy(wc, reader); err != nil {
+	if _, err = io.Copy(wc, utils.ThrottleUpload(ctx, reader)); err != nil {
+		wc.Close()
 		return fmt.Errorf("unable to copy: %v", err)
 	}
-	err = wc.Close()
-	if err != nil {
+	if err = wc.Close(); err != nil {
 		return err
 	}
 
-	cmdLogger.Infof("Successfully uploaded %d bytes to gs://%s/%s", written, bucket, path)
-
-	deleteLocalFiles(path)
+	if attrs := wc.Attrs(); attrs == nil || attrs.CRC32C != checksum {
+		return fmt.Errorf("checksum mismatch: local CRC32C is %s", encodeCRC32C(checksum))
+	}
 
 	return nil
 }
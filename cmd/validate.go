@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// flagRule checks a combination of flags of a command and returns what is wrong with them, each problem along with how
+// to fix it. The flags are valid when no problems are returned.
+type flagRule func(cmd *cobra.Command) []string
+
+// flagRules are checked before every command runs, so that invalid flags fail with every problem listed at once instead
+// of with an opaque error deep inside an exporter
+var flagRules = []flagRule{
+	validateFlagValues,
+	validateNetworks,
+	validateLedgerBackends,
+	validateLedgerRange,
+	validateSinks,
+	validateOutputFormat,
+	validateRotation,
+	validateBandwidth,
+	validateRowSinkOutput,
+	validateWebhook,
+	validateStreamPartitionKey,
+}
+
+// mustValidateFlags aborts with the problems found by flagRules and the examples of the command
+func mustValidateFlags(cmd *cobra.Command) {
+	problems := []string{}
+	for _, rule := range flagRules {
+		problems = append(problems, rule(cmd)...)
+	}
+	if len(problems) == 0 {
+		return
+	}
+
+	cmdLogger.Abort(utils.ErrorCategoryValidation, invalidFlagsMessage(cmd, problems))
+}
+
+// invalidFlagsMessage lists the problems with the flags of a command, followed by its examples
+func invalidFlagsMessage(cmd *cobra.Command, problems []string) string {
+	message := fmt.Sprintf("invalid arguments for %s:\n  - %s", cmd.Name(), strings.Join(problems, "\n  - "))
+	if cmd.Example != "" {
+		message += "\n\nExamples:\n" + cmd.Example
+	}
+	return message + fmt.Sprintf("\n\nRun '%s -h' for the description of every flag.", cmd.CommandPath())
+}
+
+// validateFlagValues checks that the flags taking one of a fixed set of values have one of them
+func validateFlagValues(cmd *cobra.Command) []string {
+	names := make([]string, 0, len(flagValues))
+	for name := range flagValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []string{}
+	for _, name := range names {
+		// network values can be followed by a ledger range, they are checked by validateNetworks
+		if name == "network" {
+			continue
+		}
+
+		value := stringFlag(cmd, name)
+		if value == "" {
+			continue
+		}
+
+		accepted := false
+		for _, allowed := range flagValues[name] {
+			accepted = accepted || value == allowed
+		}
+		if !accepted {
+			problems = append(problems, fmt.Sprintf("--%s %q is not supported, use one of %s", name, value, strings.Join(flagValues[name], ", ")))
+		}
+	}
+
+	return problems
+}
+
+// validateNetworks checks the network flags, and that only the commands whose network flag allows it select several
+// networks or ledger ranges with them
+func validateNetworks(cmd *cobra.Command) []string {
+	values := stringArrayFlag(cmd, "network")
+	if len(values) == 0 {
+		return nil
+	}
+
+	problems := []string{}
+	if boolFlag(cmd, "testnet") || boolFlag(cmd, "futurenet") {
+		problems = append(problems, "--network selects the network in place of --testnet and --futurenet, remove them")
+	}
+
+	multiNetwork := utils.AllowsSeveralNetworks(cmd.Flags())
+	if len(values) > 1 && !multiNetwork {
+		problems = append(problems, fmt.Sprintf("%s exports a single network, but %d were selected with --network; run it once per network", cmd.Name(), len(values)))
+	}
+	if len(values) > 1 && (stringFlag(cmd, "horizon-db-url") != "" || stringFlag(cmd, "rpc-url") != "") {
+		problems = append(problems, "--horizon-db-url and --rpc-url serve a single network and cannot be used with several --network flags")
+	}
+
+	for _, value := range values {
+		networkRange, err := utils.ParseNetworkRange(value)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if networkRange.Start != 0 && !multiNetwork {
+			problems = append(problems, fmt.Sprintf("%s reads its ledger range from --start-ledger and --end-ledger, use --network %s", cmd.Name(), networkRange.Network))
+		}
+	}
+
+	return problems
+}
+
+// validateLedgerBackends checks that at most one ledger backend is selected
+func validateLedgerBackends(cmd *cobra.Command) []string {
+	selected := []string{}
+	if boolFlag(cmd, "captive-core") {
+		selected = append(selected, "--captive-core")
+	}
+	for _, name := range []string{"horizon-db-url", "rpc-url"} {
+		if stringFlag(cmd, name) != "" {
+			selected = append(selected, "--"+name)
+		}
+	}
+
+	if len(selected) > 1 {
+		return []string{fmt.Sprintf("%s select different ledger backends, keep only one of them", strings.Join(selected, " and "))}
+	}
+	return nil
+}
+
+// validateLedgerRange checks that the requested ledger range and batches are not empty
+func validateLedgerRange(cmd *cobra.Command) []string {
+	problems := []string{}
+	start, hasStart := uint32Flag(cmd, "start-ledger")
+	end, hasEnd := uint32Flag(cmd, "end-ledger")
+	if hasStart && start == 0 {
+		problems = append(problems, "--start-ledger 0 does not exist, the first ledger is 1 and the first ledger with transactions is 2")
+	}
+	if hasStart && hasEnd && end != 0 && end < start {
+		problems = append(problems, fmt.Sprintf("--end-ledger %d is before --start-ledger %d", end, start))
+	}
+
+	if batchSize, ok := uint32Flag(cmd, "batch-size"); ok && batchSize == 0 {
+		problems = append(problems, "--batch-size must be greater than 0, e.g. 64 to export one file per checkpoint")
+	}
+
+	return problems
+}
+
+// validateSinks checks that the flags of the uploads are only set when files are uploaded
+func validateSinks(cmd *cobra.Command) []string {
+	if cmd.Flags().Lookup("cloud-provider") == nil {
+		return nil
+	}
+
+	problems := []string{}
+	if stringFlag(cmd, "cloud-provider") == "" {
+		for _, name := range []string{"cloud-credentials", "manifest"} {
+			if stringFlag(cmd, name) != "" {
+				problems = append(problems, fmt.Sprintf("--%s is set but nothing is uploaded without --cloud-provider gcp", name))
+			}
+		}
+		if cmd.Flags().Changed("cloud-storage-bucket") {
+			problems = append(problems, "--cloud-storage-bucket is set but nothing is uploaded without --cloud-provider gcp")
+		}
+	} else if stringFlag(cmd, "cloud-storage-bucket") == "" {
+		problems = append(problems, "--cloud-provider needs a --cloud-storage-bucket to upload to")
+	}
+
+	return problems
+}
+
+// validateOutputFormat checks that the format of the output matches the target warehouse
+func validateOutputFormat(cmd *cobra.Command) []string {
+	// commands without timestamp columns have no format to match
+	if cmd.Flags().Lookup("timestamp-format") == nil {
+		return nil
+	}
+
+	problems := []string{}
+	warehouse := stringFlag(cmd, "warehouse")
+	if warehouse == utils.WarehouseAthena && cmd.Flags().Changed("partition-layout") && stringFlag(cmd, "partition-layout") != utils.PartitionLayoutHive {
+		problems = append(problems, "the Athena tables of generate_ddl are partitioned by dt and ledger_range, drop --partition-layout or set it to hive")
+	}
+	if warehouse == utils.WarehouseSnowflake && stringFlag(cmd, "timestamp-format") != utils.TimestampFormatRFC3339 {
+		problems = append(problems, fmt.Sprintf("--warehouse snowflake loads timestamps with the TIMESTAMP_FORMAT of its file format, drop --timestamp-format or set it to %s", utils.TimestampFormatRFC3339))
+	}
+	if targetBytes, ok := int64Flag(cmd, "compact-target-bytes"); ok && targetBytes < 0 {
+		problems = append(problems, "--compact-target-bytes must be positive, e.g. 268435456 for files of 256MB")
+	}
+
+	return problems
+}
+
+// validateRotation checks that the rotation limits of the output files are not negative
+func validateRotation(cmd *cobra.Command) []string {
+	problems := []string{}
+	for _, name := range []string{"max-file-rows", "max-file-bytes"} {
+		if limit, ok := int64Flag(cmd, name); ok && limit < 0 {
+			problems = append(problems, fmt.Sprintf("--%s must be positive, or 0 to not rotate output files", name))
+		}
+	}
+	if interval, err := cmd.Flags().GetDuration("rotate-interval"); err == nil && interval < 0 {
+		problems = append(problems, "--rotate-interval must be positive, e.g. 1h to write one file per hour of ledgers")
+	}
+
+	return problems
+}
+
+// validateBandwidth checks that the bandwidth limits are not negative
+func validateBandwidth(cmd *cobra.Command) []string {
+	problems := []string{}
+	for _, name := range []string{"max-download-mbps", "max-upload-mbps"} {
+		if mbps, err := cmd.Flags().GetFloat64(name); err == nil && mbps < 0 {
+			problems = append(problems, fmt.Sprintf("--%s must be positive, e.g. 100 for 100 megabits per second, or 0 to not limit the bandwidth", name))
+		}
+	}
+
+	return problems
+}
+
+// rowSinkExclusiveFlags are the flags that shape, format or upload output files, which a sink output does not write
+var rowSinkExclusiveFlags = []string{"warehouse", "timestamp-format", "partition-by", "partition-layout", "max-file-rows",
+	"max-file-bytes", "rotate-interval", "cloud-provider", "compact-target-bytes", "current-state"}
+
+// validateRowSinkOutput checks that an output appending rows to a sink is not combined with the flags of
+// output files
+func validateRowSinkOutput(cmd *cobra.Command) []string {
+	// only the export commands append their rows to a sink
+	if cmd.Flags().Lookup("timestamp-format") == nil {
+		return nil
+	}
+	sink, _, ok := outputRowSink(stringFlag(cmd, "output"))
+	if !ok {
+		return nil
+	}
+
+	problems := []string{}
+	for _, name := range rowSinkExclusiveFlags {
+		if cmd.Flags().Changed(name) {
+			problems = append(problems, fmt.Sprintf("--%s does not apply to a %s output, whose rows are appended to the tables of the sink", name, sink.scheme))
+		}
+	}
+	if example, ok := commandExamples[cmd.Name()]; sink.tables != nil && (!ok || !sink.acceptsTable(example.Object)) {
+		problems = append(problems, fmt.Sprintf("a %s output only accepts the %s tables", sink.scheme, strings.Join(sink.tables, " and ")))
+	}
+	if len(stringArrayFlag(cmd, "network")) > 1 {
+		problems = append(problems, fmt.Sprintf("a %s output holds the rows of a single network, export each --network into its own sink", sink.scheme))
+	}
+	if sink.cli != "" {
+		if _, err := exec.LookPath(sink.cli); err != nil {
+			problems = append(problems, fmt.Sprintf("the rows of a %s output are appended with the %s CLI, which was not found on the PATH, install it first", sink.scheme, sink.cli))
+		}
+	}
+
+	return problems
+}
+
+// validateWebhook checks that the headers and body template of a webhook output are valid, and only set for one
+func validateWebhook(cmd *cobra.Command) []string {
+	if cmd.Flags().Lookup("webhook-template") == nil {
+		return nil
+	}
+
+	problems := []string{}
+	sink, _, ok := outputRowSink(stringFlag(cmd, "output"))
+	if !ok || !strings.HasPrefix(sink.scheme, "webhook+") {
+		for _, name := range []string{"webhook-header", "webhook-template"} {
+			if cmd.Flags().Changed(name) {
+				problems = append(problems, fmt.Sprintf("--%s is set but no rows are posted without a webhook+https:// output", name))
+			}
+		}
+		return problems
+	}
+
+	if _, err := parseWebhookHeaders(stringArrayFlag(cmd, "webhook-header")); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := parseWebhookTemplate(stringFlag(cmd, "webhook-template")); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid --webhook-template: %v", err))
+	}
+
+	return problems
+}
+
+// stringFlag returns the value of a string flag, or an empty string when the command does not have it
+func stringFlag(cmd *cobra.Command, name string) string {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// stringArrayFlag returns the values of a string array flag, or nil when the command does not have it
+func stringArrayFlag(cmd *cobra.Command, name string) []string {
+	values, err := cmd.Flags().GetStringArray(name)
+	if err != nil {
+		return nil
+	}
+	return values
+}
+
+// boolFlag returns the value of a bool flag, or false when the command does not have it
+func boolFlag(cmd *cobra.Command, name string) bool {
+	value, err := cmd.Flags().GetBool(name)
+	return err == nil && value
+}
+
+// uint32Flag returns the value of a uint32 flag, and false when the command does not have it
+func uint32Flag(cmd *cobra.Command, name string) (uint32, bool) {
+	value, err := cmd.Flags().GetUint32(name)
+	return value, err == nil
+}
+
+// int64Flag returns the value of an int64 flag, and false when the command does not have it
+func int64Flag(cmd *cobra.Command, name string) (int64, bool) {
+	value, err := cmd.Flags().GetInt64(name)
+	return value, err == nil
+}
+
+// validateStreamPartitionKey checks that --stream-partition-key names a supported key and is only set with a streaming
+// output
+func validateStreamPartitionKey(cmd *cobra.Command) []string {
+	if cmd.Flags().Lookup("stream-partition-key") == nil {
+		return nil
+	}
+
+	sink, _, ok := outputRowSink(stringFlag(cmd, "output"))
+	if !ok || (sink.scheme != "kinesis://" && sink.scheme != "eventhubs://") {
+		if cmd.Flags().Changed("stream-partition-key") {
+			return []string{"--stream-partition-key is set but no rows are streamed without a kinesis:// or eventhubs:// output"}
+		}
+		return nil
+	}
+
+	if _, ok := partitionKeyColumns[stringFlag(cmd, "stream-partition-key")]; !ok {
+		return []string{fmt.Sprintf("--stream-partition-key must be one of %s, %s or %s", utils.StreamPartitionKeyLedger, utils.StreamPartitionKeyAccount, utils.StreamPartitionKeyContract)}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/parity"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// maxParityLedgers bounds the range of validate_horizon, which issues several Horizon requests per ledger
+const maxParityLedgers = 100
+
+var validateHorizonCmd = &cobra.Command{
+	Use:   "validate_horizon",
+	Short: "Compares the exported operations and effects with Horizon.",
+	Long: `Transforms the operations and effects of a small ledger range and compares them field by field with the
+operations and effects served by a Horizon instance for the same ledgers. Every discrepancy is reported, including
+records that only exist on one side. Only the details that both stellar-etl and Horizon define are compared.
+
+The command exits with an error if any discrepancy is found, so it can be used as a check before migrating off Horizon.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		startNum, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start sequence number: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output filename: ", err)
+		}
+
+		horizonURL, err := cmd.Flags().GetString("horizon-url")
+		if err != nil {
+			cmdLogger.Fatal("could not get horizon url: ", err)
+		}
+
+		if horizonURL == "" {
+			horizonURL = utils.NetworkPresets[env.Network].HorizonURL
+		}
+
+		if commonArgs.EndNum < startNum {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("end-ledger (%d) must not be before start-ledger (%d)", commonArgs.EndNum, startNum))
+		}
+		if commonArgs.EndNum-startNum+1 > maxParityLedgers {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("validate_horizon compares at most %d ledgers per run", maxParityLedgers))
+		}
+
+		transactions, err := input.GetTransactions(cmd.Context(), startNum, commonArgs.EndNum, -1, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", utils.InputError{Err: err})
+		}
+
+		operations, err := input.GetOperations(cmd.Context(), startNum, commonArgs.EndNum, -1, env, commonArgs.UseCaptiveCore)
+		if err != nil {
+			cmdLogger.Fatal("could not read operations: ", utils.InputError{Err: err})
+		}
+
+		transformedOperations := []transform.OperationOutput{}
+		for _, transformInput := range operations {
+			transformed, err := transform.TransformOperation(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+			if err != nil {
+				cmdLogger.Fatalf("could not transform operation %d in ledger %d: %v", transformInput.OperationIndex, transformInput.LedgerSeqNum, utils.TransformError{Err: err})
+			}
+			transformedOperations = append(transformedOperations, transformed)
+		}
+
+		transformedEffects := []transform.EffectOutput{}
+		for _, transformInput := range transactions {
+			ledgerSeq := uint32(transformInput.LedgerHistory.Header.LedgerSeq)
+			effects, err := transform.TransformEffect(transformInput.Transaction, ledgerSeq, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+			if err != nil {
+				cmdLogger.Fatalf("could not transform effects of transaction %d in ledger %d: %v", transformInput.Transaction.Index, ledgerSeq, utils.TransformError{Err: err})
+			}
+			transformedEffects = append(transformedEffects, effects...)
+		}
+
+		client := &http.Client{Timeout: 30 * time.Second}
+		horizonOperations := []map[string]interface{}{}
+		horizonEffects := []map[string]interface{}{}
+		for seq := startNum; seq <= commonArgs.EndNum; seq++ {
+			records, err := parity.FetchLedgerRecords(client, horizonURL, seq, "operations")
+			if err != nil {
+				cmdLogger.Fatal("could not fetch horizon operations: ", utils.InputError{Err: err})
+			}
+			horizonOperations = append(horizonOperations, records...)
+
+			records, err = parity.FetchLedgerRecords(client, horizonURL, seq, "effects")
+			if err != nil {
+				cmdLogger.Fatal("could not fetch horizon effects: ", utils.InputError{Err: err})
+			}
+			horizonEffects = append(horizonEffects, records...)
+		}
+
+		discrepancies := append(
+			parity.CompareOperations(transformedOperations, horizonOperations),
+			parity.CompareEffects(transformedEffects, horizonEffects)...,
+		)
+
+		outFile := mustOutFile(path)
+		for _, discrepancy := range discrepancies {
+			cmdLogger.Warn(discrepancy.String())
+			marshalled, err := json.Marshal(discrepancy)
+			if err != nil {
+				cmdLogger.Fatal("could not marshal discrepancy: ", err)
+			}
+			outFile.Write(append(marshalled, '\n'))
+		}
+		outFile.Close()
+		mustCommitOutputFile(outFile)
+
+		cmdLogger.Infof("compared %d operations and %d effects with %s", len(transformedOperations), len(transformedEffects), horizonURL)
+		if len(discrepancies) > 0 {
+			cmdLogger.Abort(utils.ErrorCategoryValidation, fmt.Sprintf("found %d discrepancies with Horizon; see %s", len(discrepancies), path))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateHorizonCmd)
+	utils.AddCommonFlags(validateHorizonCmd.Flags())
+	validateHorizonCmd.Flags().Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the compared range")
+	validateHorizonCmd.Flags().StringP("output", "o", "horizon_discrepancies.txt", "Filename of the newline delimited JSON discrepancy report")
+	validateHorizonCmd.Flags().String("horizon-url", "", "URL of the Horizon instance to compare against. Defaults to the SDF Horizon of the selected network")
+	validateHorizonCmd.MarkFlagRequired("end-ledger")
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// snowflakeTimestampFormat matches the TIMESTAMP_FORMAT 'YYYY-MM-DD HH24:MI:SS.FF6 TZH:TZM' used by the generated file format
+const snowflakeTimestampFormat = "2006-01-02 15:04:05.000000 -07:00"
+
+// snowflakeMaxFileBytes is the compressed size at which gzipped output is split into a new file.
+// Snowflake recommends 100-250MB of compressed data per file so that COPY INTO can load files in parallel.
+const snowflakeMaxFileBytes = 200 << 20
+
+// applyWarehouseFormat rewrites a decoded entry to match the conventions of the target warehouse
+func applyWarehouseFormat(i map[string]interface{}, entry interface{}, warehouse string) {
+	if warehouse != utils.WarehouseSnowflake {
+		return
+	}
+
+	fieldTypes := jsonFieldTypes(reflect.TypeOf(entry))
+	keys := make([]string, 0, len(i))
+	for k := range i {
+		keys = append(keys, k)
+	}
+
+	for _, k := range keys {
+		v := i[k]
+		if fieldType, ok := fieldTypes[k]; ok && isTimestampType(fieldType) {
+			if timestamp, ok := v.(string); ok {
+				parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+				if err != nil {
+					cmdLogger.Errorf("could not parse timestamp %s of %s: %v", timestamp, k, err)
+				} else {
+					v = parsed.UTC().Format(snowflakeTimestampFormat)
+				}
+			}
+		}
+
+		delete(i, k)
+		i[utils.ToLowerSnakeCase(k)] = v
+	}
+}
+
+// finalizeOutputFile moves a closed output file into its partition layout, prepares it for the target warehouse,
+// commits it and returns the paths of the files that should be uploaded
+func finalizeOutputFile(ctx context.Context, outFile *outputFile, start, end uint32, commonArgs utils.CommonFlagValues) []string {
+	paths, err := commitFiles(stageOutputFile(ctx, outFile, start, end, commonArgs))
+	if err != nil {
+		cmdLogger.LogError(utils.SinkError{Err: err})
+	}
+
+	return paths
+}
+
+// stageOutputFile moves a closed output file into its partition layout, rotates it and compresses it for its table or the
+// target warehouse, inside the staging folder. It returns the staged paths, which are committed once the whole batch is staged. The rows of
+// sink outputs are appended to their sink instead, leaving nothing to commit.
+func stageOutputFile(ctx context.Context, outFile *outputFile, start, end uint32, commonArgs utils.CommonFlagValues) []string {
+	if outFile.sink != nil {
+		if err := appendToRowSink(ctx, outFile, commonArgs); err != nil {
+			cmdLogger.LogError(utils.SinkError{Err: err})
+		}
+		return nil
+	}
+
+	paths := rotateOutputFiles(partitionOutputFile(outFile, start, end, commonArgs), commonArgs)
+	// the settings of the table in the config file take precedence over the compression of the warehouse
+	if tableFormat(outFile.table) == formatParquet {
+		compression, _ := tableCompression(outFile.table)
+		return convertFilesToParquet(ctx, paths, outFile.table, compression, commonArgs)
+	}
+	if compression, ok := tableCompression(outFile.table); ok {
+		return compressFiles(paths, compression)
+	}
+	if commonArgs.Warehouse != utils.WarehouseSnowflake {
+		return paths
+	}
+
+	warehousePaths := []string{}
+	for _, path := range paths {
+		gzipPaths, err := gzipAndSplit(path, snowflakeMaxFileBytes)
+		if err != nil {
+			cmdLogger.Errorf("could not compress %s: %v", path, err)
+			warehousePaths = append(warehousePaths, path)
+			continue
+		}
+
+		deleteLocalFiles(path)
+		warehousePaths = append(warehousePaths, gzipPaths...)
+	}
+
+	return warehousePaths
+}
+
+// gzipAndSplit compresses a newline delimited file into one or more gzip files of about maxBytes each. The compressor
+// buffers its output, so a file can exceed maxBytes by the size of a compressed block. A single file is written to
+// <path>.gz, otherwise the files are numbered <path>.<n>.gz
+func gzipAndSplit(path string, maxBytes int) ([]string, error) {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer inFile.Close()
+
+	reader := bufio.NewReader(inFile)
+	paths := []string{}
+	var outFile *os.File
+	var compressed *countingWriter
+	var gzipWriter *gzip.Writer
+
+	closePart := func() error {
+		if gzipWriter == nil {
+			return nil
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return err
+		}
+		return outFile.Close()
+	}
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			closePart()
+			return nil, readErr
+		}
+
+		if len(line) > 0 {
+			if gzipWriter == nil || compressed.written >= maxBytes {
+				if err := closePart(); err != nil {
+					return nil, err
+				}
+				partPath := fmt.Sprintf("%s.%d.gz", path, len(paths))
+				outFile, err = os.Create(partPath)
+				if err != nil {
+					return nil, err
+				}
+				compressed = &countingWriter{w: outFile}
+				gzipWriter = gzip.NewWriter(compressed)
+				paths = append(paths, partPath)
+			}
+
+			if _, err := gzipWriter.Write(line); err != nil {
+				closePart()
+				return nil, err
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if err := closePart(); err != nil {
+		return nil, err
+	}
+
+	// Empty files still produce an empty gzip file so that every export has an output
+	if len(paths) == 0 {
+		emptyPath := path + ".gz"
+		emptyFile, err := os.Create(emptyPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := gzip.NewWriter(emptyFile).Close(); err != nil {
+			return nil, err
+		}
+		return []string{emptyPath}, emptyFile.Close()
+	}
+
+	if len(paths) == 1 {
+		singlePath := path + ".gz"
+		if err := os.Rename(paths[0], singlePath); err != nil {
+			return nil, err
+		}
+		paths[0] = singlePath
+	}
+
+	return paths, nil
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w       io.Writer
+	written int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.written += n
+	return n, err
+}
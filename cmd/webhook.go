@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// webhookDefaultTemplate is the body posted for each batch of rows when --webhook-template is not set
+const webhookDefaultTemplate = `{"table": "{{.Table}}", "rows": {{.Rows}}}`
+
+// webhookBatchRows is the number of rows posted in each request
+const webhookBatchRows = 100
+
+// postAttempts is the number of times a batch is posted to a webhook or stream before the rows are kept for a later run
+const postAttempts = 5
+
+// postMaxBackoff bounds the exponential wait between the attempts of a batch
+const postMaxBackoff = 30 * time.Second
+
+// webhookBatch is the data of --webhook-template
+type webhookBatch struct {
+	Table string
+	Count int
+	// Rows is the JSON array of the rows of the batch
+	Rows string
+}
+
+// newWebhookSink returns the sink posting batches of rows to the HTTP endpoint following the scheme, reached over protocol
+func newWebhookSink(scheme, protocol string) *rowSink {
+	return &rowSink{
+		scheme:        scheme,
+		stagingPrefix: "webhook",
+		append: func(ctx context.Context, location, table, path string, commonArgs utils.CommonFlagValues) error {
+			return postToWebhook(ctx, protocol+"://"+location, table, path, commonArgs)
+		},
+	}
+}
+
+// parseWebhookTemplate parses the body template of a webhook output, or the default template when it is not set
+func parseWebhookTemplate(body string) (*template.Template, error) {
+	if body == "" {
+		body = webhookDefaultTemplate
+	}
+	return template.New("webhook").Option("missingkey=error").Parse(body)
+}
+
+// parseWebhookHeaders parses the values of --webhook-header, formatted as Name: value, expanding the environment
+// variables of the values
+func parseWebhookHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, value := range values {
+		name, headerValue, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid webhook header %q, must be formatted as Name: value", value)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(os.ExpandEnv(headerValue)))
+	}
+	return headers, nil
+}
+
+// postToWebhook posts the rows of the newline delimited file at path to the endpoint in batches of webhookBatchRows,
+// with the body rendered by the template of the output
+func postToWebhook(ctx context.Context, endpoint, table, path string, commonArgs utils.CommonFlagValues) error {
+	body, err := parseWebhookTemplate(commonArgs.WebhookTemplate)
+	if err != nil {
+		return err
+	}
+	headers, err := parseWebhookHeaders(commonArgs.WebhookHeaders)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]byte{}
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		payload := bytes.Buffer{}
+		batch := webhookBatch{Table: table, Count: len(rows), Rows: "[" + string(bytes.Join(rows, []byte(","))) + "]"}
+		if err := body.Execute(&payload, batch); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		return postWithRetries(ctx, endpoint, headers, payload.Bytes())
+	}
+
+	err = forEachRow(path, func(row []byte) error {
+		rows = append(rows, row)
+		if len(rows) == webhookBatchRows {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// postWithRetries posts a batch, retrying failed connections, rate limited requests and server errors with an
+// exponential backoff until ctx is done. Other client errors are not retried, as sending the same batch again cannot
+// fix them.
+func postWithRetries(ctx context.Context, endpoint string, headers http.Header, payload []byte) error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		retryable, err := postOnce(ctx, endpoint, headers, payload)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt == postAttempts {
+			return fmt.Errorf("request failed after %d attempts: %v", attempt, err)
+		}
+		cmdLogger.Warnf("request to %s failed, retrying in %s: %v", redactedLocation(endpoint), backoff, err)
+		if err := waitForRetry(ctx, backoff); err != nil {
+			return fmt.Errorf("request stopped after %d attempts: %v", attempt, err)
+		}
+		backoff = min(2*backoff, postMaxBackoff)
+	}
+}
+
+func postOnce(ctx context.Context, endpoint string, headers http.Header, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, utils.ThrottleUpload(ctx, bytes.NewReader(payload)))
+	if err != nil {
+		return false, err
+	}
+	req.ContentLength = int64(len(payload))
+	req.Header = headers.Clone()
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := sinkHTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retryable, fmt.Errorf("%s: %s", resp.Status, responseBody)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "github.com/stellar/stellar-etl/internal/utils"
+
+// addWellKnownAssets adds <prefix>asset_domain, <prefix>asset_anchor_name and <prefix>asset_decimals columns next to every
+// <prefix>asset_type column of a decoded entry, including the ones nested in operation and effect details and in lists.
+// The columns hold the metadata of the asset in the well-known assets file, and are null for assets the file does not
+// list and for assets without a code, like pool shares.
+func addWellKnownAssets(i map[string]interface{}, assets map[string]utils.WellKnownAsset) {
+	forEachAssetColumns(i, func(columns map[string]interface{}, prefix string) {
+		code, _ := columns[prefix+"asset_code"].(string)
+		issuer, _ := columns[prefix+"asset_issuer"].(string)
+		asset, ok := assets[utils.WellKnownAssetKey(code, issuer)]
+		if !ok || (code == "" && !isNativeAssetType(columns[prefix+"asset_type"])) {
+			columns[prefix+"asset_domain"] = nil
+			columns[prefix+"asset_anchor_name"] = nil
+			columns[prefix+"asset_decimals"] = nil
+			return
+		}
+
+		columns[prefix+"asset_domain"] = asset.Domain
+		columns[prefix+"asset_anchor_name"] = asset.AnchorName
+		if asset.Decimals != nil {
+			columns[prefix+"asset_decimals"] = *asset.Decimals
+		} else {
+			columns[prefix+"asset_decimals"] = nil
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func TestAddWellKnownAssets(t *testing.T) {
+	decimals := 7
+	assets := map[string]utils.WellKnownAsset{
+		"native": {Code: "XLM", Domain: "stellar.org", AnchorName: "Lumens", Decimals: &decimals},
+		"USDC:GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN": {
+			Code: "USDC", Issuer: "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN", Domain: "centre.io", AnchorName: "USD Coin",
+		},
+	}
+	row := map[string]interface{}{
+		"asset_type": "native",
+		"details": map[string]interface{}{
+			"asset_type":   "credit_alphanum4",
+			"asset_code":   "USDC",
+			"asset_issuer": "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN",
+			"path": []interface{}{
+				map[string]interface{}{"asset_type": "native"},
+			},
+			// pool shares have no code or issuer, and are not lumens
+			"reserves_asset_type": "liquidity_pool_shares",
+		},
+	}
+
+	addWellKnownAssets(row, assets)
+	assert.Equal(t, map[string]interface{}{
+		"asset_type":        "native",
+		"asset_domain":      "stellar.org",
+		"asset_anchor_name": "Lumens",
+		"asset_decimals":    7,
+		"details": map[string]interface{}{
+			"asset_type":        "credit_alphanum4",
+			"asset_code":        "USDC",
+			"asset_issuer":      "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN",
+			"asset_domain":      "centre.io",
+			"asset_anchor_name": "USD Coin",
+			"asset_decimals":    nil,
+			"path": []interface{}{
+				map[string]interface{}{
+					"asset_type":        "native",
+					"asset_domain":      "stellar.org",
+					"asset_anchor_name": "Lumens",
+					"asset_decimals":    7,
+				},
+			},
+			"reserves_asset_type":        "liquidity_pool_shares",
+			"reserves_asset_domain":      nil,
+			"reserves_asset_anchor_name": nil,
+			"reserves_asset_decimals":    nil,
+		},
+	}, row)
+}
+
+func TestAddWellKnownAssetsPoolShareTrustline(t *testing.T) {
+	decimals := 7
+	assets := map[string]utils.WellKnownAsset{
+		"native": {Code: "XLM", Domain: "stellar.org", AnchorName: "Lumens", Decimals: &decimals},
+	}
+	// trustlines write the asset type as its number, 3 for pool shares
+	row := map[string]interface{}{"asset_type": json.Number("3"), "asset_code": "", "asset_issuer": ""}
+
+	addWellKnownAssets(row, assets)
+	assert.Nil(t, row["asset_domain"])
+	assert.Nil(t, row["asset_anchor_name"])
+	assert.Nil(t, row["asset_decimals"])
+}
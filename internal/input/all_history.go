@@ -20,9 +20,7 @@ type AllHistoryTransformInput struct {
 
 // GetAllHistory returns a slice of operations, trades, effects, transactions, diagnostic events
 // for the ledgers in the provided range (inclusive on both ends)
-func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) (AllHistoryTransformInput, error) {
-	ctx := context.Background()
-
+func GetAllHistory(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) (AllHistoryTransformInput, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return AllHistoryTransformInput{}, err
@@ -57,6 +55,9 @@ func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails,
 			if err == io.EOF {
 				break
 			}
+			if !utils.InSample(tx.Result.TransactionHash, env.CommonFlagValues.SampleRate) {
+				continue
+			}
 
 			for index, op := range tx.Envelope.Operations() {
 				// Operations
@@ -69,7 +70,7 @@ func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails,
 				})
 
 				// Trades
-				if operationResultsInTrade(op) && tx.Result.Successful() {
+				if OperationResultsInTrade(op) && tx.Result.Successful() {
 					tradeSlice = append(tradeSlice, TradeTransformInput{
 						OperationIndex:     int32(index),
 						Transaction:        tx,
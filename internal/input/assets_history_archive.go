@@ -10,14 +10,13 @@ import (
 )
 
 // GetPaymentOperations returns a slice of payment operations that can include new assets from the ledgers in the provided range (inclusive on both ends)
-func GetPaymentOperationsHistoryArchive(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptivere bool) ([]AssetTransformInput, error) {
-	backend, err := utils.CreateBackend(start, end, env.ArchiveURLs)
+func GetPaymentOperationsHistoryArchive(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptivere bool) ([]AssetTransformInput, error) {
+	backend, err := utils.CreateBackend(ctx, start, end, env.ArchiveURLs, env.CommonFlagValues.HistoryCacheDir)
 	if err != nil {
 		return []AssetTransformInput{}, err
 	}
 
 	assetSlice := []AssetTransformInput{}
-	ctx := context.Background()
 	for seq := start; seq <= end; seq++ {
 		// Get ledger from sequence number
 		ledger, err := backend.GetLedgerArchive(ctx, seq)
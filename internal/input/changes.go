@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 
+	"github.com/stellar/stellar-etl/internal/transform"
 	"github.com/stellar/stellar-etl/internal/utils"
 
 	"github.com/stellar/go/ingest"
@@ -20,11 +21,20 @@ var (
 type LedgerChanges struct {
 	Changes       []ingest.Change
 	LedgerHeaders []xdr.LedgerHeaderHistoryEntry
+	// Causes holds what caused each change, see transform.LedgerEntryChangeCauses
+	Causes []transform.LedgerEntryChangeCause
+}
+
+// LedgerEvictions holds the keys of the entries evicted from the bucket list when a ledger closed
+type LedgerEvictions struct {
+	Keys         []xdr.LedgerKey
+	LedgerHeader xdr.LedgerHeaderHistoryEntry
 }
 
 // ChangeBatch represents the changes in a batch of ledgers represented by the range [BatchStart, BatchEnd)
 type ChangeBatch struct {
 	Changes    map[xdr.LedgerEntryType]LedgerChanges
+	Evictions  []LedgerEvictions
 	BatchStart uint32
 	BatchEnd   uint32
 }
@@ -83,12 +93,14 @@ func PrepareCaptiveCore(execPath string, tomlPath string, start, end uint32, env
 
 // extractBatch gets the changes from the ledgers in the range [batchStart, batchEnd] and compacts them
 func extractBatch(
+	ctx context.Context,
 	batchStart, batchEnd uint32,
 	backend *ledgerbackend.LedgerBackend,
 	env utils.EnvironmentDetails, logger *utils.EtlLogger) ChangeBatch {
 
 	dataTypes := []xdr.LedgerEntryType{
 		xdr.LedgerEntryTypeAccount,
+		xdr.LedgerEntryTypeData,
 		xdr.LedgerEntryTypeOffer,
 		xdr.LedgerEntryTypeTrustline,
 		xdr.LedgerEntryTypeLiquidityPool,
@@ -99,7 +111,7 @@ func extractBatch(
 		xdr.LedgerEntryTypeTtl}
 
 	ledgerChanges := map[xdr.LedgerEntryType]LedgerChanges{}
-	ctx := context.Background()
+	var evictions []LedgerEvictions
 	for seq := batchStart; seq <= batchEnd; {
 		changeCompactors := map[xdr.LedgerEntryType]*ingest.ChangeCompactor{}
 		for _, dt := range dataTypes {
@@ -109,26 +121,49 @@ func extractBatch(
 		// if this ledger is available, we process its changes and move on to the next ledger by incrementing seq.
 		// Otherwise, nothing is incremented, and we try again on the next iteration of the loop
 		var header xdr.LedgerHeaderHistoryEntry
+		var causes map[string]transform.LedgerEntryChangeCause
 		if seq <= batchEnd {
-			changeReader, err := ingest.NewLedgerChangeReader(ctx, *backend, env.NetworkPassphrase, seq)
+			lcm, err := (*backend).GetLedger(ctx, seq)
+			if err != nil && ctx.Err() != nil {
+				// the export was cancelled or timed out, the incomplete batch is dropped by the caller
+				return ChangeBatch{}
+			}
+			if err != nil {
+				logger.Fatal(fmt.Sprintf("unable to get ledger %d: ", seq), utils.InputError{Err: err})
+			}
+			changeReader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(env.NetworkPassphrase, lcm)
 			if err != nil {
-				logger.Fatal(fmt.Sprintf("unable to create change reader for ledger %d: ", seq), err)
+				logger.Fatal(fmt.Sprintf("unable to create change reader for ledger %d: ", seq), utils.InputError{Err: err})
 			}
 			header = changeReader.LedgerTransactionReader.GetHeader()
 
+			causes, err = transform.LedgerEntryChangeCauses(lcm, env.NetworkPassphrase)
+			if err != nil {
+				logger.Fatal(fmt.Sprintf("unable to find the causes of the changes of ledger %d: ", seq), utils.TransformError{Err: err})
+			}
+
+			evictedKeys, err := transform.EvictedLedgerKeys(lcm)
+			if err != nil {
+				logger.Fatal(fmt.Sprintf("unable to read the evicted entries of ledger %d: ", seq), utils.InputError{Err: err})
+			}
+			if len(evictedKeys) > 0 {
+				evictions = append(evictions, LedgerEvictions{Keys: evictedKeys, LedgerHeader: header})
+			}
+
 			for {
 				change, err := changeReader.Read()
 				if err == io.EOF {
 					break
 				}
 				if err != nil {
-					logger.Fatal(fmt.Sprintf("unable to read changes from ledger %d: ", seq), err)
+					logger.Fatal(fmt.Sprintf("unable to read changes from ledger %d: ", seq), utils.InputError{Err: err})
 				}
 				cache, ok := changeCompactors[change.Type]
 				if !ok {
-					// TODO: once LedgerEntryTypeData is tracked as well, all types should be addressed,
-					// so this info log should be a warning.
-					logger.Infof("change type: %v not tracked", change.Type)
+					if utils.StrictSchema {
+						logger.Fatal(fmt.Sprintf("unable to read changes from ledger %d: ", seq), utils.TransformError{Err: utils.UnknownVariantError{Kind: "ledger entry type", Value: change.Type.String()}})
+					}
+					logger.Warnf("change type: %v not tracked", change.Type)
 				} else {
 					cache.AddChange(change)
 				}
@@ -140,9 +175,15 @@ func extractBatch(
 
 		for dataType, compactor := range changeCompactors {
 			for _, change := range compactor.GetChanges() {
+				var cause transform.LedgerEntryChangeCause
+				if key, err := transform.ChangeCauseKey(change); err == nil {
+					cause = causes[key]
+				}
+
 				dataTypeChanges := ledgerChanges[dataType]
 				dataTypeChanges.Changes = append(dataTypeChanges.Changes, change)
 				dataTypeChanges.LedgerHeaders = append(dataTypeChanges.LedgerHeaders, header)
+				dataTypeChanges.Causes = append(dataTypeChanges.Causes, cause)
 				ledgerChanges[dataType] = dataTypeChanges
 			}
 		}
@@ -151,27 +192,40 @@ func extractBatch(
 
 	return ChangeBatch{
 		Changes:    ledgerChanges,
+		Evictions:  evictions,
 		BatchStart: batchStart,
 		BatchEnd:   batchEnd,
 	}
 }
 
 // StreamChanges reads in ledgers, processes the changes, and send the changes to the channel matching their type
-// Ledgers are processed in batches of size <batchSize>.
-func StreamChanges(backend *ledgerbackend.LedgerBackend, start, end, batchSize uint32, changeChannel chan ChangeBatch, closeChan chan int, env utils.EnvironmentDetails, logger *utils.EtlLogger) {
+// Ledgers are processed in batches of size <batchSize>. Reading from the backend is aborted when ctx is done, and the
+// batch being read is then dropped.
+func StreamChanges(ctx context.Context, backend *ledgerbackend.LedgerBackend, start, end, batchSize uint32, changeChannel chan ChangeBatch, closeChan chan int, env utils.EnvironmentDetails, logger *utils.EtlLogger) {
+	StreamChangesWithBatchSizer(ctx, backend, start, end, func() uint32 { return batchSize }, changeChannel, closeChan, env, logger)
+}
+
+// StreamChangesWithBatchSizer behaves like StreamChanges, but asks batchSizer for the size of every batch so that it can change while streaming
+func StreamChangesWithBatchSizer(ctx context.Context, backend *ledgerbackend.LedgerBackend, start, end uint32, batchSizer func() uint32, changeChannel chan ChangeBatch, closeChan chan int, env utils.EnvironmentDetails, logger *utils.EtlLogger) {
 	batchStart := start
-	batchEnd := uint32(math.Min(float64(batchStart+batchSize), float64(end)))
+	batchEnd := uint32(math.Min(float64(batchStart+batchSizer()), float64(end)))
 	for batchStart < batchEnd {
 		if batchEnd < end {
 			batchEnd = uint32(batchEnd - 1)
 		}
-		batch := ExtractBatch(batchStart, batchEnd, backend, env, logger)
+		batch := ExtractBatch(ctx, batchStart, batchEnd, backend, env, logger)
+		if ctx.Err() != nil {
+			break
+		}
 		changeChannel <- batch
 		// batchStart and batchEnd should not overlap
 		// overlapping batches causes duplicate record loads
 		batchStart = uint32(math.Min(float64(batchEnd), float64(end)) + 1)
-		batchEnd = uint32(math.Min(float64(batchStart+batchSize), float64(end)))
+		batchEnd = uint32(math.Min(float64(batchStart+batchSizer()), float64(end)))
 	}
 	close(changeChannel)
-	closeChan <- 1
+	select {
+	case closeChan <- 1:
+	case <-ctx.Done():
+	}
 }
@@ -1,6 +1,7 @@
 package input
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stellar/go/ingest"
@@ -128,6 +129,7 @@ func wrapLedgerEntry(entryType xdr.LedgerEntryType, entry xdr.LedgerEntry) Chang
 }
 
 func mockExtractBatch(
+	ctx context.Context,
 	batchStart, batchEnd uint32,
 	backend *ledgerbackend.LedgerBackend,
 	env utils.EnvironmentDetails, logger *utils.EtlLogger) ChangeBatch {
@@ -216,7 +218,7 @@ func TestStreamChangesBatchNumbers(t *testing.T) {
 			}
 			logger := utils.NewEtlLogger()
 			ExtractBatch = mockExtractBatch
-			go StreamChanges(nil, tt.args.batchStart, tt.args.batchEnd, batchSize, changeChan, closeChan, env, logger)
+			go StreamChanges(context.Background(), nil, tt.args.batchStart, tt.args.batchEnd, batchSize, changeChan, closeChan, env, logger)
 			var got []batchRange
 			for b := range changeChan {
 				got = append(got, batchRange{
@@ -0,0 +1,67 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// GetLatestCheckpoint returns the last checkpoint ledger published to the history archives, along with its close time
+func GetLatestCheckpoint(ctx context.Context, archiveURLs []string) (uint32, time.Time, error) {
+	client, err := utils.CreateHistoryArchiveClientWithContext(ctx, archiveURLs)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	root, err := client.GetRootHAS()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unable to get the root history archive state: %v", err)
+	}
+
+	header, err := client.GetLedgerHeader(root.CurrentLedger)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unable to get ledger %d: %v", root.CurrentLedger, err)
+	}
+
+	closeTime, err := utils.ExtractLedgerCloseTime(header)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unable to extract close time from ledger %d: %v", root.CurrentLedger, err)
+	}
+
+	return root.CurrentLedger, closeTime, nil
+}
+
+// GetNetworkTip returns the latest ledger available from the ledger backend, which is the network tip when reading from
+// captive core, along with its close time. The backend is prepared from the ledger from, which should be recent since
+// captive core replays every ledger after it.
+func GetNetworkTip(ctx context.Context, from uint32, env utils.EnvironmentDetails, useCaptiveCore bool) (uint32, time.Time, error) {
+	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer backend.Close()
+
+	if err = backend.PrepareRange(ctx, ledgerbackend.UnboundedRange(from)); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	tip, err := backend.GetLatestLedgerSequence(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	lcm, err := backend.GetLedger(ctx, tip)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unable to get ledger %d: %v", tip, err)
+	}
+
+	closeTime, err := utils.GetCloseTime(lcm)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("unable to extract close time from ledger %d: %v", tip, err)
+	}
+
+	return tip, closeTime, nil
+}
@@ -67,6 +67,16 @@ func GetLedgerRange(startTime, endTime time.Time, isTest bool, isFuture bool) (i
 	return startLedger, endLedger, nil
 }
 
+// GetLedgerForTime returns the ledger closed at the provided time, i.e. the first ledger of a range starting then
+func GetLedgerForTime(t time.Time, isTest bool, isFuture bool) (uint32, error) {
+	ledger, _, err := GetLedgerRange(t, t, isTest, isFuture)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(ledger), nil
+}
+
 // createNewGraph makes a new graph with the endpoints equal to the network's endpoints
 func createNewGraph(archiveURLs []string) (graph, error) {
 	graph := graph{}
@@ -11,8 +11,7 @@ import (
 )
 
 // GetLedgers returns a slice of ledger close metas for the ledgers in the provided range (inclusive on both ends)
-func GetLedgers(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
-	ctx := context.Background()
+func GetLedgers(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return []utils.HistoryArchiveLedgerAndLCM{}, err
@@ -27,46 +26,7 @@ func GetLedgers(start, end uint32, limit int64, env utils.EnvironmentDetails, us
 			return []utils.HistoryArchiveLedgerAndLCM{}, err
 		}
 
-		var ext xdr.TransactionHistoryEntryExt
-		var transactionResultPair []xdr.TransactionResultPair
-
-		switch lcm.V {
-		case 0:
-			ext = xdr.TransactionHistoryEntryExt{
-				V:                0,
-				GeneralizedTxSet: nil,
-			}
-			for _, transactionResultMeta := range lcm.V0.TxProcessing {
-				transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
-			}
-		case 1:
-			ext = xdr.TransactionHistoryEntryExt{
-				V:                1,
-				GeneralizedTxSet: &lcm.V1.TxSet,
-			}
-			for _, transactionResultMeta := range lcm.V1.TxProcessing {
-				transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
-			}
-		}
-
-		ledger := historyarchive.Ledger{
-			Header: lcm.LedgerHeaderHistoryEntry(),
-			Transaction: xdr.TransactionHistoryEntry{
-				LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
-				TxSet: xdr.TransactionSet{
-					PreviousLedgerHash: lcm.LedgerHeaderHistoryEntry().Header.PreviousLedgerHash,
-					Txs:                lcm.TransactionEnvelopes(),
-				},
-				Ext: ext,
-			},
-			TransactionResult: xdr.TransactionHistoryResultEntry{
-				LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
-				TxResultSet: xdr.TransactionResultSet{
-					Results: transactionResultPair,
-				},
-				Ext: xdr.TransactionHistoryResultEntryExt{},
-			},
-		}
+		ledger := HistoryArchiveLedger(lcm)
 
 		ledgerLCM := utils.HistoryArchiveLedgerAndLCM{
 			Ledger: ledger,
@@ -81,3 +41,47 @@ func GetLedgers(start, end uint32, limit int64, env utils.EnvironmentDetails, us
 
 	return ledgerSlice, nil
 }
+
+// HistoryArchiveLedger builds the history archive representation of the ledger from its ledger close meta
+func HistoryArchiveLedger(lcm xdr.LedgerCloseMeta) historyarchive.Ledger {
+	var ext xdr.TransactionHistoryEntryExt
+	var transactionResultPair []xdr.TransactionResultPair
+
+	switch lcm.V {
+	case 0:
+		ext = xdr.TransactionHistoryEntryExt{
+			V:                0,
+			GeneralizedTxSet: nil,
+		}
+		for _, transactionResultMeta := range lcm.V0.TxProcessing {
+			transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
+		}
+	case 1:
+		ext = xdr.TransactionHistoryEntryExt{
+			V:                1,
+			GeneralizedTxSet: &lcm.V1.TxSet,
+		}
+		for _, transactionResultMeta := range lcm.V1.TxProcessing {
+			transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
+		}
+	}
+
+	return historyarchive.Ledger{
+		Header: lcm.LedgerHeaderHistoryEntry(),
+		Transaction: xdr.TransactionHistoryEntry{
+			LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
+			TxSet: xdr.TransactionSet{
+				PreviousLedgerHash: lcm.LedgerHeaderHistoryEntry().Header.PreviousLedgerHash,
+				Txs:                lcm.TransactionEnvelopes(),
+			},
+			Ext: ext,
+		},
+		TransactionResult: xdr.TransactionHistoryResultEntry{
+			LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
+			TxResultSet: xdr.TransactionResultSet{
+				Results: transactionResultPair,
+			},
+			Ext: xdr.TransactionHistoryResultEntryExt{},
+		},
+	}
+}
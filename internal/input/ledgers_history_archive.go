@@ -7,14 +7,13 @@ import (
 )
 
 // GetLedgers returns a slice of ledger close metas for the ledgers in the provided range (inclusive on both ends)
-func GetLedgersHistoryArchive(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
-	backend, err := utils.CreateBackend(start, end, env.ArchiveURLs)
+func GetLedgersHistoryArchive(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
+	backend, err := utils.CreateBackend(ctx, start, end, env.ArchiveURLs, env.CommonFlagValues.HistoryCacheDir)
 	if err != nil {
 		return []utils.HistoryArchiveLedgerAndLCM{}, err
 	}
 
 	ledgerSlice := []utils.HistoryArchiveLedgerAndLCM{}
-	ctx := context.Background()
 	for seq := start; seq <= end; seq++ {
 		ledger, err := backend.GetLedgerArchive(ctx, seq)
 		if err != nil {
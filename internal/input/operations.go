@@ -27,9 +27,7 @@ func panicIf(err error) {
 }
 
 // GetOperations returns a slice of operations for the ledgers in the provided range (inclusive on both ends)
-func GetOperations(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]OperationTransformInput, error) {
-	ctx := context.Background()
-
+func GetOperations(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]OperationTransformInput, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return []OperationTransformInput{}, err
@@ -54,6 +52,9 @@ func GetOperations(start, end uint32, limit int64, env utils.EnvironmentDetails,
 			if err == io.EOF {
 				break
 			}
+			if !utils.InSample(tx.Result.TransactionHash, env.CommonFlagValues.SampleRate) {
+				continue
+			}
 
 			for index, op := range tx.Envelope.Operations() {
 				opSlice = append(opSlice, OperationTransformInput{
@@ -23,9 +23,7 @@ type TradeTransformInput struct {
 }
 
 // GetTrades returns a slice of trades for the ledgers in the provided range (inclusive on both ends)
-func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]TradeTransformInput, error) {
-	ctx := context.Background()
-
+func GetTrades(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]TradeTransformInput, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return []TradeTransformInput{}, err
@@ -52,6 +50,9 @@ func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, use
 			if err == io.EOF {
 				break
 			}
+			if !utils.InSample(tx.Result.TransactionHash, env.CommonFlagValues.SampleRate) {
+				continue
+			}
 
 			for index, op := range tx.Envelope.Operations() {
 				/*
@@ -61,7 +62,7 @@ func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, use
 
 					Trades also can only occur when these operations are successful
 				*/
-				if operationResultsInTrade(op) && tx.Result.Successful() {
+				if OperationResultsInTrade(op) && tx.Result.Successful() {
 					tradeSlice = append(tradeSlice, TradeTransformInput{
 						OperationIndex:     int32(index),
 						Transaction:        tx,
@@ -85,8 +86,8 @@ func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, use
 	return tradeSlice, nil
 }
 
-// operationResultsInTrade returns true if the operation results in a trade
-func operationResultsInTrade(operation xdr.Operation) bool {
+// OperationResultsInTrade returns true if the operation results in a trade
+func OperationResultsInTrade(operation xdr.Operation) bool {
 	switch operation.Body.Type {
 	case xdr.OperationTypeManageBuyOffer:
 		return true
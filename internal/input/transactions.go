@@ -20,9 +20,7 @@ type LedgerTransformInput struct {
 }
 
 // GetTransactions returns a slice of transactions for the ledgers in the provided range (inclusive on both ends)
-func GetTransactions(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]LedgerTransformInput, error) {
-	ctx := context.Background()
-
+func GetTransactions(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]LedgerTransformInput, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return []LedgerTransformInput{}, err
@@ -53,6 +51,9 @@ func GetTransactions(start, end uint32, limit int64, env utils.EnvironmentDetail
 			if err == io.EOF {
 				break
 			}
+			if !utils.InSample(tx.Result.TransactionHash, env.CommonFlagValues.SampleRate) {
+				continue
+			}
 
 			txSlice = append(txSlice, LedgerTransformInput{
 				Transaction:     tx,
@@ -0,0 +1,215 @@
+// Package parity compares the operations and effects exported by stellar-etl with the ones served by Horizon
+package parity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+)
+
+// Discrepancy is a field whose value differs between stellar-etl and Horizon
+type Discrepancy struct {
+	Kind    string      `json:"kind"`
+	ID      string      `json:"id"`
+	Field   string      `json:"field"`
+	ETL     interface{} `json:"etl"`
+	Horizon interface{} `json:"horizon"`
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("%s %s: %s is %v in stellar-etl but %v in Horizon", d.Kind, d.ID, d.Field, d.ETL, d.Horizon)
+}
+
+// horizonPage is the HAL envelope of Horizon collection responses
+type horizonPage struct {
+	Embedded struct {
+		Records []map[string]interface{} `json:"records"`
+	} `json:"_embedded"`
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+}
+
+// FetchLedgerRecords returns every record of a Horizon ledger sub-collection, e.g. "operations" or "effects", following the pagination links
+func FetchLedgerRecords(client *http.Client, horizonURL string, ledgerSeq uint32, collection string) ([]map[string]interface{}, error) {
+	url := fmt.Sprintf("%s/ledgers/%d/%s?limit=200&include_failed=true", strings.TrimSuffix(horizonURL, "/"), ledgerSeq, collection)
+
+	records := []map[string]interface{}{}
+	for url != "" {
+		resp, err := client.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch %s: %v", url, err)
+		}
+
+		var page horizonPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("horizon returned %s for %s", resp.Status, url)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s: %v", url, err)
+		}
+
+		if len(page.Embedded.Records) == 0 {
+			break
+		}
+		records = append(records, page.Embedded.Records...)
+		url = page.Links.Next.Href
+	}
+
+	return records, nil
+}
+
+// CompareOperations diffs the exported operations with the Horizon operations of the same ledgers, matched by id.
+// Only the details that both sides define are compared, since the detail sets are not identical.
+func CompareOperations(operations []transform.OperationOutput, horizonOperations []map[string]interface{}) []Discrepancy {
+	horizonByID := map[string]map[string]interface{}{}
+	for _, record := range horizonOperations {
+		horizonByID[fmt.Sprint(record["id"])] = record
+	}
+
+	discrepancies := []Discrepancy{}
+	for _, operation := range operations {
+		id := strconv.FormatInt(operation.OperationID, 10)
+		record, ok := horizonByID[id]
+		if !ok {
+			discrepancies = append(discrepancies, Discrepancy{Kind: "operation", ID: id, Field: "id", ETL: id, Horizon: nil})
+			continue
+		}
+		delete(horizonByID, id)
+
+		discrepancies = append(discrepancies, compareField("operation", id, "type", operation.TypeString, record["type"])...)
+		discrepancies = append(discrepancies, compareField("operation", id, "source_account", operation.SourceAccount, record["source_account"])...)
+		discrepancies = append(discrepancies, compareDetails("operation", id, operation.OperationDetails, record)...)
+	}
+
+	for _, id := range sortedKeys(horizonByID) {
+		discrepancies = append(discrepancies, Discrepancy{Kind: "operation", ID: id, Field: "id", ETL: nil, Horizon: id})
+	}
+
+	return discrepancies
+}
+
+// CompareEffects diffs the exported effects with the Horizon effects of the same ledgers. Horizon effect ids are
+// "<operation id>-<index>", where index is the 1-based position of the effect in its operation.
+func CompareEffects(effects []transform.EffectOutput, horizonEffects []map[string]interface{}) []Discrepancy {
+	horizonByID := map[string]map[string]interface{}{}
+	for _, record := range horizonEffects {
+		horizonByID[fmt.Sprint(record["id"])] = record
+	}
+
+	discrepancies := []Discrepancy{}
+	indexByOperation := map[int64]int{}
+	for _, effect := range effects {
+		indexByOperation[effect.OperationID]++
+		id := fmt.Sprintf("%019d-%010d", effect.OperationID, indexByOperation[effect.OperationID])
+		record, ok := horizonByID[id]
+		if !ok {
+			discrepancies = append(discrepancies, Discrepancy{Kind: "effect", ID: id, Field: "id", ETL: id, Horizon: nil})
+			continue
+		}
+		delete(horizonByID, id)
+
+		discrepancies = append(discrepancies, compareField("effect", id, "type", effect.TypeString, record["type"])...)
+		discrepancies = append(discrepancies, compareField("effect", id, "account", effect.Address, record["account"])...)
+		discrepancies = append(discrepancies, compareDetails("effect", id, effect.Details, record)...)
+	}
+
+	for _, id := range sortedKeys(horizonByID) {
+		discrepancies = append(discrepancies, Discrepancy{Kind: "effect", ID: id, Field: "id", ETL: nil, Horizon: id})
+	}
+
+	return discrepancies
+}
+
+func compareDetails(kind, id string, details map[string]interface{}, record map[string]interface{}) []Discrepancy {
+	discrepancies := []Discrepancy{}
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		horizonValue, ok := record[key]
+		if !ok {
+			continue
+		}
+		discrepancies = append(discrepancies, compareField(kind, id, key, details[key], horizonValue)...)
+	}
+
+	return discrepancies
+}
+
+func compareField(kind, id, field string, etlValue, horizonValue interface{}) []Discrepancy {
+	if equivalent(etlValue, horizonValue) {
+		return nil
+	}
+	return []Discrepancy{{Kind: kind, ID: id, Field: field, ETL: etlValue, Horizon: horizonValue}}
+}
+
+// equivalent compares values after normalizing the representations that differ between the two systems:
+// Horizon writes amounts as decimal strings while stellar-etl writes numbers, and both sides may use
+// different numeric and slice types for the same value.
+func equivalent(etlValue, horizonValue interface{}) bool {
+	etlNumber, etlIsNumber := toFloat(etlValue)
+	horizonNumber, horizonIsNumber := toFloat(horizonValue)
+	if etlIsNumber && horizonIsNumber {
+		return etlNumber == horizonNumber
+	}
+
+	etlJSON, err := json.Marshal(etlValue)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(etlJSON, &normalized); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalized, horizonValue)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		return parsed, err == nil
+	case json.Number:
+		parsed, err := v.Float64()
+		return parsed, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func sortedKeys(records map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
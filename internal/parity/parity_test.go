@@ -0,0 +1,67 @@
+package parity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+)
+
+func TestCompareOperations(t *testing.T) {
+	operations := []transform.OperationOutput{
+		{
+			OperationID:   4098,
+			SourceAccount: "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
+			TypeString:    "payment",
+			OperationDetails: map[string]interface{}{
+				"amount":     10.5,
+				"asset_type": "native",
+				"to":         "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
+				"asset_id":   int64(-5706705804583548011),
+			},
+		},
+		{
+			OperationID:      4099,
+			TypeString:       "bump_sequence",
+			OperationDetails: map[string]interface{}{},
+		},
+	}
+
+	horizonOperations := []map[string]interface{}{
+		{
+			"id":             "4098",
+			"source_account": "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
+			"type":           "payment",
+			"amount":         "10.5000000",
+			"asset_type":     "native",
+			"to":             "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ",
+		},
+		{
+			"id":   "4100",
+			"type": "bump_sequence",
+		},
+	}
+
+	assert.Equal(t, []Discrepancy{
+		{Kind: "operation", ID: "4098", Field: "to", ETL: "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN", Horizon: "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ"},
+		{Kind: "operation", ID: "4099", Field: "id", ETL: "4099", Horizon: nil},
+		{Kind: "operation", ID: "4100", Field: "id", ETL: nil, Horizon: "4100"},
+	}, CompareOperations(operations, horizonOperations))
+}
+
+func TestCompareEffects(t *testing.T) {
+	effects := []transform.EffectOutput{
+		{OperationID: 4098, Address: "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF", TypeString: "account_debited", Details: map[string]interface{}{"amount": 10.5}},
+		{OperationID: 4098, Address: "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN", TypeString: "account_credited", Details: map[string]interface{}{"amount": 10.5}},
+	}
+
+	horizonEffects := []map[string]interface{}{
+		{"id": "0000000000000004098-0000000001", "account": "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF", "type": "account_debited", "amount": "10.5000000"},
+		{"id": "0000000000000004098-0000000002", "account": "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN", "type": "account_credited", "amount": "1.0000000"},
+	}
+
+	assert.Equal(t, []Discrepancy{
+		{Kind: "effect", ID: "0000000000000004098-0000000002", Field: "amount", ETL: 10.5, Horizon: "1.0000000"},
+	}, CompareEffects(effects, horizonEffects))
+}
@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"github.com/stellar/go/xdr"
+)
+
+// AccountID returns a deterministic account for tests, different for every n
+func AccountID(n byte) xdr.AccountId {
+	var key xdr.Uint256
+	key[0] = n
+	return xdr.AccountId{Type: xdr.PublicKeyTypePublicKeyTypeEd25519, Ed25519: &key}
+}
+
+// MuxedAccount returns the account of AccountID as a transaction or operation source
+func MuxedAccount(n byte) xdr.MuxedAccount {
+	id := AccountID(n)
+	return id.ToMuxedAccount()
+}
+
+// MuxedAccountWithID returns the account of AccountID muxed with the given id
+func MuxedAccountWithID(n byte, id uint64) xdr.MuxedAccount {
+	return xdr.MuxedAccount{
+		Type:     xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{Id: xdr.Uint64(id), Ed25519: *AccountID(n).Ed25519},
+	}
+}
+
+// AccountEntry returns the ledger entry of an account holding balance stroops, last modified in the given ledger
+func AccountEntry(account xdr.AccountId, balance int64, lastModifiedLedger uint32) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(lastModifiedLedger),
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: account,
+				Balance:   xdr.Int64(balance),
+			},
+		},
+	}
+}
+
+// Created returns the change creating the entry
+func Created(entry xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &entry}}
+}
+
+// Updated returns the changes updating an entry from its state before to its state after the update
+func Updated(before, after xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &before},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &after},
+	}
+}
+
+// Removed returns the changes removing the entry
+func Removed(entry xdr.LedgerEntry) ([]xdr.LedgerEntryChange, error) {
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return []xdr.LedgerEntryChange{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &entry},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+	}, nil
+}
@@ -0,0 +1,395 @@
+// Package testutil builds synthetic ledgers for tests, so that transforms can be tested with the operations, results
+// and ledger entry changes they need instead of checked in XDR blobs.
+package testutil
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// defaultProtocolVersion is the protocol version of the ledgers built without an explicit version
+const defaultProtocolVersion = 21
+
+// LedgerBuilder builds a LedgerCloseMeta holding the transactions added to it
+type LedgerBuilder struct {
+	sequence        uint32
+	closeTime       time.Time
+	protocolVersion uint32
+	transactions    []*TransactionBuilder
+}
+
+// NewLedger starts building the ledger with the given sequence, closed at the unix epoch with the current protocol
+func NewLedger(sequence uint32) *LedgerBuilder {
+	return &LedgerBuilder{
+		sequence:        sequence,
+		closeTime:       time.Unix(0, 0).UTC(),
+		protocolVersion: defaultProtocolVersion,
+	}
+}
+
+// ClosedAt sets the close time of the ledger
+func (l *LedgerBuilder) ClosedAt(closeTime time.Time) *LedgerBuilder {
+	l.closeTime = closeTime
+	return l
+}
+
+// ProtocolVersion sets the protocol version of the ledger header
+func (l *LedgerBuilder) ProtocolVersion(version uint32) *LedgerBuilder {
+	l.protocolVersion = version
+	return l
+}
+
+// AddTransaction adds a successful transaction of the source account holding the operations, and returns it so that
+// its results and changes can be set. Transactions are applied in the order they are added.
+func (l *LedgerBuilder) AddTransaction(source xdr.MuxedAccount, operations ...xdr.Operation) *TransactionBuilder {
+	transaction := &TransactionBuilder{
+		source:           source,
+		sequence:         int64(l.sequence)<<32 + int64(len(l.transactions)) + 1,
+		maxFee:           uint32(100 * len(operations)),
+		feeCharged:       int64(100 * len(operations)),
+		memo:             xdr.Memo{Type: xdr.MemoTypeMemoNone},
+		operations:       operations,
+		results:          make([]*xdr.OperationResultTr, len(operations)),
+		operationChanges: make([]xdr.LedgerEntryChanges, len(operations)),
+	}
+	l.transactions = append(l.transactions, transaction)
+	return transaction
+}
+
+// Build returns the LedgerCloseMeta of the ledger. The transaction hashes are computed with the network passphrase, which
+// has to be the one the ledger is read with.
+func (l *LedgerBuilder) Build(networkPassphrase string) (xdr.LedgerCloseMeta, error) {
+	envelopes := make([]xdr.TransactionEnvelope, 0, len(l.transactions))
+	processing := make([]xdr.TransactionResultMeta, 0, len(l.transactions))
+	for i, transaction := range l.transactions {
+		envelope := transaction.envelope()
+		hash, err := network.HashTransactionInEnvelope(envelope, networkPassphrase)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, fmt.Errorf("could not hash transaction %d: %v", i, err)
+		}
+		innerHash, err := network.HashTransactionInEnvelope(transaction.innerEnvelope(), networkPassphrase)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, fmt.Errorf("could not hash inner transaction %d: %v", i, err)
+		}
+
+		envelopes = append(envelopes, envelope)
+		processing = append(processing, transaction.resultMeta(hash, innerHash))
+	}
+
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					LedgerVersion: xdr.Uint32(l.protocolVersion),
+					LedgerSeq:     xdr.Uint32(l.sequence),
+					ScpValue: xdr.StellarValue{
+						CloseTime: xdr.TimePoint(l.closeTime.Unix()),
+					},
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V: 1,
+				V1TxSet: &xdr.TransactionSetV1{
+					Phases: []xdr.TransactionPhase{{
+						V: 0,
+						V0Components: &[]xdr.TxSetComponent{{
+							Type: xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+							TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{
+								Txs: envelopes,
+							},
+						}},
+					}},
+				},
+			},
+			TxProcessing: processing,
+		},
+	}, nil
+}
+
+// LedgerTransactions builds the ledger and reads its transactions back the way the exporters read them
+func (l *LedgerBuilder) LedgerTransactions(networkPassphrase string) ([]ingest.LedgerTransaction, error) {
+	ledgerCloseMeta, err := l.Build(networkPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, ledgerCloseMeta)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	transactions := []ingest.LedgerTransaction{}
+	for {
+		transaction, err := reader.Read()
+		if err == io.EOF {
+			return transactions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+}
+
+// TransactionBuilder builds a transaction of a LedgerBuilder
+type TransactionBuilder struct {
+	source           xdr.MuxedAccount
+	sequence         int64
+	maxFee           uint32
+	feeCharged       int64
+	memo             xdr.Memo
+	sorobanData      *xdr.SorobanTransactionData
+	failed           bool
+	operations       []xdr.Operation
+	results          []*xdr.OperationResultTr
+	operationChanges []xdr.LedgerEntryChanges
+	feeChanges       xdr.LedgerEntryChanges
+	feeBump          *xdr.FeeBumpTransaction
+}
+
+// Sequence sets the sequence number of the transaction, which defaults to a number unique within the ledger
+func (t *TransactionBuilder) Sequence(sequence int64) *TransactionBuilder {
+	t.sequence = sequence
+	return t
+}
+
+// Fee sets the maximum fee of the transaction and the fee it was charged, which default to 100 stroops per operation
+func (t *TransactionBuilder) Fee(maxFee uint32, feeCharged int64) *TransactionBuilder {
+	t.maxFee = maxFee
+	t.feeCharged = feeCharged
+	return t
+}
+
+// Memo sets the memo of the transaction
+func (t *TransactionBuilder) Memo(memo xdr.Memo) *TransactionBuilder {
+	t.memo = memo
+	return t
+}
+
+// SorobanData sets the Soroban resources and footprint declared by the transaction
+func (t *TransactionBuilder) SorobanData(data xdr.SorobanTransactionData) *TransactionBuilder {
+	t.sorobanData = &data
+	return t
+}
+
+// FeeBump wraps the transaction in a fee bump transaction of the fee source, paying at most maxFee stroops. The fee
+// charged by the fee bump is the one set with Fee, and the inner transaction records the same fee as charged.
+func (t *TransactionBuilder) FeeBump(feeSource xdr.MuxedAccount, maxFee int64) *TransactionBuilder {
+	t.feeBump = &xdr.FeeBumpTransaction{FeeSource: feeSource, Fee: xdr.Int64(maxFee)}
+	return t
+}
+
+// Failed marks the transaction as failed. Failed transactions do not change ledger entries besides charging the fee.
+func (t *TransactionBuilder) Failed() *TransactionBuilder {
+	t.failed = true
+	return t
+}
+
+// OperationResult sets the result of the operation at index. Operations without a result get the success result of
+// their type, without any of the values the operation produced.
+func (t *TransactionBuilder) OperationResult(index int, result xdr.OperationResultTr) *TransactionBuilder {
+	t.results[index] = &result
+	return t
+}
+
+// OperationChanges sets the ledger entry changes caused by the operation at index
+func (t *TransactionBuilder) OperationChanges(index int, changes ...xdr.LedgerEntryChange) *TransactionBuilder {
+	t.operationChanges[index] = changes
+	return t
+}
+
+// FeeChanges sets the ledger entry changes caused by charging the fee of the transaction
+func (t *TransactionBuilder) FeeChanges(changes ...xdr.LedgerEntryChange) *TransactionBuilder {
+	t.feeChanges = changes
+	return t
+}
+
+func (t *TransactionBuilder) envelope() xdr.TransactionEnvelope {
+	inner := t.innerEnvelope()
+	if t.feeBump == nil {
+		return inner
+	}
+
+	feeBump := *t.feeBump
+	feeBump.InnerTx = xdr.FeeBumpTransactionInnerTx{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: inner.V1}
+	return xdr.TransactionEnvelope{
+		Type:    xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{Tx: feeBump},
+	}
+}
+
+// innerEnvelope returns the envelope of the transaction, without the fee bump wrapping it if any
+func (t *TransactionBuilder) innerEnvelope() xdr.TransactionEnvelope {
+	ext := xdr.TransactionExt{V: 0}
+	if t.sorobanData != nil {
+		ext = xdr.TransactionExt{V: 1, SorobanData: t.sorobanData}
+	}
+
+	return xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: t.source,
+				Fee:           xdr.Uint32(t.maxFee),
+				SeqNum:        xdr.SequenceNumber(t.sequence),
+				Cond:          xdr.Preconditions{Type: xdr.PreconditionTypePrecondNone},
+				Memo:          t.memo,
+				Operations:    t.operations,
+				Ext:           ext,
+			},
+		},
+	}
+}
+
+func (t *TransactionBuilder) resultMeta(hash, innerHash [32]byte) xdr.TransactionResultMeta {
+	results := make([]xdr.OperationResult, 0, len(t.operations))
+	operationMeta := []xdr.OperationMeta{}
+	for i, operation := range t.operations {
+		result := t.results[i]
+		if result == nil {
+			defaultResult := successResult(operation.Body.Type)
+			result = &defaultResult
+		}
+		results = append(results, xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: result})
+
+		if !t.failed {
+			operationMeta = append(operationMeta, xdr.OperationMeta{Changes: t.operationChanges[i]})
+		}
+	}
+
+	code := xdr.TransactionResultCodeTxSuccess
+	if t.failed {
+		code = xdr.TransactionResultCodeTxFailed
+	}
+
+	result := xdr.TransactionResultResult{Code: code, Results: &results}
+	if t.feeBump != nil {
+		feeBumpCode := xdr.TransactionResultCodeTxFeeBumpInnerSuccess
+		if t.failed {
+			feeBumpCode = xdr.TransactionResultCodeTxFeeBumpInnerFailed
+		}
+		result = xdr.TransactionResultResult{
+			Code: feeBumpCode,
+			InnerResultPair: &xdr.InnerTransactionResultPair{
+				TransactionHash: xdr.Hash(innerHash),
+				Result: xdr.InnerTransactionResult{
+					FeeCharged: xdr.Int64(t.feeCharged),
+					Result:     xdr.InnerTransactionResultResult{Code: code, Results: &results},
+				},
+			},
+		}
+	}
+
+	return xdr.TransactionResultMeta{
+		Result: xdr.TransactionResultPair{
+			TransactionHash: xdr.Hash(hash),
+			Result: xdr.TransactionResult{
+				FeeCharged: xdr.Int64(t.feeCharged),
+				Result:     result,
+			},
+		},
+		FeeProcessing: t.feeChanges,
+		TxApplyProcessing: xdr.TransactionMeta{
+			V:  3,
+			V3: &xdr.TransactionMetaV3{Operations: operationMeta},
+		},
+	}
+}
+
+// successResult returns the success result of an operation type, without any of the values it produced
+func successResult(operationType xdr.OperationType) xdr.OperationResultTr {
+	result := xdr.OperationResultTr{Type: operationType}
+	switch operationType {
+	case xdr.OperationTypeCreateAccount:
+		result.CreateAccountResult = &xdr.CreateAccountResult{Code: xdr.CreateAccountResultCodeCreateAccountSuccess}
+	case xdr.OperationTypePayment:
+		result.PaymentResult = &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		result.PathPaymentStrictReceiveResult = &xdr.PathPaymentStrictReceiveResult{
+			Code:    xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess,
+			Success: &xdr.PathPaymentStrictReceiveResultSuccess{},
+		}
+	case xdr.OperationTypeManageSellOffer:
+		result.ManageSellOfferResult = &xdr.ManageSellOfferResult{
+			Code:    xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+			Success: &xdr.ManageOfferSuccessResult{Offer: xdr.ManageOfferSuccessResultOffer{Effect: xdr.ManageOfferEffectManageOfferDeleted}},
+		}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		result.CreatePassiveSellOfferResult = &xdr.ManageSellOfferResult{
+			Code:    xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+			Success: &xdr.ManageOfferSuccessResult{Offer: xdr.ManageOfferSuccessResultOffer{Effect: xdr.ManageOfferEffectManageOfferDeleted}},
+		}
+	case xdr.OperationTypeSetOptions:
+		result.SetOptionsResult = &xdr.SetOptionsResult{Code: xdr.SetOptionsResultCodeSetOptionsSuccess}
+	case xdr.OperationTypeChangeTrust:
+		result.ChangeTrustResult = &xdr.ChangeTrustResult{Code: xdr.ChangeTrustResultCodeChangeTrustSuccess}
+	case xdr.OperationTypeAllowTrust:
+		result.AllowTrustResult = &xdr.AllowTrustResult{Code: xdr.AllowTrustResultCodeAllowTrustSuccess}
+	case xdr.OperationTypeAccountMerge:
+		balance := xdr.Int64(0)
+		result.AccountMergeResult = &xdr.AccountMergeResult{
+			Code:                 xdr.AccountMergeResultCodeAccountMergeSuccess,
+			SourceAccountBalance: &balance,
+		}
+	case xdr.OperationTypeInflation:
+		result.InflationResult = &xdr.InflationResult{
+			Code:    xdr.InflationResultCodeInflationSuccess,
+			Payouts: &[]xdr.InflationPayout{},
+		}
+	case xdr.OperationTypeManageData:
+		result.ManageDataResult = &xdr.ManageDataResult{Code: xdr.ManageDataResultCodeManageDataSuccess}
+	case xdr.OperationTypeBumpSequence:
+		result.BumpSeqResult = &xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess}
+	case xdr.OperationTypeManageBuyOffer:
+		result.ManageBuyOfferResult = &xdr.ManageBuyOfferResult{
+			Code:    xdr.ManageBuyOfferResultCodeManageBuyOfferSuccess,
+			Success: &xdr.ManageOfferSuccessResult{Offer: xdr.ManageOfferSuccessResultOffer{Effect: xdr.ManageOfferEffectManageOfferDeleted}},
+		}
+	case xdr.OperationTypePathPaymentStrictSend:
+		result.PathPaymentStrictSendResult = &xdr.PathPaymentStrictSendResult{
+			Code:    xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+			Success: &xdr.PathPaymentStrictSendResultSuccess{},
+		}
+	case xdr.OperationTypeCreateClaimableBalance:
+		result.CreateClaimableBalanceResult = &xdr.CreateClaimableBalanceResult{
+			Code:      xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess,
+			BalanceId: &xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &xdr.Hash{}},
+		}
+	case xdr.OperationTypeClaimClaimableBalance:
+		result.ClaimClaimableBalanceResult = &xdr.ClaimClaimableBalanceResult{Code: xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess}
+	case xdr.OperationTypeBeginSponsoringFutureReserves:
+		result.BeginSponsoringFutureReservesResult = &xdr.BeginSponsoringFutureReservesResult{Code: xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesSuccess}
+	case xdr.OperationTypeEndSponsoringFutureReserves:
+		result.EndSponsoringFutureReservesResult = &xdr.EndSponsoringFutureReservesResult{Code: xdr.EndSponsoringFutureReservesResultCodeEndSponsoringFutureReservesSuccess}
+	case xdr.OperationTypeRevokeSponsorship:
+		result.RevokeSponsorshipResult = &xdr.RevokeSponsorshipResult{Code: xdr.RevokeSponsorshipResultCodeRevokeSponsorshipSuccess}
+	case xdr.OperationTypeClawback:
+		result.ClawbackResult = &xdr.ClawbackResult{Code: xdr.ClawbackResultCodeClawbackSuccess}
+	case xdr.OperationTypeClawbackClaimableBalance:
+		result.ClawbackClaimableBalanceResult = &xdr.ClawbackClaimableBalanceResult{Code: xdr.ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess}
+	case xdr.OperationTypeSetTrustLineFlags:
+		result.SetTrustLineFlagsResult = &xdr.SetTrustLineFlagsResult{Code: xdr.SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess}
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		result.LiquidityPoolDepositResult = &xdr.LiquidityPoolDepositResult{Code: xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess}
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		result.LiquidityPoolWithdrawResult = &xdr.LiquidityPoolWithdrawResult{Code: xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess}
+	case xdr.OperationTypeInvokeHostFunction:
+		result.InvokeHostFunctionResult = &xdr.InvokeHostFunctionResult{
+			Code:    xdr.InvokeHostFunctionResultCodeInvokeHostFunctionSuccess,
+			Success: &xdr.Hash{},
+		}
+	case xdr.OperationTypeExtendFootprintTtl:
+		result.ExtendFootprintTtlResult = &xdr.ExtendFootprintTtlResult{Code: xdr.ExtendFootprintTtlResultCodeExtendFootprintTtlSuccess}
+	case xdr.OperationTypeRestoreFootprint:
+		result.RestoreFootprintResult = &xdr.RestoreFootprintResult{Code: xdr.RestoreFootprintResultCodeRestoreFootprintSuccess}
+	}
+
+	return result
+}
@@ -0,0 +1,48 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerTransactions(t *testing.T) {
+	destination := MuxedAccount(2)
+	payment := xdr.Operation{Body: xdr.OperationBody{
+		Type:      xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{Destination: destination, Asset: xdr.MustNewNativeAsset(), Amount: 100},
+	}}
+	before := AccountEntry(AccountID(1), 1000, 9)
+	after := AccountEntry(AccountID(1), 900, 10)
+
+	ledger := NewLedger(10).ClosedAt(time.Unix(1700000000, 0))
+	ledger.AddTransaction(MuxedAccount(1), payment).OperationChanges(0, Updated(before, after)...)
+	ledger.AddTransaction(MuxedAccount(3), payment, payment).Failed()
+
+	ledgerCloseMeta, err := ledger.Build(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), ledgerCloseMeta.LedgerSequence())
+	assert.Equal(t, int64(1700000000), ledgerCloseMeta.LedgerCloseTime())
+
+	// the built ledger can be encoded like the ledgers of the datastore
+	_, err = ledgerCloseMeta.MarshalBinary()
+	assert.NoError(t, err)
+
+	transactions, err := ledger.LedgerTransactions(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	assert.True(t, transactions[0].Result.Successful())
+	assert.Equal(t, AccountID(1).Address(), transactions[0].Envelope.SourceAccount().ToAccountId().Address())
+	changes, err := transactions[0].GetOperationChanges(0)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, xdr.Int64(900), changes[0].Post.Data.MustAccount().Balance)
+
+	assert.False(t, transactions[1].Result.Successful())
+	assert.Len(t, transactions[1].Envelope.Operations(), 2)
+	assert.Equal(t, int64(200), int64(transactions[1].Result.Result.FeeCharged))
+}
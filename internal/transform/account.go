@@ -99,6 +99,7 @@ func TransformAccount(ledgerChange ingest.Change, header xdr.LedgerHeaderHistory
 		ThresholdLow:         outputThreshLow,
 		ThresholdMedium:      outputThreshMed,
 		ThresholdHigh:        outputThreshHigh,
+		SignerSummary:        accountSignerSummary(accountEntry),
 		LastModifiedLedger:   outputLastModifiedLedger,
 		Sponsor:              ledgerEntrySponsorToNullString(ledgerEntry),
 		NumSponsored:         uint32(accountEntry.NumSponsored()),
@@ -110,3 +111,28 @@ func TransformAccount(ledgerChange ingest.Change, header xdr.LedgerHeaderHistory
 	}
 	return transformedAccount, nil
 }
+
+// accountSignerSummary adds up the weights of the master key and the signers of an account. A threshold is met when
+// all the signers together can reach it; an account without any weight is locked and meets none of its thresholds.
+func accountSignerSummary(accountEntry xdr.AccountEntry) SignerSummary {
+	var summary SignerSummary
+	if weight := int32(accountEntry.MasterKeyWeight()); weight > 0 {
+		summary.SignerCount++
+		summary.TotalWeight += weight
+	}
+	for _, signer := range accountEntry.Signers {
+		if signer.Weight > 0 {
+			summary.SignerCount++
+			summary.TotalWeight += int32(signer.Weight)
+		}
+	}
+
+	meets := func(threshold byte) bool {
+		return summary.TotalWeight > 0 && summary.TotalWeight >= int32(threshold)
+	}
+	summary.MeetsLow = meets(accountEntry.ThresholdLow())
+	summary.MeetsMedium = meets(accountEntry.ThresholdMedium())
+	summary.MeetsHigh = meets(accountEntry.ThresholdHigh())
+
+	return summary
+}
@@ -0,0 +1,44 @@
+package transform
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// TransformAccountData converts a data entry set by manage data operations into a form suitable for BigQuery
+func TransformAccountData(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (AccountDataOutput, error) {
+	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
+	if err != nil {
+		return AccountDataOutput{}, err
+	}
+
+	dataEntry, dataFound := ledgerEntry.Data.GetData()
+	if !dataFound {
+		return AccountDataOutput{}, fmt.Errorf("could not extract data from ledger entry; actual type is %s", ledgerEntry.Data.Type)
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return AccountDataOutput{}, err
+	}
+
+	ledgerSequence := header.Header.LedgerSeq
+
+	transformedData := AccountDataOutput{
+		AccountID:          dataEntry.AccountId.Address(),
+		DataName:           string(dataEntry.DataName),
+		DataValue:          base64.StdEncoding.EncodeToString(dataEntry.DataValue),
+		Sponsor:            ledgerEntrySponsorToNullString(ledgerEntry),
+		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:  uint32(changeType),
+		Deleted:            outputDeleted,
+		ClosedAt:           closedAt,
+		LedgerSequence:     uint32(ledgerSequence),
+	}
+
+	return transformedData, nil
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformAccountData(t *testing.T) {
+	type transformTest struct {
+		input      ingest.Change
+		wantOutput AccountDataOutput
+		wantErr    error
+	}
+
+	tests := []transformTest{
+		{
+			ingest.Change{
+				Type: xdr.LedgerEntryTypeOffer,
+				Pre:  nil,
+				Post: &xdr.LedgerEntry{
+					Data: xdr.LedgerEntryData{
+						Type: xdr.LedgerEntryTypeOffer,
+					},
+				},
+			},
+			AccountDataOutput{}, fmt.Errorf("could not extract data from ledger entry; actual type is LedgerEntryTypeOffer"),
+		},
+		{
+			makeAccountDataTestInput(),
+			makeAccountDataTestOutput(),
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		header := xdr.LedgerHeaderHistoryEntry{
+			Header: xdr.LedgerHeader{
+				ScpValue: xdr.StellarValue{
+					CloseTime: 1000,
+				},
+				LedgerSeq: 10,
+			},
+		}
+		actualOutput, actualError := TransformAccountData(test.input, header)
+		assert.Equal(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeAccountDataTestInput() ingest.Change {
+	dataLedgerEntry := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 30705278,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{
+				AccountId: testAccount1ID,
+				DataName:  "test",
+				DataValue: xdr.DataValue([]byte{0x76, 0x61, 0x6c, 0x75, 0x65}),
+			},
+		},
+		Ext: xdr.LedgerEntryExt{
+			V: 1,
+			V1: &xdr.LedgerEntryExtensionV1{
+				SponsoringId: &testAccount3ID,
+			},
+		},
+	}
+
+	return ingest.Change{
+		Type: xdr.LedgerEntryTypeData,
+		Pre:  &dataLedgerEntry,
+		Post: nil,
+	}
+}
+
+func makeAccountDataTestOutput() AccountDataOutput {
+	return AccountDataOutput{
+		AccountID:          testAccount1Address,
+		DataName:           "test",
+		DataValue:          "dmFsdWU=",
+		Sponsor:            null.StringFrom(testAccount3Address),
+		LastModifiedLedger: 30705278,
+		LedgerEntryChange:  2,
+		Deleted:            true,
+		LedgerSequence:     10,
+		ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+	}
+}
@@ -184,13 +184,47 @@ func makeAccountTestOutput() AccountOutput {
 		ThresholdLow:         1,
 		ThresholdMedium:      3,
 		ThresholdHigh:        5,
-		Sponsor:              null.StringFrom(testAccount3Address),
-		NumSponsored:         3,
-		NumSponsoring:        1,
-		LastModifiedLedger:   30705278,
-		LedgerEntryChange:    2,
-		Deleted:              true,
-		LedgerSequence:       10,
-		ClosedAt:             time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+		SignerSummary: SignerSummary{
+			SignerCount: 1,
+			TotalWeight: 2,
+			MeetsLow:    true,
+		},
+		Sponsor:            null.StringFrom(testAccount3Address),
+		NumSponsored:       3,
+		NumSponsoring:      1,
+		LastModifiedLedger: 30705278,
+		LedgerEntryChange:  2,
+		Deleted:            true,
+		LedgerSequence:     10,
+		ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+	}
+}
+
+func TestAccountSignerSummary(t *testing.T) {
+	signer := func(weight xdr.Uint32) xdr.Signer {
+		return xdr.Signer{Key: xdr.MustSigner(testAccount2Address), Weight: weight}
+	}
+
+	tests := []struct {
+		account    xdr.AccountEntry
+		wantOutput SignerSummary
+	}{
+		{
+			xdr.AccountEntry{
+				Thresholds: xdr.Thresholds([4]byte{1, 1, 2, 3}),
+				Signers:    []xdr.Signer{signer(1), signer(0)},
+			},
+			SignerSummary{SignerCount: 2, TotalWeight: 2, MeetsLow: true, MeetsMedium: true},
+		},
+		{
+			xdr.AccountEntry{
+				Thresholds: xdr.Thresholds([4]byte{0, 0, 0, 0}),
+			},
+			SignerSummary{},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.wantOutput, accountSignerSummary(test.account))
 	}
 }
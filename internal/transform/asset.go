@@ -11,7 +11,9 @@ import (
 )
 
 // TransformAsset converts an asset from a payment operation into a form suitable for BigQuery
-func TransformAsset(operation xdr.Operation, operationIndex int32, transactionIndex int32, ledgerSeq int32) (AssetOutput, error) {
+func TransformAsset(operation xdr.Operation, operationIndex int32, transactionIndex int32, ledgerSeq int32) (_ AssetOutput, err error) {
+	defer recoverPanic(&err)
+
 	operationID := toid.New(ledgerSeq, int32(transactionIndex), operationIndex).ToInt64()
 
 	opType := operation.Body.Type
@@ -79,6 +81,11 @@ func hashAsset(assetCode, assetIssuer string) (uint64, error) {
 	return hash, nil
 }
 
+// nativeAssetID is the asset id of lumens
+var nativeAssetID = FarmHashAsset("", "", "native")
+
+// FarmHashAsset returns the 64-bit fingerprint of an asset that is used as its asset_id, the same id as in the Hubble tables.
+// assetType is the type as returned by xdr.Asset.Extract, e.g. native or credit_alphanum4.
 func FarmHashAsset(assetCode, assetIssuer, assetType string) int64 {
 	asset := fmt.Sprintf("%s%s%s", assetCode, assetIssuer, assetType)
 	hash := farm.Fingerprint64([]byte(asset))
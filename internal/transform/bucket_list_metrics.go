@@ -0,0 +1,35 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+
+	"github.com/stellar/go/xdr"
+)
+
+// TransformBucketListMetrics reads the size of the bucket list and the evictions of a ledger from its close meta. Ledgers
+// closed before protocol 20 do not carry these metrics, so their row only has the sequence and close time.
+func TransformBucketListMetrics(lcm xdr.LedgerCloseMeta) (_ BucketListMetricsOutput, err error) {
+	defer recoverPanic(&err)
+
+	lhe := lcm.LedgerHeaderHistoryEntry()
+	outputSequence := uint32(lhe.Header.LedgerSeq)
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(lhe.Header.ScpValue.CloseTime)
+	if err != nil {
+		return BucketListMetricsOutput{}, fmt.Errorf("for ledger %d: %v", outputSequence, err)
+	}
+
+	metrics := BucketListMetricsOutput{
+		Sequence: outputSequence,
+		ClosedAt: outputCloseTime,
+	}
+
+	if v1, ok := lcm.GetV1(); ok {
+		metrics.BucketListSizeBytes = uint64(v1.TotalByteSizeOfBucketList)
+		metrics.EvictedTemporaryEntryCount = int32(len(v1.EvictedTemporaryLedgerKeys))
+		metrics.EvictedPersistentEntryCount = int32(len(v1.EvictedPersistentLedgerEntries))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,61 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformBucketListMetrics(t *testing.T) {
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1000,
+			},
+			LedgerSeq: 10,
+		},
+	}
+
+	tests := []struct {
+		input      xdr.LedgerCloseMeta
+		wantOutput BucketListMetricsOutput
+	}{
+		{
+			xdr.LedgerCloseMeta{
+				V: 1,
+				V1: &xdr.LedgerCloseMetaV1{
+					LedgerHeader:                   header,
+					TotalByteSizeOfBucketList:      123456,
+					EvictedTemporaryLedgerKeys:     []xdr.LedgerKey{{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.LedgerKeyTtl{}}},
+					EvictedPersistentLedgerEntries: []xdr.LedgerEntry{{}, {}},
+				},
+			},
+			BucketListMetricsOutput{
+				Sequence:                    10,
+				ClosedAt:                    time.Unix(1000, 0).UTC(),
+				BucketListSizeBytes:         123456,
+				EvictedTemporaryEntryCount:  1,
+				EvictedPersistentEntryCount: 2,
+			},
+		},
+		{
+			xdr.LedgerCloseMeta{
+				V:  0,
+				V0: &xdr.LedgerCloseMetaV0{LedgerHeader: header},
+			},
+			BucketListMetricsOutput{
+				Sequence: 10,
+				ClosedAt: time.Unix(1000, 0).UTC(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError := TransformBucketListMetrics(test.input)
+		assert.NoError(t, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
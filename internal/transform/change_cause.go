@@ -0,0 +1,159 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// Operation types of the ledger entry changes that are not caused by an operation
+const (
+	ChangeCauseTransactionFee = "transaction_fee"
+	ChangeCauseTransaction    = "transaction"
+	ChangeCauseUpgrade        = "upgrade"
+	ChangeCauseEviction       = "eviction"
+)
+
+// LedgerEntryChangeCauses returns the cause of the last change of every ledger entry changed in the ledger, keyed by
+// ChangeCauseKey. Changes are visited in the order they are applied: transaction fees, then the transactions and their
+// operations, then upgrades and evictions. When several changes of an entry are compacted together, the cause is the
+// one of the last change.
+func LedgerEntryChangeCauses(lcm xdr.LedgerCloseMeta, passphrase string) (map[string]LedgerEntryChangeCause, error) {
+	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(passphrase, lcm)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the transactions of ledger %d: %v", lcm.LedgerSequence(), err)
+	}
+	defer txReader.Close()
+
+	var transactions []ingest.LedgerTransaction
+	for {
+		tx, err := txReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read a transaction of ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	var upgrades []xdr.UpgradeEntryMeta
+	switch lcm.V {
+	case 0:
+		upgrades = lcm.MustV0().UpgradesProcessing
+	case 1:
+		upgrades = lcm.MustV1().UpgradesProcessing
+	}
+
+	evictedKeys, err := EvictedLedgerKeys(lcm)
+	if err != nil {
+		return nil, err
+	}
+
+	return changeCauses(transactions, upgrades, evictedKeys)
+}
+
+func changeCauses(transactions []ingest.LedgerTransaction, upgrades []xdr.UpgradeEntryMeta, evictedKeys []xdr.LedgerKey) (map[string]LedgerEntryChangeCause, error) {
+	causes := map[string]LedgerEntryChangeCause{}
+	setCause := func(changes xdr.LedgerEntryChanges, cause LedgerEntryChangeCause) error {
+		for _, change := range ingest.GetChangesFromLedgerEntryChanges(changes) {
+			key, err := ChangeCauseKey(change)
+			if err != nil {
+				return err
+			}
+			causes[key] = cause
+		}
+		return nil
+	}
+
+	// The fees of every transaction are charged before any transaction is applied
+	for _, tx := range transactions {
+		hash := utils.HashToHexString(tx.Result.TransactionHash)
+		if err := setCause(tx.FeeChanges, LedgerEntryChangeCause{TransactionHash: hash, OperationType: ChangeCauseTransactionFee}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tx := range transactions {
+		hash := utils.HashToHexString(tx.Result.TransactionHash)
+		txCause := LedgerEntryChangeCause{TransactionHash: hash, OperationType: ChangeCauseTransaction}
+		before, operations, after := transactionMetaChanges(tx.UnsafeMeta)
+
+		if err := setCause(before, txCause); err != nil {
+			return nil, err
+		}
+		txOperations := tx.Envelope.Operations()
+		for i, operation := range operations {
+			if i >= len(txOperations) {
+				return nil, fmt.Errorf("transaction %s has meta for more operations than its %d operations", hash, len(txOperations))
+			}
+			operationType, err := mapOperationType(txOperations[i])
+			if err != nil {
+				return nil, err
+			}
+			opCause := LedgerEntryChangeCause{TransactionHash: hash, OperationIndex: null.IntFrom(int64(i)), OperationType: operationType}
+			if err := setCause(operation.Changes, opCause); err != nil {
+				return nil, err
+			}
+		}
+		if err := setCause(after, txCause); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, upgrade := range upgrades {
+		if err := setCause(upgrade.Changes, LedgerEntryChangeCause{OperationType: ChangeCauseUpgrade}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range evictedKeys {
+		encoded, err := xdr.MarshalBase64(key)
+		if err != nil {
+			return nil, err
+		}
+		causes[encoded] = LedgerEntryChangeCause{OperationType: ChangeCauseEviction}
+	}
+
+	return causes, nil
+}
+
+// ChangeCauseKey returns the key of the changed entry in the causes returned by LedgerEntryChangeCauses, which is the
+// base64 encoding of its ledger key
+func ChangeCauseKey(change ingest.Change) (string, error) {
+	entry := change.Post
+	if entry == nil {
+		entry = change.Pre
+	}
+	if entry == nil {
+		return "", fmt.Errorf("change of type %s has no entry", change.Type)
+	}
+
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return "", err
+	}
+	return xdr.MarshalBase64(key)
+}
+
+// transactionMetaChanges returns the changes applied before the operations of the transaction, the changes of each
+// operation and the changes applied after them
+func transactionMetaChanges(meta xdr.TransactionMeta) (xdr.LedgerEntryChanges, []xdr.OperationMeta, xdr.LedgerEntryChanges) {
+	switch meta.V {
+	case 0:
+		return nil, *meta.Operations, nil
+	case 1:
+		return meta.V1.TxChanges, meta.V1.Operations, nil
+	case 2:
+		return meta.V2.TxChangesBefore, meta.V2.Operations, meta.V2.TxChangesAfter
+	case 3:
+		return meta.V3.TxChangesBefore, meta.V3.Operations, meta.V3.TxChangesAfter
+	default:
+		return nil, nil, nil
+	}
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func TestChangeCauses(t *testing.T) {
+	account := func(id xdr.AccountId, balance xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:    xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{AccountId: id, Balance: balance},
+			},
+		}
+	}
+	data := &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{AccountId: testAccount1ID, DataName: "test"},
+		},
+	}
+	updated := func(pre, post *xdr.LedgerEntry) xdr.LedgerEntryChanges {
+		return xdr.LedgerEntryChanges{
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: pre},
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: post},
+		}
+	}
+
+	transaction := ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type:         xdr.OperationTypeManageData,
+								ManageDataOp: &xdr.ManageDataOp{DataName: "test"},
+							},
+						},
+					},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{TransactionHash: xdr.Hash{1}},
+		// the fee is charged, then the sequence number is bumped before the operation creates the data entry
+		FeeChanges: updated(account(testAccount1ID, 1000), account(testAccount1ID, 900)),
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 2,
+			V2: &xdr.TransactionMetaV2{
+				TxChangesBefore: updated(account(testAccount1ID, 900), account(testAccount1ID, 900)),
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: data},
+						},
+					},
+				},
+			},
+		},
+	}
+	upgrades := []xdr.UpgradeEntryMeta{
+		{Changes: updated(account(testAccount2ID, 1000), account(testAccount2ID, 2000))},
+	}
+	var evicted xdr.LedgerKey
+	err := evicted.SetTtl(xdr.Hash{2})
+	assert.NoError(t, err)
+
+	actualCauses, err := changeCauses([]ingest.LedgerTransaction{transaction}, upgrades, []xdr.LedgerKey{evicted})
+	assert.NoError(t, err)
+
+	causeKey := func(entry *xdr.LedgerEntry) string {
+		key, err := ChangeCauseKey(ingest.Change{Type: entry.Data.Type, Post: entry})
+		assert.NoError(t, err)
+		return key
+	}
+	evictedKey, err := xdr.MarshalBase64(evicted)
+	assert.NoError(t, err)
+	hash := utils.HashToHexString(xdr.Hash{1})
+
+	assert.Equal(t, map[string]LedgerEntryChangeCause{
+		causeKey(account(testAccount1ID, 0)): {TransactionHash: hash, OperationType: ChangeCauseTransaction},
+		causeKey(data):                       {TransactionHash: hash, OperationIndex: null.IntFrom(0), OperationType: "manage_data"},
+		causeKey(account(testAccount2ID, 0)): {OperationType: ChangeCauseUpgrade},
+		evictedKey:                           {OperationType: ChangeCauseEviction},
+	}, actualCauses)
+}
@@ -8,16 +8,30 @@ import (
 	"github.com/stellar/stellar-etl/internal/utils"
 )
 
-func transformClaimants(claimants []xdr.Claimant) []Claimant {
+func transformClaimants(claimants []xdr.Claimant) ([]Claimant, error) {
 	var transformed []Claimant
 	for _, c := range claimants {
 		cv0 := c.MustV0()
+		predicateJSON, err := utils.XdrJSON.ClaimPredicate(cv0.Predicate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid predicate of claimant %s: %v", cv0.Destination.Address(), err)
+		}
 		transformed = append(transformed, Claimant{
-			Destination: cv0.Destination.Address(),
-			Predicate:   cv0.Predicate,
+			Destination:   cv0.Destination.Address(),
+			Predicate:     cv0.Predicate,
+			PredicateJSON: predicateJSON,
 		})
 	}
-	return transformed
+	return transformed, nil
+}
+
+// claimantAddresses returns the accounts that can claim a balance, for queries that do not need the predicates
+func claimantAddresses(claimants []xdr.Claimant) []string {
+	addresses := make([]string, 0, len(claimants))
+	for _, c := range claimants {
+		addresses = append(addresses, c.MustV0().Destination.Address())
+	}
+	return addresses
 }
 
 // TransformClaimableBalance converts a claimable balance from the history archive ingestion system into a form suitable for BigQuery
@@ -40,7 +54,10 @@ func TransformClaimableBalance(ledgerChange ingest.Change, header xdr.LedgerHead
 	if err != nil {
 		return ClaimableBalanceOutput{}, err
 	}
-	outputClaimants := transformClaimants(balanceEntry.Claimants)
+	outputClaimants, err := transformClaimants(balanceEntry.Claimants)
+	if err != nil {
+		return ClaimableBalanceOutput{}, err
+	}
 	outputAmount := balanceEntry.Amount
 
 	outputLastModifiedLedger := uint32(ledgerEntry.LastModifiedLedgerSeq)
@@ -112,6 +112,7 @@ func makeClaimableBalanceTestOutput() ClaimableBalanceOutput {
 				Predicate: xdr.ClaimPredicate{
 					Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional,
 				},
+				PredicateJSON: "unconditional",
 			},
 		},
 		AssetIssuer:        "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
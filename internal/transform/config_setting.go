@@ -9,6 +9,24 @@ import (
 	"github.com/stellar/stellar-etl/internal/utils"
 )
 
+// transformedConfigSettingIds are the config settings whose values have columns. The other settings, such as the
+// eviction iterator, only get their id written.
+var transformedConfigSettingIds = map[xdr.ConfigSettingId]bool{
+	xdr.ConfigSettingIdConfigSettingContractMaxSizeBytes:              true,
+	xdr.ConfigSettingIdConfigSettingContractComputeV0:                 true,
+	xdr.ConfigSettingIdConfigSettingContractLedgerCostV0:              true,
+	xdr.ConfigSettingIdConfigSettingContractHistoricalDataV0:          true,
+	xdr.ConfigSettingIdConfigSettingContractEventsV0:                  true,
+	xdr.ConfigSettingIdConfigSettingContractBandwidthV0:               true,
+	xdr.ConfigSettingIdConfigSettingContractCostParamsCpuInstructions: true,
+	xdr.ConfigSettingIdConfigSettingContractCostParamsMemoryBytes:     true,
+	xdr.ConfigSettingIdConfigSettingContractDataKeySizeBytes:          true,
+	xdr.ConfigSettingIdConfigSettingContractDataEntrySizeBytes:        true,
+	xdr.ConfigSettingIdConfigSettingStateArchival:                     true,
+	xdr.ConfigSettingIdConfigSettingContractExecutionLanes:            true,
+	xdr.ConfigSettingIdConfigSettingBucketlistSizeWindow:              true,
+}
+
 // TransformConfigSetting converts an config setting ledger change entry into a form suitable for BigQuery
 func TransformConfigSetting(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (ConfigSettingOutput, error) {
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
@@ -22,6 +40,9 @@ func TransformConfigSetting(ledgerChange ingest.Change, header xdr.LedgerHeaderH
 	}
 
 	configSettingId := configSetting.ConfigSettingId
+	if utils.StrictSchema && !transformedConfigSettingIds[configSettingId] {
+		return ConfigSettingOutput{}, utils.UnknownVariantError{Kind: "config setting", Value: configSettingId.String()}
+	}
 
 	contractMaxSizeBytes, _ := configSetting.GetContractMaxSizeBytes()
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/utils"
 )
 
 func TestTransformConfigSetting(t *testing.T) {
@@ -58,6 +60,31 @@ func TestTransformConfigSetting(t *testing.T) {
 	}
 }
 
+func TestTransformConfigSettingStrictSchema(t *testing.T) {
+	change := ingest.Change{
+		Type: xdr.LedgerEntryTypeConfigSetting,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeConfigSetting,
+				ConfigSetting: &xdr.ConfigSettingEntry{
+					ConfigSettingId:  xdr.ConfigSettingIdConfigSettingEvictionIterator,
+					EvictionIterator: &xdr.EvictionIterator{},
+				},
+			},
+		},
+	}
+	header := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 10}}
+
+	output, err := TransformConfigSetting(change, header)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(xdr.ConfigSettingIdConfigSettingEvictionIterator), output.ConfigSettingId)
+
+	utils.StrictSchema = true
+	defer func() { utils.StrictSchema = false }()
+	_, err = TransformConfigSetting(change, header)
+	assert.Equal(t, utils.UnknownVariantError{Kind: "config setting", Value: xdr.ConfigSettingIdConfigSettingEvictionIterator.String()}, err)
+}
+
 func makeConfigSettingTestInput() []ingest.Change {
 	var contractMaxByte xdr.Uint32 = 0
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/guregu/null"
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/xdr"
@@ -30,7 +31,7 @@ var (
 )
 
 type AssetFromContractDataFunc func(ledgerEntry xdr.LedgerEntry, passphrase string) *xdr.Asset
-type ContractBalanceFromContractDataFunc func(ledgerEntry xdr.LedgerEntry, passphrase string) ([32]byte, *big.Int, bool)
+type ContractBalanceFromContractDataFunc func(ledgerEntry xdr.LedgerEntry, passphrase string) (xdr.ScAddress, *big.Int, bool)
 
 type TransformContractDataStruct struct {
 	AssetFromContractData           AssetFromContractDataFunc
@@ -66,12 +67,18 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 	var contractDataAssetType string
 	var contractDataAssetCode string
 	var contractDataAssetIssuer string
+	var contractDataAssetID null.Int
 
 	contractDataAsset := t.AssetFromContractData(ledgerEntry, passphrase)
 	if contractDataAsset != nil {
 		contractDataAssetType = contractDataAsset.Type.String()
 		contractDataAssetCode = contractDataAsset.GetCode()
 		contractDataAssetIssuer = contractDataAsset.GetIssuer()
+
+		var assetType, code, issuer string
+		if err := contractDataAsset.Extract(&assetType, &code, &issuer); err == nil {
+			contractDataAssetID = null.IntFrom(FarmHashAsset(code, issuer, assetType))
+		}
 	}
 
 	var contractDataBalanceHolder string
@@ -79,8 +86,7 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 
 	dataBalanceHolder, dataBalance, _ := t.ContractBalanceFromContractData(ledgerEntry, passphrase)
 	if dataBalance != nil {
-		holderHashByte, _ := xdr.Hash(dataBalanceHolder).MarshalBinary()
-		contractDataBalanceHolder, _ = strkey.Encode(strkey.VersionByteContract, holderHashByte)
+		contractDataBalanceHolder, _ = utils.ScAddressToStrkey(dataBalanceHolder)
 		contractDataBalance = dataBalance.String()
 	}
 
@@ -109,6 +115,7 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 		ContractDataAssetCode:     contractDataAssetCode,
 		ContractDataAssetIssuer:   contractDataAssetIssuer,
 		ContractDataAssetType:     contractDataAssetType,
+		ContractDataAssetID:       contractDataAssetID,
 		ContractDataBalanceHolder: contractDataBalanceHolder,
 		ContractDataBalance:       contractDataBalance,
 		LastModifiedLedger:        uint32(ledgerEntry.LastModifiedLedgerSeq),
@@ -121,6 +128,14 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 	return transformedData, nil, true
 }
 
+// JoinContractDataTtl fills the ttl columns of the contract data with the live until ledger of its ttl entry.
+// The entry is expired if its ttl ended before the ledger the contract data was exported at.
+func JoinContractDataTtl(contractData ContractDataOutput, liveUntilLedgerSeq uint32) ContractDataOutput {
+	contractData.LiveUntilLedgerSeq = null.IntFrom(int64(liveUntilLedgerSeq))
+	contractData.Expired = null.BoolFrom(liveUntilLedgerSeq < contractData.LedgerSequence)
+	return contractData
+}
+
 // AssetFromContractData takes a ledger entry and verifies if the ledger entry
 // corresponds to the asset info entry written to contract storage by the Stellar
 // Asset Contract upon initialization.
@@ -262,29 +277,30 @@ func AssetFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) *xdr.
 
 // ContractBalanceFromContractData takes a ledger entry and verifies that the
 // ledger entry corresponds to the balance entry written to contract storage by
-// the Stellar Asset Contract.
+// the Stellar Asset Contract. It returns the address holding the balance along
+// with the amount.
 //
 // Reference:
 //
 //	https://github.com/stellar/rs-soroban-env/blob/da325551829d31dcbfa71427d51c18e71a121c5f/soroban-env-host/src/native_contract/token/storage_types.rs#L11-L24
-func ContractBalanceFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) ([32]byte, *big.Int, bool) {
+func ContractBalanceFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) (xdr.ScAddress, *big.Int, bool) {
 	contractData, ok := ledgerEntry.Data.GetContractData()
 	if !ok {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	_, err := xdr.MustNewNativeAsset().ContractID(passphrase)
 	if err != nil {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	if contractData.Contract.ContractId == nil {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	keyEnumVecPtr, ok := contractData.Key.GetVec()
 	if !ok || keyEnumVecPtr == nil {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 	keyEnumVec := *keyEnumVecPtr
 	if len(keyEnumVec) != 2 || !keyEnumVec[0].Equals(
@@ -293,51 +309,50 @@ func ContractBalanceFromContractData(ledgerEntry xdr.LedgerEntry, passphrase str
 			Sym:  &balanceMetadataSym,
 		},
 	) {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	scAddress, ok := keyEnumVec[1].GetAddress()
 	if !ok {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
-	holder, ok := scAddress.GetContractId()
-	if !ok {
-		return [32]byte{}, nil, false
+	// balances can be held by both accounts and contracts
+	if scAddress.Type != xdr.ScAddressTypeScAddressTypeAccount &&
+		scAddress.Type != xdr.ScAddressTypeScAddressTypeContract {
+		return xdr.ScAddress{}, nil, false
 	}
 
 	balanceMapPtr, ok := contractData.Val.GetMap()
 	if !ok || balanceMapPtr == nil {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 	balanceMap := *balanceMapPtr
 	if !ok || len(balanceMap) != 3 {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	var keySym xdr.ScSymbol
 	if keySym, ok = balanceMap[0].Key.GetSym(); !ok || keySym != "amount" {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 	if keySym, ok = balanceMap[1].Key.GetSym(); !ok || keySym != "authorized" ||
 		!balanceMap[1].Val.IsBool() {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 	if keySym, ok = balanceMap[2].Key.GetSym(); !ok || keySym != "clawback" ||
 		!balanceMap[2].Val.IsBool() {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 	amount, ok := balanceMap[0].Val.GetI128()
 	if !ok {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
 
 	// amount cannot be negative
 	// https://github.com/stellar/rs-soroban-env/blob/a66f0815ba06a2f5328ac420950690fd1642f887/soroban-env-host/src/native_contract/token/balance.rs#L92-L93
 	if int64(amount.Hi) < 0 {
-		return [32]byte{}, nil, false
+		return xdr.ScAddress{}, nil, false
 	}
-	amt := new(big.Int).Lsh(new(big.Int).SetInt64(int64(amount.Hi)), 64)
-	amt.Add(amt, new(big.Int).SetUint64(uint64(amount.Lo)))
-	return holder, amt, true
+	return scAddress, utils.Int128ToBigInt(amount), true
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
@@ -69,9 +70,9 @@ func MockAssetFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) *
 	}
 }
 
-func MockContractBalanceFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) ([32]byte, *big.Int, bool) {
-	var holder [32]byte
-	return holder, big.NewInt(0), true
+func MockContractBalanceFromContractData(ledgerEntry xdr.LedgerEntry, passphrase string) (xdr.ScAddress, *big.Int, bool) {
+	var holder xdr.Hash
+	return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &holder}, big.NewInt(0), true
 }
 
 func makeContractDataTestInput() []ingest.Change {
@@ -131,6 +132,7 @@ func makeContractDataTestOutput() []ContractDataOutput {
 			ContractDataAssetCode:     "",
 			ContractDataAssetIssuer:   "",
 			ContractDataAssetType:     "AssetTypeAssetTypeNative",
+			ContractDataAssetID:       null.IntFrom(-5706705804583548011),
 			ContractDataBalanceHolder: "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4",
 			ContractDataBalance:       "0",
 			LastModifiedLedger:        24229503,
@@ -142,3 +144,25 @@ func makeContractDataTestOutput() []ContractDataOutput {
 		},
 	}
 }
+
+func TestJoinContractDataTtl(t *testing.T) {
+	type joinTest struct {
+		liveUntilLedgerSeq uint32
+		wantExpired        bool
+	}
+
+	tests := []joinTest{
+		{liveUntilLedgerSeq: 9, wantExpired: true},
+		{liveUntilLedgerSeq: 10, wantExpired: false},
+		{liveUntilLedgerSeq: 100, wantExpired: false},
+	}
+
+	for _, test := range tests {
+		contractData := makeContractDataTestOutput()[0]
+		wantOutput := contractData
+		wantOutput.LiveUntilLedgerSeq = null.IntFrom(int64(test.liveUntilLedgerSeq))
+		wantOutput.Expired = null.BoolFrom(test.wantExpired)
+
+		assert.Equal(t, wantOutput, JoinContractDataTtl(contractData, test.liveUntilLedgerSeq))
+	}
+}
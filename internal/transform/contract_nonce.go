@@ -0,0 +1,57 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// TransformContractNonce converts a contract data ledger change entry that stores the nonce of an address into a form
+// suitable for BigQuery. The returned bool is false when the contract data entry is not a nonce.
+func TransformContractNonce(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (ContractNonceOutput, error, bool) {
+	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
+	if err != nil {
+		return ContractNonceOutput{}, err, false
+	}
+
+	contractData, ok := ledgerEntry.Data.GetContractData()
+	if !ok {
+		return ContractNonceOutput{}, fmt.Errorf("could not extract contract data from ledger entry; actual type is %s", ledgerEntry.Data.Type), false
+	}
+
+	nonceKey, ok := contractData.Key.GetNonceKey()
+	if !ok {
+		return ContractNonceOutput{}, nil, false
+	}
+
+	address, err := utils.ScAddressToStrkey(contractData.Contract)
+	if err != nil {
+		return ContractNonceOutput{}, err, false
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return ContractNonceOutput{}, err, false
+	}
+
+	transformedNonce := ContractNonceOutput{
+		Address:            address,
+		Nonce:              int64(nonceKey.Nonce),
+		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:  uint32(changeType),
+		Deleted:            outputDeleted,
+		ClosedAt:           closedAt,
+		LedgerSequence:     uint32(header.Header.LedgerSeq),
+		LedgerKeyHash:      utils.LedgerEntryToLedgerKeyHash(ledgerEntry),
+	}
+	return transformedNonce, nil, true
+}
+
+// JoinContractNonceTtl fills the live_until_ledger_seq column of the nonce with the live until ledger of its ttl entry
+func JoinContractNonceTtl(contractNonce ContractNonceOutput, liveUntilLedgerSeq uint32) ContractNonceOutput {
+	contractNonce.LiveUntilLedgerSeq = null.IntFrom(int64(liveUntilLedgerSeq))
+	return contractNonce
+}
@@ -0,0 +1,110 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformContractNonce(t *testing.T) {
+	type transformTest struct {
+		input      ingest.Change
+		wantOutput ContractNonceOutput
+		wantErr    error
+		wantNonce  bool
+	}
+
+	contractDataInput := makeContractDataTestInput()[0]
+	tests := []transformTest{
+		{
+			ingest.Change{
+				Type: xdr.LedgerEntryTypeOffer,
+				Post: &xdr.LedgerEntry{
+					Data: xdr.LedgerEntryData{
+						Type: xdr.LedgerEntryTypeOffer,
+					},
+				},
+			},
+			ContractNonceOutput{}, fmt.Errorf("could not extract contract data from ledger entry; actual type is LedgerEntryTypeOffer"), false,
+		},
+		{
+			contractDataInput,
+			ContractNonceOutput{}, nil, false,
+		},
+		{
+			makeContractNonceTestInput(),
+			makeContractNonceTestOutput(), nil, true,
+		},
+	}
+
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1000,
+			},
+			LedgerSeq: 10,
+		},
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError, actualNonce := TransformContractNonce(test.input, header)
+		assert.Equal(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantNonce, actualNonce)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeContractNonceTestInput() ingest.Change {
+	accountID := xdr.MustAddress(testAccount1Address)
+
+	return ingest.Change{
+		Type: xdr.LedgerEntryTypeContractData,
+		Post: &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 8,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeContractData,
+				ContractData: &xdr.ContractDataEntry{
+					Contract: xdr.ScAddress{
+						Type:      xdr.ScAddressTypeScAddressTypeAccount,
+						AccountId: &accountID,
+					},
+					Key: xdr.ScVal{
+						Type:     xdr.ScValTypeScvLedgerKeyNonce,
+						NonceKey: &xdr.ScNonceKey{Nonce: 42},
+					},
+					Durability: xdr.ContractDataDurabilityTemporary,
+					Val: xdr.ScVal{
+						Type: xdr.ScValTypeScvVoid,
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeContractNonceTestOutput() ContractNonceOutput {
+	return ContractNonceOutput{
+		Address:            testAccount1Address,
+		Nonce:              42,
+		LastModifiedLedger: 8,
+		LedgerEntryChange:  0,
+		Deleted:            false,
+		ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+		LedgerSequence:     10,
+		LedgerKeyHash:      "1058f3e317370a61eae88dc4fcba80f43333d4acac8e1b268a48a74c493bc0ee",
+	}
+}
+
+func TestJoinContractNonceTtl(t *testing.T) {
+	contractNonce := makeContractNonceTestOutput()
+	wantOutput := contractNonce
+	wantOutput.LiveUntilLedgerSeq = null.IntFrom(20)
+
+	assert.Equal(t, wantOutput, JoinContractNonceTtl(contractNonce, 20))
+}
@@ -0,0 +1,536 @@
+package transform
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/guregu/null/zero"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// OutputSchemas maps the name of each exported table to an empty instance of its output struct
+var OutputSchemas = map[string]interface{}{
+	"ledgers":             LedgerOutput{},
+	"ledger_stats":        LedgerStatsOutput{},
+	"bucket_list_metrics": BucketListMetricsOutput{},
+	"transactions":        TransactionOutput{},
+	"operations":          OperationOutput{},
+	"participants":        ParticipantOutput{},
+	"effects":             EffectOutput{},
+	"trades":              TradeOutput{},
+	"assets":              AssetOutput{},
+	"diagnostic_events":   DiagnosticEventOutput{},
+	"tx_footprints":       TransactionFootprintOutput{},
+	"accounts":            AccountOutput{},
+	"signers":             AccountSignerOutput{},
+	"account_data":        AccountDataOutput{},
+	"claimable_balances":  ClaimableBalanceOutput{},
+	"offers":              OfferOutput{},
+	"trustlines":          TrustlineOutput{},
+	"liquidity_pools":     PoolOutput{},
+	"contract_data":       ContractDataOutput{},
+	// temporary contract data is written to its own table with --split-temporary-contract-data
+	"contract_data_temporary": ContractDataOutput{},
+	"contract_nonces":         ContractNonceOutput{},
+	"contract_code":           ContractCodeOutput{},
+	"config_settings":         ConfigSettingOutput{},
+	"ttl":                     TtlOutput{},
+	"evicted_entries":         EvictedEntryOutput{},
+}
+
+// LedgerEntryChangeTables are the tables exported by export_ledger_entry_changes, whose rows also have the columns of
+// LedgerEntryChangeCause
+var LedgerEntryChangeTables = map[string]bool{
+	"accounts":                true,
+	"signers":                 true,
+	"account_data":            true,
+	"claimable_balances":      true,
+	"offers":                  true,
+	"trustlines":              true,
+	"liquidity_pools":         true,
+	"contract_data":           true,
+	"contract_data_temporary": true,
+	"contract_nonces":         true,
+	"contract_code":           true,
+	"config_settings":         true,
+	"ttl":                     true,
+}
+
+// columnKind is the warehouse agnostic type of an output column
+type columnKind int
+
+const (
+	columnString columnKind = iota
+	columnInteger
+	columnUnsignedInteger
+	columnFloat
+	columnBoolean
+	columnTimestamp
+	columnJSON
+)
+
+// column is a single column of an output table. Columns tagged with bigquery:"partition" or bigquery:"cluster" are the
+// recommended partitioning and clustering columns of BigQuery tables.
+type column struct {
+	name      string
+	kind      columnKind
+	partition bool
+	cluster   bool
+}
+
+// DialectDuckDB and DialectSQLite are the dialects of the local databases that exports can append their rows to
+const (
+	DialectDuckDB = "duckdb"
+	DialectSQLite = "sqlite"
+)
+
+// warehouseTypes maps each column kind to the type name used by a warehouse
+var warehouseTypes = map[string]map[columnKind]string{
+	utils.WarehouseBigQuery: {
+		columnString:          "STRING",
+		columnInteger:         "INT64",
+		columnUnsignedInteger: "NUMERIC",
+		columnFloat:           "FLOAT64",
+		columnBoolean:         "BOOL",
+		columnTimestamp:       "TIMESTAMP",
+		columnJSON:            "JSON",
+	},
+	utils.WarehouseSnowflake: {
+		columnString:          "VARCHAR",
+		columnInteger:         "NUMBER(38, 0)",
+		columnUnsignedInteger: "NUMBER(38, 0)",
+		columnFloat:           "FLOAT",
+		columnBoolean:         "BOOLEAN",
+		columnTimestamp:       "TIMESTAMP_TZ",
+		columnJSON:            "VARIANT",
+	},
+	utils.WarehouseRedshift: {
+		columnString:          "VARCHAR(65535)",
+		columnInteger:         "BIGINT",
+		columnUnsignedInteger: "NUMERIC(20, 0)",
+		columnFloat:           "DOUBLE PRECISION",
+		columnBoolean:         "BOOLEAN",
+		columnTimestamp:       "TIMESTAMPTZ",
+		columnJSON:            "SUPER",
+	},
+	utils.WarehouseAthena: {
+		columnString:          "string",
+		columnInteger:         "bigint",
+		columnUnsignedInteger: "decimal(20, 0)",
+		columnFloat:           "double",
+		columnBoolean:         "boolean",
+		columnTimestamp:       "timestamp",
+		columnJSON:            "string",
+	},
+	DialectDuckDB: {
+		columnString:          "VARCHAR",
+		columnInteger:         "BIGINT",
+		columnUnsignedInteger: "UBIGINT",
+		columnFloat:           "DOUBLE",
+		columnBoolean:         "BOOLEAN",
+		columnTimestamp:       "TIMESTAMPTZ",
+		columnJSON:            "JSON",
+	},
+	// SQLite integers are signed 64 bit, so unsigned ones are kept as text rather than stored as lossy REAL values
+	DialectSQLite: {
+		columnString:          "TEXT",
+		columnInteger:         "INTEGER",
+		columnUnsignedInteger: "TEXT",
+		columnFloat:           "REAL",
+		columnBoolean:         "INTEGER",
+		columnTimestamp:       "TEXT",
+		columnJSON:            "TEXT",
+	},
+}
+
+// DDLOptions are the export flags that change the columns of the exported files
+type DDLOptions struct {
+	// TimestampFormat is the --timestamp-format of the files. Epoch timestamps are declared as integers.
+	TimestampFormat string
+	// AssetContractIDs is set by --asset-contract-ids, which adds an asset_contract_id column next to the string
+	// asset_type columns
+	AssetContractIDs bool
+	// IncludeXDR is set by --include-xdr, which adds the operation_body_xdr column to operations and the
+	// ledger_entry_xdr column to ledger entry changes
+	IncludeXDR bool
+	// WellKnownAssets is set by --well-known-assets, which adds the asset_domain, asset_anchor_name and asset_decimals
+	// columns next to every asset_type column
+	WellKnownAssets bool
+	// AddressLabels is set by --address-labels, which adds a <column>_label column next to every address column
+	AddressLabels bool
+	// BatchMetadata is set by --batch-metadata, which adds the batch_id, batch_run_date, batch_insert_ts and etl_version
+	// columns to every table
+	BatchMetadata bool
+}
+
+// addressColumns are the columns holding an address that are not named after an account, such as the destination of a
+// payment or the funder of a sponsored reserve
+var addressColumns = map[string]bool{
+	"to":          true,
+	"from":        true,
+	"funder":      true,
+	"trustor":     true,
+	"trustee":     true,
+	"sponsor":     true,
+	"contract_id": true,
+}
+
+// IsAddressColumn reports whether a column holds an address that --address-labels labels: the columns named after an
+// account, such as source_account, and the ones in addressColumns
+func IsAddressColumn(name string) bool {
+	return strings.HasSuffix(name, "account") || addressColumns[name]
+}
+
+// athenaPartitionColumns are the Hive partition keys written by --partition-layout hive
+var athenaPartitionColumns = []string{"dt string", "ledger_range string"}
+
+// tableColumns returns the columns of the provided output struct in field order, keyed by their json names
+func tableColumns(output interface{}) []column {
+	return structColumns(reflect.TypeOf(output))
+}
+
+func structColumns(t reflect.Type) []column {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	columns := []column{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			columns = append(columns, structColumns(field.Type)...)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		bigqueryTag := field.Tag.Get("bigquery")
+		columns = append(columns, column{
+			name:      name,
+			kind:      kindOf(field.Type),
+			partition: bigqueryTag == "partition",
+			cluster:   bigqueryTag == "cluster",
+		})
+	}
+
+	return columns
+}
+
+func kindOf(t reflect.Type) columnKind {
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(null.Time{}), reflect.TypeOf(zero.Time{}):
+		return columnTimestamp
+	case reflect.TypeOf(null.String{}), reflect.TypeOf(zero.String{}):
+		return columnString
+	case reflect.TypeOf(null.Int{}), reflect.TypeOf(zero.Int{}):
+		return columnInteger
+	case reflect.TypeOf(null.Float{}), reflect.TypeOf(zero.Float{}):
+		return columnFloat
+	case reflect.TypeOf(null.Bool{}), reflect.TypeOf(zero.Bool{}):
+		return columnBoolean
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return columnString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return columnInteger
+	case reflect.Uint, reflect.Uint64:
+		return columnUnsignedInteger
+	case reflect.Float32, reflect.Float64:
+		return columnFloat
+	case reflect.Bool:
+		return columnBoolean
+	}
+
+	return columnJSON
+}
+
+// GenerateDDL builds the CREATE TABLE statement for the provided table in the dialect of the warehouse.
+// Column names are normalized to lower_snake_case to match the exported files. Athena tables are external
+// Glue tables partitioned by dt and ledger_range, which read the files found under location. BigQuery tables
+// are created in the dataset given as location, partitioned by day and clustered by their tagged columns. The columns
+// match the files exported with the flags of options.
+func GenerateDDL(warehouse, table, location string, options DDLOptions) (string, error) {
+	if warehouse == utils.WarehouseBigQueryAlias {
+		warehouse = utils.WarehouseBigQuery
+	}
+
+	if _, ok := OutputSchemas[table]; !ok {
+		return "", fmt.Errorf("unknown table %s; must be one of %s", table, strings.Join(TableNames(), ", "))
+	}
+
+	typeNames, ok := warehouseTypes[warehouse]
+	if !ok {
+		return "", fmt.Errorf("DDL generation is not supported for warehouse %q", warehouse)
+	}
+
+	columns := schemaColumns(table, options)
+	definitions := make([]string, len(columns))
+	for i, col := range columns {
+		definitions[i] = fmt.Sprintf("    %s %s", utils.ToLowerSnakeCase(col.name), typeNames[col.kind])
+	}
+
+	if warehouse == utils.WarehouseAthena {
+		if location == "" {
+			return "", fmt.Errorf("a location is required to generate an Athena table")
+		}
+
+		return fmt.Sprintf(`CREATE EXTERNAL TABLE IF NOT EXISTS %s (
+%s
+)
+PARTITIONED BY (%s)
+ROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'
+WITH SERDEPROPERTIES ('timestamp.formats' = "yyyy-MM-dd'T'HH:mm:ss'Z',yyyy-MM-dd'T'HH:mm:ss.SSSSSSSSS'Z'")
+LOCATION '%s';
+`, table, strings.Join(definitions, ",\n"), strings.Join(athenaPartitionColumns, ", "), strings.TrimSuffix(location, "/")+"/"), nil
+	}
+
+	if warehouse == utils.WarehouseBigQuery {
+		return bigQueryDDL(table, location, columns, definitions), nil
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);\n", table, strings.Join(definitions, ",\n")), nil
+}
+
+// bigQueryDDL builds the CREATE TABLE statement of a BigQuery table, with the partitioning and clustering of its tagged
+// columns. Tables are partitioned by the UTC day their ledgers closed, so that range queries only scan those days.
+// Tables of epoch timestamps are not partitioned, since BigQuery only partitions integers by fixed ranges.
+func bigQueryDDL(table, dataset string, columns []column, definitions []string) string {
+	name := table
+	if dataset != "" {
+		name = dataset + "." + table
+	}
+
+	ddl := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n)", name, strings.Join(definitions, ",\n"))
+	clustering := []string{}
+	for _, col := range columns {
+		if col.partition && col.kind == columnTimestamp {
+			ddl += fmt.Sprintf("\nPARTITION BY DATE(%s)", utils.ToLowerSnakeCase(col.name))
+		}
+		if col.cluster {
+			clustering = append(clustering, utils.ToLowerSnakeCase(col.name))
+		}
+	}
+	if len(clustering) > 0 {
+		ddl += "\nCLUSTER BY " + strings.Join(clustering, ", ")
+	}
+
+	return ddl + ";\n"
+}
+
+// DuckDBAppend builds the statements that create the table in a DuckDB database, unless it exists already, and append
+// the rows of the newline delimited JSON file at path to it. Keys of the file that are not columns of the output schema,
+// such as the columns of --extra-fields, are not loaded.
+func DuckDBAppend(table, path string, options DDLOptions) (string, error) {
+	ddl, err := GenerateDDL(DialectDuckDB, table, "", options)
+	if err != nil {
+		return "", err
+	}
+
+	return ddl + fmt.Sprintf("INSERT INTO %s BY NAME SELECT * FROM %s;\n", table, duckDBReadJSON(table, path, options)), nil
+}
+
+// parquetCodecs maps the compression of a table in the config file to the codec of its parquet files
+var parquetCodecs = map[string]string{
+	"":     "SNAPPY",
+	"none": "UNCOMPRESSED",
+	"gzip": "GZIP",
+	"zstd": "ZSTD",
+}
+
+// DuckDBParquet builds the statement that converts the newline delimited JSON file at path into the parquet file at
+// parquetPath with the DuckDB CLI, compressing its pages with the codec matching compression. Like DuckDBAppend, keys
+// of the file that are not columns of the output schema are not converted.
+func DuckDBParquet(table, path, parquetPath, compression string, options DDLOptions) (string, error) {
+	if _, ok := OutputSchemas[table]; !ok {
+		return "", fmt.Errorf("unknown table %s; must be one of %s", table, strings.Join(TableNames(), ", "))
+	}
+	codec, ok := parquetCodecs[compression]
+	if !ok {
+		return "", fmt.Errorf("unknown compression %s", compression)
+	}
+
+	return fmt.Sprintf("COPY (SELECT * FROM %s) TO '%s' (FORMAT PARQUET, COMPRESSION %s);\n",
+		duckDBReadJSON(table, path, options), strings.ReplaceAll(parquetPath, "'", "''"), codec), nil
+}
+
+// duckDBReadJSON returns the DuckDB table function reading the rows of the newline delimited JSON file at path, typed
+// with the columns of the output schema
+func duckDBReadJSON(table, path string, options DDLOptions) string {
+	columns := schemaColumns(table, options)
+	types := make([]string, len(columns))
+	for i, col := range columns {
+		types[i] = fmt.Sprintf("'%s': '%s'", utils.ToLowerSnakeCase(col.name), warehouseTypes[DialectDuckDB][col.kind])
+	}
+
+	return fmt.Sprintf("read_json('%s', format = 'newline_delimited', columns = {%s})", strings.ReplaceAll(path, "'", "''"), strings.Join(types, ", "))
+}
+
+// SQLiteAppend writes the statements that create the table in a SQLite database, unless it exists already, and append
+// the rows of newline delimited JSON to it, inserting batchRows rows per statement. Each column is extracted from the
+// JSON of its row, so keys that are not columns of the output schema are not loaded. The statements run in a single
+// transaction, so a file whose append fails leaves no rows behind and can be appended again.
+func SQLiteAppend(w io.Writer, table string, rows io.Reader, batchRows int, options DDLOptions) error {
+	ddl, err := GenerateDDL(DialectSQLite, table, "", options)
+	if err != nil {
+		return err
+	}
+
+	columns := schemaColumns(table, options)
+	names := make([]string, len(columns))
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = utils.ToLowerSnakeCase(col.name)
+		values[i] = fmt.Sprintf("json_extract(row, '$.%s')", names[i])
+		if col.kind == columnUnsignedInteger {
+			// json_extract reads integers above 2^63 as REAL, so the JSON text of the number is kept instead
+			values[i] = fmt.Sprintf("nullif(row -> '$.%s', 'null')", names[i])
+		}
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM (SELECT column1 AS row FROM (VALUES ", table, strings.Join(names, ", "), strings.Join(values, ", "))
+
+	writer := bufio.NewWriter(w)
+	writer.WriteString("BEGIN;\n")
+	writer.WriteString(ddl)
+	reader := bufio.NewReader(rows)
+	inBatch := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+
+		if line = strings.TrimSpace(line); line != "" {
+			if inBatch == 0 {
+				writer.WriteString(insert)
+			} else {
+				writer.WriteString(", ")
+			}
+			writer.WriteString("('" + strings.ReplaceAll(line, "'", "''") + "')")
+			inBatch++
+			if inBatch == batchRows {
+				writer.WriteString("));\n")
+				inBatch = 0
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if inBatch > 0 {
+		writer.WriteString("));\n")
+	}
+	writer.WriteString("COMMIT;\n")
+
+	return writer.Flush()
+}
+
+// schemaColumns returns the columns of an exported table, including the change cause columns of ledger entry changes,
+// as they are written with the flags of options
+func schemaColumns(table string, options DDLOptions) []column {
+	columns := tableColumns(OutputSchemas[table])
+	if options.IncludeXDR && table == "operations" {
+		columns = append(columns, column{name: "operation_body_xdr", kind: columnString})
+	}
+	if options.IncludeXDR && LedgerEntryChangeTables[table] {
+		columns = append(columns, column{name: "ledger_entry_xdr", kind: columnString})
+	}
+	if LedgerEntryChangeTables[table] {
+		columns = append(columns, tableColumns(LedgerEntryChangeCause{})...)
+	}
+	if options.BatchMetadata {
+		columns = append(columns,
+			column{name: "batch_id", kind: columnString},
+			column{name: "batch_run_date", kind: columnTimestamp},
+			column{name: "batch_insert_ts", kind: columnTimestamp},
+			column{name: "etl_version", kind: columnString},
+		)
+	}
+	if options.TimestampFormat == utils.TimestampFormatEpochSeconds || options.TimestampFormat == utils.TimestampFormatEpochMicros {
+		for i := range columns {
+			if columns[i].kind == columnTimestamp {
+				columns[i].kind = columnInteger
+			}
+		}
+	}
+	if options.AssetContractIDs {
+		// integer asset types, like the ones of trustlines, cannot be turned into an asset and get no contract id
+		columns = withAssetColumns(columns, func(assetType column, prefix string) []column {
+			if assetType.kind != columnString {
+				return nil
+			}
+			return []column{{name: prefix + "asset_contract_id", kind: columnString}}
+		})
+	}
+	if options.WellKnownAssets {
+		columns = withAssetColumns(columns, func(assetType column, prefix string) []column {
+			return []column{
+				{name: prefix + "asset_domain", kind: columnString},
+				{name: prefix + "asset_anchor_name", kind: columnString},
+				{name: prefix + "asset_decimals", kind: columnInteger},
+			}
+		})
+	}
+	if options.AddressLabels {
+		labelled := make([]column, 0, len(columns))
+		for _, col := range columns {
+			labelled = append(labelled, col)
+			if col.kind == columnString && IsAddressColumn(col.name) {
+				labelled = append(labelled, column{name: col.name + "_label", kind: columnString})
+			}
+		}
+		columns = labelled
+	}
+	return columns
+}
+
+// withAssetColumns inserts the columns returned by fn after every <prefix>asset_type column, the way the export adds
+// columns next to the asset columns of a row
+func withAssetColumns(columns []column, fn func(assetType column, prefix string) []column) []column {
+	result := make([]column, 0, len(columns))
+	for _, col := range columns {
+		result = append(result, col)
+		if strings.HasSuffix(col.name, "asset_type") {
+			result = append(result, fn(col, strings.TrimSuffix(col.name, "asset_type"))...)
+		}
+	}
+	return result
+}
+
+// OutputTable returns the name of the table whose rows are the provided output struct
+func OutputTable(output interface{}) (string, bool) {
+	outputType := reflect.TypeOf(output)
+	// tables sharing an output struct, like contract_data_temporary, resolve to the first one in name order
+	for _, table := range TableNames() {
+		if reflect.TypeOf(OutputSchemas[table]) == outputType {
+			return table, true
+		}
+	}
+	return "", false
+}
+
+// TableNames returns the sorted names of every exported table
+func TableNames() []string {
+	names := make([]string, 0, len(OutputSchemas))
+	for name := range OutputSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,379 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateDDL(t *testing.T) {
+	type ddlTest struct {
+		warehouse string
+		table     string
+		location  string
+		options   DDLOptions
+		wantDDL   string
+		wantErr   error
+	}
+
+	tests := []ddlTest{
+		{
+			warehouse: "snowflake",
+			table:     "ttl",
+			wantDDL: `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash VARCHAR,
+    live_until_ledger_seq NUMBER(38, 0),
+    last_modified_ledger NUMBER(38, 0),
+    ledger_entry_change NUMBER(38, 0),
+    deleted BOOLEAN,
+    closed_at TIMESTAMP_TZ,
+    ledger_sequence NUMBER(38, 0),
+    transaction_hash VARCHAR,
+    operation_index NUMBER(38, 0),
+    operation_type VARCHAR
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "snowflake",
+			table:     "account_data",
+			wantDDL: `CREATE TABLE IF NOT EXISTS account_data (
+    account_id VARCHAR,
+    data_name VARCHAR,
+    data_value VARCHAR,
+    sponsor VARCHAR,
+    last_modified_ledger NUMBER(38, 0),
+    ledger_entry_change NUMBER(38, 0),
+    deleted BOOLEAN,
+    closed_at TIMESTAMP_TZ,
+    ledger_sequence NUMBER(38, 0),
+    transaction_hash VARCHAR,
+    operation_index NUMBER(38, 0),
+    operation_type VARCHAR
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "athena",
+			table:     "ttl",
+			location:  "s3://stellar-etl/ttl",
+			wantDDL: `CREATE EXTERNAL TABLE IF NOT EXISTS ttl (
+    key_hash string,
+    live_until_ledger_seq bigint,
+    last_modified_ledger bigint,
+    ledger_entry_change bigint,
+    deleted boolean,
+    closed_at timestamp,
+    ledger_sequence bigint,
+    transaction_hash string,
+    operation_index bigint,
+    operation_type string
+)
+PARTITIONED BY (dt string, ledger_range string)
+ROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'
+WITH SERDEPROPERTIES ('timestamp.formats' = "yyyy-MM-dd'T'HH:mm:ss'Z',yyyy-MM-dd'T'HH:mm:ss.SSSSSSSSS'Z'")
+LOCATION 's3://stellar-etl/ttl/';
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "bigquery",
+			table:     "ttl",
+			location:  "crypto-stellar.crypto_stellar",
+			wantDDL: `CREATE TABLE IF NOT EXISTS crypto-stellar.crypto_stellar.ttl (
+    key_hash STRING,
+    live_until_ledger_seq INT64,
+    last_modified_ledger INT64,
+    ledger_entry_change INT64,
+    deleted BOOL,
+    closed_at TIMESTAMP,
+    ledger_sequence INT64,
+    transaction_hash STRING,
+    operation_index INT64,
+    operation_type STRING
+)
+PARTITION BY DATE(closed_at)
+CLUSTER BY key_hash;
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "bigquery",
+			table:     "ttl",
+			options:   DDLOptions{TimestampFormat: "epoch_micros"},
+			wantDDL: `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash STRING,
+    live_until_ledger_seq INT64,
+    last_modified_ledger INT64,
+    ledger_entry_change INT64,
+    deleted BOOL,
+    closed_at INT64,
+    ledger_sequence INT64,
+    transaction_hash STRING,
+    operation_index INT64,
+    operation_type STRING
+)
+CLUSTER BY key_hash;
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "redshift",
+			table:     "ttl",
+			options:   DDLOptions{TimestampFormat: "epoch_seconds"},
+			wantDDL: `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash VARCHAR(65535),
+    live_until_ledger_seq BIGINT,
+    last_modified_ledger BIGINT,
+    ledger_entry_change BIGINT,
+    deleted BOOLEAN,
+    closed_at BIGINT,
+    ledger_sequence BIGINT,
+    transaction_hash VARCHAR(65535),
+    operation_index BIGINT,
+    operation_type VARCHAR(65535)
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "snowflake",
+			table:     "assets",
+			options:   DDLOptions{AssetContractIDs: true},
+			wantDDL: `CREATE TABLE IF NOT EXISTS assets (
+    asset_code VARCHAR,
+    asset_issuer VARCHAR,
+    asset_type VARCHAR,
+    asset_contract_id VARCHAR,
+    id NUMBER(38, 0),
+    asset_id NUMBER(38, 0)
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "snowflake",
+			table:     "ttl",
+			options:   DDLOptions{IncludeXDR: true},
+			wantDDL: `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash VARCHAR,
+    live_until_ledger_seq NUMBER(38, 0),
+    last_modified_ledger NUMBER(38, 0),
+    ledger_entry_change NUMBER(38, 0),
+    deleted BOOLEAN,
+    closed_at TIMESTAMP_TZ,
+    ledger_sequence NUMBER(38, 0),
+    ledger_entry_xdr VARCHAR,
+    transaction_hash VARCHAR,
+    operation_index NUMBER(38, 0),
+    operation_type VARCHAR
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "snowflake",
+			table:     "assets",
+			options:   DDLOptions{WellKnownAssets: true},
+			wantDDL: `CREATE TABLE IF NOT EXISTS assets (
+    asset_code VARCHAR,
+    asset_issuer VARCHAR,
+    asset_type VARCHAR,
+    asset_domain VARCHAR,
+    asset_anchor_name VARCHAR,
+    asset_decimals NUMBER(38, 0),
+    id NUMBER(38, 0),
+    asset_id NUMBER(38, 0)
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "snowflake",
+			table:     "account_data",
+			options:   DDLOptions{AddressLabels: true},
+			wantDDL: `CREATE TABLE IF NOT EXISTS account_data (
+    account_id VARCHAR,
+    data_name VARCHAR,
+    data_value VARCHAR,
+    sponsor VARCHAR,
+    sponsor_label VARCHAR,
+    last_modified_ledger NUMBER(38, 0),
+    ledger_entry_change NUMBER(38, 0),
+    deleted BOOLEAN,
+    closed_at TIMESTAMP_TZ,
+    ledger_sequence NUMBER(38, 0),
+    transaction_hash VARCHAR,
+    operation_index NUMBER(38, 0),
+    operation_type VARCHAR
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "redshift",
+			table:     "ttl",
+			options:   DDLOptions{TimestampFormat: "epoch_seconds", BatchMetadata: true},
+			wantDDL: `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash VARCHAR(65535),
+    live_until_ledger_seq BIGINT,
+    last_modified_ledger BIGINT,
+    ledger_entry_change BIGINT,
+    deleted BOOLEAN,
+    closed_at BIGINT,
+    ledger_sequence BIGINT,
+    transaction_hash VARCHAR(65535),
+    operation_index BIGINT,
+    operation_type VARCHAR(65535),
+    batch_id VARCHAR(65535),
+    batch_run_date BIGINT,
+    batch_insert_ts BIGINT,
+    etl_version VARCHAR(65535)
+);
+`,
+			wantErr: nil,
+		},
+		{
+			warehouse: "athena",
+			table:     "ttl",
+			wantDDL:   "",
+			wantErr:   fmt.Errorf("a location is required to generate an Athena table"),
+		},
+		{
+			warehouse: "snowflake",
+			table:     "unknown",
+			wantDDL:   "",
+			wantErr:   fmt.Errorf("unknown table unknown; must be one of %s", "account_data, accounts, assets, bucket_list_metrics, claimable_balances, config_settings, contract_code, contract_data, contract_data_temporary, contract_nonces, diagnostic_events, effects, evicted_entries, ledger_stats, ledgers, liquidity_pools, offers, operations, participants, signers, trades, transactions, trustlines, ttl, tx_footprints"),
+		},
+		{
+			warehouse: "unsupported",
+			table:     "ttl",
+			wantDDL:   "",
+			wantErr:   fmt.Errorf("DDL generation is not supported for warehouse \"unsupported\""),
+		},
+	}
+
+	for _, test := range tests {
+		actualDDL, actualError := GenerateDDL(test.warehouse, test.table, test.location, test.options)
+		assert.Equal(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantDDL, actualDDL)
+	}
+}
+
+func TestDuckDBParquet(t *testing.T) {
+	statement, err := DuckDBParquet("ttl", "/tmp/1-64-ttl.txt", "/tmp/1-64-ttl.parquet", "zstd", DDLOptions{TimestampFormat: "epoch_micros"})
+	assert.NoError(t, err)
+	assert.Equal(t, `COPY (SELECT * FROM read_json('/tmp/1-64-ttl.txt', format = 'newline_delimited', columns = {'key_hash': 'VARCHAR', 'live_until_ledger_seq': 'BIGINT', 'last_modified_ledger': 'BIGINT', 'ledger_entry_change': 'BIGINT', 'deleted': 'BOOLEAN', 'closed_at': 'BIGINT', 'ledger_sequence': 'BIGINT', 'transaction_hash': 'VARCHAR', 'operation_index': 'BIGINT', 'operation_type': 'VARCHAR'})) TO '/tmp/1-64-ttl.parquet' (FORMAT PARQUET, COMPRESSION ZSTD);
+`, statement)
+
+	// parquet pages are compressed with snappy unless the table sets a compression
+	statement, err = DuckDBParquet("ttl", "/tmp/1-64-ttl.txt", "/tmp/1-64-ttl.parquet", "", DDLOptions{})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(statement, "(FORMAT PARQUET, COMPRESSION SNAPPY);\n"))
+	assert.Contains(t, statement, "'closed_at': 'TIMESTAMPTZ'")
+
+	_, err = DuckDBParquet("ttl", "/tmp/1-64-ttl.txt", "/tmp/1-64-ttl.parquet", "lz4", DDLOptions{})
+	assert.Error(t, err)
+}
+
+func TestDuckDBAppend(t *testing.T) {
+	statements, err := DuckDBAppend("ttl", "/tmp/it's.ndjson", DDLOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS ttl (
+    key_hash VARCHAR,
+    live_until_ledger_seq BIGINT,
+    last_modified_ledger BIGINT,
+    ledger_entry_change BIGINT,
+    deleted BOOLEAN,
+    closed_at TIMESTAMPTZ,
+    ledger_sequence BIGINT,
+    transaction_hash VARCHAR,
+    operation_index BIGINT,
+    operation_type VARCHAR
+);
+INSERT INTO ttl BY NAME SELECT * FROM read_json('/tmp/it''s.ndjson', format = 'newline_delimited', columns = {'key_hash': 'VARCHAR', 'live_until_ledger_seq': 'BIGINT', 'last_modified_ledger': 'BIGINT', 'ledger_entry_change': 'BIGINT', 'deleted': 'BOOLEAN', 'closed_at': 'TIMESTAMPTZ', 'ledger_sequence': 'BIGINT', 'transaction_hash': 'VARCHAR', 'operation_index': 'BIGINT', 'operation_type': 'VARCHAR'});
+`, statements)
+
+	// unsigned 64 bit columns, like the farm hash id of assets, are read without overflowing
+	statements, err = DuckDBAppend("assets", "/tmp/assets.ndjson", DDLOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS assets (
+    asset_code VARCHAR,
+    asset_issuer VARCHAR,
+    asset_type VARCHAR,
+    id UBIGINT,
+    asset_id BIGINT
+);
+INSERT INTO assets BY NAME SELECT * FROM read_json('/tmp/assets.ndjson', format = 'newline_delimited', columns = {'asset_code': 'VARCHAR', 'asset_issuer': 'VARCHAR', 'asset_type': 'VARCHAR', 'id': 'UBIGINT', 'asset_id': 'BIGINT'});
+`, statements)
+
+	_, err = DuckDBAppend("unknown", "/tmp/unknown.ndjson", DDLOptions{})
+	assert.Error(t, err)
+}
+
+func TestSQLiteAppend(t *testing.T) {
+	rows := `{"key_hash":"a","deleted":false}
+{"key_hash":"it's","deleted":true}
+
+{"key_hash":"c","deleted":false}`
+	statements := &strings.Builder{}
+	assert.NoError(t, SQLiteAppend(statements, "ttl", strings.NewReader(rows), 2, DDLOptions{}))
+
+	insert := "INSERT INTO ttl (key_hash, live_until_ledger_seq, last_modified_ledger, ledger_entry_change, deleted, closed_at, ledger_sequence, transaction_hash, operation_index, operation_type) " +
+		"SELECT json_extract(row, '$.key_hash'), json_extract(row, '$.live_until_ledger_seq'), json_extract(row, '$.last_modified_ledger'), json_extract(row, '$.ledger_entry_change'), " +
+		"json_extract(row, '$.deleted'), json_extract(row, '$.closed_at'), json_extract(row, '$.ledger_sequence'), json_extract(row, '$.transaction_hash'), " +
+		"json_extract(row, '$.operation_index'), json_extract(row, '$.operation_type') FROM (SELECT column1 AS row FROM (VALUES "
+	assert.Equal(t, `BEGIN;
+CREATE TABLE IF NOT EXISTS ttl (
+    key_hash TEXT,
+    live_until_ledger_seq INTEGER,
+    last_modified_ledger INTEGER,
+    ledger_entry_change INTEGER,
+    deleted INTEGER,
+    closed_at TEXT,
+    ledger_sequence INTEGER,
+    transaction_hash TEXT,
+    operation_index INTEGER,
+    operation_type TEXT
+);
+`+insert+`('{"key_hash":"a","deleted":false}'), ('{"key_hash":"it''s","deleted":true}')));
+`+insert+`('{"key_hash":"c","deleted":false}')));
+COMMIT;
+`, statements.String())
+
+	assert.Error(t, SQLiteAppend(&strings.Builder{}, "unknown", strings.NewReader(rows), 2, DDLOptions{}))
+
+	// unsigned 64 bit columns, like the farm hash id of assets, are kept as text since they can overflow SQLite integers
+	statements = &strings.Builder{}
+	assetRows := `{"asset_code":"USDC","id":18446744073709551615,"asset_id":1}`
+	assert.NoError(t, SQLiteAppend(statements, "assets", strings.NewReader(assetRows), 2, DDLOptions{}))
+	assert.Equal(t, `BEGIN;
+CREATE TABLE IF NOT EXISTS assets (
+    asset_code TEXT,
+    asset_issuer TEXT,
+    asset_type TEXT,
+    id TEXT,
+    asset_id INTEGER
+);
+INSERT INTO assets (asset_code, asset_issuer, asset_type, id, asset_id) SELECT json_extract(row, '$.asset_code'), json_extract(row, '$.asset_issuer'), `+
+		`json_extract(row, '$.asset_type'), nullif(row -> '$.id', 'null'), json_extract(row, '$.asset_id') FROM (SELECT column1 AS row FROM (VALUES ('`+assetRows+`')));
+COMMIT;
+`, statements.String())
+}
+
+func TestOutputTable(t *testing.T) {
+	table, ok := OutputTable(TtlOutput{})
+	assert.True(t, ok)
+	assert.Equal(t, "ttl", table)
+
+	table, ok = OutputTable(ContractDataOutput{})
+	assert.True(t, ok)
+	assert.Equal(t, "contract_data", table)
+
+	_, ok = OutputTable(LedgerTransactionOutput{})
+	assert.False(t, ok)
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
-// TransformDiagnosticEvent converts a transaction's diagnostic events from the history archive ingestion system into a form suitable for BigQuery
-func TransformDiagnosticEvent(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) ([]DiagnosticEventOutput, error, bool) {
+// TransformDiagnosticEvent converts a transaction's diagnostic events from the history archive ingestion system into a form suitable for BigQuery.
+// The events of the Stellar Asset Contracts of the network are decoded into the sac columns, and the data of other events is rendered as JSON.
+func TransformDiagnosticEvent(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, network string) (_ []DiagnosticEventOutput, err error, _ bool) {
+	defer recoverPanic(&err)
+
 	ledgerHeader := lhe.Header
 	outputTransactionHash := utils.HashToHexString(transaction.Result.TransactionHash)
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
@@ -44,6 +47,11 @@ func TransformDiagnosticEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledg
 		body, _ := event.Body.GetV0()
 
 		outputBody, _ := xdr.MarshalBase64(body)
+		outputTopics, err := renderEventTopics(body.Topics)
+		if err != nil {
+			return []DiagnosticEventOutput{}, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %v", outputLedgerSequence, transactionIndex, outputTransactionID, err), false
+		}
+		decodedTopics := decodeEventTopics(body.Topics)
 
 		if event.ContractId != nil {
 			contractId := *event.ContractId
@@ -62,6 +70,25 @@ func TransformDiagnosticEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledg
 			Type:                     outputType,
 			BodyV:                    outputBodyV,
 			Body:                     outputBody,
+			Topics:                   outputTopics,
+			Topic1:                   eventTopic(decodedTopics, 0),
+			Topic2:                   eventTopic(decodedTopics, 1),
+			Topic3:                   eventTopic(decodedTopics, 2),
+			Topic4:                   eventTopic(decodedTopics, 3),
+		}
+
+		if decoded, ok := decodeSACEvent(event, network); ok {
+			transformedDiagnosticEvent.SacEventType = decoded.Type
+			transformedDiagnosticEvent.SacFrom = decoded.From
+			transformedDiagnosticEvent.SacTo = decoded.To
+			transformedDiagnosticEvent.SacAmount = decoded.Amount
+			transformedDiagnosticEvent.SacAsset = decoded.Asset
+			transformedDiagnosticEvent.SacAuthorized = decoded.Authorized
+		} else {
+			transformedDiagnosticEvent.Data, err = utils.XdrJSON.ScVal(body.Data)
+			if err != nil {
+				return []DiagnosticEventOutput{}, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %w", outputLedgerSequence, transactionIndex, outputTransactionID, err), false
+			}
 		}
 
 		transformedDiagnosticEvents = append(transformedDiagnosticEvents, transformedDiagnosticEvent)
@@ -69,3 +96,34 @@ func TransformDiagnosticEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledg
 
 	return transformedDiagnosticEvents, nil, true
 }
+
+// renderEventTopics returns the topics of a contract event rendered by utils.XdrJSON
+func renderEventTopics(topics []xdr.ScVal) ([]interface{}, error) {
+	rendered := make([]interface{}, 0, len(topics))
+	for _, topic := range topics {
+		topicJSON, err := utils.XdrJSON.ScVal(topic)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, topicJSON)
+	}
+	return rendered, nil
+}
+
+// decodeEventTopics returns the human readable form of each topic of a contract event
+func decodeEventTopics(topics []xdr.ScVal) []string {
+	decoded := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		decoded = append(decoded, utils.ScValString(topic))
+	}
+	return decoded
+}
+
+// eventTopic returns the decoded topic at index i, or an empty string when the event has fewer topics. Contract events
+// have at most 4 topics.
+func eventTopic(topics []string, i int) string {
+	if i >= len(topics) {
+		return ""
+	}
+	return topics[i]
+}
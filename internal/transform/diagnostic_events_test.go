@@ -4,9 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
 	"github.com/stellar/go/xdr"
 )
 
@@ -37,7 +39,7 @@ func TestTransformDiagnosticEvent(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError, _ := TransformDiagnosticEvent(test.input.transaction, test.input.historyHeader)
+		actualOutput, actualError, _ := TransformDiagnosticEvent(test.input.transaction, test.input.historyHeader, network.TestNetworkPassphrase)
 		assert.Equal(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
@@ -56,6 +58,9 @@ func makeDiagnosticEventTestOutput() (output [][]DiagnosticEventOutput, err erro
 			Type:                     "ContractEventTypeDiagnostic",
 			BodyV:                    0,
 			Body:                     "AAAAAQAAAAAAAAABAAAAAAAAAAE=",
+			Topics:                   []interface{}{map[string]interface{}{"bool": true}},
+			Topic1:                   "true",
+			Data:                     map[string]interface{}{"bool": true},
 		},
 	}}
 	return
@@ -175,3 +180,45 @@ func makeDiagnosticEventTestInput() (transaction []ingest.LedgerTransaction, his
 	}
 	return
 }
+
+func TestDecodeSACEvent(t *testing.T) {
+	asset := xdr.MustNewCreditAsset("USDC", testAccount3Address)
+	assetContractID, err := asset.ContractID(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	contractID := xdr.Hash(assetContractID)
+	otherContractID := xdr.Hash{1}
+
+	symbol := func(name string) xdr.ScVal {
+		sym := xdr.ScSymbol(name)
+		return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+	}
+	address := func(account xdr.AccountId) xdr.ScVal {
+		return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &account}}
+	}
+	assetName := xdr.ScString(asset.StringCanonical())
+	assetTopic := xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &assetName}
+	amount := xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Hi: 0, Lo: 12345678}}
+	authorized := false
+	event := func(contractID xdr.Hash, data xdr.ScVal, topics ...xdr.ScVal) xdr.ContractEvent {
+		return xdr.ContractEvent{
+			ContractId: &contractID,
+			Type:       xdr.ContractEventTypeContract,
+			Body:       xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{Topics: topics, Data: data}},
+		}
+	}
+
+	decoded, ok := decodeSACEvent(event(contractID, amount, symbol("transfer"), address(testAccount1ID), address(testAccount2ID), assetTopic), network.TestNetworkPassphrase)
+	assert.True(t, ok)
+	assert.Equal(t, sacEvent{Type: "transfer", From: testAccount1Address, To: testAccount2Address, Amount: "1.2345678", Asset: asset.StringCanonical()}, decoded)
+
+	decoded, ok = decodeSACEvent(event(contractID, xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &authorized},
+		symbol("set_authorized"), address(testAccount3ID), address(testAccount1ID), assetTopic), network.TestNetworkPassphrase)
+	assert.True(t, ok)
+	assert.Equal(t, sacEvent{Type: "set_authorized", From: testAccount3Address, To: testAccount1Address, Asset: asset.StringCanonical(), Authorized: null.BoolFrom(false)}, decoded)
+
+	// the same topics emitted by a contract that is not the asset contract are not decoded
+	_, ok = decodeSACEvent(event(otherContractID, amount, symbol("transfer"), address(testAccount1ID), address(testAccount2ID), assetTopic), network.TestNetworkPassphrase)
+	assert.False(t, ok)
+	_, ok = decodeSACEvent(event(contractID, amount, symbol("approve"), address(testAccount1ID), address(testAccount2ID)), network.TestNetworkPassphrase)
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 
 	"fmt"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
@@ -16,11 +17,14 @@ import (
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/contractevents"
 	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/internal/utils"
 )
 
-func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string) ([]EffectOutput, error) {
+func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string) (_ []EffectOutput, err error) {
+	defer recoverPanic(&err)
+
 	effects := []EffectOutput{}
 
 	outputCloseTime, err := utils.GetCloseTime(ledgerCloseMeta)
@@ -60,7 +64,7 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 		err error
 	)
 
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	changes, err := operationChanges(operation.transaction, operation.index)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +110,7 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 	case xdr.OperationTypeBeginSponsoringFutureReserves, xdr.OperationTypeEndSponsoringFutureReserves, xdr.OperationTypeRevokeSponsorship:
 	// The effects of these operations are obtained  indirectly from the ledger entries
 	case xdr.OperationTypeClawback:
-		err = wrapper.addClawbackEffects()
+		err = wrapper.addClawbackEffects(changes)
 	case xdr.OperationTypeClawbackClaimableBalance:
 		err = wrapper.addClawbackClaimableBalanceEffects(changes)
 	case xdr.OperationTypeSetTrustLineFlags:
@@ -126,12 +130,15 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 		// For now, the only effects are related to the events themselves.
 		// Possible add'l work: https://github.com/stellar/go/issues/4585
 		err = wrapper.addInvokeHostFunctionEffects(filterEvents(diagnosticEvents))
+		if err == nil {
+			err = wrapper.addContractBalanceChangedEffects(filterEvents(diagnosticEvents))
+		}
 	case xdr.OperationTypeExtendFootprintTtl:
 		err = wrapper.addExtendFootprintTtlEffect()
 	case xdr.OperationTypeRestoreFootprint:
 		err = wrapper.addRestoreFootprintExpirationEffect()
 	default:
-		return nil, fmt.Errorf("unknown operation type: %s", op.Body.Type)
+		return nil, utils.UnknownVariantError{Kind: "operation type", Value: op.Body.Type.String()}
 	}
 	if err != nil {
 		return nil, err
@@ -337,6 +344,7 @@ func (e *effectsWrapper) addLedgerEntrySponsorshipEffects(change ingest.Change)
 			details["liquidity_pool_id"] = PoolIDToString(*tl.Asset.LiquidityPoolId)
 		} else {
 			details["asset"] = tl.Asset.ToAsset().StringCanonical()
+			addAssetDetails(details, tl.Asset.ToAsset(), "")
 		}
 	case xdr.LedgerEntryTypeData:
 		muxedAccount = e.operation.SourceAccount()
@@ -410,6 +418,7 @@ func (e *effectsWrapper) addAccountCreatedEffects() {
 		EffectAccountDebited,
 		map[string]interface{}{
 			"asset_type": "native",
+			"asset_id":   nativeAssetID,
 			"amount":     amount.String(op.StartingBalance),
 		},
 	)
@@ -563,7 +572,7 @@ func (e *effectsWrapper) addSetOptionsEffects() error {
 			},
 		)
 	}
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
@@ -635,7 +644,7 @@ func (e *effectsWrapper) addChangeTrustEffects() error {
 	source := e.operation.SourceAccount()
 
 	op := e.operation.operation.Body.MustChangeTrustOp()
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
@@ -732,6 +741,7 @@ func (e *effectsWrapper) addAccountMergeEffects() {
 	details := map[string]interface{}{
 		"amount":     amount.String(result.MustSourceAccountBalance()),
 		"asset_type": "native",
+		"asset_id":   nativeAssetID,
 	}
 
 	e.addMuxed(source, EffectAccountDebited, details)
@@ -746,6 +756,7 @@ func (e *effectsWrapper) addInflationEffects() {
 			map[string]interface{}{
 				"amount":     amount.String(payout.Amount),
 				"asset_type": "native",
+				"asset_id":   nativeAssetID,
 			},
 		)
 	}
@@ -756,7 +767,7 @@ func (e *effectsWrapper) addManageDataEffects() error {
 	op := e.operation.operation.Body.MustManageDataOp()
 	details := map[string]interface{}{"name": op.DataName}
 	effect := EffectType(0)
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
@@ -794,7 +805,7 @@ func (e *effectsWrapper) addManageDataEffects() error {
 
 func (e *effectsWrapper) addBumpSequenceEffects() error {
 	source := e.operation.SourceAccount()
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
@@ -886,14 +897,19 @@ func (e *effectsWrapper) addClaimableBalanceEntryCreatedEffects(source *xdr.Muxe
 	}
 	for _, c := range claimants {
 		cv0 := c.MustV0()
+		predicateJSON, err := utils.XdrJSON.ClaimPredicate(cv0.Predicate)
+		if err != nil {
+			return err
+		}
 		e.addUnmuxed(
 			&cv0.Destination,
 			EffectClaimableBalanceClaimantCreated,
 			map[string]interface{}{
-				"balance_id": id,
-				"amount":     amount.String(cb.Amount),
-				"predicate":  cv0.Predicate,
-				"asset":      cb.Asset.StringCanonical(),
+				"balance_id":     id,
+				"amount":         amount.String(cb.Amount),
+				"predicate":      cv0.Predicate,
+				"predicate_json": predicateJSON,
+				"asset":          cb.Asset.StringCanonical(),
 			},
 		)
 	}
@@ -1027,7 +1043,7 @@ func (e *effectsWrapper) addClaimLiquidityPoolTradeEffect(claim xdr.ClaimAtom) e
 	return nil
 }
 
-func (e *effectsWrapper) addClawbackEffects() error {
+func (e *effectsWrapper) addClawbackEffects(changes []ingest.Change) error {
 	op := e.operation.operation.Body.MustClawbackOp()
 	details := map[string]interface{}{
 		"amount": amount.String(op.Amount),
@@ -1048,6 +1064,44 @@ func (e *effectsWrapper) addClawbackEffects() error {
 		details,
 	)
 
+	clawedBack := map[string]interface{}{
+		"amount":          amount.String(op.Amount),
+		"clawback_source": "trustline",
+	}
+	addAssetDetails(clawedBack, op.Asset, "")
+	if err := addAccountAndMuxedAccountDetails(clawedBack, op.From, "from"); err != nil {
+		return err
+	}
+
+	// The trustline of the holder should lose exactly the clawed back amount. When it does not, the effect keeps the
+	// amount of the operation and records the change of the trustline in trustline_delta, so that supply checks can
+	// find the drift.
+	from := op.From.ToAccountId()
+	asset := op.Asset.ToTrustLineAsset()
+	for _, c := range changes {
+		if c.Type != xdr.LedgerEntryTypeTrustline || c.Pre == nil || c.Post == nil {
+			continue
+		}
+		pre, post := c.Pre.Data.MustTrustLine(), c.Post.Data.MustTrustLine()
+		if !pre.AccountId.Equals(from) || !pre.Asset.Equals(asset) {
+			continue
+		}
+		if delta := pre.Balance - post.Balance; delta != op.Amount {
+			log.Warnf("clawback in op %d removed %s from the trustline of %s, but the operation claws back %s",
+				e.operation.index, amount.String(delta), from.Address(), amount.String(op.Amount))
+			clawedBack["trustline_delta"] = amount.String(delta)
+		}
+		clawedBack["balance_before"] = amount.String(pre.Balance)
+		clawedBack["balance_after"] = amount.String(post.Balance)
+		break
+	}
+
+	e.addMuxed(
+		source,
+		EffectAssetClawedBack,
+		clawedBack,
+	)
+
 	return nil
 }
 
@@ -1060,6 +1114,24 @@ func (e *effectsWrapper) addClawbackClaimableBalanceEffects(changes []ingest.Cha
 	details := map[string]interface{}{
 		"balance_id": balanceId,
 	}
+
+	// The clawed back balance is removed by the operation, its asset and amount are only found in the changes. Only the
+	// balance named by the operation is considered, so that the clawed back amount is the one of the right entry.
+	var clawedBack *xdr.ClaimableBalanceEntry
+	for _, c := range changes {
+		if c.Type == xdr.LedgerEntryTypeClaimableBalance && c.Post == nil && c.Pre != nil {
+			entry := c.Pre.Data.MustClaimableBalance()
+			if entryId, err := xdr.MarshalHex(entry.BalanceId); err == nil && entryId == balanceId {
+				clawedBack = &entry
+				break
+			}
+		}
+	}
+	if clawedBack != nil {
+		details["amount"] = amount.String(clawedBack.Amount)
+		addAssetDetails(details, clawedBack.Asset, "")
+	}
+
 	source := e.operation.SourceAccount()
 	e.addMuxed(
 		source,
@@ -1068,18 +1140,26 @@ func (e *effectsWrapper) addClawbackClaimableBalanceEffects(changes []ingest.Cha
 	)
 
 	// Generate the account credited effect (although the funds will be burned) for the asset issuer
-	for _, c := range changes {
-		if c.Type == xdr.LedgerEntryTypeClaimableBalance && c.Post == nil && c.Pre != nil {
-			cb := c.Pre.Data.ClaimableBalance
-			details = map[string]interface{}{"amount": amount.String(cb.Amount)}
-			addAssetDetails(details, cb.Asset, "")
-			e.addMuxed(
-				source,
-				EffectAccountCredited,
-				details,
-			)
-			break
+	if clawedBack != nil {
+		details = map[string]interface{}{"amount": amount.String(clawedBack.Amount)}
+		addAssetDetails(details, clawedBack.Asset, "")
+		e.addMuxed(
+			source,
+			EffectAccountCredited,
+			details,
+		)
+
+		details = map[string]interface{}{
+			"amount":          amount.String(clawedBack.Amount),
+			"clawback_source": "claimable_balance",
+			"balance_id":      balanceId,
 		}
+		addAssetDetails(details, clawedBack.Asset, "")
+		e.addMuxed(
+			source,
+			EffectAssetClawedBack,
+			details,
+		)
 	}
 
 	return nil
@@ -1146,7 +1226,7 @@ func (e *effectsWrapper) addLiquidityPoolRevokedEffect() error {
 		}
 		return err
 	}
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
@@ -1426,15 +1506,108 @@ func (e *effectsWrapper) addInvokeHostFunctionEffects(events []contractevents.Ev
 	return nil
 }
 
+// addContractBalanceChangedEffects generates a contract_balance_changed effect for every Stellar Asset Contract
+// balance held by a contract that the operation changed. Unlike the event based effects, the delta is derived
+// from the balance entries themselves, so it also covers balance changes that the events do not describe.
+// The asset is resolved from the Stellar Asset Contract events of the operation when possible.
+func (e *effectsWrapper) addContractBalanceChangedEffects(events []contractevents.Event) error {
+	assetsByContract := map[string]xdr.Asset{}
+	for _, event := range events {
+		evt, err := contractevents.NewStellarAssetContractEvent(&event, e.operation.network)
+		if err != nil {
+			continue // irrelevant or unsupported event
+		}
+
+		asset := evt.GetAsset()
+		contractID, err := asset.ContractID(e.operation.network)
+		if err != nil {
+			continue
+		}
+		assetsByContract[strkey.MustEncode(strkey.VersionByteContract, contractID[:])] = asset
+	}
+
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
+	if err != nil {
+		return err
+	}
+
+	source := e.operation.SourceAccount()
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeContractData {
+			continue
+		}
+
+		contractID, holder, delta, ok := contractBalanceDelta(change, e.operation.network)
+		if !ok || delta.Sign() == 0 {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"contract_id": contractID,
+			"holder":      holder,
+			"delta":       new(big.Rat).SetFrac(delta, big.NewInt(amount.One)).FloatString(7),
+		}
+		if asset, ok := assetsByContract[contractID]; ok {
+			addAssetDetails(details, asset, "")
+		}
+
+		e.addMuxed(source, EffectContractBalanceChanged, details)
+	}
+
+	return nil
+}
+
+// contractBalanceDelta returns the Stellar Asset Contract that holds a contract balance entry, the contract that owns
+// the balance and how much the balance changed. Created and removed entries count from and to a zero balance.
+func contractBalanceDelta(change ingest.Change, passphrase string) (string, string, *big.Int, bool) {
+	var entry xdr.LedgerEntry
+	preBalance, postBalance := big.NewInt(0), big.NewInt(0)
+	var holder xdr.ScAddress
+
+	if change.Pre != nil {
+		preHolder, balance, ok := ContractBalanceFromContractData(*change.Pre, passphrase)
+		if !ok {
+			return "", "", nil, false
+		}
+		entry, holder, preBalance = *change.Pre, preHolder, balance
+	}
+
+	if change.Post != nil {
+		postHolder, balance, ok := ContractBalanceFromContractData(*change.Post, passphrase)
+		if !ok {
+			return "", "", nil, false
+		}
+		entry, holder, postBalance = *change.Post, postHolder, balance
+	}
+
+	if change.Pre == nil && change.Post == nil {
+		return "", "", nil, false
+	}
+
+	contractData := entry.Data.MustContractData()
+	contractID, err := strkey.Encode(strkey.VersionByteContract, contractData.Contract.ContractId[:])
+	if err != nil {
+		return "", "", nil, false
+	}
+
+	holderID, err := utils.ScAddressToStrkey(holder)
+	if err != nil {
+		return "", "", nil, false
+	}
+
+	return contractID, holderID, new(big.Int).Sub(postBalance, preBalance), true
+}
+
 func (e *effectsWrapper) addExtendFootprintTtlEffect() error {
 	op := e.operation.operation.Body.MustExtendFootprintTtlOp()
 
 	// Figure out which entries were affected
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
 	entries := make([]string, 0, len(changes))
+	entriesJSON := make([]interface{}, 0, len(changes))
 	for _, change := range changes {
 		// They should all have a post
 		if change.Post == nil {
@@ -1459,10 +1632,16 @@ func (e *effectsWrapper) addExtendFootprintTtlEffect() error {
 			return err
 		}
 		entries = append(entries, b64)
+		keyJSON, err := utils.XdrJSON.LedgerKey(key)
+		if err != nil {
+			return err
+		}
+		entriesJSON = append(entriesJSON, keyJSON)
 	}
 	details := map[string]interface{}{
-		"entries":   entries,
-		"extend_to": op.ExtendTo,
+		"entries":      entries,
+		"entries_json": entriesJSON,
+		"extend_to":    op.ExtendTo,
 	}
 	e.addMuxed(e.operation.SourceAccount(), EffectExtendFootprintTtl, details)
 	return nil
@@ -1472,11 +1651,12 @@ func (e *effectsWrapper) addRestoreFootprintExpirationEffect() error {
 	op := e.operation.operation.Body.MustRestoreFootprintOp()
 
 	// Figure out which entries were affected
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	changes, err := operationChanges(e.operation.transaction, e.operation.index)
 	if err != nil {
 		return err
 	}
 	entries := make([]string, 0, len(changes))
+	entriesJSON := make([]interface{}, 0, len(changes))
 	for _, change := range changes {
 		// They should all have a post
 		if change.Post == nil {
@@ -1501,9 +1681,15 @@ func (e *effectsWrapper) addRestoreFootprintExpirationEffect() error {
 			return err
 		}
 		entries = append(entries, b64)
+		keyJSON, err := utils.XdrJSON.LedgerKey(key)
+		if err != nil {
+			return err
+		}
+		entriesJSON = append(entriesJSON, keyJSON)
 	}
 	details := map[string]interface{}{
-		"entries": entries,
+		"entries":      entries,
+		"entries_json": entriesJSON,
 	}
 	e.addMuxed(e.operation.SourceAccount(), EffectRestoreFootprint, details)
 	return nil
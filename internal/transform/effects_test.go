@@ -390,6 +390,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "1000.0000000",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					Type:         int32(EffectAccountDebited),
 					TypeString:   EffectTypeNames[EffectAccountDebited],
@@ -454,6 +455,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "10.0000000",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					Type:         int32(EffectAccountCredited),
 					TypeString:   EffectTypeNames[EffectAccountCredited],
@@ -465,6 +467,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "10.0000000",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					Type:         int32(EffectAccountDebited),
 					TypeString:   EffectTypeNames[EffectAccountDebited],
@@ -491,6 +494,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "ARS",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(6093450377478630918),
 					},
 					Type:         int32(EffectAccountCredited),
 					TypeString:   EffectTypeNames[EffectAccountCredited],
@@ -504,6 +508,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "BRL",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(-7536931339446163286),
 					},
 					Type:         int32(EffectAccountDebited),
 					TypeString:   EffectTypeNames[EffectAccountDebited],
@@ -517,12 +522,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -536,12 +543,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -555,12 +564,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -574,12 +585,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -593,12 +606,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -612,12 +627,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -644,6 +661,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "ARS",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(6093450377478630918),
 					},
 					Type:         int32(EffectAccountCredited),
 					TypeString:   EffectTypeNames[EffectAccountCredited],
@@ -658,6 +676,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "BRL",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(-7536931339446163286),
 					},
 					Type:         int32(EffectAccountDebited),
 					TypeString:   EffectTypeNames[EffectAccountDebited],
@@ -672,12 +691,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -691,6 +712,7 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
@@ -699,6 +721,7 @@ func TestOperationEffects(t *testing.T) {
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -713,12 +736,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -732,6 +757,7 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
@@ -740,6 +766,7 @@ func TestOperationEffects(t *testing.T) {
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -754,12 +781,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(6093450377478630918),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(-7536931339446163286),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -773,6 +802,7 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(-7536931339446163286),
 						"offer_id":            xdr.Int64(10072128),
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
@@ -781,6 +811,7 @@ func TestOperationEffects(t *testing.T) {
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       int64(6093450377478630918),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -817,10 +848,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(2030452208542475962),
 						"offer_id":            xdr.Int64(9248760),
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -832,12 +865,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(9248760),
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(2030452208542475962),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -851,10 +886,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(2030452208542475962),
 						"offer_id":            xdr.Int64(9248760),
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -866,12 +903,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(9248760),
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(2030452208542475962),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -885,10 +924,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(2030452208542475962),
 						"offer_id":            xdr.Int64(9248760),
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -900,12 +941,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(9248760),
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(2030452208542475962),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -919,10 +962,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(2030452208542475962),
 						"offer_id":            xdr.Int64(9248760),
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -934,12 +979,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(9248760),
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(2030452208542475962),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -965,10 +1012,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
+						"bought_asset_id":     int64(-4387039311605219262),
 						"offer_id":            xdr.Int64(10104690),
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -980,12 +1029,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10104690),
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     int64(-4387039311605219262),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -999,10 +1050,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
+						"bought_asset_id":     int64(-4387039311605219262),
 						"offer_id":            xdr.Int64(10104690),
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -1014,12 +1067,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10104690),
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     int64(-4387039311605219262),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -1033,10 +1088,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
+						"bought_asset_id":     int64(-4387039311605219262),
 						"offer_id":            xdr.Int64(10104690),
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -1048,12 +1105,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10104690),
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     int64(-4387039311605219262),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -1067,10 +1126,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
+						"bought_asset_id":     int64(-4387039311605219262),
 						"offer_id":            xdr.Int64(10104690),
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -1082,12 +1143,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10104690),
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     int64(-4387039311605219262),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -1113,10 +1176,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(668608523179550368),
 						"offer_id":            xdr.Int64(10694502),
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -1128,12 +1193,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10694502),
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(668608523179550368),
 					},
 					Type:         int32(EffectTrade),
 					TypeString:   EffectTypeNames[EffectTrade],
@@ -1147,10 +1214,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(668608523179550368),
 						"offer_id":            xdr.Int64(10694502),
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -1162,12 +1231,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10694502),
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(668608523179550368),
 					},
 					Type:         int32(EffectOfferUpdated),
 					TypeString:   EffectTypeNames[EffectOfferUpdated],
@@ -1181,10 +1252,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(668608523179550368),
 						"offer_id":            xdr.Int64(10694502),
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -1196,12 +1269,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10694502),
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(668608523179550368),
 					},
 					Type:         int32(EffectOfferRemoved),
 					TypeString:   EffectTypeNames[EffectOfferRemoved],
@@ -1215,10 +1290,12 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
+						"bought_asset_id":     int64(668608523179550368),
 						"offer_id":            xdr.Int64(10694502),
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       int64(-5706705804583548011),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -1230,12 +1307,14 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
+						"bought_asset_id":   int64(-5706705804583548011),
 						"offer_id":          xdr.Int64(10694502),
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     int64(668608523179550368),
 					},
 					Type:         int32(EffectOfferCreated),
 					TypeString:   EffectTypeNames[EffectOfferCreated],
@@ -1340,6 +1419,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "922337203685.4775807",
 						"asset_code":   "USD",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(-6937339003237137217),
 						"asset_issuer": "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 					},
 					LedgerClosed: genericCloseTime.UTC(),
@@ -1365,6 +1445,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "0.0000000",
 						"asset_code":   "OCIToken",
 						"asset_type":   "credit_alphanum12",
+						"asset_id":     int64(-8552497837338156363),
 						"asset_issuer": "GBE4L76HUCHCQ2B7IIWBXRAJDBDPIY6MGWX7VZHUZD2N5RO7XI4J6GTJ",
 					},
 					LedgerClosed: genericCloseTime.UTC(),
@@ -1390,6 +1471,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "100.0000000",
 						"asset_code":   "TESTASSET",
 						"asset_type":   "credit_alphanum12",
+						"asset_id":     int64(-8679555853391113614),
 						"asset_issuer": "GA5SKSJEB7VWACRNWFGVZBDSZYLGK44A2JPPBWUK3GB7NYEFOOQJAC2B",
 					},
 					LedgerClosed: genericCloseTime.UTC(),
@@ -1415,6 +1497,7 @@ func TestOperationEffects(t *testing.T) {
 						"trustor":      "GCVW5LCRZFP7PENXTAGOVIQXADDNUXXZJCNKF4VQB2IK7W2LPJWF73UG",
 						"asset_code":   "USD",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(-6937339003237137217),
 						"asset_issuer": "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 					},
 					LedgerClosed: genericCloseTime.UTC(),
@@ -1428,6 +1511,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":      "USD",
 						"asset_issuer":    "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 						"asset_type":      "credit_alphanum4",
+						"asset_id":        int64(-6937339003237137217),
 						"authorized_flag": true,
 						"trustor":         "GCVW5LCRZFP7PENXTAGOVIQXADDNUXXZJCNKF4VQB2IK7W2LPJWF73UG",
 					},
@@ -1453,6 +1537,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "999.9999900",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -1464,6 +1549,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "999.9999900",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -1495,6 +1581,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "15257676.9536092",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -1506,6 +1593,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "3814420.0001419",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -2014,6 +2102,7 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(8456997905258871731),
 				"trustor":      "GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3",
 			},
 			Type:         int32(EffectTrustlineFlagsUpdated),
@@ -2027,6 +2116,7 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 				"asset_code":                        "COP",
 				"asset_issuer":                      "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":                        "credit_alphanum4",
+				"asset_id":                          int64(8456997905258871731),
 				"authorized_to_maintain_liabilites": true,
 				"trustor":                           "GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3",
 			},
@@ -2038,8 +2128,21 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 	tt.Equal(expected, effects)
 }
 
-func TestOperationEffectsClawback(t *testing.T) {
-	tt := assert.New(t)
+func clawbackTrustlineEntry(balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: xdr.MustAddress("GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3"),
+				Asset:     xdr.MustNewCreditAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD").ToTrustLineAsset(),
+				Balance:   balance,
+				Limit:     1000,
+			},
+		},
+	}
+}
+
+func clawbackOperation(clawedBack xdr.Int64, pre, post xdr.LedgerEntry) transactionOperationWrapper {
 	aid := xdr.MustAddress("GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD")
 	source := aid.ToMuxedAccount()
 	op := xdr.Operation{
@@ -2049,23 +2152,39 @@ func TestOperationEffectsClawback(t *testing.T) {
 			ClawbackOp: &xdr.ClawbackOp{
 				Asset:  xdr.MustNewCreditAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD"),
 				From:   xdr.MustMuxedAddress("GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3"),
-				Amount: 34,
+				Amount: clawedBack,
 			},
 		},
 	}
 
-	operation := transactionOperationWrapper{
+	return transactionOperationWrapper{
 		index: 0,
 		transaction: ingest.LedgerTransaction{
 			UnsafeMeta: xdr.TransactionMeta{
-				V:  2,
-				V2: &xdr.TransactionMetaV2{},
+				V: 2,
+				V2: &xdr.TransactionMetaV2{
+					Operations: []xdr.OperationMeta{{Changes: xdr.LedgerEntryChanges{
+						{
+							Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+							State: &pre,
+						},
+						{
+							Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+							Updated: &post,
+						},
+					}}},
+				},
 			},
 		},
 		operation:      op,
 		ledgerSequence: 1,
 		ledgerClosed:   genericCloseTime.UTC(),
 	}
+}
+
+func TestOperationEffectsClawback(t *testing.T) {
+	tt := assert.New(t)
+	operation := clawbackOperation(34, clawbackTrustlineEntry(100), clawbackTrustlineEntry(66))
 
 	effects, err := operation.effects()
 	tt.NoError(err)
@@ -2078,6 +2197,7 @@ func TestOperationEffectsClawback(t *testing.T) {
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(8456997905258871731),
 				"amount":       "0.0000034",
 			},
 			Type:         int32(EffectAccountCredited),
@@ -2091,22 +2211,64 @@ func TestOperationEffectsClawback(t *testing.T) {
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(8456997905258871731),
 				"amount":       "0.0000034",
 			},
 			Type:         int32(EffectAccountDebited),
 			TypeString:   EffectTypeNames[EffectAccountDebited],
 			LedgerClosed: genericCloseTime.UTC(),
 		},
+		{
+			Address:     "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"asset_code":      "COP",
+				"asset_issuer":    "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+				"asset_type":      "credit_alphanum4",
+				"asset_id":        int64(8456997905258871731),
+				"amount":          "0.0000034",
+				"clawback_source": "trustline",
+				"from":            "GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3",
+				"balance_before":  "0.0000100",
+				"balance_after":   "0.0000066",
+			},
+			Type:         int32(EffectAssetClawedBack),
+			TypeString:   EffectTypeNames[EffectAssetClawedBack],
+			LedgerClosed: genericCloseTime.UTC(),
+		},
 	}
 	tt.Equal(expected, effects)
 }
 
+func TestOperationEffectsClawbackBalanceMismatch(t *testing.T) {
+	tt := assert.New(t)
+	operation := clawbackOperation(34, clawbackTrustlineEntry(100), clawbackTrustlineEntry(70))
+
+	effects, err := operation.effects()
+	tt.NoError(err)
+
+	tt.Len(effects, 3)
+	tt.Equal(EffectAssetClawedBack, EffectType(effects[2].Type))
+	tt.Equal("0.0000034", effects[2].Details["amount"])
+	tt.Equal("0.0000030", effects[2].Details["trustline_delta"])
+}
+
 func TestOperationEffectsClawbackClaimableBalance(t *testing.T) {
 	tt := assert.New(t)
 	aid := xdr.MustAddress("GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD")
 	source := aid.ToMuxedAccount()
 	var balanceID xdr.ClaimableBalanceId
 	xdr.SafeUnmarshalBase64("AAAAANoNV9p9SFDn/BDSqdDrxzH3r7QFdMAzlbF9SRSbkfW+", &balanceID)
+	clawedBackEntry := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeClaimableBalance,
+			ClaimableBalance: &xdr.ClaimableBalanceEntry{
+				BalanceId: balanceID,
+				Asset:     xdr.MustNewCreditAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD"),
+				Amount:    34,
+			},
+		},
+	}
 	op := xdr.Operation{
 		SourceAccount: &source,
 		Body: xdr.OperationBody{
@@ -2121,8 +2283,22 @@ func TestOperationEffectsClawbackClaimableBalance(t *testing.T) {
 		index: 0,
 		transaction: ingest.LedgerTransaction{
 			UnsafeMeta: xdr.TransactionMeta{
-				V:  2,
-				V2: &xdr.TransactionMetaV2{},
+				V: 2,
+				V2: &xdr.TransactionMetaV2{
+					Operations: []xdr.OperationMeta{{Changes: xdr.LedgerEntryChanges{
+						{
+							Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+							State: &clawedBackEntry,
+						},
+						{
+							Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+							Removed: &xdr.LedgerKey{
+								Type:             xdr.LedgerEntryTypeClaimableBalance,
+								ClaimableBalance: &xdr.LedgerKeyClaimableBalance{BalanceId: balanceID},
+							},
+						},
+					}}},
+				},
 			},
 		},
 		operation:      op,
@@ -2138,12 +2314,47 @@ func TestOperationEffectsClawbackClaimableBalance(t *testing.T) {
 			Address:     "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 			OperationID: 4294967297,
 			Details: map[string]interface{}{
-				"balance_id": "00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be",
+				"balance_id":   "00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be",
+				"amount":       "0.0000034",
+				"asset_code":   "COP",
+				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(8456997905258871731),
 			},
 			Type:         int32(EffectClaimableBalanceClawedBack),
 			TypeString:   EffectTypeNames[EffectClaimableBalanceClawedBack],
 			LedgerClosed: genericCloseTime.UTC(),
 		},
+		{
+			Address:     "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"amount":       "0.0000034",
+				"asset_code":   "COP",
+				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(8456997905258871731),
+			},
+			Type:         int32(EffectAccountCredited),
+			TypeString:   EffectTypeNames[EffectAccountCredited],
+			LedgerClosed: genericCloseTime.UTC(),
+		},
+		{
+			Address:     "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"amount":          "0.0000034",
+				"asset_code":      "COP",
+				"asset_issuer":    "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+				"asset_type":      "credit_alphanum4",
+				"asset_id":        int64(8456997905258871731),
+				"balance_id":      "00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be",
+				"clawback_source": "claimable_balance",
+			},
+			Type:         int32(EffectAssetClawedBack),
+			TypeString:   EffectTypeNames[EffectAssetClawedBack],
+			LedgerClosed: genericCloseTime.UTC(),
+		},
 	}
 	tt.Equal(expected, effects)
 }
@@ -2192,6 +2403,7 @@ func TestOperationEffectsSetTrustLineFlags(t *testing.T) {
 				"asset_code":                        "USD",
 				"asset_issuer":                      "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":                        "credit_alphanum4",
+				"asset_id":                          int64(9197508166616672139),
 				"authorized_flag":                   false,
 				"authorized_to_maintain_liabilites": true,
 				"clawback_enabled_flag":             false,
@@ -2328,9 +2540,12 @@ func TestTrustlineSponsorhipEffects(t *testing.T) {
 			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 			OperationID: 4294967297,
 			Details: map[string]interface{}{
-				"asset": "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
-				// `asset_type` set in `Effect.UnmarshalDetails` to prevent reingestion
-				"sponsor": "GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2",
+				"asset":        "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_code":   "USD",
+				"asset_issuer": "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_type":   "credit_alphanum4",
+				"asset_id":     int64(-5393974927649603207),
+				"sponsor":      "GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2",
 			},
 			LedgerClosed: genericCloseTime.UTC(),
 		},
@@ -2340,8 +2555,11 @@ func TestTrustlineSponsorhipEffects(t *testing.T) {
 			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 			OperationID: 4294967297,
 			Details: map[string]interface{}{
-				"asset": "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
-				// `asset_type` set in `Effect.UnmarshalDetails` to prevent reingestion
+				"asset":          "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_code":     "USD",
+				"asset_issuer":   "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_type":     "credit_alphanum4",
+				"asset_id":       int64(-5393974927649603207),
 				"former_sponsor": "GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2",
 				"new_sponsor":    "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 			},
@@ -2353,8 +2571,11 @@ func TestTrustlineSponsorhipEffects(t *testing.T) {
 			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 			OperationID: 4294967297,
 			Details: map[string]interface{}{
-				"asset": "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
-				// `asset_type` set in `Effect.UnmarshalDetails` to prevent reingestion
+				"asset":          "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_code":     "USD",
+				"asset_issuer":   "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				"asset_type":     "credit_alphanum4",
+				"asset_id":       int64(-5393974927649603207),
 				"former_sponsor": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 			},
 			LedgerClosed: genericCloseTime.UTC(),
@@ -2777,6 +2998,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 						"asset_code":   "USD",
 						"asset_issuer": "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     int64(-5393974927649603207),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -2788,6 +3010,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "0.0000010",
 						"asset_type": "native",
+						"asset_id":   int64(-5706705804583548011),
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -2962,6 +3185,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 						"asset_code":      "USD",
 						"asset_issuer":    "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						"asset_type":      "credit_alphanum4",
+						"asset_id":        int64(-5393974927649603207),
 						"authorized_flag": false,
 						"trustor":         "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 					},
@@ -2985,10 +3209,11 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 					OperationID: 4294967297,
 					Details: map[string]interface{}{
-						"amount":     "0.0000100",
-						"asset":      "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
-						"balance_id": "000000000a0b000000000000000000000000000000000000000000000000000000000000",
-						"predicate":  xdr.ClaimPredicate{},
+						"amount":         "0.0000100",
+						"asset":          "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+						"balance_id":     "000000000a0b000000000000000000000000000000000000000000000000000000000000",
+						"predicate":      xdr.ClaimPredicate{},
+						"predicate_json": "unconditional",
 					},
 					LedgerClosed: genericCloseTime.UTC(),
 				},
@@ -3374,6 +3599,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountDebited),
@@ -3387,6 +3613,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountCredited),
@@ -3409,6 +3636,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            fromContract,
 						"contract_event_type": "transfer",
 					},
@@ -3423,6 +3651,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            toContract,
 						"contract_event_type": "transfer",
 					},
@@ -3444,6 +3673,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "mint",
 					},
 					Type:         int32(EffectAccountCredited),
@@ -3464,6 +3694,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "burn",
 					},
 					Type:         int32(EffectAccountDebited),
@@ -3485,6 +3716,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            fromContract,
 						"contract_event_type": "burn",
 					},
@@ -3506,6 +3738,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "clawback",
 					},
 					Type:         int32(EffectAccountDebited),
@@ -3527,6 +3760,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            fromContract,
 						"contract_event_type": "clawback",
 					},
@@ -3546,6 +3780,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":              "0.0012345",
 						"asset_type":          "native",
+						"asset_id":            int64(-5706705804583548011),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountDebited),
@@ -3557,6 +3792,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":              "0.0012345",
 						"asset_type":          "native",
+						"asset_id":            int64(-5706705804583548011),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountCredited),
@@ -3578,6 +3814,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountDebited),
@@ -3591,6 +3828,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            toContract,
 						"contract_event_type": "transfer",
 					},
@@ -3613,6 +3851,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract":            fromContract,
 						"contract_event_type": "transfer",
 					},
@@ -3627,6 +3866,7 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
 						"contract_event_type": "transfer",
 					},
 					Type:         int32(EffectAccountCredited),
@@ -3847,6 +4087,11 @@ func TestBumpFootprintExpirationEffects(t *testing.T) {
 					"entries": []string{
 						ledgerEntryKeyStr,
 					},
+					"entries_json": []interface{}{
+						map[string]interface{}{
+							"ttl": map[string]interface{}{"key_hash": "0000000000000000000000000000000000000000000000000000000000000000"},
+						},
+					},
 					"extend_to": xdr.Uint32(1234),
 				},
 				Type:         int32(EffectExtendFootprintTtl),
@@ -3962,6 +4207,11 @@ func TestAddRestoreFootprintExpirationEffect(t *testing.T) {
 					"entries": []string{
 						ledgerEntryKeyStr,
 					},
+					"entries_json": []interface{}{
+						map[string]interface{}{
+							"ttl": map[string]interface{}{"key_hash": "0000000000000000000000000000000000000000000000000000000000000000"},
+						},
+					},
 				},
 				Type:         int32(EffectRestoreFootprint),
 				TypeString:   EffectTypeNames[EffectRestoreFootprint],
@@ -3971,3 +4221,95 @@ func TestAddRestoreFootprintExpirationEffect(t *testing.T) {
 		effects,
 	)
 }
+
+func makeContractBalanceEntry(contractID xdr.Hash, holder xdr.ScAddress, balance uint64) *xdr.LedgerEntry {
+	balanceSym := xdr.ScSymbol("Balance")
+	amountSym := xdr.ScSymbol("amount")
+	authorizedSym := xdr.ScSymbol("authorized")
+	clawbackSym := xdr.ScSymbol("clawback")
+	trueVal := true
+	falseVal := false
+
+	keyVec := &xdr.ScVec{
+		{Type: xdr.ScValTypeScvSymbol, Sym: &balanceSym},
+		{Type: xdr.ScValTypeScvAddress, Address: &holder},
+	}
+	balanceMap := &xdr.ScMap{
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &amountSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Hi: 0, Lo: xdr.Uint64(balance)}},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &authorizedSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &trueVal},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &clawbackSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &falseVal},
+		},
+	}
+
+	return &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &keyVec},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &balanceMap},
+			},
+		},
+	}
+}
+
+func TestContractBalanceDelta(t *testing.T) {
+	contractID := xdr.Hash{1}
+	holderID := xdr.Hash{2}
+	holder := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &holderID}
+	accountID := xdr.MustAddress(testAccount1Address)
+	accountHolder := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &accountID}
+	contractAddress := strkey.MustEncode(strkey.VersionByteContract, contractID[:])
+	holderAddress := strkey.MustEncode(strkey.VersionByteContract, holderID[:])
+
+	tests := []struct {
+		name       string
+		change     ingest.Change
+		wantHolder string
+		wantDelta  *big.Int
+	}{
+		{
+			name:       "updated",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeContractData, Pre: makeContractBalanceEntry(contractID, holder, 100), Post: makeContractBalanceEntry(contractID, holder, 40)},
+			wantHolder: holderAddress,
+			wantDelta:  big.NewInt(-60),
+		},
+		{
+			name:       "created",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeContractData, Post: makeContractBalanceEntry(contractID, holder, 25)},
+			wantHolder: holderAddress,
+			wantDelta:  big.NewInt(25),
+		},
+		{
+			name:       "removed",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeContractData, Pre: makeContractBalanceEntry(contractID, holder, 25)},
+			wantHolder: holderAddress,
+			wantDelta:  big.NewInt(-25),
+		},
+		{
+			name:       "account holder",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeContractData, Pre: makeContractBalanceEntry(contractID, accountHolder, 10), Post: makeContractBalanceEntry(contractID, accountHolder, 30)},
+			wantHolder: testAccount1Address,
+			wantDelta:  big.NewInt(20),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotContract, gotHolder, gotDelta, ok := contractBalanceDelta(test.change, "")
+			assert.True(t, ok)
+			assert.Equal(t, contractAddress, gotContract)
+			assert.Equal(t, test.wantHolder, gotHolder)
+			assert.Equal(t, 0, test.wantDelta.Cmp(gotDelta))
+		})
+	}
+}
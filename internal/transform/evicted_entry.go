@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// EvictedLedgerKeys returns the keys of the temporary and persistent entries evicted from the bucket list when the ledger
+// closed. Only ledgers closed with protocol 20 or later carry evictions.
+func EvictedLedgerKeys(lcm xdr.LedgerCloseMeta) ([]xdr.LedgerKey, error) {
+	v1, ok := lcm.GetV1()
+	if !ok {
+		return nil, nil
+	}
+
+	keys := append([]xdr.LedgerKey{}, v1.EvictedTemporaryLedgerKeys...)
+	for _, entry := range v1.EvictedPersistentLedgerEntries {
+		key, err := entry.LedgerKey()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the key of an entry evicted in ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// TransformEvictedEntry converts the key of an entry evicted from the bucket list into a form suitable for BigQuery
+func TransformEvictedEntry(key xdr.LedgerKey, header xdr.LedgerHeaderHistoryEntry) (EvictedEntryOutput, error) {
+	encodedKey, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return EvictedEntryOutput{}, fmt.Errorf("could not encode the evicted ledger key: %v", err)
+	}
+
+	var durability, contractId string
+	switch key.Type {
+	case xdr.LedgerEntryTypeContractData:
+		durability = key.MustContractData().Durability.String()
+		contractId, err = ledgerKeyContractId(key)
+		if err != nil {
+			return EvictedEntryOutput{}, err
+		}
+	case xdr.LedgerEntryTypeContractCode:
+		durability = xdr.ContractDataDurabilityPersistent.String()
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return EvictedEntryOutput{}, err
+	}
+
+	return EvictedEntryOutput{
+		LedgerKey:       encodedKey,
+		LedgerKeyHash:   utils.LedgerKeyToLedgerKeyHash(key),
+		LedgerEntryType: key.Type.String(),
+		Durability:      durability,
+		ContractId:      contractId,
+		ClosedAt:        closedAt,
+		LedgerSequence:  uint32(header.Header.LedgerSeq),
+	}, nil
+}
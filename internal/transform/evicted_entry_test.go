@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func TestTransformEvictedEntry(t *testing.T) {
+	contractId := xdr.Hash{1}
+	var contractData xdr.LedgerKey
+	err := contractData.SetContractData(
+		xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractId},
+		xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+		xdr.ContractDataDurabilityTemporary,
+	)
+	assert.NoError(t, err)
+
+	var contractCode xdr.LedgerKey
+	err = contractCode.SetContractCode(xdr.Hash{2})
+	assert.NoError(t, err)
+
+	encodedContractId, err := strkey.Encode(strkey.VersionByteContract, contractId[:])
+	assert.NoError(t, err)
+
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+
+	tests := []struct {
+		key        xdr.LedgerKey
+		wantOutput EvictedEntryOutput
+	}{
+		{
+			contractData,
+			EvictedEntryOutput{
+				LedgerEntryType: "LedgerEntryTypeContractData",
+				Durability:      "ContractDataDurabilityTemporary",
+				ContractId:      encodedContractId,
+			},
+		},
+		{
+			contractCode,
+			EvictedEntryOutput{
+				LedgerEntryType: "LedgerEntryTypeContractCode",
+				Durability:      "ContractDataDurabilityPersistent",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		encodedKey, err := xdr.MarshalBase64(test.key)
+		assert.NoError(t, err)
+		test.wantOutput.LedgerKey = encodedKey
+		test.wantOutput.LedgerKeyHash = utils.LedgerKeyToLedgerKeyHash(test.key)
+		test.wantOutput.ClosedAt = time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC)
+		test.wantOutput.LedgerSequence = 10
+
+		actualOutput, actualError := TransformEvictedEntry(test.key, header)
+		assert.NoError(t, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func TestEvictedLedgerKeys(t *testing.T) {
+	var temporary xdr.LedgerKey
+	err := temporary.SetTtl(xdr.Hash{3})
+	assert.NoError(t, err)
+
+	code := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:         xdr.LedgerEntryTypeContractCode,
+			ContractCode: &xdr.ContractCodeEntry{Hash: xdr.Hash{4}},
+		},
+	}
+	persistent, err := code.LedgerKey()
+	assert.NoError(t, err)
+
+	keys, err := EvictedLedgerKeys(xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			EvictedTemporaryLedgerKeys:     []xdr.LedgerKey{temporary},
+			EvictedPersistentLedgerEntries: []xdr.LedgerEntry{code},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []xdr.LedgerKey{temporary, persistent}, keys)
+
+	keys, err = EvictedLedgerKeys(xdr.LedgerCloseMeta{V: 0, V0: &xdr.LedgerCloseMetaV0{}})
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// The fuzz targets below decode mutated XDR and feed it to the transforms. Malformed values, or values of a protocol
+// newer than the one the transforms know about, have to return an error instead of panicking in the middle of a
+// backfill. Run one of them with e.g. go test ./internal/transform -run '^$' -fuzz FuzzTransformOperation
+
+func FuzzTransformOperation(f *testing.F) {
+	transaction, err := makeOperationTestInput()
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, op := range transaction.Envelope.Operations() {
+		seed, err := op.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed)
+	}
+	ledgerCloseMeta := makeLedgerCloseMeta()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var op xdr.Operation
+		if err := xdr.SafeUnmarshal(data, &op); err != nil {
+			return
+		}
+
+		// the operation has to be part of the envelope of its transaction, since some details are read from there
+		envelope := *transaction.Envelope.V1
+		envelope.Tx.Operations = []xdr.Operation{op}
+		fuzzedTransaction := transaction
+		fuzzedTransaction.Envelope.V1 = &envelope
+
+		TransformOperation(op, 0, fuzzedTransaction, 0, ledgerCloseMeta, "")
+	})
+}
+
+func FuzzTransformContractData(f *testing.F) {
+	for _, change := range makeContractDataTestInput() {
+		// the fixtures leave the value of the entry unset, which cannot be encoded
+		entry := *change.Post
+		contractData := *entry.Data.ContractData
+		contractData.Val = xdr.ScVal{Type: xdr.ScValTypeScvVoid}
+		entry.Data.ContractData = &contractData
+		seed, err := entry.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed)
+	}
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+	transformer := NewTransformContractDataStruct(AssetFromContractData, ContractBalanceFromContractData)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var entry xdr.LedgerEntry
+		if err := xdr.SafeUnmarshal(data, &entry); err != nil {
+			return
+		}
+
+		change := ingest.Change{Type: entry.Data.Type, Post: &entry}
+		transformer.TransformContractData(change, "unit test", header)
+	})
+}
+
+func FuzzScValDecoding(f *testing.F) {
+	symbol := xdr.ScSymbol("transfer")
+	str := xdr.ScString("a")
+	bytes := xdr.ScBytes{0, 1, 2}
+	u128 := xdr.UInt128Parts{Hi: 1, Lo: 2}
+	vec := &xdr.ScVec{{Type: xdr.ScValTypeScvSymbol, Sym: &symbol}}
+	scMap := &xdr.ScMap{{
+		Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &symbol},
+		Val: xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &str},
+	}}
+	for _, val := range []xdr.ScVal{
+		{Type: xdr.ScValTypeScvVoid},
+		{Type: xdr.ScValTypeScvSymbol, Sym: &symbol},
+		{Type: xdr.ScValTypeScvBytes, Bytes: &bytes},
+		{Type: xdr.ScValTypeScvU128, U128: &u128},
+		{Type: xdr.ScValTypeScvVec, Vec: &vec},
+		{Type: xdr.ScValTypeScvMap, Map: &scMap},
+	} {
+		seed, err := val.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var val xdr.ScVal
+		if err := xdr.SafeUnmarshal(data, &val); err != nil {
+			return
+		}
+
+		utils.XdrJSON.ScVal(val)
+		renderEventTopics([]xdr.ScVal{val})
+		decodeEventTopics([]xdr.ScVal{val})
+	})
+}
@@ -12,7 +12,9 @@ import (
 )
 
 // TransformLedger converts a ledger from the history archive ingestion system into a form suitable for BigQuery
-func TransformLedger(inputLedger historyarchive.Ledger, lcm xdr.LedgerCloseMeta) (LedgerOutput, error) {
+func TransformLedger(inputLedger historyarchive.Ledger, lcm xdr.LedgerCloseMeta) (_ LedgerOutput, err error) {
+	defer recoverPanic(&err)
+
 	ledgerHeader := inputLedger.Header.Header
 
 	outputSequence := uint32(ledgerHeader.LedgerSeq)
@@ -0,0 +1,101 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/stellar/stellar-etl/internal/utils"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// TransformLedgerStats aggregates the transactions of a ledger into network statistics
+func TransformLedgerStats(lcm xdr.LedgerCloseMeta, passphrase string) (_ LedgerStatsOutput, err error) {
+	defer recoverPanic(&err)
+
+	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(passphrase, lcm)
+	if err != nil {
+		return LedgerStatsOutput{}, fmt.Errorf("could not read transactions of ledger %d: %v", lcm.LedgerSequence(), err)
+	}
+	defer txReader.Close()
+
+	transactions := []ingest.LedgerTransaction{}
+	for {
+		tx, err := txReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return LedgerStatsOutput{}, fmt.Errorf("could not read transaction from ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return ledgerStats(lcm.LedgerHeaderHistoryEntry(), transactions)
+}
+
+func ledgerStats(lhe xdr.LedgerHeaderHistoryEntry, transactions []ingest.LedgerTransaction) (LedgerStatsOutput, error) {
+	outputSequence := uint32(lhe.Header.LedgerSeq)
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(lhe.Header.ScpValue.CloseTime)
+	if err != nil {
+		return LedgerStatsOutput{}, fmt.Errorf("for ledger %d: %v", outputSequence, err)
+	}
+
+	stats := LedgerStatsOutput{
+		Sequence:              outputSequence,
+		ClosedAt:              outputCloseTime,
+		OperationCountsByType: map[string]int32{},
+	}
+
+	sourceAccounts := map[string]struct{}{}
+	var totalFeeCharged int64
+	for _, transaction := range transactions {
+		stats.TransactionCount++
+		if transaction.Result.Successful() {
+			stats.SuccessfulTransactionCount++
+		} else {
+			stats.FailedTransactionCount++
+		}
+
+		if isSorobanTransaction(transaction) {
+			stats.SorobanTransactionCount++
+		} else {
+			stats.ClassicTransactionCount++
+		}
+
+		sourceAccount := transaction.Envelope.SourceAccount().ToAccountId()
+		sourceAccounts[sourceAccount.Address()] = struct{}{}
+		totalFeeCharged += int64(transaction.Result.Result.FeeCharged)
+
+		for _, operation := range transaction.Envelope.Operations() {
+			operationType, err := mapOperationType(operation)
+			if err != nil {
+				return LedgerStatsOutput{}, fmt.Errorf("for ledger %d; transaction %d: %v", outputSequence, transaction.Index, err)
+			}
+			stats.OperationCount++
+			stats.OperationCountsByType[operationType]++
+		}
+	}
+
+	stats.UniqueSourceAccounts = int32(len(sourceAccounts))
+	if stats.TransactionCount > 0 {
+		stats.FailedTransactionRate = float64(stats.FailedTransactionCount) / float64(stats.TransactionCount)
+		stats.AverageFeeCharged = float64(totalFeeCharged) / float64(stats.TransactionCount)
+	}
+
+	return stats, nil
+}
+
+// isSorobanTransaction reports whether the transaction, or the inner transaction of a fee bump, carries Soroban data
+func isSorobanTransaction(transaction ingest.LedgerTransaction) bool {
+	var hasSorobanData bool
+	switch transaction.Envelope.Type {
+	case xdr.EnvelopeTypeEnvelopeTypeTx:
+		_, hasSorobanData = transaction.Envelope.V1.Tx.Ext.GetSorobanData()
+	case xdr.EnvelopeTypeEnvelopeTypeTxFeeBump:
+		_, hasSorobanData = transaction.Envelope.FeeBump.Tx.InnerTx.V1.Tx.Ext.GetSorobanData()
+	}
+
+	return hasSorobanData
+}
@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func TestLedgerStats(t *testing.T) {
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1000,
+			},
+			LedgerSeq: 10,
+		},
+	}
+
+	actualOutput, actualError := ledgerStats(header, makeLedgerStatsTestInput())
+	assert.NoError(t, actualError)
+	assert.Equal(t, LedgerStatsOutput{
+		Sequence:                   10,
+		ClosedAt:                   time.Unix(1000, 0).UTC(),
+		TransactionCount:           3,
+		SuccessfulTransactionCount: 2,
+		FailedTransactionCount:     1,
+		FailedTransactionRate:      1.0 / 3.0,
+		OperationCount:             4,
+		OperationCountsByType: map[string]int32{
+			"payment":       2,
+			"bump_sequence": 1,
+			"manage_data":   1,
+		},
+		UniqueSourceAccounts:    2,
+		AverageFeeCharged:       200,
+		SorobanTransactionCount: 1,
+		ClassicTransactionCount: 2,
+	}, actualOutput)
+}
+
+func TestLedgerStatsEmptyLedger(t *testing.T) {
+	actualOutput, actualError := ledgerStats(xdr.LedgerHeaderHistoryEntry{}, []ingest.LedgerTransaction{})
+	assert.NoError(t, actualError)
+	assert.Equal(t, int32(0), actualOutput.TransactionCount)
+	assert.Equal(t, 0.0, actualOutput.FailedTransactionRate)
+	assert.Equal(t, 0.0, actualOutput.AverageFeeCharged)
+}
+
+func makeLedgerStatsTestInput() []ingest.LedgerTransaction {
+	payment := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: testAccount2,
+				Asset:       nativeAsset,
+				Amount:      100,
+			},
+		},
+	}
+	bumpSequence := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type:           xdr.OperationTypeBumpSequence,
+			BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 100},
+		},
+	}
+	manageData := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type:         xdr.OperationTypeManageData,
+			ManageDataOp: &xdr.ManageDataOp{DataName: "test"},
+		},
+	}
+
+	makeTransaction := func(source xdr.MuxedAccount, successful bool, feeCharged xdr.Int64, ext xdr.TransactionExt, operations ...xdr.Operation) ingest.LedgerTransaction {
+		result := utils.CreateSampleResultMeta(successful, len(operations)).Result
+		result.Result.FeeCharged = feeCharged
+		return ingest.LedgerTransaction{
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: source,
+						Operations:    operations,
+						Ext:           ext,
+					},
+				},
+			},
+			Result: result,
+		}
+	}
+
+	sorobanExt := xdr.TransactionExt{
+		V:           1,
+		SorobanData: &xdr.SorobanTransactionData{},
+	}
+
+	return []ingest.LedgerTransaction{
+		makeTransaction(testAccount1, true, 100, xdr.TransactionExt{}, payment),
+		makeTransaction(testAccount1, false, 200, xdr.TransactionExt{}, bumpSequence, payment),
+		makeTransaction(testAccount3, true, 300, sorobanExt, manageData),
+	}
+}
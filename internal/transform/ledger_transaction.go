@@ -10,7 +10,9 @@ import (
 )
 
 // TransformTransaction converts a transaction from the history archive ingestion system into a form suitable for BigQuery
-func TransformLedgerTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (LedgerTransactionOutput, error) {
+func TransformLedgerTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (_ LedgerTransactionOutput, err error) {
+	defer recoverPanic(&err)
+
 	ledgerHeader := lhe.Header
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
 
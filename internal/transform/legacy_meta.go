@@ -0,0 +1,50 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/ingest"
+)
+
+// The ingest package refuses the TransactionMeta V0 of the transactions applied before protocol 10, which would make
+// the transforms fail on genesis-era ledgers. V0 meta only records the changes of each operation, so the helpers below
+// read them directly and defer to the ingest package for later meta versions.
+
+// operationChanges returns the changes of the operation at operationIndex, like LedgerTransaction.GetOperationChanges
+func operationChanges(transaction ingest.LedgerTransaction, operationIndex uint32) ([]ingest.Change, error) {
+	if transaction.UnsafeMeta.V != 0 {
+		return transaction.GetOperationChanges(operationIndex)
+	}
+
+	// Failed transactions don't have operation changes
+	if !transaction.Result.Successful() {
+		return []ingest.Change{}, nil
+	}
+
+	operations, ok := transaction.UnsafeMeta.GetOperations()
+	if !ok {
+		return nil, fmt.Errorf("transaction meta V0 has no operations")
+	}
+	if int(operationIndex) >= len(operations) {
+		return nil, fmt.Errorf("operation index %d out of range of the %d operations of the transaction meta", operationIndex, len(operations))
+	}
+	return ingest.GetChangesFromLedgerEntryChanges(operations[operationIndex].Changes), nil
+}
+
+// transactionChanges returns all the changes of a transaction except the fee changes, like LedgerTransaction.GetChanges
+func transactionChanges(transaction ingest.LedgerTransaction) ([]ingest.Change, error) {
+	if transaction.UnsafeMeta.V != 0 {
+		return transaction.GetChanges()
+	}
+
+	changes := []ingest.Change{}
+	if !transaction.Result.Successful() {
+		return changes, nil
+	}
+
+	operations, _ := transaction.UnsafeMeta.GetOperations()
+	for _, operation := range operations {
+		changes = append(changes, ingest.GetChangesFromLedgerEntryChanges(operation.Changes)...)
+	}
+	return changes, nil
+}
@@ -0,0 +1,183 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeLegacyTransaction creates a path payment submitted in a V0 envelope, with the TransactionMeta V0 and the claim
+// atoms of the ledgers closed before protocol 10
+func makeLegacyTransaction(successful bool) ingest.LedgerTransaction {
+	operation := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictReceive,
+			PathPaymentStrictReceiveOp: &xdr.PathPaymentStrictReceiveOp{
+				SendAsset:   nativeAsset,
+				SendMax:     20000000,
+				Destination: testAccount2,
+				DestAsset:   usdtAsset,
+				DestAmount:  30000000,
+			},
+		},
+	}
+
+	resultCode := xdr.TransactionResultCodeTxFailed
+	if successful {
+		resultCode = xdr.TransactionResultCodeTxSuccess
+	}
+	results := []xdr.OperationResult{
+		{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type: xdr.OperationTypePathPaymentStrictReceive,
+				PathPaymentStrictReceiveResult: &xdr.PathPaymentStrictReceiveResult{
+					Code: xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess,
+					Success: &xdr.PathPaymentStrictReceiveResultSuccess{
+						Offers: []xdr.ClaimAtom{
+							{
+								Type: xdr.ClaimAtomTypeClaimAtomTypeV0,
+								V0: &xdr.ClaimOfferAtomV0{
+									SellerEd25519: *testAccount3ID.Ed25519,
+									OfferId:       42,
+									AssetSold:     usdtAsset,
+									AmountSold:    30000000,
+									AssetBought:   nativeAsset,
+									AmountBought:  10000000,
+								},
+							},
+						},
+						Last: xdr.SimplePaymentResult{
+							Destination: testAccount2ID,
+							Asset:       usdtAsset,
+							Amount:      30000000,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	accountEntry := func(balance xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: testAccount1ID,
+					Balance:   balance,
+				},
+			},
+		}
+	}
+
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTxV0,
+			V0: &xdr.TransactionV0Envelope{
+				Tx: xdr.TransactionV0{
+					SourceAccountEd25519: *testAccount1ID.Ed25519,
+					Fee:                  100,
+					SeqNum:               1,
+					Operations:           []xdr.Operation{operation},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    resultCode,
+					Results: &results,
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 0,
+			Operations: &[]xdr.OperationMeta{
+				{
+					Changes: xdr.LedgerEntryChanges{
+						{
+							Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+							State: accountEntry(50000000),
+						},
+						{
+							Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+							Updated: accountEntry(40000000),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestOperationChangesMetaV0(t *testing.T) {
+	transaction := makeLegacyTransaction(true)
+
+	changes, err := operationChanges(transaction, 0)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, xdr.Int64(50000000), changes[0].Pre.Data.MustAccount().Balance)
+	assert.Equal(t, xdr.Int64(40000000), changes[0].Post.Data.MustAccount().Balance)
+
+	allChanges, err := transactionChanges(transaction)
+	assert.NoError(t, err)
+	assert.Equal(t, changes, allChanges)
+
+	_, err = operationChanges(transaction, 1)
+	assert.EqualError(t, err, "operation index 1 out of range of the 1 operations of the transaction meta")
+
+	failed := makeLegacyTransaction(false)
+	changes, err = operationChanges(failed, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	allChanges, err = transactionChanges(failed)
+	assert.NoError(t, err)
+	assert.Empty(t, allChanges)
+}
+
+func TestTransformOperationLegacyTransaction(t *testing.T) {
+	transaction := makeLegacyTransaction(true)
+	operation := transaction.Envelope.Operations()[0]
+
+	output, err := TransformOperation(operation, 0, transaction, 1, makeLedgerCloseMeta(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, testAccount1Address, output.SourceAccount)
+	assert.Empty(t, output.SourceAccountMuxed)
+	assert.Equal(t, "path_payment_strict_receive", output.TypeString)
+	assert.Equal(t, testAccount1Address, output.OperationDetails["from"])
+	assert.Equal(t, testAccount2Address, output.OperationDetails["to"])
+	assert.Equal(t, 1.0, output.OperationDetails["source_amount"])
+
+	legs, ok := output.OperationDetails["legs"].([]PathPaymentLeg)
+	assert.True(t, ok)
+	assert.Len(t, legs, 1)
+	assert.Equal(t, null.StringFrom(testAccount3Address), legs[0].SellerAddress)
+	assert.Equal(t, null.IntFrom(42), legs[0].OfferID)
+}
+
+func TestEffectsLegacyTransaction(t *testing.T) {
+	transaction := makeLegacyTransaction(true)
+	wrapper := transactionOperationWrapper{
+		index:          0,
+		transaction:    transaction,
+		operation:      transaction.Envelope.Operations()[0],
+		ledgerSequence: 1,
+		ledgerClosed:   genericCloseTime.UTC(),
+	}
+
+	effects, err := wrapper.effects()
+	assert.NoError(t, err)
+
+	types := []string{}
+	for _, effect := range effects {
+		types = append(types, effect.TypeString)
+	}
+	assert.Contains(t, types, EffectTypeNames[EffectAccountCredited])
+	assert.Contains(t, types, EffectTypeNames[EffectAccountDebited])
+	assert.Contains(t, types, EffectTypeNames[EffectTrade])
+}
@@ -46,12 +46,12 @@ func TransformOffer(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEn
 		return OfferOutput{}, fmt.Errorf("amount is negative (%d) for offer %d", outputAmount, outputOfferID)
 	}
 
-	outputPriceN := int32(offerEntry.Price.N)
+	outputPriceN := int64(offerEntry.Price.N)
 	if outputPriceN < 0 {
 		return OfferOutput{}, fmt.Errorf("price numerator is negative (%d) for offer %d", outputPriceN, outputOfferID)
 	}
 
-	outputPriceD := int32(offerEntry.Price.D)
+	outputPriceD := int64(offerEntry.Price.D)
 	if outputPriceD == 0 {
 		return OfferOutput{}, fmt.Errorf("price denominator is 0 for offer %d", outputOfferID)
 	}
@@ -91,6 +91,7 @@ func TransformOffer(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEn
 		PriceN:             outputPriceN,
 		PriceD:             outputPriceD,
 		Price:              outputPrice,
+		PriceDecimal:       utils.PriceToDecimalString(outputPriceN, outputPriceD),
 		Flags:              outputFlags,
 		LastModifiedLedger: outputLastModifiedLedger,
 		LedgerEntryChange:  uint32(changeType),
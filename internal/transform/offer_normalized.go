@@ -165,6 +165,9 @@ func extractDimOffer(offer OfferOutput, buyingAsset, sellingAsset string, market
 		BaseAmount:    offer.Amount,
 		CounterAmount: float64(offer.Amount) * offer.Price,
 		Price:         offer.Price,
+		PriceN:        offer.PriceN,
+		PriceD:        offer.PriceD,
+		PriceDecimal:  offer.PriceDecimal,
 	}, nil
 }
 
@@ -109,6 +109,9 @@ func makeOfferNormalizedTestOutput() NormalizedOfferOutput {
 			BaseAmount:    262.8450327,
 			CounterAmount: 135.16473161502083,
 			Price:         0.5142373444404865,
+			PriceN:        920936891,
+			PriceD:        1790879058,
+			PriceDecimal:  "0.5142373444404865",
 		},
 		Account: DimAccount{
 			Address: testAccount1Address,
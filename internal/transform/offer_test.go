@@ -173,6 +173,7 @@ func makeOfferTestOutput() OfferOutput {
 		PriceN:             920936891,
 		PriceD:             1790879058,
 		Price:              0.5142373444404865,
+		PriceDecimal:       "0.5142373444404865",
 		Flags:              2,
 		LastModifiedLedger: 30715263,
 		LedgerEntryChange:  2,
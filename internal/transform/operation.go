@@ -2,7 +2,9 @@ package transform
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -27,7 +29,9 @@ type liquidityPoolDelta struct {
 }
 
 // TransformOperation converts an operation from the history archive ingestion system into a form suitable for BigQuery
-func TransformOperation(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, ledgerCloseMeta xdr.LedgerCloseMeta, network string) (OperationOutput, error) {
+func TransformOperation(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, ledgerCloseMeta xdr.LedgerCloseMeta, network string) (_ OperationOutput, err error) {
+	defer recoverPanic(&err)
+
 	outputTransactionID := toid.New(ledgerSeq, int32(transaction.Index), 0).ToInt64()
 	outputOperationID := toid.New(ledgerSeq, int32(transaction.Index), operationIndex+1).ToInt64() //operationIndex needs +1 increment to stay in sync with ingest package
 
@@ -38,12 +42,14 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 	}
 
 	var outputSourceAccountMuxed null.String
+	var outputSourceMuxedID uint64
 	if sourceAccount.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
 		muxedAddress, err := sourceAccount.GetAddress()
 		if err != nil {
 			return OperationOutput{}, err
 		}
 		outputSourceAccountMuxed = null.StringFrom(muxedAddress)
+		outputSourceMuxedID = uint64(sourceAccount.Med25519.Id)
 	}
 
 	outputOperationType := int32(operation.Body.Type)
@@ -51,7 +57,7 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 		return OperationOutput{}, fmt.Errorf("the operation type (%d) is negative for  operation %d (operation id=%d)", outputOperationType, operationIndex, outputOperationID)
 	}
 
-	outputDetails, err := extractOperationDetails(operation, transaction, operationIndex, network)
+	outputDetails, err := extractOperationDetails(operation, transaction, operationIndex, ledgerSeq, network)
 	if err != nil {
 		return OperationOutput{}, err
 	}
@@ -80,9 +86,20 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 		}
 	}
 
+	outputParticipants, err := operationParticipants(operation, operationIndex, transaction, ledgerSeq, network)
+	if err != nil {
+		return OperationOutput{}, err
+	}
+
+	outputSponsor, err := operationSponsor(operation, operationIndex, transaction)
+	if err != nil {
+		return OperationOutput{}, fmt.Errorf("for operation %d (ledger id=%d): %v", operationIndex, outputOperationID, err)
+	}
+
 	transformedOperation := OperationOutput{
 		SourceAccount:       outputSourceAccount,
 		SourceAccountMuxed:  outputSourceAccountMuxed.String,
+		SourceMuxedID:       outputSourceMuxedID,
 		Type:                outputOperationType,
 		TypeString:          outputOperationTypeString,
 		TransactionID:       outputTransactionID,
@@ -91,6 +108,8 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 		ClosedAt:            outputCloseTime,
 		OperationResultCode: outputOperationResultCode,
 		OperationTraceCode:  outputOperationTraceCode,
+		Participants:        outputParticipants,
+		Sponsor:             outputSponsor,
 	}
 
 	return transformedOperation, nil
@@ -156,7 +175,7 @@ func mapOperationType(operation xdr.Operation) (string, error) {
 	case xdr.OperationTypeRestoreFootprint:
 		op_string_type = "restore_footprint"
 	default:
-		return op_string_type, fmt.Errorf("unknown operation type: %s", operation.Body.Type.String())
+		return op_string_type, utils.UnknownVariantError{Kind: "operation type", Value: operation.Body.Type.String()}
 	}
 	return op_string_type, nil
 }
@@ -221,7 +240,7 @@ func mapOperationTrace(operationTrace xdr.OperationResultTr) (string, error) {
 	case xdr.OperationTypeRestoreFootprint:
 		operationTraceDescription = operationTrace.RestoreFootprintResult.Code.String()
 	default:
-		return operationTraceDescription, fmt.Errorf("unknown operation type: %s", operationTrace.Type.String())
+		return operationTraceDescription, utils.UnknownVariantError{Kind: "operation type", Value: operationTrace.Type.String()}
 	}
 	return operationTraceDescription, nil
 }
@@ -232,7 +251,7 @@ func PoolIDToString(id xdr.PoolId) string {
 
 // operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32
 func getLiquidityPoolAndProductDelta(operationIndex int32, transaction ingest.LedgerTransaction, lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
-	changes, err := transaction.GetOperationChanges(uint32(operationIndex))
+	changes, err := operationChanges(transaction, uint32(operationIndex))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -280,6 +299,31 @@ func getLiquidityPoolAndProductDelta(operationIndex int32, transaction ingest.Le
 	return nil, nil, fmt.Errorf("liquidity pool change not found")
 }
 
+// getClaimedClaimableBalance returns the claimable balance entry the operation removed when claiming it. It returns nil
+// when the operation has no change removing the balance, e.g. because the transaction failed.
+func getClaimedClaimableBalance(operationIndex int32, transaction ingest.LedgerTransaction, balanceID string) (*xdr.ClaimableBalanceEntry, error) {
+	changes, err := operationChanges(transaction, uint32(operationIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		if c.Type != xdr.LedgerEntryTypeClaimableBalance || c.Pre == nil || c.Post != nil {
+			continue
+		}
+		balance := c.Pre.Data.MustClaimableBalance()
+		preBalanceID, err := xdr.MarshalHex(balance.BalanceId)
+		if err != nil {
+			return nil, err
+		}
+		if preBalanceID == balanceID {
+			return &balance, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func getOperationSourceAccount(operation xdr.Operation, transaction ingest.LedgerTransaction) xdr.MuxedAccount {
 	sourceAccount := operation.SourceAccount
 	if sourceAccount != nil {
@@ -290,7 +334,7 @@ func getOperationSourceAccount(operation xdr.Operation, transaction ingest.Ledge
 }
 
 func getSponsor(operation xdr.Operation, transaction ingest.LedgerTransaction, operationIndex int32) (*xdr.AccountId, error) {
-	changes, err := transaction.GetOperationChanges(uint32(operationIndex))
+	changes, err := operationChanges(transaction, uint32(operationIndex))
 	if err != nil {
 		return nil, err
 	}
@@ -371,7 +415,7 @@ func addAssetDetailsToOperationDetails(result map[string]interface{}, asset xdr.
 	result[prefix+"asset_type"] = assetType
 
 	if asset.Type == xdr.AssetTypeAssetTypeNative {
-		result[prefix+"asset_id"] = int64(-5706705804583548011)
+		result[prefix+"asset_id"] = nativeAssetID
 		return nil
 	}
 
@@ -403,9 +447,10 @@ func addPriceDetails(result map[string]interface{}, price xdr.Price, prefix stri
 		return err
 	}
 	result[prefix+"price"] = parsedPrice
+	result[prefix+"price_decimal"] = utils.PriceToDecimalString(int64(price.N), int64(price.D))
 	result[prefix+"price_r"] = Price{
-		Numerator:   int32(price.N),
-		Denominator: int32(price.D),
+		Numerator:   int64(price.N),
+		Denominator: int64(price.D),
 	}
 	return nil
 }
@@ -455,6 +500,32 @@ func addTrustLineFlagToDetails(result map[string]interface{}, f xdr.TrustLineFla
 	result[prefix+"flags_s"] = s
 }
 
+// addSignerKeyTypeDetails adds the type of a signer key to the details. Signed payload signers also get
+// the ed25519 account and the hex encoded payload that they sign, which are not visible from the key alone.
+func addSignerKeyTypeDetails(result map[string]interface{}, key xdr.SignerKey) error {
+	switch key.Type {
+	case xdr.SignerKeyTypeSignerKeyTypeEd25519:
+		result["signer_type"] = "ed25519"
+	case xdr.SignerKeyTypeSignerKeyTypePreAuthTx:
+		result["signer_type"] = "preauth_tx"
+	case xdr.SignerKeyTypeSignerKeyTypeHashX:
+		result["signer_type"] = "hash_x"
+	case xdr.SignerKeyTypeSignerKeyTypeEd25519SignedPayload:
+		result["signer_type"] = "ed25519_signed_payload"
+		payload := key.MustEd25519SignedPayload()
+		account, err := strkey.Encode(strkey.VersionByteAccountID, payload.Ed25519[:])
+		if err != nil {
+			return err
+		}
+		result["signer_payload_account"] = account
+		result["signer_payload"] = hex.EncodeToString(payload.Payload)
+	default:
+		return fmt.Errorf("unknown signer key type: %v", key.Type)
+	}
+
+	return nil
+}
+
 func addLedgerKeyToDetails(result map[string]interface{}, ledgerKey xdr.LedgerKey) error {
 	switch ledgerKey.Type {
 	case xdr.LedgerEntryTypeAccount:
@@ -499,11 +570,61 @@ func transformPath(initialPath []xdr.Asset) []Path {
 			AssetType:   assetType,
 			AssetIssuer: issuer,
 			AssetCode:   code,
+			AssetID:     FarmHashAsset(code, issuer, assetType),
 		})
 	}
 	return path
 }
 
+// transformPathPaymentLegs converts the offers and liquidity pools a path payment crossed into legs, in the order they
+// were crossed
+func transformPathPaymentLegs(claims []xdr.ClaimAtom) ([]PathPaymentLeg, error) {
+	if len(claims) == 0 {
+		return nil, nil
+	}
+
+	legs := make([]PathPaymentLeg, 0, len(claims))
+	for _, claim := range claims {
+		var leg PathPaymentLeg
+		if err := claim.AssetSold().Extract(&leg.SellingAssetType, &leg.SellingAssetCode, &leg.SellingAssetIssuer); err != nil {
+			return nil, err
+		}
+		if err := claim.AssetBought().Extract(&leg.BuyingAssetType, &leg.BuyingAssetCode, &leg.BuyingAssetIssuer); err != nil {
+			return nil, err
+		}
+		leg.SellingAssetID = FarmHashAsset(leg.SellingAssetCode, leg.SellingAssetIssuer, leg.SellingAssetType)
+		leg.BuyingAssetID = FarmHashAsset(leg.BuyingAssetCode, leg.BuyingAssetIssuer, leg.BuyingAssetType)
+		leg.SellingAmount = utils.ConvertStroopValueToReal(claim.AmountSold())
+		leg.BuyingAmount = utils.ConvertStroopValueToReal(claim.AmountBought())
+
+		if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			leg.LiquidityPoolID = null.StringFrom(PoolIDToString(claim.MustLiquidityPool().LiquidityPoolId))
+		} else {
+			leg.SellerAddress = null.StringFrom(claim.SellerId().Address())
+			leg.OfferID = null.IntFrom(int64(claim.OfferId()))
+		}
+
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// transformInflationPayouts converts the payouts of an inflation result
+func transformInflationPayouts(payouts []xdr.InflationPayout) []InflationPayout {
+	transformed := make([]InflationPayout, 0, len(payouts))
+	for _, payout := range payouts {
+		transformed = append(transformed, InflationPayout{
+			Destination: payout.Destination.Address(),
+			Amount:      utils.ConvertStroopValueToReal(payout.Amount),
+		})
+	}
+	return transformed
+}
+
+// findInitatingBeginSponsoringOp returns the BeginSponsoringFutureReserves operation of the sandwich an operation is
+// executed in: the nearest earlier one whose matching EndSponsoringFutureReserves operation is not before the operation.
+// The operations of a sandwich are attributed to it whatever their source, like the CreateAccount operation that
+// creates the sponsored account.
 func findInitatingBeginSponsoringOp(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction) *SponsorshipOutput {
 	if !transaction.Result.Successful() {
 		// Failed transactions may not have a compliant sandwich structure
@@ -511,21 +632,59 @@ func findInitatingBeginSponsoringOp(operation xdr.Operation, operationIndex int3
 		// and thus we bail out since we could return incorrect information.
 		return nil
 	}
-	sponsoree := getOperationSourceAccount(operation, transaction).ToAccountId()
 	operations := transaction.Envelope.Operations()
+	isEnd := operation.Body.Type == xdr.OperationTypeEndSponsoringFutureReserves
 	for i := int(operationIndex) - 1; i >= 0; i-- {
-		if beginOp, ok := operations[i].Body.GetBeginSponsoringFutureReservesOp(); ok &&
-			beginOp.SponsoredId.Address() == sponsoree.Address() {
-			result := SponsorshipOutput{
-				Operation:      operations[i],
-				OperationIndex: uint32(i),
-			}
-			return &result
+		beginOp, ok := operations[i].Body.GetBeginSponsoringFutureReservesOp()
+		if !ok {
+			continue
+		}
+		endIndex, found := matchingEndSponsoringOp(beginOp, i, transaction)
+		if !found || endIndex < int(operationIndex) {
+			continue
+		}
+		// an EndSponsoringFutureReserves operation only belongs to the sandwich it closes
+		if isEnd && endIndex != int(operationIndex) {
+			continue
+		}
+		result := SponsorshipOutput{
+			Operation:      operations[i],
+			OperationIndex: uint32(i),
 		}
+		return &result
 	}
 	return nil
 }
 
+// matchingEndSponsoringOp returns the index of the EndSponsoringFutureReserves operation, sourced by the sponsored
+// account, that closes the sandwich opened at beginIndex
+func matchingEndSponsoringOp(beginOp xdr.BeginSponsoringFutureReservesOp, beginIndex int, transaction ingest.LedgerTransaction) (int, bool) {
+	operations := transaction.Envelope.Operations()
+	for i := beginIndex + 1; i < len(operations); i++ {
+		if operations[i].Body.Type == xdr.OperationTypeEndSponsoringFutureReserves &&
+			getOperationSourceAccount(operations[i], transaction).ToAccountId().Address() == beginOp.SponsoredId.Address() {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// operationSponsor returns the account sponsoring the reserves of an operation executed between a
+// BeginSponsoringFutureReserves operation and its matching EndSponsoringFutureReserves operation
+func operationSponsor(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction) (null.String, error) {
+	beginSponsorOp := findInitatingBeginSponsoringOp(operation, operationIndex, transaction)
+	if beginSponsorOp == nil {
+		return null.String{}, nil
+	}
+
+	sponsor, err := utils.GetAccountAddressFromMuxedAccount(getOperationSourceAccount(beginSponsorOp.Operation, transaction))
+	if err != nil {
+		return null.String{}, err
+	}
+
+	return null.StringFrom(sponsor), nil
+}
+
 func addOperationFlagToOperationDetails(result map[string]interface{}, flag uint32, prefix string) {
 	intFlags := make([]int32, 0)
 	stringFlags := make([]string, 0)
@@ -555,7 +714,7 @@ func addOperationFlagToOperationDetails(result map[string]interface{}, flag uint
 	result[prefix+"flags_s"] = stringFlags
 }
 
-func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerTransaction, operationIndex int32, network string) (map[string]interface{}, error) {
+func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerTransaction, operationIndex int32, ledgerSeq int32, network string) (map[string]interface{}, error) {
 	details := map[string]interface{}{}
 	sourceAccount := getOperationSourceAccount(operation, transaction)
 	operationType := operation.Body.Type
@@ -627,6 +786,14 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 				return details, fmt.Errorf("could not access PathPaymentStrictReceive result info for this operation (index %d)", operationIndex)
 			}
 			details["source_amount"] = utils.ConvertStroopValueToReal(result.SendAmount())
+
+			legs, err := transformPathPaymentLegs(result.MustSuccess().Offers)
+			if err != nil {
+				return details, err
+			}
+			if legs != nil {
+				details["legs"] = legs
+			}
 		}
 
 		details["path"] = transformPath(op.Path)
@@ -668,6 +835,14 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 				return details, fmt.Errorf("could not access GetPathPaymentStrictSendResult result info for this operation (index %d)", operationIndex)
 			}
 			details["amount"] = utils.ConvertStroopValueToReal(result.DestAmount())
+
+			legs, err := transformPathPaymentLegs(result.MustSuccess().Offers)
+			if err != nil {
+				return details, err
+			}
+			if legs != nil {
+				details["legs"] = legs
+			}
 		}
 
 		details["path"] = transformPath(op.Path)
@@ -769,6 +944,9 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 		if op.Signer != nil {
 			details["signer_key"] = op.Signer.Key.Address()
 			details["signer_weight"] = uint32(op.Signer.Weight)
+			if err := addSignerKeyTypeDetails(details, op.Signer.Key); err != nil {
+				return details, err
+			}
 		}
 
 	case xdr.OperationTypeChangeTrust:
@@ -830,8 +1008,45 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 			return details, err
 		}
 
+		if transaction.Result.Successful() {
+			allOperationResults, ok := transaction.Result.OperationResults()
+			if !ok {
+				return details, fmt.Errorf("could not access any results for this transaction")
+			}
+			currentOperationResult := allOperationResults[operationIndex]
+			resultBody, ok := currentOperationResult.GetTr()
+			if !ok {
+				return details, fmt.Errorf("could not access result body for this operation (index %d)", operationIndex)
+			}
+			result, ok := resultBody.GetAccountMergeResult()
+			if !ok {
+				return details, fmt.Errorf("could not access AccountMerge result info for this operation (index %d)", operationIndex)
+			}
+			// the balance of the merged account is credited to the destination
+			if sourceAccountBalance, ok := result.GetSourceAccountBalance(); ok {
+				details["amount"] = utils.ConvertStroopValueToReal(sourceAccountBalance)
+			}
+		}
+
 	case xdr.OperationTypeInflation:
-		// Inflation operations don't have information that affects the details struct
+		if transaction.Result.Successful() {
+			allOperationResults, ok := transaction.Result.OperationResults()
+			if !ok {
+				return details, fmt.Errorf("could not access any results for this transaction")
+			}
+			currentOperationResult := allOperationResults[operationIndex]
+			resultBody, ok := currentOperationResult.GetTr()
+			if !ok {
+				return details, fmt.Errorf("could not access result body for this operation (index %d)", operationIndex)
+			}
+			result, ok := resultBody.GetInflationResult()
+			if !ok {
+				return details, fmt.Errorf("could not access Inflation result info for this operation (index %d)", operationIndex)
+			}
+			if payouts, ok := result.GetPayouts(); ok {
+				details["payouts"] = transformInflationPayouts(payouts)
+			}
+		}
 	case xdr.OperationTypeManageData:
 		op, ok := operation.Body.GetManageDataOp()
 		if !ok {
@@ -856,7 +1071,13 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 		op := operation.Body.MustCreateClaimableBalanceOp()
 		details["asset"] = op.Asset.StringCanonical()
 		details["amount"] = utils.ConvertStroopValueToReal(op.Amount)
-		details["claimants"] = transformClaimants(op.Claimants)
+		claimants, err := transformClaimants(op.Claimants)
+		if err != nil {
+			return details, err
+		}
+		details["claimants"] = claimants
+		details["claimant_count"] = len(op.Claimants)
+		details["claimant_addresses"] = claimantAddresses(op.Claimants)
 
 	case xdr.OperationTypeClaimClaimableBalance:
 		op := operation.Body.MustClaimClaimableBalanceOp()
@@ -869,6 +1090,15 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 			return details, err
 		}
 
+		claimedBalance, err := getClaimedClaimableBalance(operationIndex, transaction, balanceID)
+		if err != nil {
+			return details, err
+		}
+		if claimedBalance != nil {
+			details["asset"] = claimedBalance.Asset.StringCanonical()
+			details["amount"] = utils.ConvertStroopValueToReal(claimedBalance.Amount)
+		}
+
 	case xdr.OperationTypeBeginSponsoringFutureReserves:
 		op := operation.Body.MustBeginSponsoringFutureReservesOp()
 		details["sponsored_id"] = op.SponsoredId.Address()
@@ -880,6 +1110,8 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 			if err := addAccountAndMuxedAccountDetails(details, beginSponsorshipSource, "begin_sponsor"); err != nil {
 				return details, err
 			}
+			// the operation ID of the sandwich's BeginSponsoringFutureReserves operation, to join the pair precisely
+			details["begin_sponsor_operation_id"] = toid.New(ledgerSeq, int32(transaction.Index), int32(beginSponsorOp.OperationIndex)+1).ToInt64()
 		}
 
 	case xdr.OperationTypeRevokeSponsorship:
@@ -1027,6 +1259,7 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 			args = append(args, invokeArgs.Args...)
 			params := make([]map[string]string, 0, len(args))
 			paramsDecoded := make([]map[string]string, 0, len(args))
+			paramsJSON := make([]interface{}, 0, len(args))
 
 			details["type"] = "invoke_contract"
 
@@ -1049,14 +1282,23 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 					serializedParamDecoded["type"] = scValTypeName
 					if raw, err := param.MarshalBinary(); err == nil {
 						serializedParam["value"] = base64.StdEncoding.EncodeToString(raw)
-						serializedParamDecoded["value"] = param.String()
+						serializedParamDecoded["value"] = utils.ScValString(param)
 					}
 				}
 				params = append(params, serializedParam)
 				paramsDecoded = append(paramsDecoded, serializedParamDecoded)
+
+				// parameters that cannot be rendered are null, like they are "n/a" in the other parameter columns,
+				// unless the strict flag requires every ScVal type to be known
+				paramJSON, err := utils.XdrJSON.ScVal(param)
+				if err != nil && utils.StrictSchema {
+					return nil, err
+				}
+				paramsJSON = append(paramsJSON, paramJSON)
 			}
 			details["parameters"] = params
 			details["parameters_decoded"] = paramsDecoded
+			details["parameters_json"] = paramsJSON
 
 			if balanceChanges, err := parseAssetBalanceChangesFromContractEvents(transaction, network); err != nil {
 				return nil, err
@@ -1113,7 +1355,7 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 		details["contract_id"] = contractIdFromTxEnvelope(transactionEnvelope)
 		details["contract_code_hash"] = contractCodeHashFromTxEnvelope(transactionEnvelope)
 	default:
-		return details, fmt.Errorf("unknown operation type: %s", operation.Body.Type.String())
+		return details, utils.UnknownVariantError{Kind: "operation type", Value: operation.Body.Type.String()}
 	}
 
 	sponsor, err := getSponsor(operation, transaction, operationIndex)
@@ -1205,7 +1447,7 @@ func (operation *transactionOperationWrapper) getSignerSponsorInChange(signerKey
 }
 
 func (operation *transactionOperationWrapper) getSponsor() (*xdr.AccountId, error) {
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	changes, err := operationChanges(operation.transaction, operation.index)
 	if err != nil {
 		return nil, err
 	}
@@ -1239,7 +1481,7 @@ func (operation *transactionOperationWrapper) getSponsor() (*xdr.AccountId, erro
 var errLiquidityPoolChangeNotFound = errors.New("liquidity pool change not found")
 
 func (operation *transactionOperationWrapper) getLiquidityPoolAndProductDelta(lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	changes, err := operationChanges(operation.transaction, operation.index)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1446,6 +1688,9 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		if op.Signer != nil {
 			details["signer_key"] = op.Signer.Key.Address()
 			details["signer_weight"] = op.Signer.Weight
+			if err := addSignerKeyTypeDetails(details, op.Signer.Key); err != nil {
+				return nil, err
+			}
 		}
 	case xdr.OperationTypeChangeTrust:
 		op := operation.operation.Body.MustChangeTrustOp()
@@ -1476,8 +1721,26 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 	case xdr.OperationTypeAccountMerge:
 		addAccountAndMuxedAccountDetails(details, *source, "account")
 		addAccountAndMuxedAccountDetails(details, operation.operation.Body.MustDestination(), "into")
+		if operation.transaction.Result.Successful() {
+			result := operation.OperationResult().MustAccountMergeResult()
+			if sourceAccountBalance, ok := result.GetSourceAccountBalance(); ok {
+				details["amount"] = amount.String(sourceAccountBalance)
+			}
+		}
 	case xdr.OperationTypeInflation:
-		// no inflation details, presently
+		if operation.transaction.Result.Successful() {
+			result := operation.OperationResult().MustInflationResult()
+			if payouts, ok := result.GetPayouts(); ok {
+				var payoutDetails = make([]map[string]interface{}, len(payouts))
+				for i, payout := range payouts {
+					payoutDetails[i] = map[string]interface{}{
+						"destination": payout.Destination.Address(),
+						"amount":      amount.String(payout.Amount),
+					}
+				}
+				details["payouts"] = payoutDetails
+			}
+		}
 	case xdr.OperationTypeManageData:
 		op := operation.operation.Body.MustManageDataOp()
 		details["name"] = string(op.DataName)
@@ -1493,15 +1756,13 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		op := operation.operation.Body.MustCreateClaimableBalanceOp()
 		details["asset"] = op.Asset.StringCanonical()
 		details["amount"] = amount.String(op.Amount)
-		var claimants []Claimant
-		for _, c := range op.Claimants {
-			cv0 := c.MustV0()
-			claimants = append(claimants, Claimant{
-				Destination: cv0.Destination.Address(),
-				Predicate:   cv0.Predicate,
-			})
+		claimants, err := transformClaimants(op.Claimants)
+		if err != nil {
+			return nil, err
 		}
 		details["claimants"] = claimants
+		details["claimant_count"] = len(op.Claimants)
+		details["claimant_addresses"] = claimantAddresses(op.Claimants)
 	case xdr.OperationTypeClaimClaimableBalance:
 		op := operation.operation.Body.MustClaimClaimableBalanceOp()
 		balanceID, err := xdr.MarshalHex(op.BalanceId)
@@ -1510,6 +1771,15 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		}
 		details["balance_id"] = balanceID
 		addAccountAndMuxedAccountDetails(details, *source, "claimant")
+
+		claimedBalance, err := getClaimedClaimableBalance(int32(operation.index), operation.transaction, balanceID)
+		if err != nil {
+			return nil, err
+		}
+		if claimedBalance != nil {
+			details["asset"] = claimedBalance.Asset.StringCanonical()
+			details["amount"] = amount.String(claimedBalance.Amount)
+		}
 	case xdr.OperationTypeBeginSponsoringFutureReserves:
 		op := operation.operation.Body.MustBeginSponsoringFutureReservesOp()
 		details["sponsored_id"] = op.SponsoredId.Address()
@@ -1518,6 +1788,7 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		if beginSponsorshipOp != nil {
 			beginSponsorshipSource := beginSponsorshipOp.SourceAccount()
 			addAccountAndMuxedAccountDetails(details, *beginSponsorshipSource, "begin_sponsor")
+			details["begin_sponsor_operation_id"] = beginSponsorshipOp.ID()
 		}
 	case xdr.OperationTypeRevokeSponsorship:
 		op := operation.operation.Body.MustRevokeSponsorshipOp()
@@ -1630,6 +1901,7 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 			args = append(args, invokeArgs.Args...)
 			params := make([]map[string]string, 0, len(args))
 			paramsDecoded := make([]map[string]string, 0, len(args))
+			paramsJSON := make([]interface{}, 0, len(args))
 
 			details["type"] = "invoke_contract"
 
@@ -1657,9 +1929,18 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 				}
 				params = append(params, serializedParam)
 				paramsDecoded = append(paramsDecoded, serializedParamDecoded)
+
+				// parameters that cannot be rendered are null, like they are "n/a" in the other parameter columns,
+				// unless the strict flag requires every ScVal type to be known
+				paramJSON, err := utils.XdrJSON.ScVal(param)
+				if err != nil && utils.StrictSchema {
+					return nil, err
+				}
+				paramsJSON = append(paramsJSON, paramJSON)
 			}
 			details["parameters"] = params
 			details["parameters_decoded"] = paramsDecoded
+			details["parameters_json"] = paramsJSON
 
 			if balanceChanges, err := operation.parseAssetBalanceChangesFromContractEvents(); err != nil {
 				return nil, err
@@ -1946,6 +2227,7 @@ func addAssetDetails(result map[string]interface{}, a xdr.Asset, prefix string)
 		return err
 	}
 	result[prefix+"asset_type"] = assetType
+	result[prefix+"asset_id"] = FarmHashAsset(code, issuer, assetType)
 
 	if a.Type == xdr.AssetTypeAssetTypeNative {
 		return nil
@@ -2135,7 +2417,7 @@ func (operation *transactionOperationWrapper) Participants() ([]xdr.AccountId, e
 	case xdr.OperationTypeRestoreFootprint:
 		// the only direct participant is the source_account
 	default:
-		return participants, fmt.Errorf("unknown operation type: %s", op.Body.Type)
+		return participants, utils.UnknownVariantError{Kind: "operation type", Value: op.Body.Type.String()}
 	}
 
 	sponsor, err := operation.getSponsor()
@@ -2149,6 +2431,31 @@ func (operation *transactionOperationWrapper) Participants() ([]xdr.AccountId, e
 	return dedupeParticipants(participants), nil
 }
 
+// operationParticipants returns the sorted addresses of the accounts taking part in the operation, matching the participants
+// Horizon indexes operations by
+func operationParticipants(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, network string) ([]string, error) {
+	wrapper := transactionOperationWrapper{
+		index:          uint32(operationIndex),
+		transaction:    transaction,
+		operation:      operation,
+		ledgerSequence: uint32(ledgerSeq),
+		network:        network,
+	}
+
+	participants, err := wrapper.Participants()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(participants))
+	for _, participant := range participants {
+		addresses = append(addresses, participant.Address())
+	}
+	sort.Strings(addresses)
+
+	return addresses, nil
+}
+
 // dedupeParticipants remove any duplicate ids from `in`
 func dedupeParticipants(in []xdr.AccountId) (out []xdr.AccountId) {
 	set := map[string]xdr.AccountId{}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/testutil"
+	"github.com/stellar/stellar-etl/internal/toid"
+	"github.com/stellar/stellar-etl/internal/utils"
 )
 
 func TestTransformOperation(t *testing.T) {
@@ -50,7 +56,7 @@ func TestTransformOperation(t *testing.T) {
 		{
 			unknownOpTypeInput,
 			OperationOutput{},
-			fmt.Errorf("unknown operation type: "),
+			utils.UnknownVariantError{Kind: "operation type", Value: ""},
 		},
 	}
 	hardCodedInputTransaction, err := makeOperationTestInput()
@@ -107,6 +113,7 @@ func makeOperationTestInput() (inputTransaction ingest.LedgerTransaction, err er
 	//var assetCode [12]byte
 	//var assetIssuer xdr.Uint256
 
+	hardCodedMergedBalance := xdr.Int64(1234567890)
 	hardCodedClearFlags := xdr.Uint32(3)
 	hardCodedSetFlags := xdr.Uint32(4)
 	hardCodedMasterWeight := xdr.Uint32(3)
@@ -721,7 +728,8 @@ func makeOperationTestInput() (inputTransaction ingest.LedgerTransaction, err er
 			Tr: &xdr.OperationResultTr{
 				Type: xdr.OperationTypeAccountMerge,
 				AccountMergeResult: &xdr.AccountMergeResult{
-					Code: xdr.AccountMergeResultCodeAccountMergeSuccess,
+					Code:                 xdr.AccountMergeResultCodeAccountMergeSuccess,
+					SourceAccountBalance: &hardCodedMergedBalance,
 				},
 			},
 		},
@@ -731,6 +739,9 @@ func makeOperationTestInput() (inputTransaction ingest.LedgerTransaction, err er
 				Type: xdr.OperationTypeInflation,
 				InflationResult: &xdr.InflationResult{
 					Code: xdr.InflationResultCodeInflationSuccess,
+					Payouts: &[]xdr.InflationPayout{
+						{Destination: testAccount4ID, Amount: 12345678},
+					},
 				},
 			},
 		},
@@ -939,6 +950,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "CreateAccountResultCodeCreateAccountSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          1,
@@ -958,6 +970,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "PaymentResultCodePaymentSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          1,
@@ -975,6 +988,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "PaymentResultCodePaymentSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          2,
@@ -997,6 +1011,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          3,
@@ -1005,9 +1020,10 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			TransactionID: 4096,
 			OperationID:   4101,
 			OperationDetails: map[string]interface{}{
-				"price":    0.514092,
-				"amount":   76.586,
-				"offer_id": int64(0.0),
+				"price":         0.514092,
+				"price_decimal": "0.514092",
+				"amount":        76.586,
+				"offer_id":      int64(0.0),
 				"price_r": Price{
 					Numerator:   128523,
 					Denominator: 250000,
@@ -1022,6 +1038,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ManageSellOfferResultCodeManageSellOfferSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          4,
@@ -1030,8 +1047,9 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			TransactionID: 4096,
 			OperationID:   4102,
 			OperationDetails: map[string]interface{}{
-				"amount": 63.1595,
-				"price":  0.0791606,
+				"amount":        63.1595,
+				"price":         0.0791606,
+				"price_decimal": "0.079160565664273961",
 				"price_r": Price{
 					Numerator:   99583200,
 					Denominator: 1257990000,
@@ -1046,6 +1064,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ManageSellOfferResultCodeManageSellOfferSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          5,
@@ -1066,10 +1085,12 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"home_domain":       "2019=DRA;n-test",
 				"signer_key":        "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
 				"signer_weight":     uint32(1),
+				"signer_type":       "ed25519",
 			},
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "SetOptionsResultCodeSetOptionsSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          6,
@@ -1089,6 +1110,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ChangeTrustResultCodeChangeTrustSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          6,
@@ -1105,6 +1127,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ChangeTrustResultCodeChangeTrustSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          7,
@@ -1124,6 +1147,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "AllowTrustResultCodeAllowTrustSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          8,
@@ -1134,21 +1158,26 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			OperationDetails: map[string]interface{}{
 				"account": hardCodedSourceAccountAddress,
 				"into":    hardCodedDestAccountAddress,
+				"amount":  123.456789,
 			},
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "AccountMergeResultCodeAccountMergeSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
-			Type:                9,
-			TypeString:          "inflation",
-			SourceAccount:       hardCodedSourceAccountAddress,
-			TransactionID:       4096,
-			OperationID:         4108,
-			OperationDetails:    map[string]interface{}{},
+			Type:          9,
+			TypeString:    "inflation",
+			SourceAccount: hardCodedSourceAccountAddress,
+			TransactionID: 4096,
+			OperationID:   4108,
+			OperationDetails: map[string]interface{}{
+				"payouts": []InflationPayout{{Destination: testAccount4Address, Amount: 1.2345678}},
+			},
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "InflationResultCodeInflationSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          10,
@@ -1163,6 +1192,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ManageDataResultCodeManageDataSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          11,
@@ -1176,6 +1206,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "BumpSequenceResultCodeBumpSequenceSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          12,
@@ -1184,8 +1215,9 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			TransactionID: 4096,
 			OperationID:   4111,
 			OperationDetails: map[string]interface{}{
-				"price":  0.3496823,
-				"amount": 765.4501001,
+				"price":         0.3496823,
+				"price_decimal": "0.3496823030933525",
+				"amount":        765.4501001,
 				"price_r": Price{
 					Numerator:   635863285,
 					Denominator: 1818402817,
@@ -1201,6 +1233,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ManageBuyOfferResultCodeManageBuyOfferSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          13,
@@ -1223,6 +1256,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          14,
@@ -1231,13 +1265,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			TransactionID: 4096,
 			OperationID:   4113,
 			OperationDetails: map[string]interface{}{
-				"asset":     "USDT:GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
-				"amount":    123456.789,
-				"claimants": []Claimant{testClaimantDetails},
+				"asset":              "USDT:GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+				"amount":             123456.789,
+				"claimants":          []Claimant{testClaimantDetails},
+				"claimant_count":     1,
+				"claimant_addresses": []string{testClaimantDetails.Destination},
 			},
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess",
+			Participants:        []string{testAccount3Address, testAccount1Address},
 		},
 		{
 			Type:          15,
@@ -1252,6 +1289,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          16,
@@ -1265,6 +1303,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          18,
@@ -1279,6 +1318,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          18,
@@ -1292,6 +1332,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          18,
@@ -1305,6 +1346,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          18,
@@ -1319,6 +1361,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          18,
@@ -1332,6 +1375,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          18,
@@ -1346,6 +1390,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          18,
@@ -1359,6 +1404,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          19,
@@ -1377,6 +1423,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ClawbackResultCodeClawbackSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          20,
@@ -1390,6 +1437,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          21,
@@ -1411,6 +1459,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess",
+			Participants:        []string{testAccount3Address, testAccount4Address},
 		},
 		{
 			Type:          22,
@@ -1431,11 +1480,13 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"reserve_b_deposit_amount": 0.00001,
 				"reserve_b_max_amount":     0.00001,
 				"max_price":                1000000.0000000,
+				"max_price_decimal":        "1000000",
 				"max_price_r": Price{
 					Numerator:   1000000,
 					Denominator: 1,
 				},
-				"min_price": 0.0000010,
+				"min_price":         0.0000010,
+				"min_price_decimal": "0.000001",
 				"min_price_r": Price{
 					Numerator:   1,
 					Denominator: 1000000,
@@ -1445,6 +1496,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		{
 			Type:          23,
@@ -1469,6 +1521,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			ClosedAt:            hardCodedLedgerClose,
 			OperationResultCode: "OperationResultCodeOpInner",
 			OperationTraceCode:  "LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess",
+			Participants:        []string{testAccount3Address},
 		},
 		//OperationOutput{
 		//	Type:          24,
@@ -1560,3 +1613,310 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 	}
 	return
 }
+
+func TestAddSignerKeyTypeDetails(t *testing.T) {
+	signedPayloadKey := xdr.SignerKey{
+		Type: xdr.SignerKeyTypeSignerKeyTypeEd25519SignedPayload,
+		Ed25519SignedPayload: &xdr.SignerKeyEd25519SignedPayload{
+			Ed25519: xdr.Uint256([32]byte{}),
+			Payload: []byte{0x01, 0x02, 0x03},
+		},
+	}
+
+	details := map[string]interface{}{}
+	err := addSignerKeyTypeDetails(details, signedPayloadKey)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"signer_type":            "ed25519_signed_payload",
+		"signer_payload_account": "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
+		"signer_payload":         "010203",
+	}, details)
+
+	hashXKey, err := xdr.NewSignerKey(xdr.SignerKeyTypeSignerKeyTypeHashX, xdr.Uint256([32]byte{}))
+	assert.NoError(t, err)
+
+	details = map[string]interface{}{}
+	err = addSignerKeyTypeDetails(details, hashXKey)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"signer_type": "hash_x"}, details)
+}
+
+func TestClaimClaimableBalanceDetails(t *testing.T) {
+	claimOp := xdr.Operation{
+		SourceAccount: &testAccount3,
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeClaimClaimableBalance,
+			ClaimClaimableBalanceOp: &xdr.ClaimClaimableBalanceOp{
+				BalanceId: genericClaimableBalance,
+			},
+		},
+	}
+	claimedBalance := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeClaimableBalance,
+			ClaimableBalance: &xdr.ClaimableBalanceEntry{
+				BalanceId: genericClaimableBalance,
+				Asset:     ethAsset,
+				Amount:    1234567,
+			},
+		},
+	}
+	transaction := ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations:    []xdr.Operation{claimOp},
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 2,
+			V2: &xdr.TransactionMetaV2{
+				Operations: []xdr.OperationMeta{{
+					Changes: xdr.LedgerEntryChanges{
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &claimedBalance},
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &xdr.LedgerKey{
+							Type:             xdr.LedgerEntryTypeClaimableBalance,
+							ClaimableBalance: &xdr.LedgerKeyClaimableBalance{BalanceId: genericClaimableBalance},
+						}},
+					},
+				}},
+			},
+		},
+	}
+
+	details, err := extractOperationDetails(claimOp, transaction, 0, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, ethAsset.StringCanonical(), details["asset"])
+	assert.Equal(t, 0.1234567, details["amount"])
+
+	wrapper := transactionOperationWrapper{index: 0, transaction: transaction, operation: claimOp}
+	details, err = wrapper.Details()
+	assert.NoError(t, err)
+	assert.Equal(t, ethAsset.StringCanonical(), details["asset"])
+	assert.Equal(t, "0.1234567", details["amount"])
+}
+
+func TestAccountMergeDetails(t *testing.T) {
+	destination := testutil.MuxedAccount(2)
+	merge := xdr.Operation{Body: xdr.OperationBody{
+		Type:        xdr.OperationTypeAccountMerge,
+		Destination: &destination,
+	}}
+	balance := xdr.Int64(50000000)
+	ledger := testutil.NewLedger(100)
+	ledger.AddTransaction(testutil.MuxedAccount(1), merge).OperationResult(0, xdr.OperationResultTr{
+		Type: xdr.OperationTypeAccountMerge,
+		AccountMergeResult: &xdr.AccountMergeResult{
+			Code:                 xdr.AccountMergeResultCodeAccountMergeSuccess,
+			SourceAccountBalance: &balance,
+		},
+	})
+	ledgerCloseMeta, err := ledger.Build(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	transactions, err := ledger.LedgerTransactions(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+
+	output, err := TransformOperation(merge, 0, transactions[0], 100, ledgerCloseMeta, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.AccountID(1).Address(), output.OperationDetails["account"])
+	assert.Equal(t, testutil.AccountID(2).Address(), output.OperationDetails["into"])
+	assert.Equal(t, 5.0, output.OperationDetails["amount"])
+}
+
+func TestEndSponsoringDetails(t *testing.T) {
+	sponsored := testutil.AccountID(2)
+	sponsor := testutil.MuxedAccount(1)
+	sponsoredSource := testutil.MuxedAccount(2)
+	begin := xdr.Operation{Body: xdr.OperationBody{
+		Type:                            xdr.OperationTypeBeginSponsoringFutureReserves,
+		BeginSponsoringFutureReservesOp: &xdr.BeginSponsoringFutureReservesOp{SponsoredId: sponsored},
+	}}
+	manageData := xdr.Operation{SourceAccount: &sponsoredSource, Body: xdr.OperationBody{
+		Type:         xdr.OperationTypeManageData,
+		ManageDataOp: &xdr.ManageDataOp{DataName: "name"},
+	}}
+	end := xdr.Operation{SourceAccount: &sponsoredSource, Body: xdr.OperationBody{Type: xdr.OperationTypeEndSponsoringFutureReserves}}
+	ledger := testutil.NewLedger(100)
+	ledger.AddTransaction(sponsor, manageData, begin, manageData, end)
+	ledgerCloseMeta, err := ledger.Build(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	transactions, err := ledger.LedgerTransactions(network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+
+	beginOperationID := toid.New(100, int32(transactions[0].Index), 2).ToInt64()
+	output, err := TransformOperation(end, 3, transactions[0], 100, ledgerCloseMeta, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.AccountID(1).Address(), output.OperationDetails["begin_sponsor"])
+	assert.Equal(t, beginOperationID, output.OperationDetails["begin_sponsor_operation_id"])
+
+	wrapper := transactionOperationWrapper{index: 3, transaction: transactions[0], operation: end, ledgerSequence: 100}
+	details, err := wrapper.Details()
+	assert.NoError(t, err)
+	assert.Equal(t, beginOperationID, details["begin_sponsor_operation_id"])
+}
+
+func TestTransformPathPaymentLegs(t *testing.T) {
+	poolID := xdr.PoolId{1, 3, 4, 5, 7, 9}
+	claims := []xdr.ClaimAtom{
+		{
+			Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+			OrderBook: &xdr.ClaimOfferAtom{
+				SellerId:     testAccount3ID,
+				OfferId:      97,
+				AssetSold:    usdtAsset,
+				AmountSold:   20000000,
+				AssetBought:  nativeAsset,
+				AmountBought: 10000000,
+			},
+		},
+		{
+			Type: xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool,
+			LiquidityPool: &xdr.ClaimLiquidityAtom{
+				LiquidityPoolId: poolID,
+				AssetSold:       ethAsset,
+				AmountSold:      5000000,
+				AssetBought:     usdtAsset,
+				AmountBought:    20000000,
+			},
+		},
+	}
+
+	legs, err := transformPathPaymentLegs(claims)
+	assert.NoError(t, err)
+	assert.Equal(t, []PathPaymentLeg{
+		{
+			SellerAddress:      null.StringFrom(testAccount3Address),
+			OfferID:            null.IntFrom(97),
+			SellingAssetType:   "credit_alphanum4",
+			SellingAssetCode:   "USDT",
+			SellingAssetIssuer: testAccount4Address,
+			SellingAssetID:     -8205667356306085451,
+			SellingAmount:      2,
+			BuyingAssetType:    "native",
+			BuyingAssetID:      -5706705804583548011,
+			BuyingAmount:       1,
+		},
+		{
+			LiquidityPoolID:    null.StringFrom("0103040507090000000000000000000000000000000000000000000000000000"),
+			SellingAssetType:   "credit_alphanum4",
+			SellingAssetCode:   "ETH",
+			SellingAssetIssuer: testAccount3Address,
+			SellingAssetID:     4476940172956910889,
+			SellingAmount:      0.5,
+			BuyingAssetType:    "credit_alphanum4",
+			BuyingAssetCode:    "USDT",
+			BuyingAssetIssuer:  testAccount4Address,
+			BuyingAssetID:      -8205667356306085451,
+			BuyingAmount:       2,
+		},
+	}, legs)
+
+	legs, err = transformPathPaymentLegs(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, legs)
+}
+
+func TestOperationSponsor(t *testing.T) {
+	sponsored := func(body xdr.OperationBody) xdr.Operation {
+		return xdr.Operation{SourceAccount: &testAccount4, Body: body}
+	}
+	operations := []xdr.Operation{
+		{
+			Body: xdr.OperationBody{
+				Type:                            xdr.OperationTypeBeginSponsoringFutureReserves,
+				BeginSponsoringFutureReservesOp: &xdr.BeginSponsoringFutureReservesOp{SponsoredId: testAccount4ID},
+			},
+		},
+		// the sponsored account is created by the sponsor, inside the sandwich
+		{
+			Body: xdr.OperationBody{
+				Type:            xdr.OperationTypeCreateAccount,
+				CreateAccountOp: &xdr.CreateAccountOp{Destination: testAccount4ID, StartingBalance: 0},
+			},
+		},
+		sponsored(xdr.OperationBody{Type: xdr.OperationTypeManageData, ManageDataOp: &xdr.ManageDataOp{DataName: "test"}}),
+		sponsored(xdr.OperationBody{Type: xdr.OperationTypeEndSponsoringFutureReserves}),
+		sponsored(xdr.OperationBody{Type: xdr.OperationTypeManageData, ManageDataOp: &xdr.ManageDataOp{DataName: "unsponsored"}}),
+	}
+	transaction := ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations:    operations,
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxSuccess},
+			},
+		},
+	}
+
+	expected := []null.String{
+		{},
+		null.StringFrom(testAccount1Address),
+		null.StringFrom(testAccount1Address),
+		null.StringFrom(testAccount1Address),
+		{},
+	}
+	for i, operation := range operations {
+		sponsor, err := operationSponsor(operation, int32(i), transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, expected[i], sponsor, "operation %d", i)
+	}
+}
+
+func TestTransformFeeBumpOperationMuxedSource(t *testing.T) {
+	type operationInput struct {
+		operation        xdr.Operation
+		index            int32
+		transaction      ingest.LedgerTransaction
+		ledgerClosedMeta xdr.LedgerCloseMeta
+	}
+	type transformTest struct {
+		input      operationInput
+		wantOutput operationMuxedSource
+	}
+
+	hardCodedInputTransactions, _, err := makeFeeBumpMuxedAccountsTestInput()
+	assert.NoError(t, err)
+	hardCodedInputLedgerCloseMeta := makeLedgerCloseMeta()
+
+	// operations without a source of their own take the muxed inner source, whether or not the fee source is muxed
+	tests := []transformTest{}
+	for _, transaction := range hardCodedInputTransactions {
+		for i, op := range transaction.Envelope.Operations() {
+			tests = append(tests, transformTest{
+				input: operationInput{op, int32(i), transaction, hardCodedInputLedgerCloseMeta},
+				wantOutput: operationMuxedSource{
+					SourceAccount:      testAccount1Address,
+					SourceAccountMuxed: "MCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XQAAAAAAAAAAAFLAGA",
+					SourceMuxedID:      42,
+				},
+			})
+		}
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError := TransformOperation(test.input.operation, test.input.index, test.input.transaction, 0, test.input.ledgerClosedMeta, "")
+		assert.NoError(t, actualError)
+		assert.Equal(t, test.wantOutput, operationMuxedSource{
+			SourceAccount:      actualOutput.SourceAccount,
+			SourceAccountMuxed: actualOutput.SourceAccountMuxed,
+			SourceMuxedID:      actualOutput.SourceMuxedID,
+		})
+	}
+}
+
+// operationMuxedSource are the source account columns of an operation output
+type operationMuxedSource struct {
+	SourceAccount      string
+	SourceAccountMuxed string
+	SourceMuxedID      uint64
+}
@@ -0,0 +1,15 @@
+package transform
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverPanic turns a panic of a transform into the error it returns, so that a pathological transaction or ledger
+// is logged or fails the export through the usual error handling instead of crashing a long export. It must be deferred
+// directly by the transform, with a pointer to its named error result.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("recovered from panic: %v\n%s", r, debug.Stack())
+	}
+}
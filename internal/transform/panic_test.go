@@ -0,0 +1,30 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	panicking := func() (_ int, err error) {
+		defer recoverPanic(&err)
+
+		var entries []int
+		return entries[1], nil
+	}
+
+	_, err := panicking()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "recovered from panic: runtime error: index out of range")
+
+	succeeding := func() (_ int, err error) {
+		defer recoverPanic(&err)
+
+		return 1, nil
+	}
+
+	value, err := succeeding()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stellar/stellar-etl/internal/toid"
+	"github.com/stellar/stellar-etl/internal/utils"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// TransformParticipants returns a row for every account taking part in the transaction. Like Horizon's transaction
+// participants, these are the source and fee accounts, the participants of each operation and the accounts whose
+// entries were changed by the transaction.
+func TransformParticipants(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (_ []ParticipantOutput, err error) {
+	defer recoverPanic(&err)
+
+	ledgerHeader := lhe.Header
+	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
+	outputTransactionID := toid.New(int32(outputLedgerSequence), int32(transaction.Index), 0).ToInt64()
+
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(ledgerHeader.ScpValue.CloseTime)
+	if err != nil {
+		return []ParticipantOutput{}, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %v", outputLedgerSequence, transaction.Index, outputTransactionID, err)
+	}
+
+	participants := []xdr.AccountId{transaction.Envelope.SourceAccount().ToAccountId()}
+	if transaction.Envelope.IsFeeBump() {
+		participants = append(participants, transaction.Envelope.FeeBumpAccount().ToAccountId())
+	}
+
+	for index, operation := range transaction.Envelope.Operations() {
+		wrapper := transactionOperationWrapper{
+			index:          uint32(index),
+			transaction:    transaction,
+			operation:      operation,
+			ledgerSequence: outputLedgerSequence,
+		}
+		operationParticipants, err := wrapper.Participants()
+		if err != nil {
+			return []ParticipantOutput{}, fmt.Errorf("for operation %d of transaction %d (transaction id=%d): %v", index, transaction.Index, outputTransactionID, err)
+		}
+		participants = append(participants, operationParticipants...)
+	}
+
+	changes, err := transactionChanges(transaction)
+	if err != nil {
+		return []ParticipantOutput{}, fmt.Errorf("could not read changes of transaction %d (transaction id=%d): %v", transaction.Index, outputTransactionID, err)
+	}
+	changes = append(changes, ingest.GetChangesFromLedgerEntryChanges(transaction.FeeChanges)...)
+	for _, change := range changes {
+		participants = append(participants, changeParticipants(change)...)
+	}
+
+	addresses := []string{}
+	for _, participant := range dedupeParticipants(participants) {
+		addresses = append(addresses, participant.Address())
+	}
+	sort.Strings(addresses)
+
+	transformedParticipants := make([]ParticipantOutput, 0, len(addresses))
+	for _, address := range addresses {
+		transformedParticipants = append(transformedParticipants, ParticipantOutput{
+			Account:        address,
+			TransactionID:  outputTransactionID,
+			LedgerSequence: outputLedgerSequence,
+			ClosedAt:       outputCloseTime,
+		})
+	}
+
+	return transformedParticipants, nil
+}
+
+// changeParticipants returns the accounts whose account entries were changed
+func changeParticipants(change ingest.Change) []xdr.AccountId {
+	participants := []xdr.AccountId{}
+	for _, entry := range []*xdr.LedgerEntry{change.Pre, change.Post} {
+		if entry != nil && entry.Data.Type == xdr.LedgerEntryTypeAccount {
+			participants = append(participants, entry.Data.MustAccount().AccountId)
+		}
+	}
+
+	return participants
+}
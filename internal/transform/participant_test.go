@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+func TestTransformParticipants(t *testing.T) {
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1000,
+			},
+			LedgerSeq: 10,
+		},
+	}
+
+	actualOutput, actualError := TransformParticipants(makeParticipantsTestInput(), header)
+	assert.NoError(t, actualError)
+	assert.Equal(t, makeParticipantsTestOutput(), actualOutput)
+}
+
+func makeParticipantsTestInput() ingest.LedgerTransaction {
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypePayment,
+								PaymentOp: &xdr.PaymentOp{
+									Destination: testAccount2,
+									Asset:       nativeAsset,
+									Amount:      350000000,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Result: utils.CreateSampleResultMeta(true, 1).Result,
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 1,
+			V1: &xdr.TransactionMetaV1{
+				TxChanges: xdr.LedgerEntryChanges{
+					{
+						Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+						Created: &xdr.LedgerEntry{
+							Data: xdr.LedgerEntryData{
+								Type: xdr.LedgerEntryTypeAccount,
+								Account: &xdr.AccountEntry{
+									AccountId: testAccount4ID,
+								},
+							},
+						},
+					},
+				},
+				Operations: []xdr.OperationMeta{{}},
+			},
+		},
+	}
+}
+
+func makeParticipantsTestOutput() []ParticipantOutput {
+	closedAt := time.Unix(1000, 0).UTC()
+	participants := []ParticipantOutput{}
+	for _, address := range []string{testAccount2Address, testAccount4Address, testAccount1Address} {
+		participants = append(participants, ParticipantOutput{
+			Account:        address,
+			TransactionID:  42949677056,
+			LedgerSequence: 10,
+			ClosedAt:       closedAt,
+		})
+	}
+
+	return participants
+}
@@ -0,0 +1,120 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/xdr"
+)
+
+// sacEventSetAuthorized is the topic of the event emitted by the set_authorized function of the Stellar Asset Contract,
+// which the contractevents package does not parse
+const sacEventSetAuthorized = "set_authorized"
+
+// sacEvent holds the typed values of a Stellar Asset Contract event. From and To are the accounts or contracts the
+// amount moved between; for set_authorized, From is the admin and To the account whose authorization was set.
+type sacEvent struct {
+	Type       string
+	From       string
+	To         string
+	Amount     string
+	Asset      string
+	Authorized null.Bool
+}
+
+// decodeSACEvent decodes the transfer, mint, burn, clawback and set_authorized events emitted by the Stellar Asset
+// Contract of an asset on the network. Events with the same topics emitted by other contracts are not decoded, since
+// their contract id is not the one of the asset they name.
+func decodeSACEvent(event xdr.ContractEvent, network string) (sacEvent, bool) {
+	if decoded, ok := decodeSetAuthorizedEvent(event, network); ok {
+		return decoded, true
+	}
+
+	parsed, err := contractevents.NewStellarAssetContractEvent(&event, network)
+	if err != nil {
+		return sacEvent{}, false
+	}
+
+	switch parsed.GetType() {
+	case contractevents.EventTypeTransfer:
+		transfer := parsed.(*contractevents.TransferEvent)
+		return sacEvent{Type: "transfer", From: transfer.From, To: transfer.To, Amount: amount.String128(transfer.Amount), Asset: transfer.Asset.StringCanonical()}, true
+	case contractevents.EventTypeMint:
+		mint := parsed.(*contractevents.MintEvent)
+		return sacEvent{Type: "mint", From: mint.Admin, To: mint.To, Amount: amount.String128(mint.Amount), Asset: mint.Asset.StringCanonical()}, true
+	case contractevents.EventTypeBurn:
+		burn := parsed.(*contractevents.BurnEvent)
+		return sacEvent{Type: "burn", From: burn.From, Amount: amount.String128(burn.Amount), Asset: burn.Asset.StringCanonical()}, true
+	case contractevents.EventTypeClawback:
+		clawback := parsed.(*contractevents.ClawbackEvent)
+		return sacEvent{Type: "clawback", From: clawback.From, Amount: amount.String128(clawback.Amount), Asset: clawback.Asset.StringCanonical()}, true
+	}
+
+	return sacEvent{}, false
+}
+
+// decodeSetAuthorizedEvent decodes the event of set_authorized, with the topics [set_authorized, admin, id, asset] and
+// the new authorization as data
+func decodeSetAuthorizedEvent(event xdr.ContractEvent, network string) (sacEvent, bool) {
+	body, ok := event.Body.GetV0()
+	if !ok || event.Type != xdr.ContractEventTypeContract || event.ContractId == nil || len(body.Topics) != 4 {
+		return sacEvent{}, false
+	}
+	if name, ok := body.Topics[0].GetSym(); !ok || string(name) != sacEventSetAuthorized {
+		return sacEvent{}, false
+	}
+
+	admin, adminOk := body.Topics[1].GetAddress()
+	id, idOk := body.Topics[2].GetAddress()
+	assetName, assetOk := body.Topics[3].GetStr()
+	authorized, authorizedOk := body.Data.GetB()
+	if !adminOk || !idOk || !assetOk || !authorizedOk {
+		return sacEvent{}, false
+	}
+
+	asset, ok := sacEventAsset(string(assetName))
+	if !ok {
+		return sacEvent{}, false
+	}
+	contractID, err := asset.ContractID(network)
+	if err != nil || xdr.Hash(contractID) != *event.ContractId {
+		return sacEvent{}, false
+	}
+
+	adminAddress, err := admin.String()
+	if err != nil {
+		return sacEvent{}, false
+	}
+	idAddress, err := id.String()
+	if err != nil {
+		return sacEvent{}, false
+	}
+
+	return sacEvent{
+		Type:       sacEventSetAuthorized,
+		From:       adminAddress,
+		To:         idAddress,
+		Asset:      asset.StringCanonical(),
+		Authorized: null.BoolFrom(authorized),
+	}, true
+}
+
+// sacEventAsset parses the asset topic of Stellar Asset Contract events: native, or code:issuer
+func sacEventAsset(name string) (xdr.Asset, bool) {
+	if name == "native" {
+		return xdr.MustNewNativeAsset(), true
+	}
+
+	code, issuer, ok := strings.Cut(name, ":")
+	if !ok {
+		return xdr.Asset{}, false
+	}
+	asset, err := xdr.NewCreditAsset(code, issuer)
+	if err != nil {
+		return xdr.Asset{}, false
+	}
+	return asset, true
+}
@@ -11,7 +11,7 @@ import (
 
 // LedgerOutput is a representation of a ledger that aligns with the BigQuery table history_ledgers
 type LedgerOutput struct {
-	Sequence                   uint32    `json:"sequence"` // sequence number of the ledger
+	Sequence                   uint32    `json:"sequence" bigquery:"cluster"` // sequence number of the ledger
 	LedgerHash                 string    `json:"ledger_hash"`
 	PreviousLedgerHash         string    `json:"previous_ledger_hash"`
 	LedgerHeader               string    `json:"ledger_header"` // base 64 encoding of the ledger header
@@ -19,8 +19,8 @@ type LedgerOutput struct {
 	OperationCount             int32     `json:"operation_count"` // counts only operations that were a part of successful transactions
 	SuccessfulTransactionCount int32     `json:"successful_transaction_count"`
 	FailedTransactionCount     int32     `json:"failed_transaction_count"`
-	TxSetOperationCount        string    `json:"tx_set_operation_count"` // counts all operations, even those that are part of failed transactions
-	ClosedAt                   time.Time `json:"closed_at"`              // UTC timestamp
+	TxSetOperationCount        string    `json:"tx_set_operation_count"`         // counts all operations, even those that are part of failed transactions
+	ClosedAt                   time.Time `json:"closed_at" bigquery:"partition"` // UTC timestamp
 	TotalCoins                 int64     `json:"total_coins"`
 	FeePool                    int64     `json:"fee_pool"`
 	BaseFee                    uint32    `json:"base_fee"`
@@ -29,49 +29,94 @@ type LedgerOutput struct {
 	ProtocolVersion            uint32    `json:"protocol_version"`
 	LedgerID                   int64     `json:"id"`
 	SorobanFeeWrite1Kb         int64     `json:"soroban_fee_write_1kb"`
+	// The Soroban write fee settings active when the ledger closed, null when they are not known
+	SorobanBucketListTargetSizeBytes      null.Int `json:"soroban_bucket_list_target_size_bytes"`
+	SorobanWriteFee1KbBucketListLow       null.Int `json:"soroban_write_fee_1kb_bucket_list_low"`
+	SorobanWriteFee1KbBucketListHigh      null.Int `json:"soroban_write_fee_1kb_bucket_list_high"`
+	SorobanBucketListWriteFeeGrowthFactor null.Int `json:"soroban_bucket_list_write_fee_growth_factor"`
+}
+
+// LedgerStatsOutput is a representation of the network statistics of a ledger that aligns with the BigQuery table ledger_stats
+type LedgerStatsOutput struct {
+	Sequence                   uint32           `json:"sequence" bigquery:"cluster"`
+	ClosedAt                   time.Time        `json:"closed_at" bigquery:"partition"`
+	TransactionCount           int32            `json:"transaction_count"`
+	SuccessfulTransactionCount int32            `json:"successful_transaction_count"`
+	FailedTransactionCount     int32            `json:"failed_transaction_count"`
+	FailedTransactionRate      float64          `json:"failed_transaction_rate"`
+	OperationCount             int32            `json:"operation_count"` // counts the operations of successful and failed transactions
+	OperationCountsByType      map[string]int32 `json:"operation_counts_by_type"`
+	UniqueSourceAccounts       int32            `json:"unique_source_accounts"`
+	AverageFeeCharged          float64          `json:"average_fee_charged"`
+	SorobanTransactionCount    int32            `json:"soroban_transaction_count"`
+	ClassicTransactionCount    int32            `json:"classic_transaction_count"`
+}
+
+// BucketListMetricsOutput is a representation of the bucket list size of a ledger that aligns with the BigQuery table bucket_list_metrics
+type BucketListMetricsOutput struct {
+	Sequence                    uint32    `json:"sequence" bigquery:"cluster"`
+	ClosedAt                    time.Time `json:"closed_at" bigquery:"partition"`
+	BucketListSizeBytes         uint64    `json:"bucket_list_size_bytes"`
+	EvictedTemporaryEntryCount  int32     `json:"evicted_temporary_entry_count"`
+	EvictedPersistentEntryCount int32     `json:"evicted_persistent_entry_count"`
 }
 
 // TransactionOutput is a representation of a transaction that aligns with the BigQuery table history_transactions
 type TransactionOutput struct {
-	TransactionHash                      string         `json:"transaction_hash"`
-	LedgerSequence                       uint32         `json:"ledger_sequence"`
-	Account                              string         `json:"account"`
-	AccountMuxed                         string         `json:"account_muxed,omitempty"`
-	AccountSequence                      int64          `json:"account_sequence"`
-	MaxFee                               uint32         `json:"max_fee"`
-	FeeCharged                           int64          `json:"fee_charged"`
-	OperationCount                       int32          `json:"operation_count"`
-	TxEnvelope                           string         `json:"tx_envelope"`
-	TxResult                             string         `json:"tx_result"`
-	TxMeta                               string         `json:"tx_meta"`
-	TxFeeMeta                            string         `json:"tx_fee_meta"`
-	CreatedAt                            time.Time      `json:"created_at"`
-	MemoType                             string         `json:"memo_type"`
-	Memo                                 string         `json:"memo"`
-	TimeBounds                           string         `json:"time_bounds"`
-	Successful                           bool           `json:"successful"`
-	TransactionID                        int64          `json:"id"`
-	FeeAccount                           string         `json:"fee_account,omitempty"`
-	FeeAccountMuxed                      string         `json:"fee_account_muxed,omitempty"`
-	InnerTransactionHash                 string         `json:"inner_transaction_hash,omitempty"`
-	NewMaxFee                            uint32         `json:"new_max_fee,omitempty"`
-	LedgerBounds                         string         `json:"ledger_bounds"`
-	MinAccountSequence                   null.Int       `json:"min_account_sequence"`
-	MinAccountSequenceAge                null.Int       `json:"min_account_sequence_age"`
-	MinAccountSequenceLedgerGap          null.Int       `json:"min_account_sequence_ledger_gap"`
-	ExtraSigners                         pq.StringArray `json:"extra_signers"`
-	ClosedAt                             time.Time      `json:"closed_at"`
-	ResourceFee                          int64          `json:"resource_fee"`
-	SorobanResourcesInstructions         uint32         `json:"soroban_resources_instructions"`
-	SorobanResourcesReadBytes            uint32         `json:"soroban_resources_read_bytes"`
-	SorobanResourcesWriteBytes           uint32         `json:"soroban_resources_write_bytes"`
-	TransactionResultCode                string         `json:"transaction_result_code"`
-	InclusionFeeBid                      int64          `json:"inclusion_fee_bid"`
-	InclusionFeeCharged                  int64          `json:"inclusion_fee_charged"`
-	ResourceFeeRefund                    int64          `json:"resource_fee_refund"`
-	TotalNonRefundableResourceFeeCharged int64          `json:"non_refundable_resource_fee_charged"`
-	TotalRefundableResourceFeeCharged    int64          `json:"refundable_resource_fee_charged"`
-	RentFeeCharged                       int64          `json:"rent_fee_charged"`
+	TransactionHash                      string           `json:"transaction_hash"`
+	LedgerSequence                       uint32           `json:"ledger_sequence" bigquery:"cluster"`
+	Account                              string           `json:"account" bigquery:"cluster"`
+	AccountMuxed                         string           `json:"account_muxed,omitempty"`
+	AccountMuxedID                       uint64           `json:"account_muxed_id,omitempty"`
+	AccountSequence                      int64            `json:"account_sequence"`
+	MaxFee                               uint32           `json:"max_fee"`
+	FeeCharged                           int64            `json:"fee_charged"`
+	OperationCount                       int32            `json:"operation_count"`
+	TxEnvelope                           string           `json:"tx_envelope"`
+	TxResult                             string           `json:"tx_result"`
+	TxMeta                               string           `json:"tx_meta"`
+	TxFeeMeta                            string           `json:"tx_fee_meta"`
+	CreatedAt                            time.Time        `json:"created_at"`
+	MemoType                             string           `json:"memo_type"`
+	Memo                                 string           `json:"memo"`
+	TimeBounds                           string           `json:"time_bounds"`
+	Successful                           bool             `json:"successful"`
+	TransactionID                        int64            `json:"id"`
+	FeeAccount                           string           `json:"fee_account,omitempty"`
+	FeeAccountMuxed                      string           `json:"fee_account_muxed,omitempty"`
+	FeeAccountMuxedID                    uint64           `json:"fee_account_muxed_id,omitempty"`
+	InnerTransactionHash                 string           `json:"inner_transaction_hash,omitempty"`
+	NewMaxFee                            uint32           `json:"new_max_fee,omitempty"`
+	InnerFeeCharged                      int64            `json:"inner_fee_charged,omitempty"`
+	LedgerBounds                         string           `json:"ledger_bounds"`
+	MinTime                              null.Time        `json:"min_time"`
+	MaxTime                              null.Time        `json:"max_time"`
+	MinLedger                            null.Int         `json:"min_ledger"`
+	MaxLedger                            null.Int         `json:"max_ledger"`
+	MinAccountSequence                   null.Int         `json:"min_account_sequence"`
+	MinAccountSequenceAge                null.Int         `json:"min_account_sequence_age"`
+	MinAccountSequenceLedgerGap          null.Int         `json:"min_account_sequence_ledger_gap"`
+	ExtraSigners                         pq.StringArray   `json:"extra_signers"`
+	ClosedAt                             time.Time        `json:"closed_at" bigquery:"partition"`
+	ResourceFee                          int64            `json:"resource_fee"`
+	SorobanResourcesInstructions         uint32           `json:"soroban_resources_instructions"`
+	SorobanResourcesReadBytes            uint32           `json:"soroban_resources_read_bytes"`
+	SorobanResourcesWriteBytes           uint32           `json:"soroban_resources_write_bytes"`
+	TransactionResultCode                string           `json:"transaction_result_code"`
+	InclusionFeeBid                      int64            `json:"inclusion_fee_bid"`
+	InclusionFeeCharged                  int64            `json:"inclusion_fee_charged"`
+	ResourceFeeRefund                    int64            `json:"resource_fee_refund"`
+	TotalNonRefundableResourceFeeCharged int64            `json:"non_refundable_resource_fee_charged"`
+	TotalRefundableResourceFeeCharged    int64            `json:"refundable_resource_fee_charged"`
+	RentFeeCharged                       int64            `json:"rent_fee_charged"`
+	SorobanCpuInstructions               null.Int         `json:"soroban_cpu_instructions"`
+	SorobanMemoryBytes                   null.Int         `json:"soroban_memory_bytes"`
+	SorobanInvokeTimeNsecs               null.Int         `json:"soroban_invoke_time_nsecs"`
+	SorobanCoreMetrics                   map[string]int64 `json:"soroban_core_metrics"`
+	TxSetPhase                           null.Int         `json:"tx_set_phase"`
+	TxSetComponent                       null.Int         `json:"tx_set_component"`
+	TxSetPosition                        null.Int         `json:"tx_set_position"`
+	HashMismatch                         null.Bool        `json:"hash_mismatch"`
 }
 
 type LedgerTransactionOutput struct {
@@ -86,86 +131,145 @@ type LedgerTransactionOutput struct {
 
 // AccountOutput is a representation of an account that aligns with the BigQuery table accounts
 type AccountOutput struct {
-	AccountID            string      `json:"account_id"` // account address
-	Balance              float64     `json:"balance"`
-	BuyingLiabilities    float64     `json:"buying_liabilities"`
-	SellingLiabilities   float64     `json:"selling_liabilities"`
-	SequenceNumber       int64       `json:"sequence_number"`
-	SequenceLedger       zero.Int    `json:"sequence_ledger"`
-	SequenceTime         zero.Int    `json:"sequence_time"`
-	NumSubentries        uint32      `json:"num_subentries"`
-	InflationDestination string      `json:"inflation_destination"`
-	Flags                uint32      `json:"flags"`
-	HomeDomain           string      `json:"home_domain"`
-	MasterWeight         int32       `json:"master_weight"`
-	ThresholdLow         int32       `json:"threshold_low"`
-	ThresholdMedium      int32       `json:"threshold_medium"`
-	ThresholdHigh        int32       `json:"threshold_high"`
-	Sponsor              null.String `json:"sponsor"`
-	NumSponsored         uint32      `json:"num_sponsored"`
-	NumSponsoring        uint32      `json:"num_sponsoring"`
-	LastModifiedLedger   uint32      `json:"last_modified_ledger"`
-	LedgerEntryChange    uint32      `json:"ledger_entry_change"`
-	Deleted              bool        `json:"deleted"`
-	ClosedAt             time.Time   `json:"closed_at"`
-	LedgerSequence       uint32      `json:"ledger_sequence"`
+	AccountID            string        `json:"account_id" bigquery:"cluster"` // account address
+	Balance              float64       `json:"balance"`
+	BuyingLiabilities    float64       `json:"buying_liabilities"`
+	SellingLiabilities   float64       `json:"selling_liabilities"`
+	SequenceNumber       int64         `json:"sequence_number"`
+	SequenceLedger       zero.Int      `json:"sequence_ledger"`
+	SequenceTime         zero.Int      `json:"sequence_time"`
+	NumSubentries        uint32        `json:"num_subentries"`
+	InflationDestination string        `json:"inflation_destination"`
+	Flags                uint32        `json:"flags"`
+	HomeDomain           string        `json:"home_domain"`
+	MasterWeight         int32         `json:"master_weight"`
+	ThresholdLow         int32         `json:"threshold_low"`
+	ThresholdMedium      int32         `json:"threshold_medium"`
+	ThresholdHigh        int32         `json:"threshold_high"`
+	SignerSummary        SignerSummary `json:"signer_summary"`
+	Sponsor              null.String   `json:"sponsor"`
+	NumSponsored         uint32        `json:"num_sponsored"`
+	NumSponsoring        uint32        `json:"num_sponsoring"`
+	LastModifiedLedger   uint32        `json:"last_modified_ledger"`
+	LedgerEntryChange    uint32        `json:"ledger_entry_change"`
+	Deleted              bool          `json:"deleted"`
+	ClosedAt             time.Time     `json:"closed_at" bigquery:"partition"`
+	LedgerSequence       uint32        `json:"ledger_sequence"`
+}
+
+// SignerSummary compares the combined weight of the signers of an account, master key included, with its thresholds
+type SignerSummary struct {
+	SignerCount int32 `json:"signer_count"` // signers with a weight, master key included
+	TotalWeight int32 `json:"total_weight"`
+	MeetsLow    bool  `json:"meets_low"`
+	MeetsMedium bool  `json:"meets_medium"`
+	MeetsHigh   bool  `json:"meets_high"`
 }
 
 // AccountSignerOutput is a representation of an account signer that aligns with the BigQuery table account_signers
 type AccountSignerOutput struct {
-	AccountID          string      `json:"account_id"`
-	Signer             string      `json:"signer"`
+	AccountID          string      `json:"account_id" bigquery:"cluster"`
+	Signer             string      `json:"signer" bigquery:"cluster"`
 	Weight             int32       `json:"weight"`
 	Sponsor            null.String `json:"sponsor"`
 	LastModifiedLedger uint32      `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
 	Deleted            bool        `json:"deleted"`
-	ClosedAt           time.Time   `json:"closed_at"`
+	ClosedAt           time.Time   `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32      `json:"ledger_sequence"`
 }
 
 // OperationOutput is a representation of an operation that aligns with the BigQuery table history_operations
 type OperationOutput struct {
-	SourceAccount       string                 `json:"source_account"`
+	SourceAccount       string                 `json:"source_account" bigquery:"cluster"`
 	SourceAccountMuxed  string                 `json:"source_account_muxed,omitempty"`
-	Type                int32                  `json:"type"`
+	SourceMuxedID       uint64                 `json:"source_account_muxed_id,omitempty"`
+	Type                int32                  `json:"type" bigquery:"cluster"`
 	TypeString          string                 `json:"type_string"`
 	OperationDetails    map[string]interface{} `json:"details"` //Details is a JSON object that varies based on operation type
 	TransactionID       int64                  `json:"transaction_id"`
 	OperationID         int64                  `json:"id"`
-	ClosedAt            time.Time              `json:"closed_at"`
+	ClosedAt            time.Time              `json:"closed_at" bigquery:"partition"`
 	OperationResultCode string                 `json:"operation_result_code"`
 	OperationTraceCode  string                 `json:"operation_trace_code"`
+	Participants        []string               `json:"participants"`
+	Sponsor             null.String            `json:"sponsor"` // account sponsoring the reserves of operations in a sponsorship sandwich
+}
+
+// ParticipantOutput is a representation of an account taking part in a transaction that aligns with the BigQuery table participants
+type ParticipantOutput struct {
+	Account        string    `json:"account" bigquery:"cluster"`
+	TransactionID  int64     `json:"transaction_id"`
+	LedgerSequence uint32    `json:"ledger_sequence"`
+	ClosedAt       time.Time `json:"closed_at" bigquery:"partition"`
+}
+
+// AccountDataOutput is a representation of a data entry set by manage data operations that aligns with the BigQuery table account_data
+type AccountDataOutput struct {
+	AccountID          string      `json:"account_id" bigquery:"cluster"`
+	DataName           string      `json:"data_name"`
+	DataValue          string      `json:"data_value"`
+	Sponsor            null.String `json:"sponsor"`
+	LastModifiedLedger uint32      `json:"last_modified_ledger"`
+	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
+	Deleted            bool        `json:"deleted"`
+	ClosedAt           time.Time   `json:"closed_at" bigquery:"partition"`
+	LedgerSequence     uint32      `json:"ledger_sequence"`
 }
 
 // ClaimableBalanceOutput is a representation of a claimable balances that aligns with the BigQuery table claimable_balances
 type ClaimableBalanceOutput struct {
-	BalanceID          string      `json:"balance_id"`
+	BalanceID          string      `json:"balance_id" bigquery:"cluster"`
 	Claimants          []Claimant  `json:"claimants"`
 	AssetCode          string      `json:"asset_code"`
 	AssetIssuer        string      `json:"asset_issuer"`
 	AssetType          string      `json:"asset_type"`
-	AssetID            int64       `json:"asset_id"`
+	AssetID            int64       `json:"asset_id" bigquery:"cluster"`
 	AssetAmount        float64     `json:"asset_amount"`
 	Sponsor            null.String `json:"sponsor"`
 	Flags              uint32      `json:"flags"`
 	LastModifiedLedger uint32      `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
 	Deleted            bool        `json:"deleted"`
-	ClosedAt           time.Time   `json:"closed_at"`
+	ClosedAt           time.Time   `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32      `json:"ledger_sequence"`
 }
 
 // Claimants
 type Claimant struct {
-	Destination string             `json:"destination"`
-	Predicate   xdr.ClaimPredicate `json:"predicate"`
+	Destination   string             `json:"destination"`
+	Predicate     xdr.ClaimPredicate `json:"predicate"`
+	PredicateJSON interface{}        `json:"predicate_json"`
 }
 
 // Price represents the price of an asset as a fraction
 type Price struct {
-	Numerator   int32 `json:"n"`
-	Denominator int32 `json:"d"`
+	Numerator   int64 `json:"n"`
+	Denominator int64 `json:"d"`
+}
+
+// PathPaymentLeg is an offer or liquidity pool that a path payment crossed. The seller sold the selling asset to the
+// path payment in exchange for the buying asset.
+type PathPaymentLeg struct {
+	SellerAddress      null.String `json:"seller"`
+	OfferID            null.Int    `json:"offer_id"`
+	LiquidityPoolID    null.String `json:"liquidity_pool_id"`
+	SellingAssetType   string      `json:"selling_asset_type"`
+	SellingAssetCode   string      `json:"selling_asset_code"`
+	SellingAssetIssuer string      `json:"selling_asset_issuer"`
+	SellingAssetID     int64       `json:"selling_asset_id"`
+	SellingAmount      float64     `json:"selling_amount"`
+	BuyingAssetType    string      `json:"buying_asset_type"`
+	BuyingAssetCode    string      `json:"buying_asset_code"`
+	BuyingAssetIssuer  string      `json:"buying_asset_issuer"`
+	BuyingAssetID      int64       `json:"buying_asset_id"`
+	BuyingAmount       float64     `json:"buying_amount"`
+}
+
+// InflationPayout is the lumens an inflation operation paid to an account that received enough inflation votes
+type InflationPayout struct {
+	Destination string  `json:"destination"`
+	Amount      float64 `json:"amount"`
 }
 
 // Path is a representation of an asset without an ID that forms part of a path in a path payment
@@ -173,6 +277,7 @@ type Path struct {
 	AssetCode   string `json:"asset_code"`
 	AssetIssuer string `json:"asset_issuer"`
 	AssetType   string `json:"asset_type"`
+	AssetID     int64  `json:"asset_id"`
 }
 
 // LiquidityPoolAsset represents the asset pairs in a liquidity pool
@@ -189,7 +294,7 @@ type LiquidityPoolAsset struct {
 
 // PoolOutput is a representation of a liquidity pool that aligns with the Bigquery table liquidity_pools
 type PoolOutput struct {
-	PoolID             string    `json:"liquidity_pool_id"`
+	PoolID             string    `json:"liquidity_pool_id" bigquery:"cluster"`
 	PoolType           string    `json:"type"`
 	PoolFee            uint32    `json:"fee"`
 	TrustlineCount     uint64    `json:"trustline_count"`
@@ -207,14 +312,14 @@ type PoolOutput struct {
 	LastModifiedLedger uint32    `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
 	Deleted            bool      `json:"deleted"`
-	ClosedAt           time.Time `json:"closed_at"`
+	ClosedAt           time.Time `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32    `json:"ledger_sequence"`
 }
 
 // AssetOutput is a representation of an asset that aligns with the BigQuery table history_assets
 type AssetOutput struct {
-	AssetCode   string `json:"asset_code"`
-	AssetIssuer string `json:"asset_issuer"`
+	AssetCode   string `json:"asset_code" bigquery:"cluster"`
+	AssetIssuer string `json:"asset_issuer" bigquery:"cluster"`
 	AssetType   string `json:"asset_type"`
 	AssetID     uint64 `json:"id"`
 	ID          int64  `json:"asset_id"`
@@ -222,69 +327,76 @@ type AssetOutput struct {
 
 // TrustlineOutput is a representation of a trustline that aligns with the BigQuery table trust_lines
 type TrustlineOutput struct {
-	LedgerKey          string      `json:"ledger_key"`
-	AccountID          string      `json:"account_id"`
-	AssetCode          string      `json:"asset_code"`
-	AssetIssuer        string      `json:"asset_issuer"`
-	AssetType          int32       `json:"asset_type"`
-	AssetID            int64       `json:"asset_id"`
-	Balance            float64     `json:"balance"`
-	TrustlineLimit     int64       `json:"trust_line_limit"`
-	LiquidityPoolID    string      `json:"liquidity_pool_id"`
-	BuyingLiabilities  float64     `json:"buying_liabilities"`
-	SellingLiabilities float64     `json:"selling_liabilities"`
-	Flags              uint32      `json:"flags"`
-	LastModifiedLedger uint32      `json:"last_modified_ledger"`
-	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
-	Sponsor            null.String `json:"sponsor"`
-	Deleted            bool        `json:"deleted"`
-	ClosedAt           time.Time   `json:"closed_at"`
-	LedgerSequence     uint32      `json:"ledger_sequence"`
+	LedgerKey                         string      `json:"ledger_key"`
+	AccountID                         string      `json:"account_id" bigquery:"cluster"`
+	AssetCode                         string      `json:"asset_code"`
+	AssetIssuer                       string      `json:"asset_issuer"`
+	AssetType                         int32       `json:"asset_type"`
+	AssetID                           int64       `json:"asset_id" bigquery:"cluster"`
+	Balance                           float64     `json:"balance"`
+	TrustlineLimit                    int64       `json:"trust_line_limit"`
+	Limit                             string      `json:"limit"`
+	LimitStroops                      int64       `json:"limit_stroops"`
+	LiquidityPoolID                   string      `json:"liquidity_pool_id"`
+	BuyingLiabilities                 float64     `json:"buying_liabilities"`
+	SellingLiabilities                float64     `json:"selling_liabilities"`
+	Flags                             uint32      `json:"flags"`
+	IsAuthorized                      bool        `json:"is_authorized"`
+	IsAuthorizedToMaintainLiabilities bool        `json:"is_authorized_to_maintain_liabilities"`
+	IsClawbackEnabled                 bool        `json:"is_clawback_enabled"`
+	LastModifiedLedger                uint32      `json:"last_modified_ledger"`
+	LedgerEntryChange                 uint32      `json:"ledger_entry_change"`
+	Sponsor                           null.String `json:"sponsor"`
+	Deleted                           bool        `json:"deleted"`
+	ClosedAt                          time.Time   `json:"closed_at" bigquery:"partition"`
+	LedgerSequence                    uint32      `json:"ledger_sequence"`
 }
 
 // OfferOutput is a representation of an offer that aligns with the BigQuery table offers
 type OfferOutput struct {
-	SellerID           string      `json:"seller_id"` // Account address of the seller
+	SellerID           string      `json:"seller_id" bigquery:"cluster"` // Account address of the seller
 	OfferID            int64       `json:"offer_id"`
 	SellingAssetType   string      `json:"selling_asset_type"`
 	SellingAssetCode   string      `json:"selling_asset_code"`
 	SellingAssetIssuer string      `json:"selling_asset_issuer"`
-	SellingAssetID     int64       `json:"selling_asset_id"`
+	SellingAssetID     int64       `json:"selling_asset_id" bigquery:"cluster"`
 	BuyingAssetType    string      `json:"buying_asset_type"`
 	BuyingAssetCode    string      `json:"buying_asset_code"`
 	BuyingAssetIssuer  string      `json:"buying_asset_issuer"`
-	BuyingAssetID      int64       `json:"buying_asset_id"`
+	BuyingAssetID      int64       `json:"buying_asset_id" bigquery:"cluster"`
 	Amount             float64     `json:"amount"`
-	PriceN             int32       `json:"pricen"`
-	PriceD             int32       `json:"priced"`
+	PriceN             int64       `json:"pricen"`
+	PriceD             int64       `json:"priced"`
 	Price              float64     `json:"price"`
+	PriceDecimal       string      `json:"price_decimal"`
 	Flags              uint32      `json:"flags"`
 	LastModifiedLedger uint32      `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
 	Deleted            bool        `json:"deleted"`
 	Sponsor            null.String `json:"sponsor"`
-	ClosedAt           time.Time   `json:"closed_at"`
+	ClosedAt           time.Time   `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32      `json:"ledger_sequence"`
 }
 
 // TradeOutput is a representation of a trade that aligns with the BigQuery table history_trades
 type TradeOutput struct {
 	Order                  int32       `json:"order"`
-	LedgerClosedAt         time.Time   `json:"ledger_closed_at"`
+	LedgerClosedAt         time.Time   `json:"ledger_closed_at" bigquery:"partition"`
 	SellingAccountAddress  string      `json:"selling_account_address"`
 	SellingAssetCode       string      `json:"selling_asset_code"`
 	SellingAssetIssuer     string      `json:"selling_asset_issuer"`
 	SellingAssetType       string      `json:"selling_asset_type"`
-	SellingAssetID         int64       `json:"selling_asset_id"`
+	SellingAssetID         int64       `json:"selling_asset_id" bigquery:"cluster"`
 	SellingAmount          float64     `json:"selling_amount"`
 	BuyingAccountAddress   string      `json:"buying_account_address"`
 	BuyingAssetCode        string      `json:"buying_asset_code"`
 	BuyingAssetIssuer      string      `json:"buying_asset_issuer"`
 	BuyingAssetType        string      `json:"buying_asset_type"`
-	BuyingAssetID          int64       `json:"buying_asset_id"`
+	BuyingAssetID          int64       `json:"buying_asset_id" bigquery:"cluster"`
 	BuyingAmount           float64     `json:"buying_amount"`
 	PriceN                 int64       `json:"price_n"`
 	PriceD                 int64       `json:"price_d"`
+	Price                  string      `json:"price"`
 	SellingOfferID         null.Int    `json:"selling_offer_id"`
 	BuyingOfferID          null.Int    `json:"buying_offer_id"`
 	SellingLiquidityPoolID null.String `json:"selling_liquidity_pool_id"`
@@ -311,6 +423,9 @@ type DimOffer struct {
 	BaseAmount    float64 `json:"base_amount"`
 	CounterAmount float64 `json:"counter_amount"`
 	Price         float64 `json:"price"`
+	PriceN        int64   `json:"price_n"`
+	PriceD        int64   `json:"price_d"`
+	PriceDecimal  string  `json:"price_decimal"`
 }
 
 // FactOfferEvent is a representation of an offer event that aligns with the BigQuery table fact_offer_events
@@ -343,13 +458,13 @@ type SponsorshipOutput struct {
 
 // EffectOutput is a representation of an operation that aligns with the BigQuery table history_effects
 type EffectOutput struct {
-	Address      string                 `json:"address"`
+	Address      string                 `json:"address" bigquery:"cluster"`
 	AddressMuxed null.String            `json:"address_muxed,omitempty"`
 	OperationID  int64                  `json:"operation_id"`
 	Details      map[string]interface{} `json:"details"`
-	Type         int32                  `json:"type"`
+	Type         int32                  `json:"type" bigquery:"cluster"`
 	TypeString   string                 `json:"type_string"`
-	LedgerClosed time.Time              `json:"closed_at"`
+	LedgerClosed time.Time              `json:"closed_at" bigquery:"partition"`
 }
 
 // EffectType is the numeric type for an effect
@@ -398,6 +513,7 @@ const (
 	EffectSignerSponsorshipUpdated           EffectType = 73
 	EffectSignerSponsorshipRemoved           EffectType = 74
 	EffectClaimableBalanceClawedBack         EffectType = 80
+	EffectAssetClawedBack                    EffectType = 81
 	EffectLiquidityPoolDeposited             EffectType = 90
 	EffectLiquidityPoolWithdrew              EffectType = 91
 	EffectLiquidityPoolTrade                 EffectType = 92
@@ -408,6 +524,7 @@ const (
 	EffectContractDebited                    EffectType = 97
 	EffectExtendFootprintTtl                 EffectType = 98
 	EffectRestoreFootprint                   EffectType = 99
+	EffectContractBalanceChanged             EffectType = 100
 )
 
 // EffectTypeNames stores a map of effect type ID and names
@@ -454,6 +571,7 @@ var EffectTypeNames = map[EffectType]string{
 	EffectSignerSponsorshipUpdated:           "signer_sponsorship_updated",
 	EffectSignerSponsorshipRemoved:           "signer_sponsorship_removed",
 	EffectClaimableBalanceClawedBack:         "claimable_balance_clawed_back",
+	EffectAssetClawedBack:                    "asset_clawed_back",
 	EffectLiquidityPoolDeposited:             "liquidity_pool_deposited",
 	EffectLiquidityPoolWithdrew:              "liquidity_pool_withdrew",
 	EffectLiquidityPoolTrade:                 "liquidity_pool_trade",
@@ -464,6 +582,7 @@ var EffectTypeNames = map[EffectType]string{
 	EffectContractDebited:                    "contract_debited",
 	EffectExtendFootprintTtl:                 "extend_footprint_ttl",
 	EffectRestoreFootprint:                   "restore_footprint",
+	EffectContractBalanceChanged:             "contract_balance_changed",
 }
 
 // TradeEffectDetails is a struct of data from `effects.DetailsString`
@@ -495,30 +614,46 @@ type TestTransaction struct {
 
 // ContractDataOutput is a representation of contract data that aligns with the Bigquery table soroban_contract_data
 type ContractDataOutput struct {
-	ContractId                string    `json:"contract_id"`
+	ContractId                string    `json:"contract_id" bigquery:"cluster"`
 	ContractKeyType           string    `json:"contract_key_type"`
 	ContractDurability        string    `json:"contract_durability"`
 	ContractDataAssetCode     string    `json:"asset_code"`
 	ContractDataAssetIssuer   string    `json:"asset_issuer"`
 	ContractDataAssetType     string    `json:"asset_type"`
+	ContractDataAssetID       null.Int  `json:"asset_id"`
 	ContractDataBalanceHolder string    `json:"balance_holder"`
 	ContractDataBalance       string    `json:"balance"` // balance is a string because it is go type big.Int
 	LastModifiedLedger        uint32    `json:"last_modified_ledger"`
 	LedgerEntryChange         uint32    `json:"ledger_entry_change"`
 	Deleted                   bool      `json:"deleted"`
-	ClosedAt                  time.Time `json:"closed_at"`
+	ClosedAt                  time.Time `json:"closed_at" bigquery:"partition"`
 	LedgerSequence            uint32    `json:"ledger_sequence"`
 	LedgerKeyHash             string    `json:"ledger_key_hash"`
+	LiveUntilLedgerSeq        null.Int  `json:"live_until_ledger_seq"`
+	Expired                   null.Bool `json:"expired"`
+}
+
+// ContractNonceOutput is a representation of the nonce an address uses for replay protection of its Soroban authorizations
+type ContractNonceOutput struct {
+	Address            string    `json:"address" bigquery:"cluster"`
+	Nonce              int64     `json:"nonce"`
+	LastModifiedLedger uint32    `json:"last_modified_ledger"`
+	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
+	Deleted            bool      `json:"deleted"`
+	ClosedAt           time.Time `json:"closed_at" bigquery:"partition"`
+	LedgerSequence     uint32    `json:"ledger_sequence"`
+	LedgerKeyHash      string    `json:"ledger_key_hash"`
+	LiveUntilLedgerSeq null.Int  `json:"live_until_ledger_seq"`
 }
 
 // ContractCodeOutput is a representation of contract code that aligns with the Bigquery table soroban_contract_code
 type ContractCodeOutput struct {
-	ContractCodeHash   string    `json:"contract_code_hash"`
+	ContractCodeHash   string    `json:"contract_code_hash" bigquery:"cluster"`
 	ContractCodeExtV   int32     `json:"contract_code_ext_v"`
 	LastModifiedLedger uint32    `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
 	Deleted            bool      `json:"deleted"`
-	ClosedAt           time.Time `json:"closed_at"`
+	ClosedAt           time.Time `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32    `json:"ledger_sequence"`
 	LedgerKeyHash      string    `json:"ledger_key_hash"`
 	//ContractCodeCode                string `json:"contract_code"`
@@ -536,7 +671,7 @@ type ContractCodeOutput struct {
 
 // ConfigSettingOutput is a representation of soroban config settings that aligns with the Bigquery table config_settings
 type ConfigSettingOutput struct {
-	ConfigSettingId                 int32               `json:"config_setting_id"`
+	ConfigSettingId                 int32               `json:"config_setting_id" bigquery:"cluster"`
 	ContractMaxSizeBytes            uint32              `json:"contract_max_size_bytes"`
 	LedgerMaxInstructions           int64               `json:"ledger_max_instructions"`
 	TxMaxInstructions               int64               `json:"tx_max_instructions"`
@@ -582,31 +717,77 @@ type ConfigSettingOutput struct {
 	LastModifiedLedger              uint32              `json:"last_modified_ledger"`
 	LedgerEntryChange               uint32              `json:"ledger_entry_change"`
 	Deleted                         bool                `json:"deleted"`
-	ClosedAt                        time.Time           `json:"closed_at"`
+	ClosedAt                        time.Time           `json:"closed_at" bigquery:"partition"`
 	LedgerSequence                  uint32              `json:"ledger_sequence"`
 }
 
+// LedgerEntryChangeCause identifies what caused a ledger entry change: an operation, the fee or sequence number processing
+// of a transaction, a protocol upgrade or the eviction of an expired entry
+type LedgerEntryChangeCause struct {
+	TransactionHash string   `json:"transaction_hash"`
+	OperationIndex  null.Int `json:"operation_index"`
+	OperationType   string   `json:"operation_type"`
+}
+
 // TtlOutput is a representation of soroban ttl that aligns with the Bigquery table ttls
 type TtlOutput struct {
-	KeyHash            string    `json:"key_hash"` // key_hash is contract_code_hash or contract_id
+	KeyHash            string    `json:"key_hash" bigquery:"cluster"` // key_hash is contract_code_hash or contract_id
 	LiveUntilLedgerSeq uint32    `json:"live_until_ledger_seq"`
 	LastModifiedLedger uint32    `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
 	Deleted            bool      `json:"deleted"`
-	ClosedAt           time.Time `json:"closed_at"`
+	ClosedAt           time.Time `json:"closed_at" bigquery:"partition"`
 	LedgerSequence     uint32    `json:"ledger_sequence"`
 }
 
+// EvictedEntryOutput is a representation of an entry evicted from the bucket list once its ttl expired
+type EvictedEntryOutput struct {
+	LedgerKey       string    `json:"ledger_key"`
+	LedgerKeyHash   string    `json:"ledger_key_hash"`
+	LedgerEntryType string    `json:"ledger_entry_type"`
+	Durability      string    `json:"durability"`
+	ContractId      string    `json:"contract_id" bigquery:"cluster"`
+	ClosedAt        time.Time `json:"closed_at" bigquery:"partition"`
+	LedgerSequence  uint32    `json:"ledger_sequence"`
+}
+
+// TransactionFootprintOutput is a ledger key declared in the footprint of a Soroban transaction
+type TransactionFootprintOutput struct {
+	TransactionHash  string      `json:"transaction_hash"`
+	LedgerSequence   uint32      `json:"ledger_sequence"`
+	TransactionID    int64       `json:"transaction_id"`
+	ClosedAt         time.Time   `json:"closed_at" bigquery:"partition"`
+	Successful       bool        `json:"successful"`
+	ContractId       string      `json:"contract_id" bigquery:"cluster"`
+	LedgerEntryType  string      `json:"ledger_entry_type"`
+	LedgerKey        string      `json:"ledger_key"`
+	LedgerKeyHash    string      `json:"ledger_key_hash" bigquery:"cluster"`
+	LedgerKeyDecoded interface{} `json:"ledger_key_decoded"`
+	ReadOnly         bool        `json:"read_only"`
+}
+
 // DiagnosticEventOutput is a representation of soroban diagnostic events that currently are not stored in a BQ table
 type DiagnosticEventOutput struct {
-	TransactionHash          string    `json:"transaction_hash"`
-	LedgerSequence           uint32    `json:"ledger_sequence"`
-	TransactionID            int64     `json:"transaction_id"`
-	ClosedAt                 time.Time `json:"closed_at"`
-	InSuccessfulContractCall bool      `json:"in_successful_contract_call"`
-	ExtV                     int32     `json:"ext_v"`
-	ContractId               string    `json:"contract_id"`
-	Type                     string    `json:"type"`
-	BodyV                    int32     `json:"body_v"`
-	Body                     string    `json:"body"`
+	TransactionHash          string        `json:"transaction_hash"`
+	LedgerSequence           uint32        `json:"ledger_sequence"`
+	TransactionID            int64         `json:"transaction_id"`
+	ClosedAt                 time.Time     `json:"closed_at" bigquery:"partition"`
+	InSuccessfulContractCall bool          `json:"in_successful_contract_call"`
+	ExtV                     int32         `json:"ext_v"`
+	ContractId               string        `json:"contract_id" bigquery:"cluster"`
+	Type                     string        `json:"type"`
+	BodyV                    int32         `json:"body_v"`
+	Body                     string        `json:"body"`
+	Topics                   []interface{} `json:"topics"`
+	Topic1                   string        `json:"topic_1"`
+	Topic2                   string        `json:"topic_2"`
+	Topic3                   string        `json:"topic_3"`
+	Topic4                   string        `json:"topic_4"`
+	Data                     interface{}   `json:"data"`
+	SacEventType             string        `json:"sac_event_type"`
+	SacFrom                  string        `json:"sac_from"`
+	SacTo                    string        `json:"sac_to"`
+	SacAmount                string        `json:"sac_amount"`
+	SacAsset                 string        `json:"sac_asset"`
+	SacAuthorized            null.Bool     `json:"sac_authorized"`
 }
@@ -0,0 +1,112 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SchemaColumn is a column of an exported table as recorded in a schema snapshot
+type SchemaColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SchemaChange is a difference between the schemas of an exported table in two versions. Breaking changes are the ones
+// that fail loads into tables created for the older version: removed tables and columns, including renamed columns,
+// and columns whose type changed.
+type SchemaChange struct {
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Change   string `json:"change"`
+	Breaking bool   `json:"breaking"`
+}
+
+func (c SchemaChange) String() string {
+	name := c.Table
+	if c.Column != "" {
+		name += "." + c.Column
+	}
+	return fmt.Sprintf("%s: %s", name, c.Change)
+}
+
+// columnKindNames are the names of the column kinds in schema snapshots
+var columnKindNames = map[columnKind]string{
+	columnString:          "string",
+	columnInteger:         "integer",
+	columnUnsignedInteger: "unsigned_integer",
+	columnFloat:           "float",
+	columnBoolean:         "boolean",
+	columnTimestamp:       "timestamp",
+	columnJSON:            "json",
+}
+
+// SchemaSnapshot returns the columns of every exported table, in the order they are written
+func SchemaSnapshot() map[string][]SchemaColumn {
+	snapshot := map[string][]SchemaColumn{}
+	for table, output := range OutputSchemas {
+		columns := tableColumns(output)
+		if LedgerEntryChangeTables[table] {
+			columns = append(columns, tableColumns(LedgerEntryChangeCause{})...)
+		}
+
+		schema := make([]SchemaColumn, 0, len(columns))
+		for _, col := range columns {
+			schema = append(schema, SchemaColumn{Name: col.name, Type: columnKindNames[col.kind]})
+		}
+		snapshot[table] = schema
+	}
+
+	return snapshot
+}
+
+// DiffSchemas returns the changes from the released snapshot to the current one, sorted by table
+func DiffSchemas(released, current map[string][]SchemaColumn) []SchemaChange {
+	tables := map[string]bool{}
+	for table := range released {
+		tables[table] = true
+	}
+	for table := range current {
+		tables[table] = true
+	}
+	names := make([]string, 0, len(tables))
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+
+	changes := []SchemaChange{}
+	for _, table := range names {
+		releasedColumns, wasReleased := released[table]
+		currentColumns, isCurrent := current[table]
+		switch {
+		case !isCurrent:
+			changes = append(changes, SchemaChange{Table: table, Change: "table removed", Breaking: true})
+			continue
+		case !wasReleased:
+			changes = append(changes, SchemaChange{Table: table, Change: "table added"})
+			continue
+		}
+
+		currentTypes := map[string]string{}
+		for _, col := range currentColumns {
+			currentTypes[col.Name] = col.Type
+		}
+		releasedTypes := map[string]string{}
+		for _, col := range releasedColumns {
+			releasedTypes[col.Name] = col.Type
+			currentType, ok := currentTypes[col.Name]
+			if !ok {
+				changes = append(changes, SchemaChange{Table: table, Column: col.Name, Change: "column removed or renamed", Breaking: true})
+			} else if currentType != col.Type {
+				changes = append(changes, SchemaChange{Table: table, Column: col.Name, Change: fmt.Sprintf("type changed from %s to %s", col.Type, currentType), Breaking: true})
+			}
+		}
+		for _, col := range currentColumns {
+			if _, ok := releasedTypes[col.Name]; !ok {
+				changes = append(changes, SchemaChange{Table: table, Column: col.Name, Change: "column added"})
+			}
+		}
+	}
+
+	return changes
+}
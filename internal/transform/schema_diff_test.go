@@ -0,0 +1,39 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaSnapshot(t *testing.T) {
+	snapshot := SchemaSnapshot()
+	assert.Len(t, snapshot, len(OutputSchemas))
+	assert.Contains(t, snapshot["ttl"], SchemaColumn{Name: "live_until_ledger_seq", Type: "integer"})
+	assert.Contains(t, snapshot["ttl"], SchemaColumn{Name: "closed_at", Type: "timestamp"})
+	assert.Contains(t, snapshot["operations"], SchemaColumn{Name: "details", Type: "json"})
+}
+
+func TestDiffSchemas(t *testing.T) {
+	released := map[string][]SchemaColumn{
+		"ledgers": {{Name: "sequence", Type: "integer"}, {Name: "ledger_hash", Type: "string"}},
+		"offers":  {{Name: "price", Type: "float"}, {Name: "seller", Type: "string"}},
+		"orders":  {{Name: "id", Type: "integer"}},
+	}
+	current := map[string][]SchemaColumn{
+		"ledgers": {{Name: "sequence", Type: "integer"}, {Name: "ledger_hash", Type: "string"}, {Name: "tx_count", Type: "integer"}},
+		"offers":  {{Name: "price", Type: "string"}, {Name: "seller_id", Type: "string"}},
+		"trades":  {{Name: "id", Type: "integer"}},
+	}
+
+	assert.Equal(t, []SchemaChange{
+		{Table: "ledgers", Column: "tx_count", Change: "column added"},
+		{Table: "offers", Column: "price", Change: "type changed from float to string", Breaking: true},
+		{Table: "offers", Column: "seller", Change: "column removed or renamed", Breaking: true},
+		{Table: "offers", Column: "seller_id", Change: "column added"},
+		{Table: "orders", Change: "table removed", Breaking: true},
+		{Table: "trades", Change: "table added"},
+	}, DiffSchemas(released, current))
+
+	assert.Empty(t, DiffSchemas(SchemaSnapshot(), SchemaSnapshot()))
+}
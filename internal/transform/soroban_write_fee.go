@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// SorobanWriteFeeConfig holds the settings of the ContractLedgerCostV0 config setting that the write fee of Soroban
+// transactions is computed from
+type SorobanWriteFeeConfig struct {
+	BucketListTargetSizeBytes      int64  `json:"bucket_list_target_size_bytes"`
+	WriteFee1KbBucketListLow       int64  `json:"write_fee_1kb_bucket_list_low"`
+	WriteFee1KbBucketListHigh      int64  `json:"write_fee_1kb_bucket_list_high"`
+	BucketListWriteFeeGrowthFactor uint32 `json:"bucket_list_write_fee_growth_factor"`
+}
+
+// SorobanWriteFeeConfigUpgrade returns the write fee settings set by the upgrades of the ledger, if any. Upgrades are
+// applied once the transactions of the ledger are, so the settings are active from the next ledger on.
+func SorobanWriteFeeConfigUpgrade(lcm xdr.LedgerCloseMeta) (SorobanWriteFeeConfig, bool) {
+	var upgrades []xdr.UpgradeEntryMeta
+	switch lcm.V {
+	case 0:
+		upgrades = lcm.MustV0().UpgradesProcessing
+	case 1:
+		upgrades = lcm.MustV1().UpgradesProcessing
+	}
+
+	var (
+		config   SorobanWriteFeeConfig
+		upgraded bool
+	)
+	for _, upgrade := range upgrades {
+		for _, change := range ingest.GetChangesFromLedgerEntryChanges(upgrade.Changes) {
+			if change.Type != xdr.LedgerEntryTypeConfigSetting || change.Post == nil {
+				continue
+			}
+			ledgerCost, ok := change.Post.Data.MustConfigSetting().GetContractLedgerCost()
+			if !ok {
+				continue
+			}
+			config = SorobanWriteFeeConfig{
+				BucketListTargetSizeBytes:      int64(ledgerCost.BucketListTargetSizeBytes),
+				WriteFee1KbBucketListLow:       int64(ledgerCost.WriteFee1KbBucketListLow),
+				WriteFee1KbBucketListHigh:      int64(ledgerCost.WriteFee1KbBucketListHigh),
+				BucketListWriteFeeGrowthFactor: uint32(ledgerCost.BucketListWriteFeeGrowthFactor),
+			}
+			upgraded = true
+		}
+	}
+
+	return config, upgraded
+}
+
+// JoinSorobanWriteFeeConfig fills the write fee settings columns of the ledger with config, leaving them null when
+// the settings are not known
+func JoinSorobanWriteFeeConfig(ledger LedgerOutput, config *SorobanWriteFeeConfig) LedgerOutput {
+	if config == nil {
+		return ledger
+	}
+
+	ledger.SorobanBucketListTargetSizeBytes = null.IntFrom(config.BucketListTargetSizeBytes)
+	ledger.SorobanWriteFee1KbBucketListLow = null.IntFrom(config.WriteFee1KbBucketListLow)
+	ledger.SorobanWriteFee1KbBucketListHigh = null.IntFrom(config.WriteFee1KbBucketListHigh)
+	ledger.SorobanBucketListWriteFeeGrowthFactor = null.IntFrom(int64(config.BucketListWriteFeeGrowthFactor))
+	return ledger
+}
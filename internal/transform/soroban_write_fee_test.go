@@ -0,0 +1,80 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeLedgerCostEntry(writeFeeLow xdr.Int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeConfigSetting,
+			ConfigSetting: &xdr.ConfigSettingEntry{
+				ConfigSettingId: xdr.ConfigSettingIdConfigSettingContractLedgerCostV0,
+				ContractLedgerCost: &xdr.ConfigSettingContractLedgerCostV0{
+					BucketListTargetSizeBytes:      14000000000,
+					WriteFee1KbBucketListLow:       writeFeeLow,
+					WriteFee1KbBucketListHigh:      100000,
+					BucketListWriteFeeGrowthFactor: 1000,
+				},
+			},
+		},
+	}
+}
+
+func TestSorobanWriteFeeConfigUpgrade(t *testing.T) {
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			UpgradesProcessing: []xdr.UpgradeEntryMeta{
+				{
+					Changes: xdr.LedgerEntryChanges{
+						{
+							Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+							State: makeLedgerCostEntry(1000),
+						},
+						{
+							Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+							Updated: makeLedgerCostEntry(2000),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	config, ok := SorobanWriteFeeConfigUpgrade(lcm)
+	assert.True(t, ok)
+	assert.Equal(t, SorobanWriteFeeConfig{
+		BucketListTargetSizeBytes:      14000000000,
+		WriteFee1KbBucketListLow:       2000,
+		WriteFee1KbBucketListHigh:      100000,
+		BucketListWriteFeeGrowthFactor: 1000,
+	}, config)
+
+	lcm.V1.UpgradesProcessing = nil
+	_, ok = SorobanWriteFeeConfigUpgrade(lcm)
+	assert.False(t, ok)
+}
+
+func TestJoinSorobanWriteFeeConfig(t *testing.T) {
+	ledger := LedgerOutput{Sequence: 10}
+	assert.Equal(t, ledger, JoinSorobanWriteFeeConfig(ledger, nil))
+
+	joined := JoinSorobanWriteFeeConfig(ledger, &SorobanWriteFeeConfig{
+		BucketListTargetSizeBytes:      14000000000,
+		WriteFee1KbBucketListLow:       2000,
+		WriteFee1KbBucketListHigh:      100000,
+		BucketListWriteFeeGrowthFactor: 1000,
+	})
+	assert.Equal(t, LedgerOutput{
+		Sequence:                              10,
+		SorobanBucketListTargetSizeBytes:      null.IntFrom(14000000000),
+		SorobanWriteFee1KbBucketListLow:       null.IntFrom(2000),
+		SorobanWriteFee1KbBucketListHigh:      null.IntFrom(100000),
+		SorobanBucketListWriteFeeGrowthFactor: null.IntFrom(1000),
+	}, joined)
+}
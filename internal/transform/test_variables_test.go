@@ -149,6 +149,7 @@ var usdtAssetPath = Path{
 	AssetType:   "credit_alphanum4",
 	AssetCode:   "USDT",
 	AssetIssuer: testAccount4Address,
+	AssetID:     -8205667356306085451,
 }
 
 var ethAsset = xdr.Asset{
@@ -190,6 +191,7 @@ var testClaimant = xdr.Claimant{
 }
 
 var testClaimantDetails = Claimant{
-	Destination: testAccount1Address,
-	Predicate:   xdr.ClaimPredicate{},
+	Destination:   testAccount1Address,
+	Predicate:     xdr.ClaimPredicate{},
+	PredicateJSON: "unconditional",
 }
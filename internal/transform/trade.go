@@ -17,7 +17,9 @@ import (
 )
 
 // TransformTrade converts a relevant operation from the history archive ingestion system into a form suitable for BigQuery
-func TransformTrade(operationIndex int32, operationID int64, transaction ingest.LedgerTransaction, ledgerCloseTime time.Time) ([]TradeOutput, error) {
+func TransformTrade(operationIndex int32, operationID int64, transaction ingest.LedgerTransaction, ledgerCloseTime time.Time) (_ []TradeOutput, err error) {
+	defer recoverPanic(&err)
+
 	operationResults, ok := transaction.Result.OperationResults()
 	if !ok {
 		return []TradeOutput{}, fmt.Errorf("could not get any results from this transaction")
@@ -139,6 +141,7 @@ func TransformTrade(operationIndex int32, operationID int64, transaction ingest.
 			BuyingAmount:           utils.ConvertStroopValueToReal(xdr.Int64(outputBuyingAmount)),
 			PriceN:                 outputPriceN,
 			PriceD:                 outputPriceD,
+			Price:                  utils.PriceToDecimalString(outputPriceN, outputPriceD),
 			SellingOfferID:         outputSellingOfferID,
 			BuyingOfferID:          outputBuyingOfferID,
 			SellingLiquidityPoolID: liquidityPoolID,
@@ -272,7 +275,7 @@ func findTradeSellPrice(t ingest.LedgerTransaction, operationIndex int32, trade
 }
 
 func findLatestOperationChange(t ingest.LedgerTransaction, operationIndex int32, key xdr.LedgerKey) (ingest.Change, error) {
-	changes, err := t.GetOperationChanges(uint32(operationIndex))
+	changes, err := operationChanges(t, uint32(operationIndex))
 	if err != nil {
 		return ingest.Change{}, errors.Wrap(err, "could not determine changes for operation")
 	}
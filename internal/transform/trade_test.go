@@ -735,6 +735,7 @@ func makeTradeTestOutput() [][]TradeOutput {
 		BuyingAmount:          12634 * 0.0000001,
 		PriceN:                12634,
 		PriceD:                13300347,
+		Price:                 "0.000949900028924057",
 		SellingOfferID:        null.IntFrom(97684906),
 		BuyingOfferID:         null.IntFrom(4611686018427388005),
 		HistoryOperationID:    101,
@@ -757,6 +758,7 @@ func makeTradeTestOutput() [][]TradeOutput {
 		BuyingAmount:          20 * 0.0000001,
 		PriceN:                25,
 		PriceD:                1,
+		Price:                 "25",
 		SellingOfferID:        null.IntFrom(86106895),
 		BuyingOfferID:         null.IntFrom(4611686018427388005),
 		HistoryOperationID:    101,
@@ -779,6 +781,7 @@ func makeTradeTestOutput() [][]TradeOutput {
 		BuyingAmount:           456 * 0.0000001,
 		PriceN:                 456,
 		PriceD:                 123,
+		Price:                  "3.707317073170731707",
 		BuyingOfferID:          null.IntFrom(4611686018427388005),
 		SellingLiquidityPoolID: null.StringFrom("0405060000000000000000000000000000000000000000000000000000000000"),
 		LiquidityPoolFee:       null.IntFrom(30),
@@ -804,6 +807,7 @@ func makeTradeTestOutput() [][]TradeOutput {
 		BuyingAmount:           1 * 0.0000001,
 		PriceN:                 1,
 		PriceD:                 1,
+		Price:                  "1",
 		BuyingOfferID:          null.IntFrom(4611686018427388005),
 		SellingLiquidityPoolID: null.StringFrom("0102030405060000000000000000000000000000000000000000000000000000"),
 		LiquidityPoolFee:       null.IntFrom(30),
@@ -825,6 +829,7 @@ func makeTradeTestOutput() [][]TradeOutput {
 	twoPriceIsAmount := offerTwoOutput
 	twoPriceIsAmount.PriceN = int64(twoPriceIsAmount.BuyingAmount * 10000000)
 	twoPriceIsAmount.PriceD = int64(twoPriceIsAmount.SellingAmount * 10000000)
+	twoPriceIsAmount.Price = "0.04"
 	twoPriceIsAmount.SellerIsExact = null.BoolFrom(true)
 
 	offerTwoOutputSecondPlace := twoPriceIsAmount
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/guregu/null"
 	"github.com/lib/pq"
@@ -16,7 +17,9 @@ import (
 )
 
 // TransformTransaction converts a transaction from the history archive ingestion system into a form suitable for BigQuery
-func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (TransactionOutput, error) {
+func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (_ TransactionOutput, err error) {
+	defer recoverPanic(&err)
+
 	ledgerHeader := lhe.Header
 	outputTransactionHash := utils.HashToHexString(transaction.Result.TransactionHash)
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
@@ -88,6 +91,8 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 	outputMemoType := memoObject.Type.String()
 	timeBound := transaction.Envelope.TimeBounds()
 	outputTimeBounds := ""
+	outputMinTime := null.Time{}
+	outputMaxTime := null.Time{}
 	if timeBound != nil {
 		if timeBound.MaxTime < timeBound.MinTime && timeBound.MaxTime != 0 {
 
@@ -101,12 +106,23 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 			outputTimeBounds = fmt.Sprintf("[%d,%d)", timeBound.MinTime, timeBound.MaxTime)
 		}
 
+		// A bound of 0 means the transaction is not bounded on that side, so it is left null. Bounds too far in the future
+		// to be written as a timestamp are left null too, their raw value is kept in time_bounds.
+		outputMinTime = timeBoundTimestamp(timeBound.MinTime)
+		outputMaxTime = timeBoundTimestamp(timeBound.MaxTime)
 	}
 
 	ledgerBound := transaction.Envelope.LedgerBounds()
 	outputLedgerBound := ""
+	outputMinLedger := null.Int{}
+	outputMaxLedger := null.Int{}
 	if ledgerBound != nil {
 		outputLedgerBound = fmt.Sprintf("[%d,%d)", int64(ledgerBound.MinLedger), int64(ledgerBound.MaxLedger))
+		outputMinLedger = null.IntFrom(int64(ledgerBound.MinLedger))
+		// A max ledger of 0 means the transaction is valid for all future ledgers
+		if ledgerBound.MaxLedger != 0 {
+			outputMaxLedger = null.IntFrom(int64(ledgerBound.MaxLedger))
+		}
 	}
 
 	minSequenceNumber := transaction.Envelope.MinSeqNum()
@@ -159,13 +175,21 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		outputSorobanResourcesWriteBytes = uint32(sorobanData.Resources.WriteBytes)
 		outputInclusionFeeBid = int64(transaction.Envelope.Fee()) - outputResourceFee
 
-		accountBalanceStart, accountBalanceEnd := getAccountBalanceFromLedgerEntryChanges(transaction.FeeChanges, sourceAccount.Address())
+		// The fee account of a fee bump transaction is charged the fees and receives the refund instead of the inner source account
+		feeSourceAccount := sourceAccount.ToAccountId()
+		if transaction.Envelope.IsFeeBump() {
+			feeBumpAccount := transaction.Envelope.FeeBumpAccount()
+			feeSourceAccount = feeBumpAccount.ToAccountId()
+			outputInclusionFeeBid = int64(transaction.Envelope.FeeBumpFee()) - outputResourceFee
+		}
+
+		accountBalanceStart, accountBalanceEnd := getAccountBalanceFromLedgerEntryChanges(transaction.FeeChanges, feeSourceAccount.Address())
 		initialFeeCharged := accountBalanceStart - accountBalanceEnd
 		outputInclusionFeeCharged = initialFeeCharged - outputResourceFee
 
 		meta, ok := transaction.UnsafeMeta.GetV3()
 		if ok {
-			accountBalanceStart, accountBalanceEnd := getAccountBalanceFromLedgerEntryChanges(meta.TxChangesAfter, sourceAccount.Address())
+			accountBalanceStart, accountBalanceEnd := getAccountBalanceFromLedgerEntryChanges(meta.TxChangesAfter, feeSourceAccount.Address())
 			outputResourceFeeRefund = accountBalanceEnd - accountBalanceStart
 			extV1, ok := meta.SorobanMeta.Ext.GetV1()
 			if ok {
@@ -187,6 +211,7 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 	outputTxResultCode := transaction.Result.Result.Result.Code.String()
 
 	outputSuccessful := transaction.Result.Successful()
+	outputSorobanCoreMetrics := sorobanCoreMetrics(transaction)
 	transformedTransaction := TransactionOutput{
 		TransactionHash:                      outputTransactionHash,
 		LedgerSequence:                       outputLedgerSequence,
@@ -206,6 +231,10 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		TimeBounds:                           outputTimeBounds,
 		Successful:                           outputSuccessful,
 		LedgerBounds:                         outputLedgerBound,
+		MinTime:                              outputMinTime,
+		MaxTime:                              outputMaxTime,
+		MinLedger:                            outputMinLedger,
+		MaxLedger:                            outputMaxLedger,
 		MinAccountSequence:                   outputMinSequence,
 		MinAccountSequenceAge:                outputMinSequenceAge,
 		MinAccountSequenceLedgerGap:          outputMinSequenceLedgerGap,
@@ -222,6 +251,10 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		TotalNonRefundableResourceFeeCharged: outputTotalNonRefundableResourceFeeCharged,
 		TotalRefundableResourceFeeCharged:    outputTotalRefundableResourceFeeCharged,
 		RentFeeCharged:                       outputRentFeeCharged,
+		SorobanCpuInstructions:               coreMetric(outputSorobanCoreMetrics, "cpu_insn"),
+		SorobanMemoryBytes:                   coreMetric(outputSorobanCoreMetrics, "mem_byte"),
+		SorobanInvokeTimeNsecs:               coreMetric(outputSorobanCoreMetrics, "invoke_time_nsecs"),
+		SorobanCoreMetrics:                   outputSorobanCoreMetrics,
 	}
 
 	// Add Muxed Account Details, if exists
@@ -231,26 +264,80 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 			return TransactionOutput{}, err
 		}
 		transformedTransaction.AccountMuxed = muxedAddress
-
+		transformedTransaction.AccountMuxedID = uint64(sourceAccount.Med25519.Id)
 	}
 
 	// Add Fee Bump Details, if exists
 	if transaction.Envelope.IsFeeBump() {
 		feeBumpAccount := transaction.Envelope.FeeBumpAccount()
 		feeAccount := feeBumpAccount.ToAccountId()
-		if sourceAccount.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
-			feeAccountMuxed := feeAccount.Address()
+		// The fee source can be muxed independently of the inner source, so that fees are attributed to its sub-account
+		if feeBumpAccount.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+			feeAccountMuxed, err := feeBumpAccount.GetAddress()
+			if err != nil {
+				return TransactionOutput{}, err
+			}
 			transformedTransaction.FeeAccountMuxed = feeAccountMuxed
+			transformedTransaction.FeeAccountMuxedID = uint64(feeBumpAccount.Med25519.Id)
 		}
 		transformedTransaction.FeeAccount = feeAccount.Address()
 		innerHash := transaction.Result.InnerHash()
 		transformedTransaction.InnerTransactionHash = hex.EncodeToString(innerHash[:])
 		transformedTransaction.NewMaxFee = uint32(transaction.Envelope.FeeBumpFee())
+		// FeeCharged is paid by the fee account; the inner result records the fee the inner transaction would have been charged
+		if innerResultPair, ok := transaction.Result.Result.Result.GetInnerResultPair(); ok {
+			transformedTransaction.InnerFeeCharged = int64(innerResultPair.Result.FeeCharged)
+		}
 	}
 
 	return transformedTransaction, nil
 }
 
+// sorobanCoreMetrics returns the values of the core_metrics diagnostic events of a Soroban transaction, keyed by metric
+// name, e.g. cpu_insn or mem_byte. Core only emits them when it runs with diagnostic events enabled, so transactions
+// closed without them have no metrics.
+func sorobanCoreMetrics(transaction ingest.LedgerTransaction) map[string]int64 {
+	diagnosticEvents, err := transaction.GetDiagnosticEvents()
+	if err != nil {
+		return nil
+	}
+
+	var metrics map[string]int64
+	for _, diagnosticEvent := range diagnosticEvents {
+		body, ok := diagnosticEvent.Event.Body.GetV0()
+		if !ok || diagnosticEvent.Event.Type != xdr.ContractEventTypeDiagnostic || len(body.Topics) != 2 {
+			continue
+		}
+		if topic, ok := body.Topics[0].GetSym(); !ok || topic != "core_metrics" {
+			continue
+		}
+		name, ok := body.Topics[1].GetSym()
+		if !ok {
+			continue
+		}
+		value, ok := body.Data.GetU64()
+		if !ok {
+			continue
+		}
+
+		if metrics == nil {
+			metrics = map[string]int64{}
+		}
+		metrics[string(name)] = int64(value)
+	}
+
+	return metrics
+}
+
+// coreMetric returns the core metric with the given name, or null when the transaction has no such metric
+func coreMetric(metrics map[string]int64, name string) null.Int {
+	value, ok := metrics[name]
+	if !ok {
+		return null.Int{}
+	}
+	return null.IntFrom(value)
+}
+
 func getAccountBalanceFromLedgerEntryChanges(changes xdr.LedgerEntryChanges, sourceAccountAddress string) (int64, int64) {
 	var accountBalanceStart int64
 	var accountBalanceEnd int64
@@ -293,3 +380,15 @@ func formatSigners(s []xdr.SignerKey) pq.StringArray {
 
 	return signers
 }
+
+// maxTimeBoundSeconds is the last second of the year 9999, the latest time that can be written as an RFC 3339 timestamp
+const maxTimeBoundSeconds = 253402300799
+
+// timeBoundTimestamp returns the timestamp of a time bound, which is null when the transaction is not bounded on that
+// side or when the bound, like the max uint64 some clients set instead of leaving it unbounded, is past the year 9999
+func timeBoundTimestamp(bound xdr.TimePoint) null.Time {
+	if bound == 0 || uint64(bound) > maxTimeBoundSeconds {
+		return null.Time{}
+	}
+	return null.TimeFrom(time.Unix(int64(bound), 0).UTC())
+}
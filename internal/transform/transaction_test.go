@@ -1,7 +1,9 @@
 package transform
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -99,6 +101,7 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			MemoType:                     "MemoTypeMemoText",
 			Memo:                         "HL5aCgozQHIW7sSc5XdcfmR",
 			TimeBounds:                   "[0,1594272628)",
+			MaxTime:                      null.TimeFrom(time.Date(2020, time.July, 9, 5, 30, 28, 0, time.UTC)),
 			Successful:                   false,
 			ClosedAt:                     time.Date(2020, time.July, 9, 5, 28, 42, 0, time.UTC),
 			ResourceFee:                  0,
@@ -124,10 +127,12 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			MemoType:                     "MemoTypeMemoText",
 			Memo:                         "HL5aCgozQHIW7sSc5XdcfmR",
 			TimeBounds:                   "[0,1594272628)",
+			MaxTime:                      null.TimeFrom(time.Date(2020, time.July, 9, 5, 30, 28, 0, time.UTC)),
 			Successful:                   true,
 			InnerTransactionHash:         "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
 			FeeAccount:                   testAccount3Address,
 			NewMaxFee:                    7200,
+			InnerFeeCharged:              100,
 			ClosedAt:                     time.Date(2020, time.July, 9, 5, 28, 42, 0, time.UTC),
 			ResourceFee:                  0,
 			SorobanResourcesInstructions: 0,
@@ -152,8 +157,11 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			MemoType:                     "MemoTypeMemoText",
 			Memo:                         "HL5aCgozQHIW7sSc5XdcfmR",
 			TimeBounds:                   "[0,1594272628)",
+			MaxTime:                      null.TimeFrom(time.Date(2020, time.July, 9, 5, 30, 28, 0, time.UTC)),
 			Successful:                   false,
 			LedgerBounds:                 "[5,10)",
+			MinLedger:                    null.IntFrom(5),
+			MaxLedger:                    null.IntFrom(10),
 			ExtraSigners:                 pq.StringArray{"GABQEAIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAB7QL"},
 			MinAccountSequenceAge:        null.IntFrom(0),
 			MinAccountSequenceLedgerGap:  null.IntFrom(0),
@@ -390,3 +398,247 @@ func makeTransactionTestInput() (transaction []ingest.LedgerTransaction, history
 	}
 	return
 }
+
+func TestTransformTransactionFarFutureTimeBounds(t *testing.T) {
+	transactions, ledgerHeaders, err := makeTransactionTestInput()
+	assert.NoError(t, err)
+	transaction := transactions[0]
+	envelope := *transaction.Envelope.V1
+	envelope.Tx.Cond = xdr.Preconditions{
+		Type:       xdr.PreconditionTypePrecondTime,
+		TimeBounds: &xdr.TimeBounds{MinTime: 1594272628, MaxTime: math.MaxUint64},
+	}
+	transaction.Envelope.V1 = &envelope
+
+	output, err := TransformTransaction(transaction, ledgerHeaders[0])
+	assert.NoError(t, err)
+	assert.Equal(t, null.TimeFrom(time.Date(2020, time.July, 9, 5, 30, 28, 0, time.UTC)), output.MinTime)
+	assert.Equal(t, null.Time{}, output.MaxTime)
+	assert.Equal(t, "[1594272628,18446744073709551615)", output.TimeBounds)
+
+	_, err = json.Marshal(output)
+	assert.NoError(t, err)
+}
+
+func TestSorobanCoreMetrics(t *testing.T) {
+	coreMetricEvent := func(name string, value uint64) xdr.DiagnosticEvent {
+		metric := xdr.ScSymbol(name)
+		topic := xdr.ScSymbol("core_metrics")
+		data := xdr.Uint64(value)
+		return xdr.DiagnosticEvent{
+			InSuccessfulContractCall: true,
+			Event: xdr.ContractEvent{
+				Type: xdr.ContractEventTypeDiagnostic,
+				Body: xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{
+					Topics: []xdr.ScVal{{Type: xdr.ScValTypeScvSymbol, Sym: &topic}, {Type: xdr.ScValTypeScvSymbol, Sym: &metric}},
+					Data:   xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &data},
+				}},
+			},
+		}
+	}
+	transferTopic := xdr.ScSymbol("transfer")
+	transaction := ingest.LedgerTransaction{
+		UnsafeMeta: xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+			SorobanMeta: &xdr.SorobanTransactionMeta{DiagnosticEvents: []xdr.DiagnosticEvent{
+				coreMetricEvent("cpu_insn", 1500000),
+				coreMetricEvent("mem_byte", 420000),
+				coreMetricEvent("read_entry", 3),
+				{Event: xdr.ContractEvent{
+					Type: xdr.ContractEventTypeContract,
+					Body: xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{
+						Topics: []xdr.ScVal{{Type: xdr.ScValTypeScvSymbol, Sym: &transferTopic}},
+					}},
+				}},
+			}},
+		}},
+	}
+
+	metrics := sorobanCoreMetrics(transaction)
+	assert.Equal(t, map[string]int64{"cpu_insn": 1500000, "mem_byte": 420000, "read_entry": 3}, metrics)
+	assert.Equal(t, null.IntFrom(1500000), coreMetric(metrics, "cpu_insn"))
+	assert.Equal(t, null.Int{}, coreMetric(metrics, "invoke_time_nsecs"))
+
+	assert.Nil(t, sorobanCoreMetrics(ingest.LedgerTransaction{UnsafeMeta: xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{}}}))
+}
+
+func TestTransformFeeBumpMuxedAccounts(t *testing.T) {
+	type inputStruct struct {
+		transaction   ingest.LedgerTransaction
+		historyHeader xdr.LedgerHeaderHistoryEntry
+	}
+	type transformTest struct {
+		input      inputStruct
+		wantOutput feeBumpMuxedAccounts
+	}
+
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeFeeBumpMuxedAccountsTestInput()
+	assert.NoError(t, err)
+	hardCodedOutput := makeFeeBumpMuxedAccountsTestOutput()
+
+	tests := []transformTest{}
+	for i := range hardCodedTransaction {
+		tests = append(tests, transformTest{
+			input:      inputStruct{hardCodedTransaction[i], hardCodedLedgerHeader[i]},
+			wantOutput: hardCodedOutput[i],
+		})
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError := TransformTransaction(test.input.transaction, test.input.historyHeader)
+		assert.NoError(t, actualError)
+		assert.Equal(t, test.wantOutput, feeBumpMuxedAccounts{
+			Account:           actualOutput.Account,
+			AccountMuxed:      actualOutput.AccountMuxed,
+			AccountMuxedID:    actualOutput.AccountMuxedID,
+			FeeAccount:        actualOutput.FeeAccount,
+			FeeAccountMuxed:   actualOutput.FeeAccountMuxed,
+			FeeAccountMuxedID: actualOutput.FeeAccountMuxedID,
+		})
+	}
+}
+
+// feeBumpMuxedAccounts are the account columns of the output of a fee bump transaction
+type feeBumpMuxedAccounts struct {
+	Account           string
+	AccountMuxed      string
+	AccountMuxedID    uint64
+	FeeAccount        string
+	FeeAccountMuxed   string
+	FeeAccountMuxedID uint64
+}
+
+func makeFeeBumpMuxedAccountsTestOutput() []feeBumpMuxedAccounts {
+	return []feeBumpMuxedAccounts{
+		{
+			Account:           testAccount1Address,
+			AccountMuxed:      "MCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XQAAAAAAAAAAAFLAGA",
+			AccountMuxedID:    42,
+			FeeAccount:        testAccount3Address,
+			FeeAccountMuxed:   "MBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXEAAAAAAAAAAAA6FZ4",
+			FeeAccountMuxedID: 7,
+		},
+		// a muxed inner source does not make an unmuxed fee source muxed
+		{
+			Account:        testAccount1Address,
+			AccountMuxed:   "MCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XQAAAAAAAAAAAFLAGA",
+			AccountMuxedID: 42,
+			FeeAccount:     testAccount3Address,
+		},
+	}
+}
+
+func makeFeeBumpMuxedAccountsTestInput() (transaction []ingest.LedgerTransaction, historyHeader []xdr.LedgerHeaderHistoryEntry, err error) {
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeTransactionTestInput()
+	if err != nil {
+		return
+	}
+
+	// the fee bump transaction of makeTransactionTestInput, with a muxed inner source that is also the source of its
+	// operation
+	feeBump := hardCodedTransaction[1]
+	payment := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: testAccount2,
+				Asset:       nativeAsset,
+				Amount:      100,
+			},
+		},
+	}
+	innerSource := xdr.MuxedAccount{
+		Type:     xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{Id: 42, Ed25519: *testAccount1ID.Ed25519},
+	}
+	feeSources := []xdr.MuxedAccount{
+		{
+			Type:     xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+			Med25519: &xdr.MuxedAccountMed25519{Id: 7, Ed25519: *testAccount3ID.Ed25519},
+		},
+		testAccount3,
+	}
+	for _, feeSource := range feeSources {
+		innerEnvelope := *feeBump.Envelope.FeeBump.Tx.InnerTx.V1
+		innerEnvelope.Tx.SourceAccount = innerSource
+		innerEnvelope.Tx.Operations = []xdr.Operation{payment}
+		envelope := *feeBump.Envelope.FeeBump
+		envelope.Tx.FeeSource = feeSource
+		envelope.Tx.InnerTx.V1 = &innerEnvelope
+
+		muxedFeeBump := feeBump
+		muxedFeeBump.Envelope.FeeBump = &envelope
+		transaction = append(transaction, muxedFeeBump)
+		historyHeader = append(historyHeader, hardCodedLedgerHeader[1])
+	}
+	return
+}
+
+// sorobanFeeBumpFees are the fee columns of the output of a Soroban fee bump transaction
+type sorobanFeeBumpFees struct {
+	FeeCharged          int64
+	NewMaxFee           uint32
+	InnerFeeCharged     int64
+	InclusionFeeBid     int64
+	InclusionFeeCharged int64
+	ResourceFeeRefund   int64
+}
+
+func TestTransformSorobanFeeBumpFees(t *testing.T) {
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeTransactionTestInput()
+	assert.NoError(t, err)
+
+	accountChange := func(changeType xdr.LedgerEntryChangeType, account xdr.AccountId, balance xdr.Int64) xdr.LedgerEntryChange {
+		entry := xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: account, Balance: balance},
+		}}
+		if changeType == xdr.LedgerEntryChangeTypeLedgerEntryState {
+			return xdr.LedgerEntryChange{Type: changeType, State: &entry}
+		}
+		return xdr.LedgerEntryChange{Type: changeType, Updated: &entry}
+	}
+
+	// the fee bump transaction of makeTransactionTestInput, whose inner transaction bids a resource fee of 800. The fee
+	// account is charged 1000 up front and refunded 300, while the balance of the inner source does not change.
+	feeBump := hardCodedTransaction[1]
+	innerEnvelope := *feeBump.Envelope.FeeBump.Tx.InnerTx.V1
+	innerEnvelope.Tx.Fee = 1000
+	innerEnvelope.Tx.Ext = xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{ResourceFee: 800}}
+	envelope := *feeBump.Envelope.FeeBump
+	envelope.Tx.InnerTx.V1 = &innerEnvelope
+	feeBump.Envelope.FeeBump = &envelope
+	feeBump.FeeChanges = xdr.LedgerEntryChanges{
+		accountChange(xdr.LedgerEntryChangeTypeLedgerEntryState, testAccount3ID, 10000),
+		accountChange(xdr.LedgerEntryChangeTypeLedgerEntryUpdated, testAccount3ID, 9000),
+		accountChange(xdr.LedgerEntryChangeTypeLedgerEntryState, testAccount1ID, 5000),
+		accountChange(xdr.LedgerEntryChangeTypeLedgerEntryUpdated, testAccount1ID, 5000),
+	}
+	feeBump.UnsafeMeta = xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+		TxChangesAfter: xdr.LedgerEntryChanges{
+			accountChange(xdr.LedgerEntryChangeTypeLedgerEntryState, testAccount3ID, 9000),
+			accountChange(xdr.LedgerEntryChangeTypeLedgerEntryUpdated, testAccount3ID, 9300),
+		},
+		SorobanMeta: &xdr.SorobanTransactionMeta{ReturnValue: xdr.ScVal{Type: xdr.ScValTypeScvVoid}},
+	}}
+
+	output, err := TransformTransaction(feeBump, hardCodedLedgerHeader[1])
+	assert.NoError(t, err)
+	assert.Equal(t, sorobanFeeBumpFees{
+		// the resource fee, minus the refund, plus the inclusion fee charged
+		FeeCharged: 700,
+		NewMaxFee:  7200,
+		// the fee the inner result records
+		InnerFeeCharged: 100,
+		// the outer fee minus the resource fee
+		InclusionFeeBid:     6400,
+		InclusionFeeCharged: 200,
+		ResourceFeeRefund:   300,
+	}, sorobanFeeBumpFees{
+		FeeCharged:          output.FeeCharged,
+		NewMaxFee:           output.NewMaxFee,
+		InnerFeeCharged:     output.InnerFeeCharged,
+		InclusionFeeBid:     output.InclusionFeeBid,
+		InclusionFeeCharged: output.InclusionFeeCharged,
+		ResourceFeeRefund:   output.ResourceFeeRefund,
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stellar/stellar-etl/internal/utils"
 
+	"github.com/stellar/go/amount"
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
 )
@@ -59,24 +60,29 @@ func TransformTrustline(ledgerChange ingest.Change, header xdr.LedgerHeaderHisto
 	ledgerSequence := header.Header.LedgerSeq
 
 	transformedTrustline := TrustlineOutput{
-		LedgerKey:          outputLedgerKey,
-		AccountID:          outputAccountID,
-		AssetType:          int32(asset.Type),
-		AssetCode:          outputAssetCode,
-		AssetIssuer:        outputAssetIssuer,
-		AssetID:            outputAssetID,
-		Balance:            utils.ConvertStroopValueToReal(trustEntry.Balance),
-		TrustlineLimit:     int64(trustEntry.Limit),
-		LiquidityPoolID:    poolID,
-		BuyingLiabilities:  utils.ConvertStroopValueToReal(liabilities.Buying),
-		SellingLiabilities: utils.ConvertStroopValueToReal(liabilities.Selling),
-		Flags:              uint32(trustEntry.Flags),
-		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
-		LedgerEntryChange:  uint32(changeType),
-		Sponsor:            ledgerEntrySponsorToNullString(ledgerEntry),
-		Deleted:            outputDeleted,
-		ClosedAt:           closedAt,
-		LedgerSequence:     uint32(ledgerSequence),
+		LedgerKey:                         outputLedgerKey,
+		AccountID:                         outputAccountID,
+		AssetType:                         int32(asset.Type),
+		AssetCode:                         outputAssetCode,
+		AssetIssuer:                       outputAssetIssuer,
+		AssetID:                           outputAssetID,
+		Balance:                           utils.ConvertStroopValueToReal(trustEntry.Balance),
+		TrustlineLimit:                    int64(trustEntry.Limit),
+		Limit:                             amount.String(trustEntry.Limit),
+		LimitStroops:                      int64(trustEntry.Limit),
+		LiquidityPoolID:                   poolID,
+		BuyingLiabilities:                 utils.ConvertStroopValueToReal(liabilities.Buying),
+		SellingLiabilities:                utils.ConvertStroopValueToReal(liabilities.Selling),
+		Flags:                             uint32(trustEntry.Flags),
+		IsAuthorized:                      xdr.TrustLineFlags(trustEntry.Flags).IsAuthorized(),
+		IsAuthorizedToMaintainLiabilities: xdr.TrustLineFlags(trustEntry.Flags).IsAuthorizedToMaintainLiabilitiesFlag(),
+		IsClawbackEnabled:                 xdr.TrustLineFlags(trustEntry.Flags).IsClawbackEnabledFlag(),
+		LastModifiedLedger:                uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:                 uint32(changeType),
+		Sponsor:                           ledgerEntrySponsorToNullString(ledgerEntry),
+		Deleted:                           outputDeleted,
+		ClosedAt:                          closedAt,
+		LedgerSequence:                    uint32(ledgerSequence),
 	}
 
 	return transformedTrustline, nil
@@ -98,6 +104,7 @@ func trustLineEntryToLedgerKeyString(trustLine xdr.TrustLineEntry) (string, erro
 
 }
 
+// ledgerEntrySponsorToNullString returns the sponsor recorded in the ledger entry extension, or null if the entry is not sponsored.
 func ledgerEntrySponsorToNullString(entry xdr.LedgerEntry) null.String {
 	sponsoringID := entry.SponsoringID()
 
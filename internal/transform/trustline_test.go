@@ -134,6 +134,9 @@ func makeTrustlineTestOutput() []TrustlineOutput {
 			AssetID:            -2311386320395871674,
 			Balance:            0.6203,
 			TrustlineLimit:     9000000000000000000,
+			Limit:              "900000000000.0000000",
+			LimitStroops:       9000000000000000000,
+			IsAuthorized:       true,
 			Flags:              1,
 			BuyingLiabilities:  0.0001,
 			SellingLiabilities: 0.0002,
@@ -150,6 +153,9 @@ func makeTrustlineTestOutput() []TrustlineOutput {
 			AssetID:            -1967220342708457407,
 			Balance:            0.5,
 			TrustlineLimit:     1111111111111111111,
+			Limit:              "111111111111.1111111",
+			LimitStroops:       1111111111111111111,
+			IsAuthorized:       true,
 			LiquidityPoolID:    "0103040507090000000000000000000000000000000000000000000000000000",
 			Flags:              1,
 			BuyingLiabilities:  0.0015,
@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/toid"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// TransformTransactionFootprint converts the footprint of a Soroban transaction into one row per ledger key it declared.
+// Transactions without Soroban data have no footprint and return no rows.
+func TransformTransactionFootprint(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (_ []TransactionFootprintOutput, err error) {
+	defer recoverPanic(&err)
+
+	var sorobanData xdr.SorobanTransactionData
+	var hasSorobanData bool
+	switch transaction.Envelope.Type {
+	case xdr.EnvelopeTypeEnvelopeTypeTx:
+		sorobanData, hasSorobanData = transaction.Envelope.V1.Tx.Ext.GetSorobanData()
+	case xdr.EnvelopeTypeEnvelopeTypeTxFeeBump:
+		sorobanData, hasSorobanData = transaction.Envelope.FeeBump.Tx.InnerTx.V1.Tx.Ext.GetSorobanData()
+	}
+	if !hasSorobanData {
+		return nil, nil
+	}
+
+	outputLedgerSequence := uint32(lhe.Header.LedgerSeq)
+	outputTransactionID := toid.New(int32(outputLedgerSequence), int32(transaction.Index), 0).ToInt64()
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(lhe.Header.ScpValue.CloseTime)
+	if err != nil {
+		return nil, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %v", outputLedgerSequence, transaction.Index, outputTransactionID, err)
+	}
+
+	footprint := sorobanData.Resources.Footprint
+	transformed := make([]TransactionFootprintOutput, 0, len(footprint.ReadOnly)+len(footprint.ReadWrite))
+	for _, access := range []struct {
+		keys     []xdr.LedgerKey
+		readOnly bool
+	}{{footprint.ReadOnly, true}, {footprint.ReadWrite, false}} {
+		for _, key := range access.keys {
+			encodedKey, err := xdr.MarshalBase64(key)
+			if err != nil {
+				return nil, fmt.Errorf("could not encode a footprint key of transaction %d: %v", outputTransactionID, err)
+			}
+			decodedKey, err := utils.XdrJSON.LedgerKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode a footprint key of transaction %d: %v", outputTransactionID, err)
+			}
+			contractId, err := ledgerKeyContractId(key)
+			if err != nil {
+				return nil, err
+			}
+
+			transformed = append(transformed, TransactionFootprintOutput{
+				TransactionHash:  utils.HashToHexString(transaction.Result.TransactionHash),
+				LedgerSequence:   outputLedgerSequence,
+				TransactionID:    outputTransactionID,
+				ClosedAt:         outputCloseTime,
+				Successful:       transaction.Result.Successful(),
+				ContractId:       contractId,
+				LedgerEntryType:  key.Type.String(),
+				LedgerKey:        encodedKey,
+				LedgerKeyHash:    utils.LedgerKeyToLedgerKeyHash(key),
+				LedgerKeyDecoded: decodedKey,
+				ReadOnly:         access.readOnly,
+			})
+		}
+	}
+
+	return transformed, nil
+}
+
+// ledgerKeyContractId returns the strkey of the contract owning a contract data key, or an empty string for other keys
+func ledgerKeyContractId(key xdr.LedgerKey) (string, error) {
+	contractData, ok := key.GetContractData()
+	if !ok {
+		return "", nil
+	}
+	id, ok := contractData.Contract.GetContractId()
+	if !ok {
+		return "", nil
+	}
+	return strkey.Encode(strkey.VersionByteContract, id[:])
+}
@@ -0,0 +1,205 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformTransactionFootprint(t *testing.T) {
+	type inputStruct struct {
+		transaction   ingest.LedgerTransaction
+		historyHeader xdr.LedgerHeaderHistoryEntry
+	}
+	type transformTest struct {
+		input      inputStruct
+		wantOutput []TransactionFootprintOutput
+		wantErr    error
+	}
+
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeTransactionFootprintTestInput()
+	assert.NoError(t, err)
+	hardCodedOutput, err := makeTransactionFootprintTestOutput()
+	assert.NoError(t, err)
+
+	tests := []transformTest{}
+	for i := range hardCodedTransaction {
+		tests = append(tests, transformTest{
+			input:      inputStruct{hardCodedTransaction[i], hardCodedLedgerHeader[i]},
+			wantOutput: hardCodedOutput[i],
+			wantErr:    nil,
+		})
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError := TransformTransactionFootprint(test.input.transaction, test.input.historyHeader)
+		assert.Equal(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeTransactionFootprintTestOutput() (output [][]TransactionFootprintOutput, err error) {
+	closedAt := time.Date(2020, time.July, 9, 5, 28, 42, 0, time.UTC)
+	output = [][]TransactionFootprintOutput{
+		{
+			{
+				TransactionHash: "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
+				LedgerSequence:  30521816,
+				TransactionID:   131090201534533632,
+				ClosedAt:        closedAt,
+				Successful:      false,
+				ContractId:      "",
+				LedgerEntryType: "LedgerEntryTypeContractCode",
+				LedgerKey:       "AAAABwQFBgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+				LedgerKeyHash:   "8dff10f772999940d867a653f238c7c85af80a9d43a1bc541614c054a76872f7",
+				LedgerKeyDecoded: map[string]interface{}{
+					"contract_code": map[string]interface{}{
+						"hash": "0405060000000000000000000000000000000000000000000000000000000000",
+					},
+				},
+				ReadOnly: true,
+			},
+			{
+				TransactionHash: "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
+				LedgerSequence:  30521816,
+				TransactionID:   131090201534533632,
+				ClosedAt:        closedAt,
+				Successful:      false,
+				ContractId:      "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+				LedgerEntryType: "LedgerEntryTypeContractData",
+				LedgerKey:       "AAAABgAAAAEBAgMAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABQAAAAB",
+				LedgerKeyHash:   "c74e644e72137ad7ccab5f4265db185e075f6d7f07255353da086ee3da0b06c3",
+				LedgerKeyDecoded: map[string]interface{}{
+					"contract_data": map[string]interface{}{
+						"contract":   "CAAQEAYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABXAS",
+						"key":        "ledger_key_contract_instance",
+						"durability": "persistent",
+					},
+				},
+				ReadOnly: false,
+			},
+		},
+		// transactions without Soroban data have no footprint
+		nil,
+	}
+	return
+}
+
+func makeTransactionFootprintTestInput() (transaction []ingest.LedgerTransaction, historyHeader []xdr.LedgerHeaderHistoryEntry, err error) {
+	hardCodedTransactionHash := xdr.Hash([32]byte{0xa8, 0x7f, 0xef, 0x5e, 0xeb, 0x26, 0x2, 0x69, 0xc3, 0x80, 0xf2, 0xde, 0x45, 0x6a, 0xad, 0x72, 0xb5, 0x9b, 0xb3, 0x15, 0xaa, 0xac, 0x77, 0x78, 0x60, 0x45, 0x6e, 0x9, 0xda, 0xc0, 0xba, 0xfb})
+	contractId := xdr.Hash{1, 2, 3}
+	contractAddress := xdr.ScAddress{
+		Type:       xdr.ScAddressTypeScAddressTypeContract,
+		ContractId: &contractId,
+	}
+	codeKey := xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractCode,
+		ContractCode: &xdr.LedgerKeyContractCode{Hash: xdr.Hash{4, 5, 6}},
+	}
+	dataKey := xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   contractAddress,
+			Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+			Durability: xdr.ContractDataDurabilityPersistent,
+		},
+	}
+
+	transaction = []ingest.LedgerTransaction{
+		{
+			Index: 1,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: testAccount1,
+						Operations: []xdr.Operation{
+							{
+								Body: xdr.OperationBody{
+									Type: xdr.OperationTypeInvokeHostFunction,
+									InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+										HostFunction: xdr.HostFunction{
+											Type:           xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+											InvokeContract: &xdr.InvokeContractArgs{ContractAddress: contractAddress},
+										},
+									},
+								},
+							},
+						},
+						Ext: xdr.TransactionExt{
+							V: 1,
+							SorobanData: &xdr.SorobanTransactionData{
+								Resources: xdr.SorobanResources{
+									Footprint: xdr.LedgerFootprint{
+										ReadOnly:  []xdr.LedgerKey{codeKey},
+										ReadWrite: []xdr.LedgerKey{dataKey},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Result: xdr.TransactionResultPair{
+				TransactionHash: hardCodedTransactionHash,
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{
+						Code:    xdr.TransactionResultCodeTxFailed,
+						Results: &[]xdr.OperationResult{},
+					},
+				},
+			},
+		},
+		{
+			Index: 2,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: testAccount1,
+						Operations: []xdr.Operation{
+							{
+								Body: xdr.OperationBody{
+									Type: xdr.OperationTypePayment,
+									PaymentOp: &xdr.PaymentOp{
+										Destination: testAccount2,
+										Asset:       nativeAsset,
+										Amount:      1,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Result: xdr.TransactionResultPair{
+				TransactionHash: hardCodedTransactionHash,
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{
+						Code:    xdr.TransactionResultCodeTxSuccess,
+						Results: &[]xdr.OperationResult{},
+					},
+				},
+			},
+		},
+	}
+	historyHeader = []xdr.LedgerHeaderHistoryEntry{
+		{
+			Header: xdr.LedgerHeader{
+				LedgerSeq: 30521816,
+				ScpValue:  xdr.StellarValue{CloseTime: 1594272522},
+			},
+		},
+		{
+			Header: xdr.LedgerHeader{
+				LedgerSeq: 30521816,
+				ScpValue:  xdr.StellarValue{CloseTime: 1594272522},
+			},
+		},
+	}
+	return
+}
@@ -0,0 +1,20 @@
+package transform
+
+import (
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+)
+
+// VerifyTransactionHash recomputes the hash of the transaction from its envelope and the network passphrase and fills
+// the hash_mismatch column, which is true when the hash differs from the one of the result pair. A mismatch means that
+// the envelope or the result read from the archives or the datastore is corrupted.
+func VerifyTransactionHash(transformed TransactionOutput, transaction ingest.LedgerTransaction, passphrase string) (TransactionOutput, error) {
+	hash, err := network.HashTransactionInEnvelope(transaction.Envelope, passphrase)
+	if err != nil {
+		return transformed, err
+	}
+
+	transformed.HashMismatch = null.BoolFrom(hash != transaction.Result.TransactionHash)
+	return transformed, nil
+}
@@ -0,0 +1,33 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyTransactionHash(t *testing.T) {
+	envelope := makeBumpSequenceTestEnvelope(1)
+	hash, err := network.HashTransactionInEnvelope(envelope, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+
+	transaction := ingest.LedgerTransaction{Envelope: envelope}
+	transaction.Result.TransactionHash = hash
+
+	verified, err := VerifyTransactionHash(TransactionOutput{}, transaction, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, null.BoolFrom(false), verified.HashMismatch)
+
+	// the hash of another network does not match
+	verified, err = VerifyTransactionHash(TransactionOutput{}, transaction, network.PublicNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, null.BoolFrom(true), verified.HashMismatch)
+
+	transaction.Result.TransactionHash[0] ^= 0xff
+	verified, err = VerifyTransactionHash(TransactionOutput{}, transaction, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, null.BoolFrom(true), verified.HashMismatch)
+}
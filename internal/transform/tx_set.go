@@ -0,0 +1,80 @@
+package transform
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// TxSetPosition is where a transaction was placed in the generalized transaction set of its ledger
+type TxSetPosition struct {
+	Phase     int64
+	Component int64
+	Position  int64
+}
+
+// TxSetPositions returns the position of every transaction in the generalized transaction set of the ledger, keyed by
+// the hex encoded transaction hash. The first phase holds classic transactions and the second one Soroban transactions.
+// Components group the transactions of a phase that are charged the same base fee, and the position counts the
+// transactions of the phase in the order they appear in the set. Ledgers closed before generalized transaction sets
+// return an empty map.
+func TxSetPositions(lcm xdr.LedgerCloseMeta, passphrase string) (map[string]TxSetPosition, error) {
+	positions := map[string]TxSetPosition{}
+
+	lcmV1, ok := lcm.GetV1()
+	if !ok {
+		return positions, nil
+	}
+
+	txSet, ok := lcmV1.TxSet.GetV1TxSet()
+	if !ok {
+		return positions, nil
+	}
+
+	for phaseIndex, phase := range txSet.Phases {
+		components, ok := phase.GetV0Components()
+		if !ok {
+			return nil, fmt.Errorf("unknown transaction phase version %d in ledger %d", phase.V, lcm.LedgerSequence())
+		}
+
+		var position int64
+		for componentIndex, component := range components {
+			txs, ok := component.GetTxsMaybeDiscountedFee()
+			if !ok {
+				return nil, fmt.Errorf("unknown transaction set component type %d in ledger %d", component.Type, lcm.LedgerSequence())
+			}
+
+			for _, envelope := range txs.Txs {
+				hash, err := network.HashTransactionInEnvelope(envelope, passphrase)
+				if err != nil {
+					return nil, err
+				}
+
+				positions[hex.EncodeToString(hash[:])] = TxSetPosition{
+					Phase:     int64(phaseIndex),
+					Component: int64(componentIndex),
+					Position:  position,
+				}
+				position++
+			}
+		}
+	}
+
+	return positions, nil
+}
+
+// JoinTxSetPosition fills the transaction set columns of the transaction with its position in positions
+func JoinTxSetPosition(transaction TransactionOutput, positions map[string]TxSetPosition) TransactionOutput {
+	position, ok := positions[transaction.TransactionHash]
+	if !ok {
+		return transaction
+	}
+
+	transaction.TxSetPhase = null.IntFrom(position.Phase)
+	transaction.TxSetComponent = null.IntFrom(position.Component)
+	transaction.TxSetPosition = null.IntFrom(position.Position)
+	return transaction
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeBumpSequenceTestEnvelope builds a bump sequence envelope owned by the caller, so that the fixtures shared with
+// other tests, which mutate them, do not change the hashes computed from it
+func makeBumpSequenceTestEnvelope(seqNum xdr.SequenceNumber) xdr.TransactionEnvelope {
+	return xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: testAccount1,
+				Fee:           100,
+				SeqNum:        seqNum,
+				Operations: []xdr.Operation{
+					{
+						Body: xdr.OperationBody{
+							Type:           xdr.OperationTypeBumpSequence,
+							BumpSequenceOp: &xdr.BumpSequenceOp{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTxSetPositions(t *testing.T) {
+	classicTx := makeBumpSequenceTestEnvelope(1)
+	discountedTx := makeBumpSequenceTestEnvelope(2)
+	sorobanTx := makeBumpSequenceTestEnvelope(3)
+
+	baseFee := xdr.Int64(100)
+	components := func(groups ...[]xdr.TransactionEnvelope) *[]xdr.TxSetComponent {
+		result := []xdr.TxSetComponent{}
+		for _, txs := range groups {
+			result = append(result, xdr.TxSetComponent{
+				Type: xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+				TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{
+					BaseFee: &baseFee,
+					Txs:     txs,
+				},
+			})
+		}
+		return &result
+	}
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			TxSet: xdr.GeneralizedTransactionSet{
+				V: 1,
+				V1TxSet: &xdr.TransactionSetV1{
+					Phases: []xdr.TransactionPhase{
+						{V: 0, V0Components: components([]xdr.TransactionEnvelope{classicTx}, []xdr.TransactionEnvelope{discountedTx})},
+						{V: 0, V0Components: components([]xdr.TransactionEnvelope{sorobanTx})},
+					},
+				},
+			},
+		},
+	}
+
+	hash := func(envelope xdr.TransactionEnvelope) string {
+		h, err := network.HashTransactionInEnvelope(envelope, network.TestNetworkPassphrase)
+		assert.NoError(t, err)
+		return hex.EncodeToString(h[:])
+	}
+
+	positions, err := TxSetPositions(lcm, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]TxSetPosition{
+		hash(classicTx):    {Phase: 0, Component: 0, Position: 0},
+		hash(discountedTx): {Phase: 0, Component: 1, Position: 1},
+		hash(sorobanTx):    {Phase: 1, Component: 0, Position: 0},
+	}, positions)
+
+	joined := JoinTxSetPosition(TransactionOutput{TransactionHash: hash(discountedTx)}, positions)
+	assert.Equal(t, null.IntFrom(0), joined.TxSetPhase)
+	assert.Equal(t, null.IntFrom(1), joined.TxSetComponent)
+	assert.Equal(t, null.IntFrom(1), joined.TxSetPosition)
+
+	missing := JoinTxSetPosition(TransactionOutput{TransactionHash: "missing"}, positions)
+	assert.False(t, missing.TxSetPhase.Valid)
+
+	positions, err = TxSetPositions(xdr.LedgerCloseMeta{V: 0, V0: &xdr.LedgerCloseMetaV0{}}, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+}
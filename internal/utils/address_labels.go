@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// addressLabelsTOML is the layout of a TOML address labels file: an [[addresses]] table per labelled address
+type addressLabelsTOML struct {
+	Addresses []struct {
+		Address string `toml:"address"`
+		Label   string `toml:"label"`
+	} `toml:"addresses"`
+}
+
+// LoadAddressLabels reads a TOML file with an [[addresses]] table per address, or a CSV file with a header row naming
+// the address and label columns, and returns the labels keyed by address
+func LoadAddressLabels(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	labels := map[string]string{}
+	add := func(address, label string) error {
+		if address == "" {
+			return fmt.Errorf("an entry of %s has no address", path)
+		}
+		if _, ok := labels[address]; ok {
+			return fmt.Errorf("address %s is listed more than once in %s", address, path)
+		}
+		labels[address] = label
+		return nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var parsed addressLabelsTOML
+		if err := toml.NewDecoder(file).DisallowUnknownFields().Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		for _, entry := range parsed.Addresses {
+			if err := add(entry.Address, entry.Label); err != nil {
+				return nil, err
+			}
+		}
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.TrimLeadingSpace = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+
+		addressColumn, labelColumn := -1, -1
+		for i, name := range header {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "address":
+				addressColumn = i
+			case "label":
+				labelColumn = i
+			}
+		}
+		if addressColumn < 0 || labelColumn < 0 {
+			return nil, fmt.Errorf("the header row of %s must have address and label columns", path)
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s: %v", path, err)
+			}
+			if err := add(strings.TrimSpace(record[addressColumn]), strings.TrimSpace(record[labelColumn])); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("address labels file %s must be a .toml or .csv file", path)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// autoTuneLedgerBytes is a conservative estimate of the memory used by one buffered txmeta file
+	autoTuneLedgerBytes = 32 << 20
+	// autoTuneMaxWorkers caps the datastore workers so that large machines do not overwhelm the datastore
+	autoTuneMaxWorkers = 32
+	// autoTuneTargetSinkLatency is the time writing and uploading a batch should take. Slower batches shrink the batch size.
+	autoTuneTargetSinkLatency = 30 * time.Second
+	// autoTuneHighMemoryPressure is the fraction of the memory limit above which batches shrink
+	autoTuneHighMemoryPressure = 0.8
+	// autoTuneLowMemoryPressure is the fraction of the memory limit below which batches are allowed to grow
+	autoTuneLowMemoryPressure = 0.5
+)
+
+// AutoTuneDatastoreWorkers sizes the datastore worker pool from the available CPUs and the buffer
+// so that the buffered txmeta files fit in a quarter of the memory limit
+func AutoTuneDatastoreWorkers() (numWorkers, bufferSize uint32) {
+	return datastoreWorkers(runtime.NumCPU(), memoryLimit())
+}
+
+// datastoreWorkers sizes the worker pool and buffer for the number of CPUs and the memory limit, which is 0 when unknown
+func datastoreWorkers(cpus int, limit uint64) (numWorkers, bufferSize uint32) {
+	numWorkers = uint32(cpus)
+	if numWorkers > autoTuneMaxWorkers {
+		numWorkers = autoTuneMaxWorkers
+	}
+
+	bufferSize = 2 * numWorkers
+	if limit > 0 {
+		maxBuffer := uint32(limit / 4 / autoTuneLedgerBytes)
+		if maxBuffer < 1 {
+			maxBuffer = 1
+		}
+		if bufferSize > maxBuffer {
+			bufferSize = maxBuffer
+		}
+		if numWorkers > bufferSize {
+			numWorkers = bufferSize
+		}
+	}
+
+	return numWorkers, bufferSize
+}
+
+// BatchTuner adjusts the number of ledgers per batch to the sink latency and memory pressure observed while exporting.
+// Batches shrink when a sink is slow or memory runs low, and grow back while both have headroom.
+type BatchTuner struct {
+	mutex        sync.Mutex
+	batchSize    uint32
+	minBatchSize uint32
+	maxBatchSize uint32
+	logger       *EtlLogger
+}
+
+// NewBatchTuner creates a tuner that starts at the provided batch size and grows up to eight times that size
+func NewBatchTuner(batchSize uint32, logger *EtlLogger) *BatchTuner {
+	minBatchSize := batchSize / 8
+	if minBatchSize < 1 {
+		minBatchSize = 1
+	}
+
+	return &BatchTuner{
+		batchSize:    batchSize,
+		minBatchSize: minBatchSize,
+		maxBatchSize: batchSize * 8,
+		logger:       logger,
+	}
+}
+
+// BatchSize returns the number of ledgers the next batch should hold
+func (t *BatchTuner) BatchSize() uint32 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.batchSize
+}
+
+// ObserveSinkLatency records how long writing and uploading the last batch took and resizes the next batches
+func (t *BatchTuner) ObserveSinkLatency(latency time.Duration) {
+	t.resize(latency, memoryPressure())
+}
+
+// resize halves the batch size when the sink latency or the memory pressure is too high, and doubles it when both
+// have headroom, within the bounds of the tuner
+func (t *BatchTuner) resize(latency time.Duration, pressure float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.batchSize
+	switch {
+	case latency > autoTuneTargetSinkLatency || pressure > autoTuneHighMemoryPressure:
+		t.batchSize = previous / 2
+		if t.batchSize < t.minBatchSize {
+			t.batchSize = t.minBatchSize
+		}
+	case latency < autoTuneTargetSinkLatency/4 && pressure < autoTuneLowMemoryPressure:
+		t.batchSize = previous * 2
+		if t.batchSize > t.maxBatchSize {
+			t.batchSize = t.maxBatchSize
+		}
+	}
+
+	if t.batchSize != previous {
+		t.logger.Infof("auto-tune: resized batches from %d to %d ledgers (sink latency %s, memory pressure %.2f)", previous, t.batchSize, latency, pressure)
+	}
+}
+
+// memoryPressure returns the fraction of the memory limit that the process has obtained from the OS, or 0 if the limit is unknown
+func memoryPressure() float64 {
+	limit := memoryLimit()
+	if limit == 0 {
+		return 0
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return float64(stats.Sys) / float64(limit)
+}
+
+// memoryLimit returns the cgroup memory limit of the process, falling back to the total memory of the machine.
+// It returns 0 if neither can be read.
+func memoryLimit() uint64 {
+	if contents, err := os.ReadFile("/sys/fs/cgroup/memory.max"); err == nil {
+		if limit, err := strconv.ParseUint(strings.TrimSpace(string(contents)), 10, 64); err == nil {
+			return limit
+		}
+	}
+
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kilobytes, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kilobytes * 1024
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatastoreWorkers(t *testing.T) {
+	tests := []struct {
+		name           string
+		cpus           int
+		limit          uint64
+		wantWorkers    uint32
+		wantBufferSize uint32
+	}{
+		{name: "unknown memory limit", cpus: 4, limit: 0, wantWorkers: 4, wantBufferSize: 8},
+		{name: "workers capped on large machines", cpus: 64, limit: 0, wantWorkers: 32, wantBufferSize: 64},
+		{name: "buffer fits in a quarter of the memory", cpus: 8, limit: 1 << 30, wantWorkers: 8, wantBufferSize: 8},
+		{name: "workers reduced to the buffer", cpus: 16, limit: 512 << 20, wantWorkers: 4, wantBufferSize: 4},
+		{name: "at least one buffered file", cpus: 4, limit: 64 << 20, wantWorkers: 1, wantBufferSize: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			numWorkers, bufferSize := datastoreWorkers(test.cpus, test.limit)
+			assert.Equal(t, test.wantWorkers, numWorkers)
+			assert.Equal(t, test.wantBufferSize, bufferSize)
+		})
+	}
+}
+
+func TestBatchTunerResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     uint32
+		latency       time.Duration
+		pressure      float64
+		wantBatchSize uint32
+	}{
+		{name: "slow sink halves batches", batchSize: 64, latency: time.Minute, pressure: 0.1, wantBatchSize: 32},
+		{name: "high memory pressure halves batches", batchSize: 64, latency: time.Second, pressure: 0.9, wantBatchSize: 32},
+		{name: "headroom doubles batches", batchSize: 64, latency: time.Second, pressure: 0.1, wantBatchSize: 128},
+		{name: "moderate latency keeps batches", batchSize: 64, latency: 20 * time.Second, pressure: 0.1, wantBatchSize: 64},
+		{name: "moderate memory pressure keeps batches", batchSize: 64, latency: time.Second, pressure: 0.6, wantBatchSize: 64},
+		{name: "batches do not shrink below an eighth", batchSize: 8, latency: time.Minute, pressure: 0.1, wantBatchSize: 8},
+		{name: "batches do not grow above eight times", batchSize: 512, latency: time.Second, pressure: 0.1, wantBatchSize: 512},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the tuners start at 64 ledgers, so they are bounded by 8 and 512
+			tuner := NewBatchTuner(64, NewEtlLogger())
+			tuner.batchSize = test.batchSize
+			tuner.resize(test.latency, test.pressure)
+			assert.Equal(t, test.wantBatchSize, tuner.BatchSize())
+		})
+	}
+
+	// a single ledger batch cannot shrink any further
+	tuner := NewBatchTuner(1, NewEtlLogger())
+	tuner.resize(time.Minute, 0)
+	assert.Equal(t, uint32(1), tuner.BatchSize())
+}
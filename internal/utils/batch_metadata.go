@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// ToolVersion returns the module version stellar-etl was built from, or (devel) for builds of a local checkout
+func ToolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(devel)"
+	}
+	return info.Main.Version
+}
+
+// defaultBatchID identifies a batch by its ledger range, so that a rerun of the same range gets the same id and its rows
+// can be merged over the rows of the earlier run. Commands without a ledger range use the run date instead.
+func defaultBatchID(flags *pflag.FlagSet, endNum uint32, runDate time.Time) string {
+	if flags.Lookup("start-ledger") != nil {
+		if startNum, err := flags.GetUint32("start-ledger"); err == nil {
+			return fmt.Sprintf("%d-%d", startNum, endNum)
+		}
+	}
+	return runDate.Format(time.RFC3339)
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"math/big"
+
+	"github.com/stellar/go/xdr"
+)
+
+// Int128ToBigInt returns the value of a signed 128 bit integer, which is negative when its high part is
+func Int128ToBigInt(parts xdr.Int128Parts) *big.Int {
+	return joinIntParts(true, uint64(parts.Hi), uint64(parts.Lo))
+}
+
+// UInt128ToBigInt returns the value of an unsigned 128 bit integer
+func UInt128ToBigInt(parts xdr.UInt128Parts) *big.Int {
+	return joinIntParts(false, uint64(parts.Hi), uint64(parts.Lo))
+}
+
+// Int256ToBigInt returns the value of a signed 256 bit integer, which is negative when its most significant part is
+func Int256ToBigInt(parts xdr.Int256Parts) *big.Int {
+	return joinIntParts(true, uint64(parts.HiHi), uint64(parts.HiLo), uint64(parts.LoHi), uint64(parts.LoLo))
+}
+
+// UInt256ToBigInt returns the value of an unsigned 256 bit integer
+func UInt256ToBigInt(parts xdr.UInt256Parts) *big.Int {
+	return joinIntParts(false, uint64(parts.HiHi), uint64(parts.HiLo), uint64(parts.LoHi), uint64(parts.LoLo))
+}
+
+// ScValBigInt returns the value of an i128, u128, i256 or u256 ScVal, and false for the other types
+func ScValBigInt(val xdr.ScVal) (*big.Int, bool) {
+	switch val.Type {
+	case xdr.ScValTypeScvI128:
+		return Int128ToBigInt(val.MustI128()), true
+	case xdr.ScValTypeScvU128:
+		return UInt128ToBigInt(val.MustU128()), true
+	case xdr.ScValTypeScvI256:
+		return Int256ToBigInt(val.MustI256()), true
+	case xdr.ScValTypeScvU256:
+		return UInt256ToBigInt(val.MustU256()), true
+	default:
+		return nil, false
+	}
+}
+
+// ScValString returns the human readable form of an ScVal, with 128 and 256 bit integers in decimal
+func ScValString(val xdr.ScVal) string {
+	if value, ok := ScValBigInt(val); ok {
+		return value.String()
+	}
+	return val.String()
+}
+
+// joinIntParts joins the 64 bit parts of a 128 or 256 bit integer, most significant first. The most significant part
+// holds the sign of signed integers.
+func joinIntParts(signed bool, parts ...uint64) *big.Int {
+	value := new(big.Int)
+	for _, part := range parts {
+		value.Lsh(value, 64)
+		value.Or(value, new(big.Int).SetUint64(part))
+	}
+	if signed && len(parts) > 0 && int64(parts[0]) < 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(64*len(parts))))
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInt128ToBigInt(t *testing.T) {
+	tests := []struct {
+		parts xdr.Int128Parts
+		want  string
+	}{
+		{xdr.Int128Parts{Hi: 0, Lo: 0}, "0"},
+		{xdr.Int128Parts{Hi: 0, Lo: 1}, "1"},
+		{xdr.Int128Parts{Hi: 0, Lo: math.MaxUint64}, "18446744073709551615"},
+		{xdr.Int128Parts{Hi: 1, Lo: 0}, "18446744073709551616"},
+		{xdr.Int128Parts{Hi: -1, Lo: math.MaxUint64}, "-1"},
+		{xdr.Int128Parts{Hi: -1, Lo: 0}, "-18446744073709551616"},
+		{xdr.Int128Parts{Hi: math.MaxInt64, Lo: math.MaxUint64}, "170141183460469231731687303715884105727"},
+		{xdr.Int128Parts{Hi: math.MinInt64, Lo: 0}, "-170141183460469231731687303715884105728"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Int128ToBigInt(test.parts).String())
+	}
+}
+
+func TestUInt128ToBigInt(t *testing.T) {
+	assert.Equal(t, "0", UInt128ToBigInt(xdr.UInt128Parts{}).String())
+	assert.Equal(t, "18446744073709551616", UInt128ToBigInt(xdr.UInt128Parts{Hi: 1, Lo: 0}).String())
+	assert.Equal(t, "340282366920938463463374607431768211455", UInt128ToBigInt(xdr.UInt128Parts{Hi: math.MaxUint64, Lo: math.MaxUint64}).String())
+}
+
+func TestInt256ToBigInt(t *testing.T) {
+	tests := []struct {
+		parts xdr.Int256Parts
+		want  string
+	}{
+		{xdr.Int256Parts{}, "0"},
+		{xdr.Int256Parts{LoLo: 42}, "42"},
+		{xdr.Int256Parts{HiHi: -1, HiLo: math.MaxUint64, LoHi: math.MaxUint64, LoLo: math.MaxUint64}, "-1"},
+		{xdr.Int256Parts{HiHi: math.MaxInt64, HiLo: math.MaxUint64, LoHi: math.MaxUint64, LoLo: math.MaxUint64},
+			"57896044618658097711785492504343953926634992332820282019728792003956564819967"},
+		{xdr.Int256Parts{HiHi: math.MinInt64}, "-57896044618658097711785492504343953926634992332820282019728792003956564819968"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Int256ToBigInt(test.parts).String())
+	}
+}
+
+func TestUInt256ToBigInt(t *testing.T) {
+	assert.Equal(t, "0", UInt256ToBigInt(xdr.UInt256Parts{}).String())
+	assert.Equal(t, "6277101735386680763835789423207666416102355444464034512896", UInt256ToBigInt(xdr.UInt256Parts{HiHi: 1}).String())
+	assert.Equal(t, "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+		UInt256ToBigInt(xdr.UInt256Parts{HiHi: math.MaxUint64, HiLo: math.MaxUint64, LoHi: math.MaxUint64, LoLo: math.MaxUint64}).String())
+}
+
+func TestScValString(t *testing.T) {
+	i128 := xdr.Int128Parts{Hi: -1, Lo: math.MaxUint64 - 99}
+	assert.Equal(t, "-100", ScValString(xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &i128}))
+
+	u256 := xdr.UInt256Parts{LoHi: 1}
+	assert.Equal(t, "18446744073709551616", ScValString(xdr.ScVal{Type: xdr.ScValTypeScvU256, U256: &u256}))
+
+	_, ok := ScValBigInt(xdr.ScVal{Type: xdr.ScValTypeScvVoid})
+	assert.False(t, ok)
+}
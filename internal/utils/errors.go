@@ -0,0 +1,104 @@
+package utils
+
+import "errors"
+
+// ErrorCategory classifies the errors of a run, so that the wrappers retrying exports can tell them apart by exit code
+// instead of matching log messages
+type ErrorCategory string
+
+const (
+	// ErrorCategoryUnknown is used for errors that were not categorized
+	ErrorCategoryUnknown ErrorCategory = "unknown"
+	// ErrorCategoryValidation is used for invalid flags and ledger ranges, which fail again when retried
+	ErrorCategoryValidation ErrorCategory = "validation"
+	// ErrorCategoryLag is used when a continuous export falls too far behind the network tip
+	ErrorCategoryLag ErrorCategory = "lag"
+	// ErrorCategoryInput is used for errors reading ledgers from the datastore, captive core or the history archives
+	ErrorCategoryInput ErrorCategory = "input"
+	// ErrorCategoryTransform is used for errors transforming ledger data into output rows
+	ErrorCategoryTransform ErrorCategory = "transform"
+	// ErrorCategorySink is used for errors writing or uploading the output
+	ErrorCategorySink ErrorCategory = "sink"
+)
+
+// errorCategoryExitCodes are the exit codes of the runs stopped by an error of each category
+var errorCategoryExitCodes = map[ErrorCategory]int{
+	ErrorCategoryUnknown:    1,
+	ErrorCategoryValidation: 2,
+	ErrorCategoryLag:        3,
+	ErrorCategoryInput:      4,
+	ErrorCategoryTransform:  5,
+	ErrorCategorySink:       6,
+}
+
+// InputError is an error reading ledgers from the datastore, captive core or the history archives
+type InputError struct {
+	Err error
+}
+
+func (e InputError) Error() string { return e.Err.Error() }
+func (e InputError) Unwrap() error { return e.Err }
+
+// TransformError is an error transforming ledger data into output rows
+type TransformError struct {
+	Err error
+}
+
+func (e TransformError) Error() string { return e.Err.Error() }
+func (e TransformError) Unwrap() error { return e.Err }
+
+// SinkError is an error writing or uploading the output
+type SinkError struct {
+	Err error
+}
+
+func (e SinkError) Error() string { return e.Err.Error() }
+func (e SinkError) Unwrap() error { return e.Err }
+
+// ValidationError is an error caused by invalid flags or ledger ranges
+type ValidationError struct {
+	Err error
+}
+
+func (e ValidationError) Error() string { return e.Err.Error() }
+func (e ValidationError) Unwrap() error { return e.Err }
+
+// LagError is raised when a continuous export falls too far behind the network tip
+type LagError struct {
+	Err error
+}
+
+func (e LagError) Error() string { return e.Err.Error() }
+func (e LagError) Unwrap() error { return e.Err }
+
+// ErrorCategoryOf returns the category of err. When err wraps errors of several categories, validation errors take
+// precedence, as retrying cannot fix them, followed by lag, sink, transform and input errors.
+func ErrorCategoryOf(err error) ErrorCategory {
+	var validationErr ValidationError
+	var sinkErr SinkError
+	var transformErr TransformError
+	var inputErr InputError
+	var lagErr LagError
+	switch {
+	case errors.As(err, &validationErr):
+		return ErrorCategoryValidation
+	case errors.As(err, &lagErr):
+		return ErrorCategoryLag
+	case errors.As(err, &sinkErr):
+		return ErrorCategorySink
+	case errors.As(err, &transformErr):
+		return ErrorCategoryTransform
+	case errors.As(err, &inputErr):
+		return ErrorCategoryInput
+	default:
+		return ErrorCategoryUnknown
+	}
+}
+
+// ExitCode returns the exit code of the runs stopped by an error of the category
+func (c ErrorCategory) ExitCode() int {
+	if code, ok := errorCategoryExitCodes[c]; ok {
+		return code
+	}
+	return errorCategoryExitCodes[ErrorCategoryUnknown]
+}
@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/xdr"
+)
+
+// historyCacheVersion is part of the cache keys, so that it can be bumped when the format of the cached checkpoints changes
+const historyCacheVersion = 1
+
+// historyCache stores the decoded ledgers of history archive checkpoints on disk, so that ranges overlapping across runs
+// do not download the transaction set and result files of the same checkpoints again
+type historyCache struct {
+	dir         string
+	archiveURLs []string
+}
+
+// checkpointPath returns the cache file of the checkpoint, named after the hash of the archives and the checkpoint
+func (c historyCache) checkpointPath(checkpoint uint32) string {
+	key := fmt.Sprintf("v%d|%s|%d", historyCacheVersion, strings.Join(c.archiveURLs, ","), checkpoint)
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".xdr.gz")
+}
+
+// getLedgers returns the ledgers of every checkpoint overlapping [start, end]. Checkpoints are read from the cache when
+// present, and downloaded and added to it otherwise.
+func (c historyCache) getLedgers(client historyarchive.ArchiveInterface, start, end uint32) (map[uint32]*historyarchive.Ledger, error) {
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("could not create the history cache directory %s: %v", c.dir, err)
+	}
+
+	manager := client.GetCheckpointManager()
+	ledgers := map[uint32]*historyarchive.Ledger{}
+	for checkpoint := manager.GetCheckpoint(start); ; checkpoint += manager.GetCheckpointFrequency() {
+		path := c.checkpointPath(checkpoint)
+		checkpointLedgers, err := readCachedCheckpoint(path)
+		if err != nil {
+			checkpointLedgers, err = fetchCheckpoint(client, checkpoint)
+			if err != nil {
+				return nil, err
+			}
+			if err = writeCachedCheckpoint(path, checkpointLedgers); err != nil {
+				return nil, err
+			}
+		}
+
+		for seq, ledger := range checkpointLedgers {
+			ledgers[seq] = ledger
+		}
+		if checkpoint >= end {
+			break
+		}
+	}
+
+	return ledgers, nil
+}
+
+// fetchCheckpoint downloads the ledger headers, transaction sets and results of the checkpoint. Files are decompressed
+// and decoded while they are streamed instead of being buffered whole.
+func fetchCheckpoint(client historyarchive.ArchiveInterface, checkpoint uint32) (map[uint32]*historyarchive.Ledger, error) {
+	ledgers := map[uint32]*historyarchive.Ledger{}
+	ledger := func(seq uint32) *historyarchive.Ledger {
+		if _, ok := ledgers[seq]; !ok {
+			ledgers[seq] = &historyarchive.Ledger{}
+		}
+		return ledgers[seq]
+	}
+
+	err := readCheckpointCategory(client, "ledger", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.LedgerHeaderHistoryEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+		ledger(uint32(entry.Header.LedgerSeq)).Header = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCheckpointCategory(client, "transactions", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.TransactionHistoryEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+		ledger(uint32(entry.LedgerSeq)).Transaction = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCheckpointCategory(client, "results", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.TransactionHistoryResultEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+		ledger(uint32(entry.LedgerSeq)).TransactionResult = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ledgers, nil
+}
+
+// readCheckpointCategory calls readOne until the checkpoint file of the category is exhausted
+func readCheckpointCategory(client historyarchive.ArchiveInterface, category string, checkpoint uint32, readOne func(stream *historyarchive.XdrStream) error) error {
+	stream, err := client.GetXdrStream(historyarchive.CategoryCheckpointPath(category, checkpoint))
+	if err != nil {
+		return fmt.Errorf("could not open the %s file of checkpoint %d: %v", category, checkpoint, err)
+	}
+	defer stream.Close()
+
+	for {
+		err = readOne(stream)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read the %s file of checkpoint %d: %v", category, checkpoint, err)
+		}
+	}
+}
+
+// readCachedCheckpoint decodes a checkpoint written by writeCachedCheckpoint. It returns an error when the checkpoint is not cached.
+func readCachedCheckpoint(path string) (map[uint32]*historyarchive.Ledger, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := historyarchive.NewXdrGzStream(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	defer stream.Close()
+
+	ledgers := map[uint32]*historyarchive.Ledger{}
+	for {
+		ledger := &historyarchive.Ledger{}
+		err = stream.ReadOne(&ledger.Header)
+		if err == io.EOF {
+			return ledgers, nil
+		}
+		if err == nil {
+			err = stream.ReadOne(&ledger.Transaction)
+		}
+		if err == nil {
+			err = stream.ReadOne(&ledger.TransactionResult)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read the cached checkpoint %s: %v", path, err)
+		}
+		ledgers[uint32(ledger.Header.Header.LedgerSeq)] = ledger
+	}
+}
+
+// writeCachedCheckpoint writes the header, transaction set and results of every ledger of the checkpoint as gzipped XDR.
+// The file is written next to its final path and then renamed, so that concurrent runs never read a partial checkpoint.
+func writeCachedCheckpoint(path string, ledgers map[uint32]*historyarchive.Ledger) error {
+	seqs := make([]uint32, 0, len(ledgers))
+	for seq := range ledgers {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create a history cache file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	for _, seq := range seqs {
+		ledger := ledgers[seq]
+		for _, entry := range []interface{}{ledger.Header, ledger.Transaction, ledger.TransactionResult} {
+			if err = xdr.MarshalFramed(gz, entry); err != nil {
+				tmp.Close()
+				return fmt.Errorf("could not write ledger %d to the history cache: %v", seq, err)
+			}
+		}
+	}
+	if err = gz.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write the history cache file: %v", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not write the history cache file: %v", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
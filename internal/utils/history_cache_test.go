@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeArchiveCheckpoint writes the ledger, transactions and results files of a checkpoint holding ledgers to a file
+// archive
+func writeArchiveCheckpoint(t *testing.T, archiveDir string, checkpoint uint32, ledgers ...uint32) {
+	for _, category := range []string{"ledger", "transactions", "results"} {
+		path := filepath.Join(archiveDir, historyarchive.CategoryCheckpointPath(category, checkpoint))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		file, err := os.Create(path)
+		assert.NoError(t, err)
+
+		gz := gzip.NewWriter(file)
+		for _, seq := range ledgers {
+			var entry interface{}
+			switch category {
+			case "ledger":
+				entry = xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(seq), BaseFee: 100}}
+			case "transactions":
+				entry = xdr.TransactionHistoryEntry{LedgerSeq: xdr.Uint32(seq)}
+			case "results":
+				entry = xdr.TransactionHistoryResultEntry{LedgerSeq: xdr.Uint32(seq)}
+			}
+			assert.NoError(t, xdr.MarshalFramed(gz, entry))
+		}
+		assert.NoError(t, gz.Close())
+		assert.NoError(t, file.Close())
+	}
+}
+
+func newTestArchive(t *testing.T) (historyarchive.ArchiveInterface, string) {
+	archiveDir := t.TempDir()
+	archive, err := historyarchive.Connect("file://"+archiveDir, historyarchive.ArchiveOptions{CheckpointFrequency: 64})
+	assert.NoError(t, err)
+	return archive, archiveDir
+}
+
+func TestHistoryCacheGetLedgers(t *testing.T) {
+	archive, archiveDir := newTestArchive(t)
+	writeArchiveCheckpoint(t, archiveDir, 63, 62, 63)
+	writeArchiveCheckpoint(t, archiveDir, 127, 64, 65)
+	cache := historyCache{dir: filepath.Join(t.TempDir(), "cache"), archiveURLs: []string{"file://" + archiveDir}}
+
+	// the first read misses the cache and downloads both checkpoints
+	ledgers, err := cache.getLedgers(archive, 62, 65)
+	assert.NoError(t, err)
+	assert.Len(t, ledgers, 4)
+	assert.Equal(t, xdr.Uint32(100), ledgers[64].Header.Header.BaseFee)
+	assert.Equal(t, xdr.Uint32(65), ledgers[65].TransactionResult.LedgerSeq)
+	for _, checkpoint := range []uint32{63, 127} {
+		_, err := os.Stat(cache.checkpointPath(checkpoint))
+		assert.NoError(t, err)
+	}
+
+	// later reads are served by the cache, even when the archive is gone
+	assert.NoError(t, os.RemoveAll(archiveDir))
+	cached, err := cache.getLedgers(archive, 62, 65)
+	assert.NoError(t, err)
+	assert.Equal(t, ledgers, cached)
+
+	// checkpoints that are not cached still have to be downloaded
+	_, err = cache.getLedgers(archive, 128, 130)
+	assert.Error(t, err)
+}
+
+func TestHistoryCacheReplacesCorruptCheckpoints(t *testing.T) {
+	archive, archiveDir := newTestArchive(t)
+	writeArchiveCheckpoint(t, archiveDir, 63, 62, 63)
+	cache := historyCache{dir: t.TempDir(), archiveURLs: []string{"file://" + archiveDir}}
+
+	path := cache.checkpointPath(63)
+	assert.NoError(t, os.WriteFile(path, []byte("not gzipped xdr"), 0644))
+	_, err := readCachedCheckpoint(path)
+	assert.Error(t, err)
+
+	ledgers, err := cache.getLedgers(archive, 62, 63)
+	assert.NoError(t, err)
+	assert.Len(t, ledgers, 2)
+
+	cached, err := readCachedCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ledgers, cached)
+}
+
+func TestReadCachedCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := readCachedCheckpoint(filepath.Join(dir, "missing.xdr.gz"))
+	assert.True(t, os.IsNotExist(err))
+
+	ledgers := map[uint32]*historyarchive.Ledger{
+		10: {Header: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 10}}, Transaction: xdr.TransactionHistoryEntry{LedgerSeq: 10}},
+		11: {Header: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 11}}, TransactionResult: xdr.TransactionHistoryResultEntry{LedgerSeq: 11}},
+	}
+	path := filepath.Join(dir, "checkpoint.xdr.gz")
+	assert.NoError(t, writeCachedCheckpoint(path, ledgers))
+	cached, err := readCachedCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ledgers, cached)
+
+	// a checkpoint cut off in the middle of a ledger is reported instead of returning part of its ledgers
+	truncated := filepath.Join(dir, "truncated.xdr.gz")
+	file, err := os.Create(truncated)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	assert.NoError(t, xdr.MarshalFramed(gz, ledgers[10].Header))
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, file.Close())
+	_, err = readCachedCheckpoint(truncated)
+	assert.Error(t, err)
+
+	assert.Error(t, writeCachedCheckpoint(filepath.Join(dir, "missing", "checkpoint.xdr.gz"), ledgers))
+}
+
+func TestFetchCheckpoint(t *testing.T) {
+	archive, archiveDir := newTestArchive(t)
+	writeArchiveCheckpoint(t, archiveDir, 63, 62, 63)
+
+	ledgers, err := fetchCheckpoint(archive, 63)
+	assert.NoError(t, err)
+	assert.Len(t, ledgers, 2)
+	assert.Equal(t, xdr.Uint32(62), ledgers[62].Header.Header.LedgerSeq)
+	assert.Equal(t, xdr.Uint32(62), ledgers[62].Transaction.LedgerSeq)
+	assert.Equal(t, xdr.Uint32(62), ledgers[62].TransactionResult.LedgerSeq)
+
+	assert.NoError(t, os.Remove(filepath.Join(archiveDir, historyarchive.CategoryCheckpointPath("results", 63))))
+	_, err = fetchCheckpoint(archive, 63)
+	assert.ErrorContains(t, err, "could not open the results file of checkpoint 63")
+}
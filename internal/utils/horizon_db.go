@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// registers the postgres driver used to read the Horizon database
+	_ "github.com/lib/pq"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+)
+
+// horizonDBPollInterval is how long GetLedger waits before looking again for a ledger that Horizon has not ingested yet
+const horizonDBPollInterval = time.Second
+
+// HorizonDBBackend is a ledger backend reading ledgers from the history tables of a Horizon Postgres database. It rebuilds
+// the ledger close meta of every ledger from its header and the envelope, result and meta of its transactions, so the
+// exports get the same rows as with captive core or the datastore. Horizon does not store the upgrades, evictions or the
+// layout of the transaction set, so these are missing from the rebuilt ledgers, and the transaction meta is only
+// available when Horizon was not configured to skip it.
+type HorizonDBBackend struct {
+	db       *sql.DB
+	prepared *ledgerbackend.Range
+}
+
+// NewHorizonDBBackend connects to the Horizon database at the given postgres url
+func NewHorizonDBBackend(ctx context.Context, url string) (*HorizonDBBackend, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the horizon database: %v", err)
+	}
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to the horizon database: %v", err)
+	}
+
+	return &HorizonDBBackend{db: db}, nil
+}
+
+// GetLatestLedgerSequence returns the last ledger ingested by Horizon
+func (b *HorizonDBBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	var sequence sql.NullInt64
+	if err := b.db.QueryRowContext(ctx, "SELECT MAX(sequence) FROM history_ledgers").Scan(&sequence); err != nil {
+		return 0, fmt.Errorf("could not get the latest ledger of the horizon database: %v", err)
+	}
+	if !sequence.Valid {
+		return 0, fmt.Errorf("the horizon database has no ledgers")
+	}
+
+	return uint32(sequence.Int64), nil
+}
+
+// PrepareRange checks that Horizon ingested every ledger of a bounded range, or the first ledger of an unbounded one
+func (b *HorizonDBBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	bounds, err := ledgerRangeBounds(ledgerRange)
+	if err != nil {
+		return err
+	}
+	to := bounds.From
+	if bounds.Bounded {
+		to = bounds.To
+	}
+
+	var count uint32
+	err = b.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM history_ledgers WHERE sequence BETWEEN $1 AND $2", bounds.From, to).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("could not count the ledgers of the horizon database: %v", err)
+	}
+	if expected := to - bounds.From + 1; count != expected {
+		return fmt.Errorf("the horizon database only has %d of the %d ledgers in [%d, %d]", count, expected, bounds.From, to)
+	}
+
+	b.prepared = &ledgerRange
+	return nil
+}
+
+// IsPrepared returns true if the range was prepared, or is included in the prepared range
+func (b *HorizonDBBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	if b.prepared == nil {
+		return false, nil
+	}
+
+	return b.prepared.Contains(ledgerRange), nil
+}
+
+// rangeBounds are the bounds of a ledgerbackend.Range, which does not export them
+type rangeBounds struct {
+	From    uint32 `json:"from"`
+	To      uint32 `json:"to"`
+	Bounded bool   `json:"bounded"`
+}
+
+// ledgerRangeBounds returns the bounds of a range, read from its JSON encoding
+func ledgerRangeBounds(ledgerRange ledgerbackend.Range) (rangeBounds, error) {
+	var bounds rangeBounds
+	encoded, err := ledgerRange.MarshalJSON()
+	if err == nil {
+		err = json.Unmarshal(encoded, &bounds)
+	}
+	if err != nil {
+		return rangeBounds{}, fmt.Errorf("could not read the bounds of range %s: %v", ledgerRange, err)
+	}
+
+	return bounds, nil
+}
+
+// GetLedger rebuilds the ledger close meta of a ledger, waiting for Horizon to ingest it if needed
+func (b *HorizonDBBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	for {
+		lcm, found, err := b.getLedger(ctx, sequence)
+		if err != nil || found {
+			return lcm, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return xdr.LedgerCloseMeta{}, ctx.Err()
+		case <-time.After(horizonDBPollInterval):
+		}
+	}
+}
+
+// Close closes the connection to the database
+func (b *HorizonDBBackend) Close() error {
+	return b.db.Close()
+}
+
+func (b *HorizonDBBackend) getLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, bool, error) {
+	var ledgerHash, ledgerHeader string
+	err := b.db.QueryRowContext(ctx, "SELECT ledger_hash, ledger_header FROM history_ledgers WHERE sequence = $1", sequence).Scan(&ledgerHash, &ledgerHeader)
+	if err == sql.ErrNoRows {
+		return xdr.LedgerCloseMeta{}, false, nil
+	}
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not read ledger %d from the horizon database: %v", sequence, err)
+	}
+
+	var lhe xdr.LedgerHeaderHistoryEntry
+	if err = xdr.SafeUnmarshalBase64(ledgerHeader, &lhe.Header); err != nil {
+		return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not decode the header of ledger %d: %v", sequence, err)
+	}
+	if lhe.Hash, err = horizonDBHash(ledgerHash); err != nil {
+		return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not decode the hash of ledger %d: %v", sequence, err)
+	}
+
+	rows, err := b.db.QueryContext(ctx, `SELECT transaction_hash, tx_envelope, tx_result, tx_meta, tx_fee_meta
+		FROM history_transactions WHERE ledger_sequence = $1 ORDER BY application_order`, sequence)
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not read the transactions of ledger %d from the horizon database: %v", sequence, err)
+	}
+	defer rows.Close()
+
+	var envelopes []xdr.TransactionEnvelope
+	var processing []xdr.TransactionResultMeta
+	for rows.Next() {
+		var txHash, txEnvelope, txResult, txMeta, txFeeMeta string
+		if err = rows.Scan(&txHash, &txEnvelope, &txResult, &txMeta, &txFeeMeta); err != nil {
+			return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not read a transaction of ledger %d: %v", sequence, err)
+		}
+
+		envelope, resultMeta, err := horizonDBTransaction(txHash, txEnvelope, txResult, txMeta, txFeeMeta)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not decode transaction %s of ledger %d: %v", txHash, sequence, err)
+		}
+		envelopes = append(envelopes, envelope)
+		processing = append(processing, resultMeta)
+	}
+	if err = rows.Err(); err != nil {
+		return xdr.LedgerCloseMeta{}, false, fmt.Errorf("could not read the transactions of ledger %d: %v", sequence, err)
+	}
+
+	return horizonDBLedgerCloseMeta(lhe, envelopes, processing), true, nil
+}
+
+func horizonDBTransaction(txHash, txEnvelope, txResult, txMeta, txFeeMeta string) (xdr.TransactionEnvelope, xdr.TransactionResultMeta, error) {
+	var envelope xdr.TransactionEnvelope
+	var resultMeta xdr.TransactionResultMeta
+	var err error
+
+	if resultMeta.Result.TransactionHash, err = horizonDBHash(txHash); err != nil {
+		return envelope, resultMeta, err
+	}
+	if err = xdr.SafeUnmarshalBase64(txEnvelope, &envelope); err != nil {
+		return envelope, resultMeta, fmt.Errorf("invalid envelope: %v", err)
+	}
+	if err = xdr.SafeUnmarshalBase64(txResult, &resultMeta.Result.Result); err != nil {
+		return envelope, resultMeta, fmt.Errorf("invalid result: %v", err)
+	}
+	if txMeta == "" {
+		return envelope, resultMeta, fmt.Errorf("the transaction meta is missing, horizon may be configured to skip it")
+	}
+	if err = xdr.SafeUnmarshalBase64(txMeta, &resultMeta.TxApplyProcessing); err != nil {
+		return envelope, resultMeta, fmt.Errorf("invalid meta: %v", err)
+	}
+	if err = xdr.SafeUnmarshalBase64(txFeeMeta, &resultMeta.FeeProcessing); err != nil {
+		return envelope, resultMeta, fmt.Errorf("invalid fee meta: %v", err)
+	}
+
+	return envelope, resultMeta, nil
+}
+
+// horizonDBLedgerCloseMeta builds the ledger close meta of a ledger in the version matching its protocol. From protocol 20
+// on, the transaction set is generalized with the classic transactions in the first phase and the Soroban ones in the
+// second, but the base fees and components of the original set are not known.
+func horizonDBLedgerCloseMeta(lhe xdr.LedgerHeaderHistoryEntry, envelopes []xdr.TransactionEnvelope, processing []xdr.TransactionResultMeta) xdr.LedgerCloseMeta {
+	if lhe.Header.LedgerVersion < 20 {
+		return xdr.LedgerCloseMeta{
+			V: 0,
+			V0: &xdr.LedgerCloseMetaV0{
+				LedgerHeader: lhe,
+				TxSet: xdr.TransactionSet{
+					PreviousLedgerHash: lhe.Header.PreviousLedgerHash,
+					Txs:                envelopes,
+				},
+				TxProcessing: processing,
+			},
+		}
+	}
+
+	var classic, soroban []xdr.TransactionEnvelope
+	for _, envelope := range envelopes {
+		var hasSorobanData bool
+		switch envelope.Type {
+		case xdr.EnvelopeTypeEnvelopeTypeTx:
+			_, hasSorobanData = envelope.V1.Tx.Ext.GetSorobanData()
+		case xdr.EnvelopeTypeEnvelopeTypeTxFeeBump:
+			_, hasSorobanData = envelope.FeeBump.Tx.InnerTx.V1.Tx.Ext.GetSorobanData()
+		}
+
+		if hasSorobanData {
+			soroban = append(soroban, envelope)
+		} else {
+			classic = append(classic, envelope)
+		}
+	}
+
+	phases := []xdr.TransactionPhase{}
+	for _, txs := range [][]xdr.TransactionEnvelope{classic, soroban} {
+		components := []xdr.TxSetComponent{}
+		if len(txs) > 0 {
+			components = append(components, xdr.TxSetComponent{
+				Type:                  xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+				TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{Txs: txs},
+			})
+		}
+		phases = append(phases, xdr.TransactionPhase{V: 0, V0Components: &components})
+	}
+
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: lhe,
+			TxSet: xdr.GeneralizedTransactionSet{
+				V: 1,
+				V1TxSet: &xdr.TransactionSetV1{
+					PreviousLedgerHash: lhe.Header.PreviousLedgerHash,
+					Phases:             phases,
+				},
+			},
+			TxProcessing: processing,
+		},
+	}
+}
+
+func horizonDBHash(hexHash string) (xdr.Hash, error) {
+	var hash xdr.Hash
+	decoded, err := hex.DecodeString(hexHash)
+	if err != nil {
+		return hash, err
+	}
+	if len(decoded) != len(hash) {
+		return hash, fmt.Errorf("hash %s is not 32 bytes long", hexHash)
+	}
+	copy(hash[:], decoded)
+
+	return hash, nil
+}
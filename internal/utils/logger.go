@@ -1,23 +1,81 @@
 package utils
 
-import "github.com/stellar/go/support/log"
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/stellar/go/support/log"
+)
 
 type EtlLogger struct {
 	*log.Entry
 	StrictExport bool
+	// StrictSchema makes the errors about unknown XDR variants fatal even when StrictExport is not set
+	StrictSchema bool
+	// OnExit, when set, is called with the exit code, the category and the message of the error before a fatal error exits
+	OnExit func(code int, category ErrorCategory, message string)
+
+	mu           sync.Mutex
+	loggedErrors map[ErrorCategory]int
 }
 
 func NewEtlLogger() *EtlLogger {
 	return &EtlLogger{
-		log.New(),
-		false,
+		Entry:        log.New(),
+		loggedErrors: map[ErrorCategory]int{},
 	}
 }
 
 func (l *EtlLogger) LogError(err error) {
-	if l.StrictExport {
+	if l.StrictExport || (l.StrictSchema && IsUnknownVariant(err)) {
 		l.Fatal(err)
 	} else {
-		l.Error(err)
+		category := ErrorCategoryOf(err)
+		l.mu.Lock()
+		l.loggedErrors[category]++
+		l.mu.Unlock()
+		l.WithField("error_category", category).Error(err)
+	}
+}
+
+// LoggedErrors returns the number of non-fatal errors logged with LogError, by category
+func (l *EtlLogger) LoggedErrors() map[ErrorCategory]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := map[ErrorCategory]int{}
+	for category, count := range l.loggedErrors {
+		counts[category] = count
+	}
+	return counts
+}
+
+// Fatal logs the message and exits with the exit code of the category of the first error in args
+func (l *EtlLogger) Fatal(args ...interface{}) {
+	l.Abort(errorCategoryOfArgs(args), fmt.Sprint(args...))
+}
+
+// Fatalf logs the formatted message and exits with the exit code of the category of the first error in args
+func (l *EtlLogger) Fatalf(format string, args ...interface{}) {
+	l.Abort(errorCategoryOfArgs(args), fmt.Sprintf(format, args...))
+}
+
+// Abort logs the message along with its category and exits with the exit code of the category
+func (l *EtlLogger) Abort(category ErrorCategory, message string) {
+	code := category.ExitCode()
+	l.WithField("error_category", category).WithField("exit_code", code).Error(message)
+	if l.OnExit != nil {
+		l.OnExit(code, category, message)
+	}
+	os.Exit(code)
+}
+
+func errorCategoryOfArgs(args []interface{}) ErrorCategory {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			return ErrorCategoryOf(err)
+		}
 	}
+	return ErrorCategoryUnknown
 }
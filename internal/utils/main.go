@@ -5,8 +5,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/spf13/pflag"
 
@@ -15,13 +20,38 @@ import (
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/ingest/ledgerbackend"
 	"github.com/stellar/go/keypair"
-	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/datastore"
 	"github.com/stellar/go/support/storage"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
 )
 
+var repeatedUnderscores = regexp.MustCompile("_+")
+
+// ToLowerSnakeCase converts a column name such as "ledgerKeyHash" or "Ledger-Key Hash" to "ledger_key_hash"
+func ToLowerSnakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Start a new word on a lower to upper transition, or at the last capital of an acronym ("TxID" -> "tx_id")
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteRune('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+
+	return strings.Trim(repeatedUnderscores.ReplaceAllString(builder.String(), "_"), "_")
+}
+
 // PanicOnError is a function that panics if the provided error is not nil
 func PanicOnError(err error) {
 	if err != nil {
@@ -52,6 +82,26 @@ func GetAccountAddressFromMuxedAccount(account xdr.MuxedAccount) (string, error)
 	return pointerToID.GetAddress()
 }
 
+// ScAddressToStrkey encodes a Soroban address as a G... account address or a C... contract address
+func ScAddressToStrkey(address xdr.ScAddress) (string, error) {
+	switch address.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		accountID, ok := address.GetAccountId()
+		if !ok {
+			return "", errors.New("account address is missing its account id")
+		}
+		return accountID.GetAddress()
+	case xdr.ScAddressTypeScAddressTypeContract:
+		contractID, ok := address.GetContractId()
+		if !ok {
+			return "", errors.New("contract address is missing its contract id")
+		}
+		return strkey.Encode(strkey.VersionByteContract, contractID[:])
+	default:
+		return "", fmt.Errorf("unknown address type %s", address.Type)
+	}
+}
+
 // CreateSampleTx creates a transaction with a single operation (BumpSequence), the min base fee, and infinite timebounds
 func CreateSampleTx(sequence int64, operationCount int) xdr.TransactionEnvelope {
 	kp, err := keypair.Random()
@@ -86,6 +136,17 @@ func ConvertStroopValueToReal(input xdr.Int64) float64 {
 	return output
 }
 
+// priceDecimalPlaces is the number of decimal places of the prices formatted by PriceToDecimalString
+const priceDecimalPlaces = 18
+
+// PriceToDecimalString formats the price n/d as a decimal string rounded to 18 decimal places, without trailing zeros.
+// The division is exact up to the rounding, unlike a float64 division.
+func PriceToDecimalString(n, d int64) string {
+	decimal := new(big.Rat).SetFrac64(n, d).FloatString(priceDecimalPlaces)
+	decimal = strings.TrimRight(decimal, "0")
+	return strings.TrimSuffix(decimal, ".")
+}
+
 // CreateSampleResultMeta creates Transaction results with the desired success flag and number of sub operation results
 func CreateSampleResultMeta(successful bool, subOperationCount int) xdr.TransactionResultMeta {
 	resultCode := xdr.TransactionResultCodeTxFailed
@@ -231,21 +292,81 @@ func AddLPOperations(txMeta []xdr.OperationMeta, AssetA, AssetB xdr.Asset) []xdr
 func AddCommonFlags(flags *pflag.FlagSet) {
 	flags.Uint32P("end-ledger", "e", 0, "The ledger sequence number for the end of the export range")
 	flags.Bool("strict-export", false, "If set, transform errors will be fatal.")
+	flags.Bool("strict", false, "If set, unknown XDR variants, such as the operation types, ScVal types, config settings or ledger entry "+
+		"types of a newer protocol, are fatal errors instead of being skipped or written as best-effort rows.")
 	flags.Bool("testnet", false, "If set, will connect to Testnet instead of Mainnet.")
 	flags.Bool("futurenet", false, "If set, will connect to Futurenet instead of Mainnet.")
+	flags.StringArray("network", nil, "Network to export: pubnet, testnet or futurenet. export_ledger_entry_changes, export_account_data and export_contract_nonces "+
+		"accept the flag several times to export the networks at once, each into a subfolder of the output named after it, and a "+
+		":start or :start-end suffix overriding the ledger range of the network, e.g. --network pubnet:50000000 --network testnet:1000. "+
+		"Other commands accept a single network.")
 	flags.StringToStringP("extra-fields", "u", map[string]string{}, "Additional fields to append to output jsons. Used for appending metadata")
 	flags.Bool("captive-core", false, "If set, run captive core to retrieve data. Otherwise use TxMeta file datastore.")
 	flags.String("datastore-path", "sdf-ledger-close-metas/ledgers", "Datastore bucket path to read txmeta files from.")
 	flags.Uint32("buffer-size", 5, "Buffer size sets the max limit for the number of txmeta files that can be held in memory.")
 	flags.Uint32("num-workers", 5, "Number of workers to spawn that read txmeta files from the datastore.")
 	flags.Uint32("retry-limit", 3, "Datastore GetLedger retry limit.")
+	flags.Bool("auto-tune", false, "If set, num-workers and buffer-size default to values sized from the available CPU and memory, and "+
+		"batch sizes adapt to the sink latency and memory pressure.")
 	flags.Uint32("retry-wait", 5, "Time in seconds to wait for GetLedger retry.")
+	flags.String("null-semantics", NullSemanticsPreserve, "How absent values are written. 'preserve' keeps the default output, 'zero' writes nulls as zero values "+
+		"(0, \"\", false, []) and 'null' writes empty strings and arrays as null.")
+	flags.String("warehouse", "", "Target warehouse preset for the output. 'snowflake' writes gzipped NDJSON with lower_snake_case columns "+
+		"and Snowflake timestamps, sized for COPY INTO. Defaults to the BigQuery compatible output.")
+	flags.String("partition-layout", "", "Directory layout of the output files. 'hive' places files under dt=YYYY-MM-DD/ledger_range=start-end/ "+
+		"so they can be queried as a partitioned external table.")
+	flags.String("partition-by", "", "If set to 'day', rows are routed into one file per UTC ledger close date, even when the ledger range spans many days.")
+	flags.Bool("include-xdr", false, "If set, operations get an operation_body_xdr column and ledger entry changes get a ledger_entry_xdr column "+
+		"holding the base64 XDR each row was transformed from.")
+	flags.Bool("asset-contract-ids", false, "If set, every asset_type column gets an asset_contract_id column next to it, holding the "+
+		"Stellar Asset Contract id of the asset on the selected network.")
+	flags.String("well-known-assets", "", "If set, a TOML or CSV file of well-known assets whose domain, anchor name and decimals are added "+
+		"next to every asset_type column of a matching asset.")
+	flags.String("address-labels", "", "If set, a TOML or CSV file of labelled addresses, such as exchange wallets or known contracts, whose labels "+
+		"are added next to every address column, e.g. source_account_label and to_label.")
+	flags.Bool("batch-metadata", false, "If set, every row gets the batch_id, batch_run_date, batch_insert_ts and etl_version columns, "+
+		"so that loads can be traced back to the run that wrote them.")
+	flags.String("batch-id", "", "The batch_id written by batch-metadata. Defaults to the ledger range of the export, e.g. 100-200.")
+	flags.String("batch-run-date", "", "The RFC3339 batch_run_date written by batch-metadata, e.g. the logical date of the "+
+		"scheduler run. Defaults to the time the command started.")
+	flags.String("history-cache-dir", "", "If set, the history archive checkpoints read by the commands exporting from the archives "+
+		"are cached in this directory, so that overlapping ranges are not downloaded again by later runs.")
+	flags.String("horizon-db-url", "", "If set, ledgers are read from the history tables of this Horizon Postgres database instead of captive core "+
+		"or the datastore, e.g. to fill gaps of ranges the datastore or archives cannot serve.")
+	flags.String("rpc-url", "", "If set, ledgers are read from the getLedgers method of this Soroban RPC server instead of captive core "+
+		"or the datastore. Only the ledgers in the retention window of the server can be exported.")
+	flags.String("sample", "", "If set to 1/N, only the transactions whose hash modulo N is 0 are exported, along with their operations, "+
+		"effects, trades and events. The same transactions are sampled by every run and command.")
+	flags.String("redact", "", "If set, free-text values (text memos, manage data values and contract event bodies) and the raw XDR "+
+		"columns holding them are redacted. 'hash' replaces them by their SHA-256 hash and 'drop' writes them as null.")
+	flags.Bool("align-checkpoints", false, "If set, the start-ledger is moved back to the first ledger of its checkpoint and the end-ledger "+
+		"forward to the last ledger of its checkpoint, so that the exported range covers whole 64 ledger checkpoints.")
+	flags.String("timestamp-format", TimestampFormatRFC3339, "Format of all timestamp columns. 'rfc3339' writes UTC RFC3339 strings, "+
+		"'epoch_seconds' and 'epoch_micros' write integers since the unix epoch.")
+	flags.Int64("max-file-rows", 0, "If set, output files are rotated into sequence numbered files of at most this many rows, e.g. 1-65-ledgers.0.txt, 1-65-ledgers.1.txt")
+	flags.Int64("max-file-bytes", 0, "If set, output files are rotated into sequence numbered files of at most this many bytes, e.g. 268435456 for 256MB")
+	flags.Float64("max-download-mbps", 0, "If set, the ledgers downloaded from the history archives and the datastore are read at most "+
+		"at this many megabits per second, shared by all parallel downloads")
+	flags.Float64("max-upload-mbps", 0, "If set, the files uploaded to the cloud storage bucket are sent at most at this many megabits per second, "+
+		"shared by all parallel uploads")
+	flags.Duration("rotate-interval", 0, "If set, output files are rotated into sequence numbered files each holding the rows of ledgers closed in one "+
+		"interval of this length, e.g. 1h")
+	flags.StringArray("webhook-header", nil, "Header sent with the rows posted to a webhook output, e.g. 'Authorization: Bearer $TOKEN'. "+
+		"Environment variables in the value are expanded. Can be set several times")
+	flags.String("webhook-template", "", "Go template of the body posted to a webhook output for each batch of rows, "+
+		"with the fields .Table, .Count and .Rows, the JSON array of the rows. Defaults to {\"table\": \"{{.Table}}\", \"rows\": {{.Rows}}}")
+	flags.String("stream-partition-key", StreamPartitionKeyLedger, "Column the rows sent to a Kinesis or Event Hubs output are partitioned by: "+
+		"ledger, account or contract. Rows without the column are partitioned by their ledger")
 }
 
+// databaseOutputUsage completes the usage of the output flag with the outputs appending the rows to a local database
+const databaseOutputUsage = ", or duckdb://<file> or sqlite://<file> to append the rows to a DuckDB or SQLite database with the duckdb or sqlite3 CLI, " +
+	"which has to be on the PATH"
+
 // AddArchiveFlags adds the history archive specific flags: start-ledger, output, and limit
 func AddArchiveFlags(objectName string, flags *pflag.FlagSet) {
 	flags.Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the export period. Defaults to genesis ledger")
-	flags.StringP("output", "o", "exported_"+objectName+".txt", "Filename of the output file")
+	flags.StringP("output", "o", "exported_"+objectName+".txt", "Filename of the output file"+databaseOutputUsage)
 	flags.Int64P("limit", "l", -1, "Maximum number of "+objectName+" to export. If the limit is set to a negative number, all the objects in the provided range are exported")
 }
 
@@ -257,8 +378,8 @@ func AddBucketFlags(objectName string, flags *pflag.FlagSet) {
 // AddCloudStorageFlags adds the cloud storage releated flags: cloud-storage-bucket, cloud-credentials
 func AddCloudStorageFlags(flags *pflag.FlagSet) {
 	flags.String("cloud-storage-bucket", "stellar-etl-cli", "Cloud storage bucket to export to.")
-	flags.String("cloud-credentials", "", "Path to cloud provider service account credentials. Only used for local/dev purposes. "+
-		"When run on GCP, credentials should be inferred by service account json.")
+	flags.String("cloud-credentials", "", "Cloud provider credentials: a path or file:PATH to read them from a file, env:NAME to read them from an environment variable, "+
+		"or aws-role:ARN to assume an IAM role. Credentials are re-read on every upload. When empty, ambient credentials such as workload identity are used.")
 	flags.String("cloud-provider", "", "Cloud provider for storage services.")
 }
 
@@ -268,7 +389,7 @@ func AddCoreFlags(flags *pflag.FlagSet, defaultFolder string) {
 	flags.StringP("core-config", "c", "", "Filepath to the config file for stellar-core")
 
 	flags.Uint32P("batch-size", "b", 64, "number of ledgers to export changes from in each batches")
-	flags.StringP("output", "o", defaultFolder, "Folder that will contain the output files")
+	flags.StringP("output", "o", defaultFolder, "Folder that will contain the output files"+databaseOutputUsage)
 
 	flags.Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the export period. Defaults to genesis ledger")
 }
@@ -276,30 +397,129 @@ func AddCoreFlags(flags *pflag.FlagSet, defaultFolder string) {
 // AddExportTypeFlags adds the captive core specifc flags: export-{type} flags
 func AddExportTypeFlags(flags *pflag.FlagSet) {
 	flags.BoolP("export-accounts", "a", false, "set in order to export account changes")
+	flags.BoolP("export-account-data", "", false, "set in order to export account data entry changes")
 	flags.BoolP("export-trustlines", "t", false, "set in order to export trustline changes")
 	flags.BoolP("export-offers", "f", false, "set in order to export offer changes")
 	flags.BoolP("export-pools", "p", false, "set in order to export liquidity pool changes")
 	flags.BoolP("export-balances", "l", false, "set in order to export claimable balance changes")
 	flags.BoolP("export-contract-code", "", false, "set in order to export contract code changes")
 	flags.BoolP("export-contract-data", "", false, "set in order to export contract data changes")
+	flags.BoolP("export-contract-nonces", "", false, "set in order to export contract nonce changes; not included when no export flags are set")
 	flags.BoolP("export-config-settings", "", false, "set in order to export config settings changes")
 	flags.BoolP("export-ttl", "", false, "set in order to export ttl changes")
+	flags.BoolP("export-evicted-entries", "", false, "set in order to export the keys of the entries evicted from the bucket list")
 }
 
 type CommonFlagValues struct {
-	EndNum         uint32
-	StrictExport   bool
-	IsTest         bool
-	IsFuture       bool
-	Extra          map[string]string
-	UseCaptiveCore bool
-	DatastorePath  string
-	BufferSize     uint32
-	NumWorkers     uint32
-	RetryLimit     uint32
-	RetryWait      uint32
+	EndNum           uint32
+	StrictExport     bool
+	StrictSchema     bool
+	IsTest           bool
+	IsFuture         bool
+	Passphrase       string
+	Extra            map[string]string
+	UseCaptiveCore   bool
+	DatastorePath    string
+	BufferSize       uint32
+	NumWorkers       uint32
+	RetryLimit       uint32
+	RetryWait        uint32
+	NullSemantics    string
+	Warehouse        string
+	PartitionLayout  string
+	PartitionBy      string
+	AutoTune         bool
+	IncludeXDR       bool
+	TimestampFormat  string
+	AssetContractIDs bool
+	WellKnownAssets  map[string]WellKnownAsset
+	AddressLabels    map[string]string
+	BatchMetadata    bool
+	BatchID          string
+	BatchRunDate     time.Time
+	HistoryCacheDir  string
+	HorizonDBURL     string
+	RPCURL           string
+	SampleRate       uint32
+	Networks         []NetworkRange
+	AlignCheckpoints bool
+	Redact           string
+	MaxFileRows      int64
+	MaxFileBytes     int64
+	RotateInterval   time.Duration
+	MaxDownloadMbps  float64
+	MaxUploadMbps    float64
+	WebhookHeaders   []string
+	WebhookTemplate  string
+	StreamPartition  string
+}
+
+// Accepted values for the null-semantics flag
+const (
+	NullSemanticsPreserve = "preserve"
+	NullSemanticsZero     = "zero"
+	NullSemanticsNull     = "null"
+)
+
+// Accepted values for the network flag
+const (
+	NetworkPubnet    = "pubnet"
+	NetworkTestnet   = "testnet"
+	NetworkFuturenet = "futurenet"
+)
+
+// NetworkRange is a network selected with the network flag, along with the ledger range exported from it. A zero Start
+// means that the range of the start-ledger and end-ledger flags is used, and a zero End that the export is unbounded.
+type NetworkRange struct {
+	Network string
+	Start   uint32
+	End     uint32
 }
 
+// Accepted values for the redact flag. An empty value exports the values as they are.
+const (
+	RedactNone = ""
+	RedactHash = "hash"
+	RedactDrop = "drop"
+)
+
+// Accepted values for the stream-partition-key flag
+const (
+	StreamPartitionKeyLedger   = "ledger"
+	StreamPartitionKeyAccount  = "account"
+	StreamPartitionKeyContract = "contract"
+)
+
+// Accepted values for the timestamp-format flag
+const (
+	TimestampFormatRFC3339      = "rfc3339"
+	TimestampFormatEpochSeconds = "epoch_seconds"
+	TimestampFormatEpochMicros  = "epoch_micros"
+)
+
+// Accepted values for the warehouse flag. An empty warehouse keeps the default BigQuery compatible output.
+const (
+	WarehouseBigQuery  = ""
+	WarehouseSnowflake = "snowflake"
+	WarehouseRedshift  = "redshift"
+	WarehouseAthena    = "athena"
+)
+
+// WarehouseBigQueryAlias selects the default BigQuery compatible output by name, e.g. to generate BigQuery DDL
+const WarehouseBigQueryAlias = "bigquery"
+
+// Accepted values for the partition-layout flag. An empty layout writes files directly to the output path.
+const (
+	PartitionLayoutNone = ""
+	PartitionLayoutHive = "hive"
+)
+
+// Accepted values for the partition-by flag. An empty value writes all rows to a single file.
+const (
+	PartitionByNone = ""
+	PartitionByDay  = "day"
+)
+
 // MustCommonFlags gets the values of the the flags common to all commands: end-ledger and strict-export.
 // If any do not exist, it stops the program fatally using the logger
 func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
@@ -313,15 +533,14 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Fatal("could not get strict-export boolean: ", err)
 	}
 
-	isTest, err := flags.GetBool("testnet")
+	strictSchema, err := flags.GetBool("strict")
 	if err != nil {
-		logger.Fatal("could not get testnet boolean: ", err)
+		logger.Fatal("could not get strict boolean: ", err)
 	}
+	StrictSchema = strictSchema
+	logger.StrictSchema = strictSchema
 
-	isFuture, err := flags.GetBool("futurenet")
-	if err != nil {
-		logger.Fatal("could not get futurenet boolean: ", err)
-	}
+	isTest, isFuture := MustNetworkFlags(flags, logger)
 
 	extra, err := flags.GetStringToString("extra-fields")
 	if err != nil {
@@ -348,6 +567,23 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Fatal("could not get num-workers uint32: ", err)
 	}
 
+	autoTune, err := flags.GetBool("auto-tune")
+	if err != nil {
+		logger.Fatal("could not get auto-tune boolean: ", err)
+	}
+
+	// Explicit worker and buffer sizes take precedence over the auto-tuned ones
+	if autoTune {
+		tunedWorkers, tunedBufferSize := AutoTuneDatastoreWorkers()
+		if !flags.Changed("num-workers") {
+			numWorkers = tunedWorkers
+		}
+		if !flags.Changed("buffer-size") {
+			bufferSize = tunedBufferSize
+		}
+		logger.Infof("auto-tune: using %d datastore workers and a buffer of %d ledgers", numWorkers, bufferSize)
+	}
+
 	retryLimit, err := flags.GetUint32("retry-limit")
 	if err != nil {
 		logger.Fatal("could not get retry-limit uint32: ", err)
@@ -358,27 +594,391 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Fatal("could not get retry-wait uint32: ", err)
 	}
 
+	nullSemantics, err := flags.GetString("null-semantics")
+	if err != nil {
+		logger.Fatal("could not get null-semantics string: ", err)
+	}
+
+	switch nullSemantics {
+	case NullSemanticsPreserve, NullSemanticsZero, NullSemanticsNull:
+	default:
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid null-semantics %q: must be one of %s, %s or %s", nullSemantics, NullSemanticsPreserve, NullSemanticsZero, NullSemanticsNull))
+	}
+
+	warehouse, err := flags.GetString("warehouse")
+	if err != nil {
+		logger.Fatal("could not get warehouse string: ", err)
+	}
+
+	if warehouse == WarehouseBigQueryAlias {
+		warehouse = WarehouseBigQuery
+	}
+
+	switch warehouse {
+	case WarehouseBigQuery, WarehouseRedshift, WarehouseAthena:
+	case WarehouseSnowflake:
+		// Snowflake treats empty strings as values rather than missing data, so default to writing them as null
+		if !flags.Changed("null-semantics") {
+			nullSemantics = NullSemanticsNull
+		}
+	default:
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid warehouse %q: must be one of %s, %s, %s or %s", warehouse, WarehouseBigQueryAlias, WarehouseSnowflake, WarehouseRedshift, WarehouseAthena))
+	}
+
+	partitionLayout, err := flags.GetString("partition-layout")
+	if err != nil {
+		logger.Fatal("could not get partition-layout string: ", err)
+	}
+
+	if partitionLayout != PartitionLayoutNone && partitionLayout != PartitionLayoutHive {
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid partition-layout %q: only %s is supported", partitionLayout, PartitionLayoutHive))
+	}
+
+	partitionBy, err := flags.GetString("partition-by")
+	if err != nil {
+		logger.Fatal("could not get partition-by string: ", err)
+	}
+
+	if partitionBy != PartitionByNone && partitionBy != PartitionByDay {
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid partition-by %q: only %s is supported", partitionBy, PartitionByDay))
+	}
+
+	includeXDR, err := flags.GetBool("include-xdr")
+	if err != nil {
+		logger.Fatal("could not get include-xdr boolean: ", err)
+	}
+
+	timestampFormat, err := flags.GetString("timestamp-format")
+	if err != nil {
+		logger.Fatal("could not get timestamp-format string: ", err)
+	}
+
+	switch timestampFormat {
+	case TimestampFormatRFC3339, TimestampFormatEpochSeconds, TimestampFormatEpochMicros:
+	default:
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid timestamp-format %q: must be one of %s, %s or %s", timestampFormat, TimestampFormatRFC3339, TimestampFormatEpochSeconds, TimestampFormatEpochMicros))
+	}
+
+	assetContractIDs, err := flags.GetBool("asset-contract-ids")
+	if err != nil {
+		logger.Fatal("could not get asset-contract-ids boolean: ", err)
+	}
+
+	wellKnownAssetsPath, err := flags.GetString("well-known-assets")
+	if err != nil {
+		logger.Fatal("could not get well-known-assets string: ", err)
+	}
+
+	var wellKnownAssets map[string]WellKnownAsset
+	if wellKnownAssetsPath != "" {
+		wellKnownAssets, err = LoadWellKnownAssets(wellKnownAssetsPath)
+		if err != nil {
+			logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid well-known-assets file: %v", err))
+		}
+	}
+
+	addressLabelsPath, err := flags.GetString("address-labels")
+	if err != nil {
+		logger.Fatal("could not get address-labels string: ", err)
+	}
+
+	var addressLabels map[string]string
+	if addressLabelsPath != "" {
+		addressLabels, err = LoadAddressLabels(addressLabelsPath)
+		if err != nil {
+			logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid address-labels file: %v", err))
+		}
+	}
+
+	batchMetadata, err := flags.GetBool("batch-metadata")
+	if err != nil {
+		logger.Fatal("could not get batch-metadata boolean: ", err)
+	}
+
+	batchID, err := flags.GetString("batch-id")
+	if err != nil {
+		logger.Fatal("could not get batch-id string: ", err)
+	}
+
+	batchRunDateString, err := flags.GetString("batch-run-date")
+	if err != nil {
+		logger.Fatal("could not get batch-run-date string: ", err)
+	}
+
+	batchRunDate := time.Now().UTC()
+	if batchRunDateString != "" {
+		batchRunDate, err = time.Parse(time.RFC3339, batchRunDateString)
+		if err != nil {
+			logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid batch-run-date %q: must be an RFC3339 timestamp", batchRunDateString))
+		}
+		batchRunDate = batchRunDate.UTC()
+	}
+	if batchID == "" {
+		batchID = defaultBatchID(flags, endNum, batchRunDate)
+	}
+
+	historyCacheDir, err := flags.GetString("history-cache-dir")
+	if err != nil {
+		logger.Fatal("could not get history-cache-dir string: ", err)
+	}
+
+	horizonDBURL, err := flags.GetString("horizon-db-url")
+	if err != nil {
+		logger.Fatal("could not get horizon-db-url string: ", err)
+	}
+
+	rpcURL, err := flags.GetString("rpc-url")
+	if err != nil {
+		logger.Fatal("could not get rpc-url string: ", err)
+	}
+
+	sample, err := flags.GetString("sample")
+	if err != nil {
+		logger.Fatal("could not get sample string: ", err)
+	}
+
+	sampleRate, err := ParseSampleRate(sample)
+	if err != nil {
+		logger.Abort(ErrorCategoryValidation, err.Error())
+	}
+
+	redact, err := flags.GetString("redact")
+	if err != nil {
+		logger.Fatal("could not get redact string: ", err)
+	}
+
+	switch redact {
+	case RedactNone, RedactHash, RedactDrop:
+	default:
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("invalid redact %q: must be one of %s or %s", redact, RedactHash, RedactDrop))
+	}
+
+	if horizonDBURL != "" && rpcURL != "" {
+		logger.Abort(ErrorCategoryValidation, "horizon-db-url and rpc-url cannot both be set")
+	}
+
+	alignCheckpoints, err := flags.GetBool("align-checkpoints")
+	if err != nil {
+		logger.Fatal("could not get align-checkpoints boolean: ", err)
+	}
+
+	if alignCheckpoints && endNum != 0 {
+		aligned := CheckpointAlignedEnd(endNum)
+		if aligned != endNum {
+			logger.Infof("align-checkpoints: end-ledger %d moved to %d, the last ledger of its checkpoint", endNum, aligned)
+		}
+		endNum = aligned
+	}
+
+	networkValues, err := flags.GetStringArray("network")
+	if err != nil {
+		logger.Fatal("could not get network strings: ", err)
+	}
+
+	networks := []NetworkRange{}
+	for _, value := range networkValues {
+		networkRange, err := ParseNetworkRange(value)
+		if err != nil {
+			logger.Abort(ErrorCategoryValidation, err.Error())
+		}
+		networks = append(networks, networkRange)
+	}
+
+	if len(networks) > 0 && (isTest || isFuture) {
+		logger.Abort(ErrorCategoryValidation, "network cannot be combined with testnet or futurenet")
+	}
+	if len(networks) > 1 && !AllowsSeveralNetworks(flags) {
+		logger.Abort(ErrorCategoryValidation, fmt.Sprintf("this command exports a single network, but %d were selected with network", len(networks)))
+	}
+	if len(networks) > 1 && (horizonDBURL != "" || rpcURL != "") {
+		logger.Abort(ErrorCategoryValidation, "horizon-db-url and rpc-url serve a single network and cannot be used with several networks")
+	}
+	if len(networks) == 1 {
+		isTest = networks[0].Network == NetworkTestnet
+		isFuture = networks[0].Network == NetworkFuturenet
+	}
+
+	maxFileRows, err := flags.GetInt64("max-file-rows")
+	if err != nil {
+		logger.Fatal("could not get max-file-rows int64: ", err)
+	}
+
+	maxFileBytes, err := flags.GetInt64("max-file-bytes")
+	if err != nil {
+		logger.Fatal("could not get max-file-bytes int64: ", err)
+	}
+
+	rotateInterval, err := flags.GetDuration("rotate-interval")
+	if err != nil {
+		logger.Fatal("could not get rotate-interval duration: ", err)
+	}
+
+	maxDownloadMbps, err := flags.GetFloat64("max-download-mbps")
+	if err != nil {
+		logger.Fatal("could not get max-download-mbps float64: ", err)
+	}
+
+	maxUploadMbps, err := flags.GetFloat64("max-upload-mbps")
+	if err != nil {
+		logger.Fatal("could not get max-upload-mbps float64: ", err)
+	}
+
+	webhookHeaders, err := flags.GetStringArray("webhook-header")
+	if err != nil {
+		logger.Fatal("could not get webhook-header: ", err)
+	}
+
+	webhookTemplate, err := flags.GetString("webhook-template")
+	if err != nil {
+		logger.Fatal("could not get webhook-template: ", err)
+	}
+
+	streamPartitionKey, err := flags.GetString("stream-partition-key")
+	if err != nil {
+		logger.Fatal("could not get stream-partition-key: ", err)
+	}
+
+	downloadLimiter = newBandwidthLimiter(maxDownloadMbps)
+	uploadLimiter = newBandwidthLimiter(maxUploadMbps)
+
+	// Athena tables generated by generate_ddl are partitioned, so files need the matching layout
+	if warehouse == WarehouseAthena && !flags.Changed("partition-layout") {
+		partitionLayout = PartitionLayoutHive
+	}
+
 	return CommonFlagValues{
-		EndNum:         endNum,
-		StrictExport:   strictExport,
-		IsTest:         isTest,
-		IsFuture:       isFuture,
-		Extra:          extra,
-		UseCaptiveCore: useCaptiveCore,
-		DatastorePath:  datastorePath,
-		BufferSize:     bufferSize,
-		NumWorkers:     numWorkers,
-		RetryLimit:     retryLimit,
-		RetryWait:      retryWait,
+		EndNum:           endNum,
+		StrictExport:     strictExport,
+		StrictSchema:     strictSchema,
+		IsTest:           isTest,
+		IsFuture:         isFuture,
+		Passphrase:       NetworkPresets[NetworkName(isTest, isFuture)].Passphrase,
+		Extra:            extra,
+		UseCaptiveCore:   useCaptiveCore,
+		DatastorePath:    datastorePath,
+		BufferSize:       bufferSize,
+		NumWorkers:       numWorkers,
+		RetryLimit:       retryLimit,
+		RetryWait:        retryWait,
+		NullSemantics:    nullSemantics,
+		Warehouse:        warehouse,
+		PartitionLayout:  partitionLayout,
+		PartitionBy:      partitionBy,
+		AutoTune:         autoTune,
+		IncludeXDR:       includeXDR,
+		TimestampFormat:  timestampFormat,
+		AssetContractIDs: assetContractIDs,
+		WellKnownAssets:  wellKnownAssets,
+		AddressLabels:    addressLabels,
+		BatchMetadata:    batchMetadata,
+		BatchID:          batchID,
+		BatchRunDate:     batchRunDate,
+		HistoryCacheDir:  historyCacheDir,
+		HorizonDBURL:     horizonDBURL,
+		RPCURL:           rpcURL,
+		SampleRate:       sampleRate,
+		Redact:           redact,
+		Networks:         networks,
+		AlignCheckpoints: alignCheckpoints,
+		MaxFileRows:      maxFileRows,
+		MaxFileBytes:     maxFileBytes,
+		RotateInterval:   rotateInterval,
+		MaxDownloadMbps:  maxDownloadMbps,
+		MaxUploadMbps:    maxUploadMbps,
+		WebhookHeaders:   webhookHeaders,
+		WebhookTemplate:  webhookTemplate,
+		StreamPartition:  streamPartitionKey,
 	}
 }
 
+// multiNetworkAnnotation marks the network flag of the commands that can export several networks at once, or override
+// the ledger range of a network
+const multiNetworkAnnotation = "multi-network"
+
+// AllowSeveralNetworks lets the network flag of the flags select several networks and their ledger ranges
+func AllowSeveralNetworks(flags *pflag.FlagSet) {
+	flags.SetAnnotation("network", multiNetworkAnnotation, []string{"true"})
+}
+
+// AllowsSeveralNetworks returns whether the network flag of the flags can select several networks and their ledger ranges
+func AllowsSeveralNetworks(flags *pflag.FlagSet) bool {
+	network := flags.Lookup("network")
+	return network != nil && len(network.Annotations[multiNetworkAnnotation]) > 0
+}
+
+// ParseNetworkRange parses a value of the network flag, formatted as network, network:start or network:start-end
+func ParseNetworkRange(value string) (NetworkRange, error) {
+	name, ledgers, hasRange := strings.Cut(value, ":")
+	networkRange := NetworkRange{Network: name}
+	switch name {
+	case NetworkPubnet, NetworkTestnet, NetworkFuturenet:
+	default:
+		return networkRange, fmt.Errorf("invalid network %q: must be one of %s, %s or %s", name, NetworkPubnet, NetworkTestnet, NetworkFuturenet)
+	}
+	if !hasRange {
+		return networkRange, nil
+	}
+
+	start, end, bounded := strings.Cut(ledgers, "-")
+	parsedStart, err := strconv.ParseUint(start, 10, 32)
+	if err != nil || parsedStart == 0 {
+		return networkRange, fmt.Errorf("invalid start ledger %q of network %s", start, name)
+	}
+	networkRange.Start = uint32(parsedStart)
+	if !bounded {
+		return networkRange, nil
+	}
+
+	parsedEnd, err := strconv.ParseUint(end, 10, 32)
+	if err != nil || parsedEnd < parsedStart {
+		return networkRange, fmt.Errorf("invalid end ledger %q of network %s", end, name)
+	}
+	networkRange.End = uint32(parsedEnd)
+
+	return networkRange, nil
+}
+
+// ForNetwork returns the flag values to use when exporting the given network
+func (c CommonFlagValues) ForNetwork(name string) CommonFlagValues {
+	c.IsTest = name == NetworkTestnet
+	c.IsFuture = name == NetworkFuturenet
+	c.Passphrase = NetworkPresets[NetworkName(c.IsTest, c.IsFuture)].Passphrase
+	return c
+}
+
+// ParseSampleRate parses the value of the sample flag, formatted as 1/N, into N. An empty value samples every transaction,
+// which is a rate of 1.
+func ParseSampleRate(sample string) (uint32, error) {
+	if sample == "" {
+		return 1, nil
+	}
+
+	var rate uint32
+	if _, err := fmt.Sscanf(sample, "1/%d", &rate); err != nil || rate == 0 || fmt.Sprintf("1/%d", rate) != sample {
+		return 0, fmt.Errorf("invalid sample %q: must be formatted as 1/N with N a positive integer", sample)
+	}
+
+	return rate, nil
+}
+
+// InSample returns true if the transaction with the given hash is part of a 1/rate sample, i.e. if its hash read as a big
+// endian integer is a multiple of rate. The sample only depends on the hashes, so it is the same across runs and commands.
+func InSample(txHash xdr.Hash, rate uint32) bool {
+	if rate <= 1 {
+		return true
+	}
+
+	remainder := new(big.Int).Mod(new(big.Int).SetBytes(txHash[:]), big.NewInt(int64(rate)))
+	return remainder.Sign() == 0
+}
+
 // MustArchiveFlags gets the values of the the history archive specific flags: start-ledger, output, and limit
 func MustArchiveFlags(flags *pflag.FlagSet, logger *EtlLogger) (startNum uint32, path string, limit int64) {
 	startNum, err := flags.GetUint32("start-ledger")
 	if err != nil {
 		logger.Fatal("could not get start sequence number: ", err)
 	}
+	startNum = maybeAlignStart(flags, logger, startNum)
 
 	path, err = flags.GetString("output")
 	if err != nil {
@@ -444,6 +1044,7 @@ func MustCoreFlags(flags *pflag.FlagSet, logger *EtlLogger) (execPath, configPat
 	if err != nil {
 		logger.Fatal("could not get start sequence number: ", err)
 	}
+	startNum = maybeAlignStart(flags, logger, startNum)
 
 	batchSize, err = flags.GetUint32("batch-size")
 	if err != nil {
@@ -459,14 +1060,17 @@ func MustExportTypeFlags(flags *pflag.FlagSet, logger *EtlLogger) map[string]boo
 	var err error
 	exports := map[string]bool{
 		"export-accounts":        false,
+		"export-account-data":    false,
 		"export-trustlines":      false,
 		"export-offers":          false,
 		"export-pools":           false,
 		"export-balances":        false,
 		"export-contract-code":   false,
 		"export-contract-data":   false,
+		"export-contract-nonces": false,
 		"export-config-settings": false,
 		"export-ttl":             false,
+		"export-evicted-entries": false,
 	}
 
 	for export_name := range exports {
@@ -548,30 +1152,33 @@ func (h historyArchiveBackend) Close() error {
 // ValidateLedgerRange validates the given ledger range
 func ValidateLedgerRange(start, end, latestNum uint32) error {
 	if start == 0 {
-		return fmt.Errorf("start sequence number equal to 0. There is no ledger 0 (genesis ledger is ledger 1)")
+		return ValidationError{Err: fmt.Errorf("start sequence number equal to 0. There is no ledger 0 (genesis ledger is ledger 1)")}
 	}
 
 	if end == 0 {
-		return fmt.Errorf("end sequence number equal to 0. There is no ledger 0 (genesis ledger is ledger 1)")
+		return ValidationError{Err: fmt.Errorf("end sequence number equal to 0. There is no ledger 0 (genesis ledger is ledger 1)")}
 	}
 
 	if end < start {
-		return fmt.Errorf("end sequence number is less than start (%d < %d)", end, start)
+		return ValidationError{Err: fmt.Errorf("end sequence number is less than start (%d < %d)", end, start)}
 	}
 
 	if latestNum < start {
-		return fmt.Errorf("latest sequence number is less than start sequence number (%d < %d)", latestNum, start)
+		return ValidationError{Err: fmt.Errorf("latest sequence number is less than start sequence number (%d < %d)", latestNum, start)}
 	}
 
 	if latestNum < end {
-		return fmt.Errorf("latest sequence number is less than end sequence number (%d < %d)", latestNum, end)
+		return ValidationError{Err: fmt.Errorf("latest sequence number is less than end sequence number (%d < %d)", latestNum, end)}
 	}
 
 	return nil
 }
 
-func CreateBackend(start, end uint32, archiveURLs []string) (historyArchiveBackend, error) {
-	client, err := CreateHistoryArchiveClient(archiveURLs)
+// CreateBackend reads the ledgers in the range [start, end] from the history archives. Requests to the archives are
+// aborted when ctx is done. When cacheDir is set, the decoded checkpoints are cached in that directory and reused by
+// later calls.
+func CreateBackend(ctx context.Context, start, end uint32, archiveURLs []string, cacheDir string) (historyArchiveBackend, error) {
+	client, err := CreateHistoryArchiveClientWithContext(ctx, archiveURLs)
 	if err != nil {
 		return historyArchiveBackend{}, err
 	}
@@ -584,38 +1191,35 @@ func CreateBackend(start, end uint32, archiveURLs []string) (historyArchiveBacke
 		return historyArchiveBackend{}, err
 	}
 
-	ledgers, err := client.GetLedgers(start, end)
+	var ledgers map[uint32]*historyarchive.Ledger
+	if cacheDir != "" {
+		ledgers, err = historyCache{dir: cacheDir, archiveURLs: archiveURLs}.getLedgers(client, start, end)
+	} else {
+		ledgers, err = client.GetLedgers(start, end)
+	}
 	if err != nil {
 		return historyArchiveBackend{}, err
 	}
 	return historyArchiveBackend{client: client, ledgers: ledgers}, nil
 }
 
-// mainnet history archive URLs
-var mainArchiveURLs = []string{
-	"https://history.stellar.org/prd/core-live/core_live_001",
-	"https://history.stellar.org/prd/core-live/core_live_002",
-	"https://history.stellar.org/prd/core-live/core_live_003",
-}
-
-// testnet is only used for local testing with new Protocol features
-var testArchiveURLs = []string{
-	"https://history.stellar.org/prd/core-testnet/core_testnet_001",
-	"https://history.stellar.org/prd/core-testnet/core_testnet_002",
-	"https://history.stellar.org/prd/core-testnet/core_testnet_003",
-}
-
-// futrenet is used for testing new Protocol features
-var futureArchiveURLs = []string{
-	"https://history-futurenet.stellar.org/",
+func CreateHistoryArchiveClient(archiveURLS []string) (historyarchive.ArchiveInterface, error) {
+	return CreateHistoryArchiveClientWithContext(context.Background(), archiveURLS)
 }
 
-func CreateHistoryArchiveClient(archiveURLS []string) (historyarchive.ArchiveInterface, error) {
+// CreateHistoryArchiveClientWithContext creates a history archive client whose requests are aborted when ctx is done
+func CreateHistoryArchiveClientWithContext(ctx context.Context, archiveURLS []string) (historyarchive.ArchiveInterface, error) {
 	archiveOptions := historyarchive.ArchiveOptions{
 		ConnectOptions: storage.ConnectOptions{
+			Context:   ctx,
 			UserAgent: "stellar-etl/1.0.0",
 		},
 	}
+	if downloadLimiter != nil {
+		archiveOptions.ConnectOptions.Wrap = func(upstream storage.Storage) (storage.Storage, error) {
+			return throttledStorage{Storage: upstream, ctx: ctx}, nil
+		}
+	}
 	return historyarchive.NewArchivePool(archiveURLS, archiveOptions)
 }
 
@@ -673,6 +1277,40 @@ func ExtractEntryFromChange(change ingest.Change) (xdr.LedgerEntry, xdr.LedgerEn
 	}
 }
 
+// CheckpointAlignedStart returns the first ledger of the checkpoint containing seq. The first checkpoint is returned as
+// starting at ledger 2, since the genesis ledger has no transactions to export.
+func CheckpointAlignedStart(seq uint32) uint32 {
+	start := seq - seq%64
+	if start < 2 {
+		return 2
+	}
+	return start
+}
+
+// CheckpointAlignedEnd returns the checkpoint ledger, which is the last ledger, of the checkpoint containing seq
+func CheckpointAlignedEnd(seq uint32) uint32 {
+	end := (uint64(seq)/64+1)*64 - 1
+	if end > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(end)
+}
+
+// maybeAlignStart moves startNum back to the start of its checkpoint when the align-checkpoints flag is set
+func maybeAlignStart(flags *pflag.FlagSet, logger *EtlLogger, startNum uint32) uint32 {
+	// commands that do not add the common flags have no align-checkpoints flag
+	alignCheckpoints, err := flags.GetBool("align-checkpoints")
+	if err != nil || !alignCheckpoints {
+		return startNum
+	}
+
+	aligned := CheckpointAlignedStart(startNum)
+	if aligned != startNum {
+		logger.Infof("align-checkpoints: start-ledger %d moved to %d, the first ledger of its checkpoint", startNum, aligned)
+	}
+	return aligned
+}
+
 // GetMostRecentCheckpoint returns the most recent checkpoint before the provided ledger
 func GetMostRecentCheckpoint(seq uint32) uint32 {
 	remainder := (seq + 1) % 64
@@ -687,40 +1325,23 @@ type EnvironmentDetails struct {
 	ArchiveURLs       []string
 	BinaryPath        string
 	CoreConfig        string
+	CoreVersion       string
 	Network           string
 	CommonFlagValues  CommonFlagValues
 }
 
-// GetPassphrase returns the correct Network Passphrase based on env preference
+// GetEnvironmentDetails returns the settings of the network selected by the testnet and futurenet flags, taken from its
+// preset in NetworkPresets
 func GetEnvironmentDetails(commonFlags CommonFlagValues) (details EnvironmentDetails) {
-	if commonFlags.IsTest {
-		// testnet passphrase to be used for testing
-		details.NetworkPassphrase = network.TestNetworkPassphrase
-		details.ArchiveURLs = testArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
-		details.CoreConfig = "/etl/docker/stellar-core_testnet.cfg"
-		details.Network = "testnet"
-		details.CommonFlagValues = commonFlags
-		return details
-	} else if commonFlags.IsFuture {
-		// details.NetworkPassphrase = network.FutureNetworkPassphrase
-		details.NetworkPassphrase = "Test SDF Future Network ; October 2022"
-		details.ArchiveURLs = futureArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
-		details.CoreConfig = "/etl/docker/stellar-core_futurenet.cfg"
-		details.Network = "futurenet"
-		details.CommonFlagValues = commonFlags
-		return details
-	} else {
-		// default: mainnet
-		details.NetworkPassphrase = network.PublicNetworkPassphrase
-		details.ArchiveURLs = mainArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
-		details.CoreConfig = "/etl/docker/stellar-core.cfg"
-		details.Network = "pubnet"
-		details.CommonFlagValues = commonFlags
-		return details
-	}
+	details.Network = NetworkName(commonFlags.IsTest, commonFlags.IsFuture)
+	preset := NetworkPresets[details.Network]
+	details.NetworkPassphrase = preset.Passphrase
+	details.ArchiveURLs = preset.ArchiveURLs
+	details.BinaryPath = preset.CoreBinaryPath
+	details.CoreConfig = preset.CoreConfig
+	details.CoreVersion = preset.CoreVersion
+	details.CommonFlagValues = commonFlags
+	return details
 }
 
 type CaptiveCore interface {
@@ -728,6 +1349,11 @@ type CaptiveCore interface {
 }
 
 func (e EnvironmentDetails) CreateCaptiveCoreBackend() (*ledgerbackend.CaptiveStellarCore, error) {
+	if e.CoreVersion != "" {
+		if err := CheckCoreVersion(e.BinaryPath, e.CoreVersion); err != nil {
+			return &ledgerbackend.CaptiveStellarCore{}, err
+		}
+	}
 	captiveCoreToml, err := ledgerbackend.NewCaptiveCoreTomlFromFile(
 		e.CoreConfig,
 		ledgerbackend.CaptiveCoreTomlParams{
@@ -781,13 +1407,11 @@ func GetCloseTime(lcm xdr.LedgerCloseMeta) (time.Time, error) {
 	return ExtractLedgerCloseTime(headerHistoryEntry)
 }
 
+// LedgerEntryToLedgerKeyHash returns the hex encoded SHA-256 hash of the ledger key of the entry.
+// For contract data and contract code entries, this is the key hash referenced by their TTL entries.
 func LedgerEntryToLedgerKeyHash(ledgerEntry xdr.LedgerEntry) string {
 	ledgerKey, _ := ledgerEntry.LedgerKey()
-	ledgerKeyByte, _ := ledgerKey.MarshalBinary()
-	hashedLedgerKeyByte := hash.Hash(ledgerKeyByte)
-	ledgerKeyHash := hex.EncodeToString(hashedLedgerKeyByte[:])
-
-	return ledgerKeyHash
+	return LedgerKeyToLedgerKeyHash(ledgerKey)
 }
 
 // CreateLedgerBackend creates a ledger backend using captive core or datastore
@@ -814,6 +1438,9 @@ func CreateLedgerBackend(ctx context.Context, useCaptiveCore bool, env Environme
 	if err != nil {
 		return nil, err
 	}
+	if downloadLimiter != nil {
+		dataStore = throttledDataStore{DataStore: dataStore}
+	}
 
 	// TODO: In the future these will come from a config file written by ledgerexporter
 	// Hard code ledger batch values for now
@@ -838,6 +1465,7 @@ func CreateLedgerBackend(ctx context.Context, useCaptiveCore bool, env Environme
 	return backend, nil
 }
 
+// LedgerKeyToLedgerKeyHash returns the hex encoded SHA-256 hash of the XDR encoded ledger key
 func LedgerKeyToLedgerKeyHash(ledgerKey xdr.LedgerKey) string {
 	ledgerKeyByte, _ := ledgerKey.MarshalBinary()
 	hashedLedgerKeyByte := hash.Hash(ledgerKeyByte)
@@ -846,6 +1474,28 @@ func LedgerKeyToLedgerKeyHash(ledgerKey xdr.LedgerKey) string {
 	return ledgerKeyHash
 }
 
+// ContractDataLedgerKeyHash returns the ledger key hash of the contract data entry stored by the contract under the key.
+// It is the key_hash of the TTL entry of the contract data entry.
+func ContractDataLedgerKeyHash(contract xdr.ScAddress, key xdr.ScVal, durability xdr.ContractDataDurability) (string, error) {
+	var ledgerKey xdr.LedgerKey
+	if err := ledgerKey.SetContractData(contract, key, durability); err != nil {
+		return "", err
+	}
+
+	return LedgerKeyToLedgerKeyHash(ledgerKey), nil
+}
+
+// ContractCodeLedgerKeyHash returns the ledger key hash of the contract code entry of the wasm hash.
+// It is the key_hash of the TTL entry of the contract code entry.
+func ContractCodeLedgerKeyHash(wasmHash xdr.Hash) (string, error) {
+	var ledgerKey xdr.LedgerKey
+	if err := ledgerKey.SetContractCode(wasmHash); err != nil {
+		return "", err
+	}
+
+	return LedgerKeyToLedgerKeyHash(ledgerKey), nil
+}
+
 // AccountSignersChanged returns true if account signers have changed.
 // Notice: this will return true on master key changes too!
 func AccountSignersChanged(c ingest.Change) bool {
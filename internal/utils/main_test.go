@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+// The key hashes are the ledger_key_hash of the contract_data and contract_code transform fixtures, which is the
+// key_hash of the ttl entries extending them
+
+func TestContractDataLedgerKeyHash(t *testing.T) {
+	var hash xdr.Hash
+	var scStr xdr.ScString = "a"
+	contract := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &hash}
+	key := xdr.ScVal{
+		Type: xdr.ScValTypeScvContractInstance,
+		Instance: &xdr.ScContractInstance{
+			Executable: xdr.ContractExecutable{
+				Type:     xdr.ContractExecutableTypeContractExecutableWasm,
+				WasmHash: &hash,
+			},
+			Storage: &xdr.ScMap{
+				xdr.ScMapEntry{
+					Key: xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &scStr},
+					Val: xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &scStr},
+				},
+			},
+		},
+	}
+
+	keyHash, err := ContractDataLedgerKeyHash(contract, key, xdr.ContractDataDurabilityPersistent)
+	assert.NoError(t, err)
+	assert.Equal(t, "abfc33272095a9df4c310cff189040192a8aee6f6a23b6b462889114d80728ca", keyHash)
+
+	// temporary entries have their own ttl entry
+	keyHash, err = ContractDataLedgerKeyHash(contract, key, xdr.ContractDataDurabilityTemporary)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "abfc33272095a9df4c310cff189040192a8aee6f6a23b6b462889114d80728ca", keyHash)
+}
+
+func TestContractCodeLedgerKeyHash(t *testing.T) {
+	keyHash, err := ContractCodeLedgerKeyHash(xdr.Hash{})
+	assert.NoError(t, err)
+	assert.Equal(t, "dfed061dbe464e0ff320744fcd604ac08b39daa74fa24110936654cbcb915ccc", keyHash)
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/stellar/go/network"
+)
+
+// NetworkPreset holds the settings stellar-etl needs to read a network. The tags name the keys of the networks section
+// of the config file, which overrides the presets.
+type NetworkPreset struct {
+	Passphrase     string   `mapstructure:"passphrase" json:"passphrase"`
+	ArchiveURLs    []string `mapstructure:"archive_urls" json:"archive_urls"`
+	HorizonURL     string   `mapstructure:"horizon_url" json:"horizon_url"`
+	CoreBinaryPath string   `mapstructure:"core_binary_path" json:"core_binary_path"`
+	CoreConfig     string   `mapstructure:"core_config" json:"core_config"`
+	// CoreVersion is the oldest stellar-core version supporting the protocol of the network. Captive core fails to
+	// start when its binary is older.
+	CoreVersion string `mapstructure:"core_version" json:"core_version"`
+}
+
+// NetworkPresets are the settings of the networks selected by the network, testnet and futurenet flags
+var NetworkPresets = map[string]NetworkPreset{
+	NetworkPubnet: {
+		Passphrase: network.PublicNetworkPassphrase,
+		ArchiveURLs: []string{
+			"https://history.stellar.org/prd/core-live/core_live_001",
+			"https://history.stellar.org/prd/core-live/core_live_002",
+			"https://history.stellar.org/prd/core-live/core_live_003",
+		},
+		HorizonURL:     "https://horizon.stellar.org",
+		CoreBinaryPath: "/usr/bin/stellar-core",
+		CoreConfig:     "/etl/docker/stellar-core.cfg",
+		CoreVersion:    "21.0.0",
+	},
+	// testnet is only used for local testing with new Protocol features
+	NetworkTestnet: {
+		Passphrase: network.TestNetworkPassphrase,
+		ArchiveURLs: []string{
+			"https://history.stellar.org/prd/core-testnet/core_testnet_001",
+			"https://history.stellar.org/prd/core-testnet/core_testnet_002",
+			"https://history.stellar.org/prd/core-testnet/core_testnet_003",
+		},
+		HorizonURL:     "https://horizon-testnet.stellar.org",
+		CoreBinaryPath: "/usr/bin/stellar-core",
+		CoreConfig:     "/etl/docker/stellar-core_testnet.cfg",
+		CoreVersion:    "21.0.0",
+	},
+	// futurenet is used for testing new Protocol features
+	NetworkFuturenet: {
+		Passphrase:     "Test SDF Future Network ; October 2022",
+		ArchiveURLs:    []string{"https://history-futurenet.stellar.org/"},
+		HorizonURL:     "https://horizon-futurenet.stellar.org",
+		CoreBinaryPath: "/usr/bin/stellar-core",
+		CoreConfig:     "/etl/docker/stellar-core_futurenet.cfg",
+		CoreVersion:    "21.0.0",
+	},
+}
+
+// OverrideNetworkPresets replaces the settings of the presets with the ones set in overrides, keyed by network name.
+// Settings left empty keep the value of the preset.
+func OverrideNetworkPresets(overrides map[string]NetworkPreset) error {
+	for name, override := range overrides {
+		preset, ok := NetworkPresets[name]
+		if !ok {
+			return fmt.Errorf("invalid network %q in the config file: must be one of %s, %s or %s", name, NetworkPubnet, NetworkTestnet, NetworkFuturenet)
+		}
+
+		if override.Passphrase != "" {
+			preset.Passphrase = override.Passphrase
+		}
+		if len(override.ArchiveURLs) > 0 {
+			preset.ArchiveURLs = override.ArchiveURLs
+		}
+		if override.HorizonURL != "" {
+			preset.HorizonURL = override.HorizonURL
+		}
+		if override.CoreBinaryPath != "" {
+			preset.CoreBinaryPath = override.CoreBinaryPath
+		}
+		if override.CoreConfig != "" {
+			preset.CoreConfig = override.CoreConfig
+		}
+		if override.CoreVersion != "" {
+			preset.CoreVersion = override.CoreVersion
+		}
+		NetworkPresets[name] = preset
+	}
+	return nil
+}
+
+// NetworkName returns the name of the network selected by the testnet and futurenet flags
+func NetworkName(isTest, isFuture bool) string {
+	switch {
+	case isTest:
+		return NetworkTestnet
+	case isFuture:
+		return NetworkFuturenet
+	default:
+		return NetworkPubnet
+	}
+}
+
+// MustNetworkFlags gets the values of the testnet and futurenet flags, stopping the program fatally using the logger if
+// they do not exist
+func MustNetworkFlags(flags *pflag.FlagSet, logger *EtlLogger) (isTest, isFuture bool) {
+	isTest, err := flags.GetBool("testnet")
+	if err != nil {
+		logger.Fatal("could not get testnet boolean: ", err)
+	}
+
+	isFuture, err = flags.GetBool("futurenet")
+	if err != nil {
+		logger.Fatal("could not get futurenet boolean: ", err)
+	}
+
+	return isTest, isFuture
+}
+
+// coreVersionPattern matches the version printed by `stellar-core version`, e.g. "stellar-core 21.0.0 (...)" or "v21.0.0"
+var coreVersionPattern = regexp.MustCompile(`v?(\d+)\.(\d+)\.(\d+)`)
+
+// CheckCoreVersion returns an error when the stellar-core binary at binaryPath is older than minVersion
+func CheckCoreVersion(binaryPath, minVersion string) error {
+	output, err := exec.Command(binaryPath, "version").Output()
+	if err != nil {
+		return fmt.Errorf("could not get the version of %s: %v", binaryPath, err)
+	}
+
+	firstLine := strings.SplitN(string(output), "\n", 2)[0]
+	version, ok := parseCoreVersion(firstLine)
+	if !ok {
+		return fmt.Errorf("could not parse the version of %s from %q", binaryPath, firstLine)
+	}
+	required, ok := parseCoreVersion(minVersion)
+	if !ok {
+		return fmt.Errorf("invalid core version %q: must be formatted as major.minor.patch", minVersion)
+	}
+
+	for i := range version {
+		if version[i] != required[i] {
+			if version[i] < required[i] {
+				return fmt.Errorf("%s is version %d.%d.%d, but the network needs stellar-core %s or later", binaryPath, version[0], version[1], version[2], minVersion)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// parseCoreVersion returns the major, minor and patch numbers of the first version in s
+func parseCoreVersion(s string) ([3]int, bool) {
+	match := coreVersionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return [3]int{}, false
+	}
+
+	var version [3]int
+	for i := range version {
+		version[i], _ = strconv.Atoi(match[i+1])
+	}
+	return version, true
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// rpcPollInterval is how long GetLedger waits before asking again for a ledger that has not closed yet
+	rpcPollInterval = time.Second
+	// rpcLedgersPerRequest is the number of ledgers fetched by every getLedgers request
+	rpcLedgersPerRequest = 10
+)
+
+// RPCBackend is a ledger backend reading the ledger close meta of recent ledgers from the getLedgers method of a
+// Soroban RPC server. The meta holds the contract events, so they do not need to be read from getEvents. Only the
+// ledgers in the retention window of the server can be read, but new ledgers are available within seconds of closing,
+// without running captive core.
+type RPCBackend struct {
+	url      string
+	client   *http.Client
+	ledgers  map[uint32]xdr.LedgerCloseMeta
+	prepared *ledgerbackend.Range
+}
+
+// rpcHealth is the result of the getHealth method
+type rpcHealth struct {
+	LatestLedger uint32 `json:"latestLedger"`
+	OldestLedger uint32 `json:"oldestLedger"`
+}
+
+// rpcLedgers is the result of the getLedgers method
+type rpcLedgers struct {
+	Ledgers []struct {
+		Sequence    uint32 `json:"sequence"`
+		MetadataXdr string `json:"metadataXdr"`
+	} `json:"ledgers"`
+}
+
+// NewRPCBackend returns a backend reading ledgers from the Soroban RPC server at the given url
+func NewRPCBackend(url string) *RPCBackend {
+	return &RPCBackend{
+		url:     url,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		ledgers: map[uint32]xdr.LedgerCloseMeta{},
+	}
+}
+
+// GetLatestLedgerSequence returns the last ledger ingested by the server
+func (b *RPCBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	var health rpcHealth
+	if err := b.call(ctx, "getHealth", nil, &health); err != nil {
+		return 0, err
+	}
+
+	return health.LatestLedger, nil
+}
+
+// PrepareRange checks that the start of the range is still in the retention window of the server
+func (b *RPCBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	bounds, err := ledgerRangeBounds(ledgerRange)
+	if err != nil {
+		return err
+	}
+
+	var health rpcHealth
+	if err := b.call(ctx, "getHealth", nil, &health); err != nil {
+		return err
+	}
+	if bounds.From < health.OldestLedger {
+		return fmt.Errorf("ledger %d is older than the retention window of the rpc server, which starts at ledger %d", bounds.From, health.OldestLedger)
+	}
+
+	b.prepared = &ledgerRange
+	return nil
+}
+
+// IsPrepared returns true if the range was prepared, or is included in the prepared range
+func (b *RPCBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	if b.prepared == nil {
+		return false, nil
+	}
+
+	return b.prepared.Contains(ledgerRange), nil
+}
+
+// GetLedger returns the ledger close meta of a ledger, waiting for the ledger to close if needed. Ledgers are fetched
+// in pages, and the following ledgers of a page are kept until they are asked for.
+func (b *RPCBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	for {
+		if lcm, ok := b.ledgers[sequence]; ok {
+			delete(b.ledgers, sequence)
+			return lcm, nil
+		}
+
+		latest, err := b.GetLatestLedgerSequence(ctx)
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, err
+		}
+
+		if sequence <= latest {
+			if err = b.fetchLedgers(ctx, sequence); err != nil {
+				return xdr.LedgerCloseMeta{}, err
+			}
+			if _, ok := b.ledgers[sequence]; !ok {
+				return xdr.LedgerCloseMeta{}, fmt.Errorf("the rpc server did not return ledger %d", sequence)
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return xdr.LedgerCloseMeta{}, ctx.Err()
+		case <-time.After(rpcPollInterval):
+		}
+	}
+}
+
+// fetchLedgers replaces the kept ledgers with the page of ledgers starting at the given ledger
+func (b *RPCBackend) fetchLedgers(ctx context.Context, start uint32) error {
+	params := map[string]interface{}{
+		"startLedger": start,
+		"pagination":  map[string]interface{}{"limit": rpcLedgersPerRequest},
+	}
+	var page rpcLedgers
+	if err := b.call(ctx, "getLedgers", params, &page); err != nil {
+		return err
+	}
+
+	b.ledgers = map[uint32]xdr.LedgerCloseMeta{}
+	for _, ledger := range page.Ledgers {
+		var lcm xdr.LedgerCloseMeta
+		if err := xdr.SafeUnmarshalBase64(ledger.MetadataXdr, &lcm); err != nil {
+			return fmt.Errorf("could not decode the meta of ledger %d: %v", ledger.Sequence, err)
+		}
+		b.ledgers[ledger.Sequence] = lcm
+	}
+
+	return nil
+}
+
+// Close releases the ledgers that were fetched but not read
+func (b *RPCBackend) Close() error {
+	b.ledgers = map[uint32]xdr.LedgerCloseMeta{}
+	return nil
+}
+
+// call sends a JSON-RPC request and decodes its result
+func (b *RPCBackend) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not call %s on the rpc server: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rpc server returned %s for %s", resp.Status, method)
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("could not decode the response to %s: %v", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("rpc server returned an error for %s: %s (%d)", method, response.Error.Message, response.Error.Code)
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
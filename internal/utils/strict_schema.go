@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StrictSchema makes the transforms fail on the XDR variants they do not know how to transform, such as the config
+// settings or ScVal types of a newer protocol, instead of writing best-effort rows. It is set by the strict flag.
+var StrictSchema bool
+
+// UnknownVariantError is an error about an XDR variant, such as an operation type or a ledger entry type, that stellar-etl
+// does not know how to transform
+type UnknownVariantError struct {
+	Kind  string
+	Value string
+}
+
+func (e UnknownVariantError) Error() string { return fmt.Sprintf("unknown %s: %s", e.Kind, e.Value) }
+
+// IsUnknownVariant reports whether err is or wraps an UnknownVariantError
+func IsUnknownVariant(err error) bool {
+	var unknownErr UnknownVariantError
+	return errors.As(err, &unknownErr)
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"io"
+
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/support/storage"
+	"golang.org/x/time/rate"
+)
+
+// Bandwidth limits of the max-download-mbps and max-upload-mbps flags, shared by every reader of the command so that the
+// limit holds across parallel downloads and uploads. They are nil when the bandwidth is not limited.
+var (
+	downloadLimiter *rate.Limiter
+	uploadLimiter   *rate.Limiter
+)
+
+// newBandwidthLimiter returns a limiter of mbps megabits per second, or nil when mbps is not positive
+func newBandwidthLimiter(mbps float64) *rate.Limiter {
+	if mbps <= 0 {
+		return nil
+	}
+
+	bytesPerSecond := mbps * 1000 * 1000 / 8
+	// reads are split into chunks of at most the burst, which allows a second of traffic
+	burst := int(bytesPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
+
+// throttledReader waits for the limiter after every read, so that the data is read at most at the rate of the limiter
+type throttledReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// throttledReadCloser is a throttledReader that closes the reader it wraps
+type throttledReadCloser struct {
+	throttledReader
+	closer io.Closer
+}
+
+func (r *throttledReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// ThrottleDownload limits the rate the reader is read at to the max-download-mbps flag
+func ThrottleDownload(ctx context.Context, reader io.ReadCloser) io.ReadCloser {
+	if downloadLimiter == nil {
+		return reader
+	}
+	return &throttledReadCloser{throttledReader: throttledReader{ctx: ctx, reader: reader, limiter: downloadLimiter}, closer: reader}
+}
+
+// ThrottleUpload limits the rate the reader is read at to the max-upload-mbps flag
+func ThrottleUpload(ctx context.Context, reader io.Reader) io.Reader {
+	if uploadLimiter == nil {
+		return reader
+	}
+	return &throttledReader{ctx: ctx, reader: reader, limiter: uploadLimiter}
+}
+
+// throttledStorage limits the bandwidth of the files downloaded from a history archive
+type throttledStorage struct {
+	storage.Storage
+	ctx context.Context
+}
+
+func (s throttledStorage) GetFile(path string) (io.ReadCloser, error) {
+	file, err := s.Storage.GetFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ThrottleDownload(s.ctx, file), nil
+}
+
+// throttledDataStore limits the bandwidth of the ledger files downloaded from the datastore
+type throttledDataStore struct {
+	datastore.DataStore
+}
+
+func (s throttledDataStore) GetFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	file, err := s.DataStore.GetFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return ThrottleDownload(ctx, file), nil
+}
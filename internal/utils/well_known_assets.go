@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// WellKnownAsset is the metadata of an asset listed in a well-known assets file
+type WellKnownAsset struct {
+	Code       string `toml:"code"`
+	Issuer     string `toml:"issuer"`
+	Domain     string `toml:"domain"`
+	AnchorName string `toml:"anchor_name"`
+	Decimals   *int   `toml:"decimals"`
+}
+
+// wellKnownAssetsTOML is the layout of a TOML well-known assets file: an [[assets]] table per asset
+type wellKnownAssetsTOML struct {
+	Assets []WellKnownAsset `toml:"assets"`
+}
+
+// WellKnownAssetKey returns the key of an asset in the map returned by LoadWellKnownAssets: native for lumens, and
+// code:issuer for other assets
+func WellKnownAssetKey(code, issuer string) string {
+	if issuer == "" && (code == "" || strings.EqualFold(code, "native") || code == "XLM") {
+		return "native"
+	}
+	return code + ":" + issuer
+}
+
+// LoadWellKnownAssets reads a TOML file with an [[assets]] table per asset, or a CSV file with a header row naming the
+// code, issuer, domain, anchor_name and decimals columns, and returns the assets keyed by WellKnownAssetKey
+func LoadWellKnownAssets(path string) (map[string]WellKnownAsset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var assets []WellKnownAsset
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var parsed wellKnownAssetsTOML
+		if err := toml.NewDecoder(file).DisallowUnknownFields().Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		assets = parsed.Assets
+	case ".csv":
+		assets, err = readWellKnownAssetsCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("well-known assets file %s must be a .toml or .csv file", path)
+	}
+
+	byKey := make(map[string]WellKnownAsset, len(assets))
+	for _, asset := range assets {
+		key := WellKnownAssetKey(asset.Code, asset.Issuer)
+		if _, ok := byKey[key]; ok {
+			return nil, fmt.Errorf("asset %s is listed more than once in %s", key, path)
+		}
+		byKey[key] = asset
+	}
+
+	return byKey, nil
+}
+
+// readWellKnownAssetsCSV reads the assets of a CSV file, whose columns are named by its header row
+func readWellKnownAssetsCSV(r io.Reader) ([]WellKnownAsset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["code"]; !ok {
+		return nil, fmt.Errorf("the header row has no code column")
+	}
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	assets := []WellKnownAsset{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return assets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		asset := WellKnownAsset{
+			Code:       value(record, "code"),
+			Issuer:     value(record, "issuer"),
+			Domain:     value(record, "domain"),
+			AnchorName: value(record, "anchor_name"),
+		}
+		if decimals := value(record, "decimals"); decimals != "" {
+			parsed, err := strconv.Atoi(decimals)
+			if err != nil {
+				return nil, fmt.Errorf("invalid decimals %q of asset %s", decimals, asset.Code)
+			}
+			asset.Decimals = &parsed
+		}
+		assets = append(assets, asset)
+	}
+}
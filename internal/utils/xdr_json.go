@@ -0,0 +1,321 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// XdrJSONRenderer renders the raw XDR values written to the outputs as JSON values
+type XdrJSONRenderer interface {
+	ScVal(val xdr.ScVal) (interface{}, error)
+	LedgerKey(key xdr.LedgerKey) (interface{}, error)
+	ClaimPredicate(predicate xdr.ClaimPredicate) (interface{}, error)
+}
+
+// XdrJSON is the renderer used by the transforms. Programs embedding the processors can replace it with
+// processor.SetXdrJSONRenderer to render the values in another format.
+var XdrJSON XdrJSONRenderer = StellarXdrJSON{}
+
+// StellarXdrJSON renders XDR values like the JSON of the Rust stellar-xdr crate, which is the format of
+// `stellar xdr decode --output json` and the other SDF tooling. Structs are objects with snake_case field names, enums
+// are snake_case strings and unions are objects keyed by the snake_case name of their arm, or plain strings for arms
+// without a value. 64 bit and larger integers are decimal strings, binary values are hex strings and addresses are strkeys.
+type StellarXdrJSON struct{}
+
+// ScVal renders a Soroban value
+func (r StellarXdrJSON) ScVal(val xdr.ScVal) (interface{}, error) {
+	switch val.Type {
+	case xdr.ScValTypeScvBool:
+		return unionArm("bool", *val.B), nil
+	case xdr.ScValTypeScvVoid:
+		return "void", nil
+	case xdr.ScValTypeScvError:
+		scError, err := r.scError(*val.Error)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("error", scError), nil
+	case xdr.ScValTypeScvU32:
+		return unionArm("u32", uint32(*val.U32)), nil
+	case xdr.ScValTypeScvI32:
+		return unionArm("i32", int32(*val.I32)), nil
+	case xdr.ScValTypeScvU64:
+		return unionArm("u64", fmt.Sprintf("%d", uint64(*val.U64))), nil
+	case xdr.ScValTypeScvI64:
+		return unionArm("i64", fmt.Sprintf("%d", int64(*val.I64))), nil
+	case xdr.ScValTypeScvTimepoint:
+		return unionArm("timepoint", fmt.Sprintf("%d", uint64(*val.Timepoint))), nil
+	case xdr.ScValTypeScvDuration:
+		return unionArm("duration", fmt.Sprintf("%d", uint64(*val.Duration))), nil
+	case xdr.ScValTypeScvU128:
+		return unionArm("u128", UInt128ToBigInt(*val.U128).String()), nil
+	case xdr.ScValTypeScvI128:
+		return unionArm("i128", Int128ToBigInt(*val.I128).String()), nil
+	case xdr.ScValTypeScvU256:
+		return unionArm("u256", UInt256ToBigInt(*val.U256).String()), nil
+	case xdr.ScValTypeScvI256:
+		return unionArm("i256", Int256ToBigInt(*val.I256).String()), nil
+	case xdr.ScValTypeScvBytes:
+		return unionArm("bytes", hex.EncodeToString(*val.Bytes)), nil
+	case xdr.ScValTypeScvString:
+		return unionArm("string", string(*val.Str)), nil
+	case xdr.ScValTypeScvSymbol:
+		return unionArm("symbol", string(*val.Sym)), nil
+	case xdr.ScValTypeScvVec:
+		if val.Vec == nil || *val.Vec == nil {
+			return unionArm("vec", nil), nil
+		}
+		vec := make([]interface{}, 0, len(**val.Vec))
+		for _, item := range **val.Vec {
+			rendered, err := r.ScVal(item)
+			if err != nil {
+				return nil, err
+			}
+			vec = append(vec, rendered)
+		}
+		return unionArm("vec", vec), nil
+	case xdr.ScValTypeScvMap:
+		if val.Map == nil || *val.Map == nil {
+			return unionArm("map", nil), nil
+		}
+		scMap, err := r.scMap(**val.Map)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("map", scMap), nil
+	case xdr.ScValTypeScvAddress:
+		address, err := scAddressString(*val.Address)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("address", address), nil
+	case xdr.ScValTypeScvLedgerKeyContractInstance:
+		return "ledger_key_contract_instance", nil
+	case xdr.ScValTypeScvLedgerKeyNonce:
+		return unionArm("ledger_key_nonce", map[string]interface{}{
+			"nonce": fmt.Sprintf("%d", int64(val.NonceKey.Nonce)),
+		}), nil
+	case xdr.ScValTypeScvContractInstance:
+		instance, err := r.contractInstance(*val.Instance)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("contract_instance", instance), nil
+	default:
+		return nil, UnknownVariantError{Kind: "ScVal type", Value: fmt.Sprintf("%d", val.Type)}
+	}
+}
+
+// LedgerKey renders the key of a ledger entry
+func (r StellarXdrJSON) LedgerKey(key xdr.LedgerKey) (interface{}, error) {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return unionArm("account", map[string]interface{}{
+			"account_id": key.Account.AccountId.Address(),
+		}), nil
+	case xdr.LedgerEntryTypeTrustline:
+		asset, err := r.trustLineAsset(key.TrustLine.Asset)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("trustline", map[string]interface{}{
+			"account_id": key.TrustLine.AccountId.Address(),
+			"asset":      asset,
+		}), nil
+	case xdr.LedgerEntryTypeOffer:
+		return unionArm("offer", map[string]interface{}{
+			"seller_id": key.Offer.SellerId.Address(),
+			"offer_id":  fmt.Sprintf("%d", int64(key.Offer.OfferId)),
+		}), nil
+	case xdr.LedgerEntryTypeData:
+		return unionArm("data", map[string]interface{}{
+			"account_id": key.Data.AccountId.Address(),
+			"data_name":  string(key.Data.DataName),
+		}), nil
+	case xdr.LedgerEntryTypeClaimableBalance:
+		balanceID := key.ClaimableBalance.BalanceId
+		if balanceID.Type != xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0 {
+			return nil, fmt.Errorf("unknown claimable balance id type %d", balanceID.Type)
+		}
+		return unionArm("claimable_balance", map[string]interface{}{
+			"balance_id": unionArm("claimable_balance_id_type_v0", hex.EncodeToString(balanceID.V0[:])),
+		}), nil
+	case xdr.LedgerEntryTypeLiquidityPool:
+		return unionArm("liquidity_pool", map[string]interface{}{
+			"liquidity_pool_id": hex.EncodeToString(key.LiquidityPool.LiquidityPoolId[:]),
+		}), nil
+	case xdr.LedgerEntryTypeContractData:
+		contract, err := scAddressString(key.ContractData.Contract)
+		if err != nil {
+			return nil, err
+		}
+		scKey, err := r.ScVal(key.ContractData.Key)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("contract_data", map[string]interface{}{
+			"contract":   contract,
+			"key":        scKey,
+			"durability": enumName(key.ContractData.Durability.String(), "ContractDataDurability"),
+		}), nil
+	case xdr.LedgerEntryTypeContractCode:
+		return unionArm("contract_code", map[string]interface{}{
+			"hash": hex.EncodeToString(key.ContractCode.Hash[:]),
+		}), nil
+	case xdr.LedgerEntryTypeConfigSetting:
+		return unionArm("config_setting", map[string]interface{}{
+			"config_setting_id": enumName(key.ConfigSetting.ConfigSettingId.String(), "ConfigSettingIdConfigSetting"),
+		}), nil
+	case xdr.LedgerEntryTypeTtl:
+		return unionArm("ttl", map[string]interface{}{
+			"key_hash": hex.EncodeToString(key.Ttl.KeyHash[:]),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown ledger key type %d", key.Type)
+	}
+}
+
+// ClaimPredicate renders the predicate of a claimable balance claimant
+func (r StellarXdrJSON) ClaimPredicate(predicate xdr.ClaimPredicate) (interface{}, error) {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return "unconditional", nil
+	case xdr.ClaimPredicateTypeClaimPredicateAnd:
+		predicates, err := r.claimPredicates(*predicate.AndPredicates)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("and", predicates), nil
+	case xdr.ClaimPredicateTypeClaimPredicateOr:
+		predicates, err := r.claimPredicates(*predicate.OrPredicates)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("or", predicates), nil
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		if predicate.NotPredicate == nil || *predicate.NotPredicate == nil {
+			return unionArm("not", nil), nil
+		}
+		inner, err := r.ClaimPredicate(**predicate.NotPredicate)
+		if err != nil {
+			return nil, err
+		}
+		return unionArm("not", inner), nil
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		return unionArm("before_absolute_time", fmt.Sprintf("%d", int64(*predicate.AbsBefore))), nil
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		return unionArm("before_relative_time", fmt.Sprintf("%d", int64(*predicate.RelBefore))), nil
+	default:
+		return nil, fmt.Errorf("unknown claim predicate type %d", predicate.Type)
+	}
+}
+
+func (r StellarXdrJSON) claimPredicates(predicates []xdr.ClaimPredicate) ([]interface{}, error) {
+	rendered := make([]interface{}, 0, len(predicates))
+	for _, predicate := range predicates {
+		p, err := r.ClaimPredicate(predicate)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, p)
+	}
+	return rendered, nil
+}
+
+func (r StellarXdrJSON) scError(scError xdr.ScError) (interface{}, error) {
+	arm := enumName(scError.Type.String(), "ScErrorTypeSce")
+	if scError.Type == xdr.ScErrorTypeSceContract {
+		return unionArm(arm, uint32(*scError.ContractCode)), nil
+	}
+	if scError.Code == nil {
+		return nil, fmt.Errorf("missing code in ScError of type %s", scError.Type)
+	}
+	return unionArm(arm, enumName(scError.Code.String(), "ScErrorCodeScec")), nil
+}
+
+func (r StellarXdrJSON) scMap(scMap xdr.ScMap) ([]interface{}, error) {
+	entries := make([]interface{}, 0, len(scMap))
+	for _, entry := range scMap {
+		key, err := r.ScVal(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := r.ScVal(entry.Val)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, map[string]interface{}{"key": key, "val": val})
+	}
+	return entries, nil
+}
+
+func (r StellarXdrJSON) contractInstance(instance xdr.ScContractInstance) (interface{}, error) {
+	var executable interface{}
+	switch instance.Executable.Type {
+	case xdr.ContractExecutableTypeContractExecutableWasm:
+		executable = unionArm("wasm", hex.EncodeToString(instance.Executable.WasmHash[:]))
+	case xdr.ContractExecutableTypeContractExecutableStellarAsset:
+		executable = "stellar_asset"
+	default:
+		return nil, fmt.Errorf("unknown contract executable type %d", instance.Executable.Type)
+	}
+
+	var storage interface{}
+	if instance.Storage != nil {
+		scMap, err := r.scMap(*instance.Storage)
+		if err != nil {
+			return nil, err
+		}
+		storage = scMap
+	}
+
+	return map[string]interface{}{"executable": executable, "storage": storage}, nil
+}
+
+func (r StellarXdrJSON) trustLineAsset(asset xdr.TrustLineAsset) (interface{}, error) {
+	switch asset.Type {
+	case xdr.AssetTypeAssetTypeNative:
+		return "native", nil
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		return unionArm("credit_alphanum4", map[string]interface{}{
+			"asset_code": strings.TrimRight(string(asset.AlphaNum4.AssetCode[:]), "\x00"),
+			"issuer":     asset.AlphaNum4.Issuer.Address(),
+		}), nil
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		return unionArm("credit_alphanum12", map[string]interface{}{
+			"asset_code": strings.TrimRight(string(asset.AlphaNum12.AssetCode[:]), "\x00"),
+			"issuer":     asset.AlphaNum12.Issuer.Address(),
+		}), nil
+	case xdr.AssetTypeAssetTypePoolShare:
+		return unionArm("pool_share", hex.EncodeToString(asset.LiquidityPoolId[:])), nil
+	default:
+		return nil, fmt.Errorf("unknown trustline asset type %d", asset.Type)
+	}
+}
+
+// unionArm renders the arm of a union holding a value
+func unionArm(name string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{name: value}
+}
+
+// enumName converts the Go name of an XDR enum value, such as "ScErrorCodeScecArithDomain", to the snake_case name used
+// by stellar-xdr, such as "arith_domain". prefix is the part of the Go name shared by all the values of the enum.
+func enumName(goName, prefix string) string {
+	return ToLowerSnakeCase(strings.TrimPrefix(goName, prefix))
+}
+
+// scAddressString returns the strkey of an account or contract address
+func scAddressString(address xdr.ScAddress) (string, error) {
+	switch address.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		return address.AccountId.Address(), nil
+	case xdr.ScAddressTypeScAddressTypeContract:
+		return strkey.Encode(strkey.VersionByteContract, address.ContractId[:])
+	default:
+		return "", fmt.Errorf("unknown ScAddress type %d", address.Type)
+	}
+}
@@ -0,0 +1,183 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// NewAccountsProcessor returns a processor for the accounts table
+func NewAccountsProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeAccount, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		changed, err := change.AccountChangedExceptSigners()
+		if err != nil || !changed {
+			return nil, err
+		}
+
+		account, err := transform.TransformAccount(change, header)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Record{{Table: "accounts", Data: account}}, nil
+	})
+}
+
+// NewSignersProcessor returns a processor for the signers table
+func NewSignersProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeAccount, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		if !utils.AccountSignersChanged(change) {
+			return nil, nil
+		}
+
+		signers, err := transform.TransformSigners(change, header)
+		if err != nil {
+			return nil, err
+		}
+
+		records := make([]Record, 0, len(signers))
+		for _, signer := range signers {
+			records = append(records, Record{Table: "signers", Data: signer})
+		}
+
+		return records, nil
+	})
+}
+
+// NewAccountDataProcessor returns a processor for the account_data table
+func NewAccountDataProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeData, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("account_data").record(transform.TransformAccountData(change, header))
+	})
+}
+
+// NewClaimableBalancesProcessor returns a processor for the claimable_balances table
+func NewClaimableBalancesProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeClaimableBalance, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("claimable_balances").record(transform.TransformClaimableBalance(change, header))
+	})
+}
+
+// NewOffersProcessor returns a processor for the offers table
+func NewOffersProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeOffer, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("offers").record(transform.TransformOffer(change, header))
+	})
+}
+
+// NewTrustlinesProcessor returns a processor for the trustlines table
+func NewTrustlinesProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeTrustline, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("trustlines").record(transform.TransformTrustline(change, header))
+	})
+}
+
+// NewLiquidityPoolsProcessor returns a processor for the liquidity_pools table
+func NewLiquidityPoolsProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeLiquidityPool, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("liquidity_pools").record(transform.TransformPool(change, header))
+	})
+}
+
+// NewContractDataProcessor returns a processor for the contract_data table. Nonces are skipped, like in export_ledger_entry_changes.
+func NewContractDataProcessor(passphrase string) Processor {
+	transformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeContractData, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		contractData, err, ok := transformContractData.TransformContractData(change, passphrase, header)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		return []Record{{Table: "contract_data", Data: contractData}}, nil
+	})
+}
+
+// NewContractNoncesProcessor returns a processor for the contract_nonces table
+func NewContractNoncesProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeContractData, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		contractNonce, err, isNonce := transform.TransformContractNonce(change, header)
+		if err != nil || !isNonce {
+			return nil, err
+		}
+
+		return []Record{{Table: "contract_nonces", Data: contractNonce}}, nil
+	})
+}
+
+// NewContractCodeProcessor returns a processor for the contract_code table
+func NewContractCodeProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeContractCode, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("contract_code").record(transform.TransformContractCode(change, header))
+	})
+}
+
+// NewConfigSettingsProcessor returns a processor for the config_settings table
+func NewConfigSettingsProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeConfigSetting, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("config_settings").record(transform.TransformConfigSetting(change, header))
+	})
+}
+
+// NewTtlProcessor returns a processor for the ttl table
+func NewTtlProcessor(passphrase string) Processor {
+	return changeProcessor(passphrase, xdr.LedgerEntryTypeTtl, func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error) {
+		return table("ttl").record(transform.TransformTtl(change, header))
+	})
+}
+
+// table is the name of the table a transform produces rows for
+type table string
+
+// record wraps the single row produced by a transform
+func (t table) record(data interface{}, err error) ([]Record, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return []Record{{Table: string(t), Data: data}}, nil
+}
+
+// changeProcessor returns a processor that calls transformChange on the compacted changes of the ledger entry type in the ledger
+func changeProcessor(passphrase string, entryType xdr.LedgerEntryType, transformChange func(ingest.Change, xdr.LedgerHeaderHistoryEntry) ([]Record, error)) Processor {
+	return ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		changeReader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(passphrase, lcm)
+		if err != nil {
+			return nil, fmt.Errorf("could not read changes of ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+		defer changeReader.Close()
+
+		compactor := ingest.NewChangeCompactor()
+		for {
+			change, err := changeReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("could not read change from ledger %d: %v", lcm.LedgerSequence(), err)
+			}
+			if change.Type == entryType {
+				if err := compactor.AddChange(change); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		header := lcm.LedgerHeaderHistoryEntry()
+		records := []Record{}
+		for _, change := range compactor.GetChanges() {
+			transformed, err := transformChange(change, header)
+			if err != nil {
+				entry, _, _, _ := utils.ExtractEntryFromChange(change)
+				return nil, fmt.Errorf("could not transform %s entry last updated at %d: %v", entryType, entry.LastModifiedLedgerSeq, err)
+			}
+			records = append(records, transformed...)
+		}
+
+		return records, nil
+	})
+}
@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// PluginSymbol is the name of the function a plugin must export. Its type must be func(passphrase string) processor.Processor.
+const PluginSymbol = "NewProcessor"
+
+// LoadPlugin opens a Go plugin built with `go build -buildmode=plugin` and returns the processor it provides.
+// The plugin has to be built with the same Go toolchain and dependency versions as stellar-etl.
+func LoadPlugin(path, passphrase string) (Processor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open plugin %s: %v", path, err)
+	}
+
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export %s: %v", path, PluginSymbol, err)
+	}
+
+	newProcessor, ok := symbol.(func(string) Processor)
+	if !ok {
+		return nil, fmt.Errorf("%s of plugin %s has type %T; expected func(string) processor.Processor", PluginSymbol, path, symbol)
+	}
+
+	return newProcessor(passphrase), nil
+}
+
+// NewTransactionProcessor returns a processor that calls transform on every transaction of the ledger.
+// It lets plugins produce custom tables from transactions.
+func NewTransactionProcessor(passphrase string, transform func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error)) Processor {
+	return transactionProcessor(passphrase, transform)
+}
+
+// NewChangeProcessor returns a processor that calls transform on the compacted changes of the ledger entry type in the ledger.
+// It lets plugins produce custom tables from ledger entry changes.
+func NewChangeProcessor(passphrase string, entryType xdr.LedgerEntryType, transform func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]Record, error)) Processor {
+	return changeProcessor(passphrase, entryType, transform)
+}
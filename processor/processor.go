@@ -0,0 +1,239 @@
+// Package processor exposes the stellar-etl transforms to Go programs that run their own ingestion loop,
+// for example on top of the ledger backends of the stellar/go ingest framework.
+package processor
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/internal/input"
+	"github.com/stellar/stellar-etl/internal/toid"
+	"github.com/stellar/stellar-etl/internal/transform"
+	"github.com/stellar/stellar-etl/internal/utils"
+)
+
+// Record is a single row produced by a processor, along with the name of the table it belongs to.
+// Data holds one of the output structs of the table, e.g. transform.TransactionOutput for transactions.
+type Record struct {
+	Table string
+	Data  interface{}
+}
+
+// Processor transforms a ledger into rows
+type Processor interface {
+	ProcessLedger(lcm xdr.LedgerCloseMeta) ([]Record, error)
+}
+
+// ProcessorFunc adapts a function to the Processor interface
+type ProcessorFunc func(lcm xdr.LedgerCloseMeta) ([]Record, error)
+
+// ProcessLedger calls f(lcm)
+func (f ProcessorFunc) ProcessLedger(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+	return f(lcm)
+}
+
+// Combine returns a processor that runs every processor on the ledger and returns all of their rows
+func Combine(processors ...Processor) Processor {
+	return ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		records := []Record{}
+		for _, processor := range processors {
+			processed, err := processor.ProcessLedger(lcm)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, processed...)
+		}
+
+		return records, nil
+	})
+}
+
+// XdrJSONRenderer renders the raw XDR values of the rows, such as Soroban values, ledger keys and claim predicates, as JSON values
+type XdrJSONRenderer = utils.XdrJSONRenderer
+
+// SetXdrJSONRenderer replaces the renderer of the raw XDR values of the rows, which renders them like the stellar-xdr
+// tooling by default. It must not be called while ledgers are processed.
+func SetXdrJSONRenderer(renderer XdrJSONRenderer) {
+	utils.XdrJSON = renderer
+}
+
+// NewLedgersProcessor returns a processor for the ledgers table
+func NewLedgersProcessor() Processor {
+	return ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		ledger, err := transform.TransformLedger(input.HistoryArchiveLedger(lcm), lcm)
+		if err != nil {
+			return nil, fmt.Errorf("could not transform ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+
+		return []Record{{Table: "ledgers", Data: ledger}}, nil
+	})
+}
+
+// NewTransactionsProcessor returns a processor for the transactions table
+func NewTransactionsProcessor(passphrase string) Processor {
+	return ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		// the positions are read once per ledger, as reading them hashes every transaction of the set
+		positions, err := transform.TxSetPositions(lcm, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the transaction set of ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+
+		return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+			transaction, err := transform.TransformTransaction(tx, lcm.LedgerHeaderHistoryEntry())
+			if err != nil {
+				return nil, err
+			}
+
+			return []Record{{Table: "transactions", Data: transform.JoinTxSetPosition(transaction, positions)}}, nil
+		}).ProcessLedger(lcm)
+	})
+}
+
+// NewOperationsProcessor returns a processor for the operations table
+func NewOperationsProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		records := []Record{}
+		for index, op := range tx.Envelope.Operations() {
+			operation, err := transform.TransformOperation(op, int32(index), tx, int32(lcm.LedgerSequence()), lcm, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, Record{Table: "operations", Data: operation})
+		}
+
+		return records, nil
+	})
+}
+
+// NewEffectsProcessor returns a processor for the effects table
+func NewEffectsProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		effects, err := transform.TransformEffect(tx, lcm.LedgerSequence(), lcm, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		records := make([]Record, 0, len(effects))
+		for _, effect := range effects {
+			records = append(records, Record{Table: "effects", Data: effect})
+		}
+
+		return records, nil
+	})
+}
+
+// NewTradesProcessor returns a processor for the trades table
+func NewTradesProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		if !tx.Result.Successful() {
+			return nil, nil
+		}
+
+		closeTime, err := utils.GetCloseTime(lcm)
+		if err != nil {
+			return nil, err
+		}
+
+		records := []Record{}
+		for index, op := range tx.Envelope.Operations() {
+			if !input.OperationResultsInTrade(op) {
+				continue
+			}
+
+			operationID := toid.New(int32(lcm.LedgerSequence()), int32(tx.Index), int32(index)).ToInt64()
+			trades, err := transform.TransformTrade(int32(index), operationID, tx, closeTime)
+			if err != nil {
+				return nil, err
+			}
+			for _, trade := range trades {
+				records = append(records, Record{Table: "trades", Data: trade})
+			}
+		}
+
+		return records, nil
+	})
+}
+
+// NewDiagnosticEventsProcessor returns a processor for the diagnostic_events table
+func NewDiagnosticEventsProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		events, err, ok := transform.TransformDiagnosticEvent(tx, lcm.LedgerHeaderHistoryEntry(), passphrase)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		records := make([]Record, 0, len(events))
+		for _, event := range events {
+			records = append(records, Record{Table: "diagnostic_events", Data: event})
+		}
+
+		return records, nil
+	})
+}
+
+// NewTxFootprintsProcessor returns a processor for the tx_footprints table
+func NewTxFootprintsProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		footprint, err := transform.TransformTransactionFootprint(tx, lcm.LedgerHeaderHistoryEntry())
+		if err != nil {
+			return nil, err
+		}
+
+		records := make([]Record, 0, len(footprint))
+		for _, key := range footprint {
+			records = append(records, Record{Table: "tx_footprints", Data: key})
+		}
+
+		return records, nil
+	})
+}
+
+// NewParticipantsProcessor returns a processor for the participants table
+func NewParticipantsProcessor(passphrase string) Processor {
+	return transactionProcessor(passphrase, func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		participants, err := transform.TransformParticipants(tx, lcm.LedgerHeaderHistoryEntry())
+		if err != nil {
+			return nil, err
+		}
+
+		records := make([]Record, 0, len(participants))
+		for _, participant := range participants {
+			records = append(records, Record{Table: "participants", Data: participant})
+		}
+
+		return records, nil
+	})
+}
+
+// transactionProcessor returns a processor that calls transformTransaction on every transaction of the ledger
+func transactionProcessor(passphrase string, transformTransaction func(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) ([]Record, error)) Processor {
+	return ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(passphrase, lcm)
+		if err != nil {
+			return nil, fmt.Errorf("could not read transactions of ledger %d: %v", lcm.LedgerSequence(), err)
+		}
+		defer txReader.Close()
+
+		records := []Record{}
+		for {
+			tx, err := txReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("could not read transaction from ledger %d: %v", lcm.LedgerSequence(), err)
+			}
+
+			transformed, err := transformTransaction(tx, lcm)
+			if err != nil {
+				return nil, fmt.Errorf("could not transform transaction %d in ledger %d: %v", tx.Index, lcm.LedgerSequence(), err)
+			}
+			records = append(records, transformed...)
+		}
+
+		return records, nil
+	})
+}
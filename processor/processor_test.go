@@ -0,0 +1,38 @@
+package processor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombine(t *testing.T) {
+	first := ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		return []Record{{Table: "ledgers", Data: 1}}, nil
+	})
+	second := ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		return []Record{{Table: "transactions", Data: 2}, {Table: "transactions", Data: 3}}, nil
+	})
+	failing := ProcessorFunc(func(lcm xdr.LedgerCloseMeta) ([]Record, error) {
+		return nil, fmt.Errorf("failed")
+	})
+
+	records, err := Combine(first, second).ProcessLedger(xdr.LedgerCloseMeta{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Table: "ledgers", Data: 1}, {Table: "transactions", Data: 2}, {Table: "transactions", Data: 3}}, records)
+
+	_, err = Combine(first, failing).ProcessLedger(xdr.LedgerCloseMeta{})
+	assert.EqualError(t, err, "failed")
+}
+
+func TestTableRecord(t *testing.T) {
+	records, err := table("offers").record("offer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Table: "offers", Data: "offer"}}, records)
+
+	records, err = table("offers").record("offer", fmt.Errorf("failed"))
+	assert.EqualError(t, err, "failed")
+	assert.Nil(t, records)
+}